@@ -5,6 +5,7 @@ package api
 
 import (
 	"context"
+	"crypto"
 	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/tls"
@@ -12,6 +13,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -598,6 +600,8 @@ type Client struct {
 	responseCallbacks     []ResponseCallback
 	replicationStateStore *replicationStateStore
 	hcpCookie             *http.Cookie
+	dpopSigner            crypto.Signer
+	dpopNonce             *dpopNonce
 }
 
 // NewClient returns a new client for the given configuration.
@@ -1306,6 +1310,9 @@ func (c *Client) clone(cloneHeaders bool) (*Client, error) {
 
 	if config.CloneToken {
 		client.SetToken(c.token)
+		// A DPoP-bound token is unusable without the key it is bound to
+		client.dpopSigner = c.dpopSigner
+		client.dpopNonce = c.dpopNonce
 	}
 
 	client.replicationStateStore = c.replicationStateStore
@@ -1415,6 +1422,8 @@ func (c *Client) RawRequestWithContext(ctx context.Context, r *Request) (*Respon
 func (c *Client) rawRequestWithContext(ctx context.Context, r *Request) (*Response, error) {
 	c.modifyLock.RLock()
 	token := c.token
+	dpopSigner := c.dpopSigner
+	dpopNonce := c.dpopNonce
 
 	c.config.modifyLock.RLock()
 	limiter := c.config.Limiter
@@ -1460,6 +1469,7 @@ func (c *Client) rawRequestWithContext(ctx context.Context, r *Request) (*Respon
 	}
 
 	redirectCount := 0
+	dpopNonceRetried := false
 START:
 	req, err := r.toRetryableHTTP()
 	if err != nil {
@@ -1469,6 +1479,12 @@ START:
 		return nil, fmt.Errorf("nil request created")
 	}
 
+	if dpopSigner != nil {
+		if err := setDPoPProof(req.Request, dpopSigner, dpopNonce, r.ClientToken); err != nil {
+			return nil, err
+		}
+	}
+
 	if outputCurlString {
 		// Note that although we're building this up here and returning it as an error object, the Error()
 		// interface method on it only gets called in a context where the actual string returned from that
@@ -1517,6 +1533,13 @@ START:
 		ErrorHandler: retryablehttp.PassthroughErrorHandler,
 	}
 
+	if dpopSigner != nil {
+		// Every attempt needs its own proof since Vault rejects replays
+		client.PrepareRetry = func(req *http.Request) error {
+			return setDPoPProof(req, dpopSigner, dpopNonce, r.ClientToken)
+		}
+	}
+
 	var result *Response
 	resp, err := client.Do(req)
 	if resp != nil {
@@ -1529,6 +1552,22 @@ START:
 		return result, err
 	}
 
+	if dpopSigner != nil {
+		dpopNonce.update(resp)
+
+		// Retry once with the nonce Vault requires proofs to carry, unless
+		// the body can't be sent again
+		if dpopNonceRequired(resp) && !dpopNonceRetried && (r.Body == nil || r.BodyBytes != nil) {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if err := r.ResetJSONBody(); err != nil {
+				return result, err
+			}
+			dpopNonceRetried = true
+			goto START
+		}
+	}
+
 	// Check for a redirect, only allowing for a single redirect (if redirects aren't disabled)
 	if (resp.StatusCode == 301 || resp.StatusCode == 302 || resp.StatusCode == 307) && redirectCount == 0 && !disableRedirects {
 		// Parse the updated location
@@ -1583,6 +1622,8 @@ func (c *Client) httpRequestWithContext(ctx context.Context, r *Request) (*Respo
 
 	c.modifyLock.RLock()
 	token := c.token
+	dpopSigner := c.dpopSigner
+	dpopNonce := c.dpopNonce
 
 	c.config.modifyLock.RLock()
 	limiter := c.config.Limiter
@@ -1638,6 +1679,12 @@ func (c *Client) httpRequestWithContext(ctx context.Context, r *Request) (*Respo
 		req.Header.Set("X-Vault-Policy-Override", "true")
 	}
 
+	if dpopSigner != nil {
+		if err := setDPoPProof(req, dpopSigner, dpopNonce, r.ClientToken); err != nil {
+			return nil, err
+		}
+	}
+
 	if limiter != nil {
 		limiter.Wait(ctx)
 	}
@@ -1662,6 +1709,27 @@ func (c *Client) httpRequestWithContext(ctx context.Context, r *Request) (*Respo
 		return result, err
 	}
 
+	if dpopSigner != nil {
+		dpopNonce.update(resp)
+
+		// Retry once with the nonce Vault requires proofs to carry, unless
+		// the body can't be sent again
+		if dpopNonceRequired(resp) && r.Body == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if err := setDPoPProof(req, dpopSigner, dpopNonce, r.ClientToken); err != nil {
+				return nil, err
+			}
+			resp, err = httpClient.Do(req)
+			if resp != nil {
+				result = &Response{Response: resp}
+			}
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
 	// Check for a redirect, only allowing for a single redirect, if redirects aren't disabled
 	if (resp.StatusCode == 301 || resp.StatusCode == 302 || resp.StatusCode == 307) && !disableRedirects {
 		// Parse the updated location
@@ -1683,6 +1751,12 @@ func (c *Client) httpRequestWithContext(ctx context.Context, r *Request) (*Respo
 			return result, fmt.Errorf("redirect failed: %s", err)
 		}
 
+		if dpopSigner != nil {
+			if err := setDPoPProof(req, dpopSigner, dpopNonce, r.ClientToken); err != nil {
+				return result, fmt.Errorf("redirect failed: %s", err)
+			}
+		}
+
 		// Retry the request
 		resp, err = httpClient.Do(req)
 		if err != nil {
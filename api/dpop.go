@@ -0,0 +1,178 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// DPoPHeaderName is the name of the header carrying a DPoP proof JWT as
+// defined by RFC 9449.
+const DPoPHeaderName = "DPoP"
+
+// DPoPNonceHeaderName is the name of the header with which Vault returns the
+// nonce that DPoP proofs must carry.
+const DPoPNonceHeaderName = "DPoP-Nonce"
+
+const dpopProofType = "dpop+jwt"
+
+// SetDPoPSigner configures the client to attach a DPoP proof signed by the
+// given key to every request. Tokens obtained by logging in through such a
+// client are bound to the key's public half, and Vault rejects any request
+// using them that isn't accompanied by a proof signed with the same key.
+// Supported keys are *ecdsa.PrivateKey, *rsa.PrivateKey and
+// ed25519.PrivateKey. Passing nil stops sending proofs.
+func (c *Client) SetDPoPSigner(signer crypto.Signer) error {
+	if signer != nil {
+		if _, err := dpopSigningAlgorithm(signer); err != nil {
+			return err
+		}
+	}
+
+	c.modifyLock.Lock()
+	defer c.modifyLock.Unlock()
+	c.dpopSigner = signer
+	c.dpopNonce = &dpopNonce{}
+	return nil
+}
+
+// DPoPSigner returns the key used to sign DPoP proofs, if any.
+func (c *Client) DPoPSigner() crypto.Signer {
+	c.modifyLock.RLock()
+	defer c.modifyLock.RUnlock()
+	return c.dpopSigner
+}
+
+// dpopNonce is the last nonce returned by Vault, shared by the clones of a
+// client since they sign with the same key.
+type dpopNonce struct {
+	l     sync.RWMutex
+	value string
+}
+
+func (n *dpopNonce) get() string {
+	n.l.RLock()
+	defer n.l.RUnlock()
+	return n.value
+}
+
+// update records the nonce of the response, if any.
+func (n *dpopNonce) update(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	if value := resp.Header.Get(DPoPNonceHeaderName); value != "" {
+		n.l.Lock()
+		defer n.l.Unlock()
+		n.value = value
+	}
+}
+
+// dpopNonceRequired reports whether Vault rejected the proof of the request
+// because it didn't carry a current nonce, in which case the request can be
+// retried with the nonce of the response.
+func dpopNonceRequired(resp *http.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusUnauthorized &&
+		resp.Header.Get(DPoPNonceHeaderName) != "" &&
+		strings.Contains(resp.Header.Get("WWW-Authenticate"), "use_dpop_nonce")
+}
+
+// setDPoPProof signs a fresh DPoP proof for the request and sets it as the
+// DPoP header, replacing any previous proof so that retries and redirects
+// never resend an already used one.
+func setDPoPProof(req *http.Request, signer crypto.Signer, nonce *dpopNonce, token string) error {
+	proof, err := newDPoPProof(signer, req.Method, req.URL, nonce.get(), token)
+	if err != nil {
+		return fmt.Errorf("failed to create DPoP proof: %w", err)
+	}
+	req.Header.Set(DPoPHeaderName, proof)
+	return nil
+}
+
+func newDPoPProof(signer crypto.Signer, method string, u *url.URL, nonce, token string) (string, error) {
+	alg, err := dpopSigningAlgorithm(signer)
+	if err != nil {
+		return "", err
+	}
+
+	joseSigner, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: signer}, &jose.SignerOptions{
+		EmbedJWK: true,
+		ExtraHeaders: map[jose.HeaderKey]interface{}{
+			jose.HeaderType: dpopProofType,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	htu := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   u.Path,
+	}
+
+	claims := struct {
+		jwt.Claims
+		HTTPMethod      string `json:"htm"`
+		HTTPURI         string `json:"htu"`
+		AccessTokenHash string `json:"ath,omitempty"`
+		Nonce           string `json:"nonce,omitempty"`
+	}{
+		Claims: jwt.Claims{
+			ID:       base64.RawURLEncoding.EncodeToString(jti),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+		HTTPMethod: method,
+		HTTPURI:    htu.String(),
+		Nonce:      nonce,
+	}
+	if token != "" {
+		sum := sha256.Sum256([]byte(token))
+		claims.AccessTokenHash = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	return jwt.Signed(joseSigner).Claims(claims).Serialize()
+}
+
+func dpopSigningAlgorithm(signer crypto.Signer) (jose.SignatureAlgorithm, error) {
+	switch key := signer.(type) {
+	case *ecdsa.PrivateKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+		return "", fmt.Errorf("unsupported DPoP key curve %q", key.Curve.Params().Name)
+	case *rsa.PrivateKey:
+		return jose.PS256, nil
+	case ed25519.PrivateKey:
+		return jose.EdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported DPoP key type %T", signer)
+	}
+}
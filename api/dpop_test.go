@@ -0,0 +1,156 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+func TestClientDPoPProof(t *testing.T) {
+	var proofs []string
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		proofs = append(proofs, req.Header.Get(DPoPHeaderName))
+	}
+
+	config, ln := testHTTPServer(t, http.HandlerFunc(handler))
+	defer ln.Close()
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client.SetToken("foo")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetDPoPSigner(key); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.RawRequest(client.NewRequest(http.MethodPut, "/v1/secret/foo")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(proofs) != 2 {
+		t.Fatalf("expected 2 proofs, got %d", len(proofs))
+	}
+
+	var jtis []string
+	for _, proof := range proofs {
+		jws, err := jose.ParseSigned(proof, []jose.SignatureAlgorithm{jose.ES256})
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := jws.Signatures[0].Protected
+		if header.ExtraHeaders[jose.HeaderType] != dpopProofType {
+			t.Fatalf("bad typ header: %v", header.ExtraHeaders[jose.HeaderType])
+		}
+		thumbprint, err := header.JSONWebKey.Thumbprint(crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := (&jose.JSONWebKey{Key: key.Public()}).Thumbprint(crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(thumbprint) != string(expected) {
+			t.Fatal("proof was not signed with the configured key")
+		}
+
+		payload, err := jws.Verify(header.JSONWebKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var claims map[string]interface{}
+		if err := json.Unmarshal(payload, &claims); err != nil {
+			t.Fatal(err)
+		}
+		if claims["htm"] != http.MethodPut {
+			t.Fatalf("bad htm: %v", claims["htm"])
+		}
+		if claims["htu"] != config.Address+"/v1/secret/foo" {
+			t.Fatalf("bad htu: %v", claims["htu"])
+		}
+		sum := sha256.Sum256([]byte("foo"))
+		if claims["ath"] != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			t.Fatalf("bad ath: %v", claims["ath"])
+		}
+		jtis = append(jtis, claims["jti"].(string))
+	}
+	if jtis[0] == jtis[1] {
+		t.Fatal("expected a unique jti per request")
+	}
+
+	if err := client.SetDPoPSigner(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RawRequest(client.NewRequest(http.MethodPut, "/v1/secret/foo")); err != nil {
+		t.Fatal(err)
+	}
+	if proofs[2] != "" {
+		t.Fatal("expected no proof once the signer is cleared")
+	}
+}
+
+func TestClientDPoPNonce(t *testing.T) {
+	var nonces []interface{}
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		jws, err := jose.ParseSigned(req.Header.Get(DPoPHeaderName), []jose.SignatureAlgorithm{jose.ES256})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var claims map[string]interface{}
+		if err := json.Unmarshal(jws.UnsafePayloadWithoutVerification(), &claims); err != nil {
+			t.Fatal(err)
+		}
+		nonces = append(nonces, claims["nonce"])
+
+		w.Header().Set(DPoPNonceHeaderName, "n1")
+		if claims["nonce"] != "n1" {
+			w.Header().Set("WWW-Authenticate", `DPoP error="use_dpop_nonce"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}
+
+	config, ln := testHTTPServer(t, http.HandlerFunc(handler))
+	defer ln.Close()
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client.SetToken("foo")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetDPoPSigner(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first request is retried with the nonce returned by the server,
+	// which the next ones carry from the start
+	for i := 0; i < 2; i++ {
+		if _, err := client.Logical().Write("secret/foo", map[string]interface{}{"a": "b"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(nonces) != 3 || nonces[0] != nil || nonces[1] != "n1" || nonces[2] != "n1" {
+		t.Fatalf("bad nonces: %v", nonces)
+	}
+}
@@ -35,6 +35,8 @@ var sudoPaths = map[string]*regexp.Regexp{
 	// slash DOES require sudo. But the part of the Vault CLI that uses this logic doesn't pass operation-appropriate
 	// trailing slashes, it always strips them off, so we end up giving the wrong answer for one of these.
 	"/sys/leases/lookup/{prefix}":                 regexp.MustCompile(`^/sys/leases/lookup(?:/.+)?$`),
+	"/sys/leases/query":                           regexp.MustCompile(`^/sys/leases/query$`),
+	"/sys/leases/revoke-query":                    regexp.MustCompile(`^/sys/leases/revoke-query$`),
 	"/sys/leases/revoke-force/{prefix}":           regexp.MustCompile(`^/sys/leases/revoke-force/.+$`),
 	"/sys/leases/revoke-prefix/{prefix}":          regexp.MustCompile(`^/sys/leases/revoke-prefix/.+$`),
 	"/sys/plugins/catalog/{name}":                 regexp.MustCompile(`^/sys/plugins/catalog/[^/]+$`),
@@ -52,13 +54,17 @@ var sudoPaths = map[string]*regexp.Regexp{
 	"/identity/entity/merge":                      regexp.MustCompile(`^/identity/entity/merge/?$`),
 
 	// enterprise-only paths
-	"/sys/replication/dr/primary/secondary-token":          regexp.MustCompile(`^/sys/replication/dr/primary/secondary-token$`),
-	"/sys/replication/performance/primary/secondary-token": regexp.MustCompile(`^/sys/replication/performance/primary/secondary-token$`),
-	"/sys/replication/primary/secondary-token":             regexp.MustCompile(`^/sys/replication/primary/secondary-token$`),
-	"/sys/replication/reindex":                             regexp.MustCompile(`^/sys/replication/reindex$`),
-	"/sys/storage/raft/snapshot-auto/config":               regexp.MustCompile(`^/sys/storage/raft/snapshot-auto/config/?$`),
-	"/sys/storage/raft/snapshot-auto/config/{name}":        regexp.MustCompile(`^/sys/storage/raft/snapshot-auto/config/[^/]+$`),
-	"/sys/reporting/scan":                                  regexp.MustCompile(`^/sys/reporting/scan$`),
+	"/sys/replication/dr/primary/secondary-token":           regexp.MustCompile(`^/sys/replication/dr/primary/secondary-token$`),
+	"/sys/replication/performance/primary/secondary-token":  regexp.MustCompile(`^/sys/replication/performance/primary/secondary-token$`),
+	"/sys/replication/primary/secondary-token":              regexp.MustCompile(`^/sys/replication/primary/secondary-token$`),
+	"/sys/replication/reindex":                              regexp.MustCompile(`^/sys/replication/reindex$`),
+	"/sys/storage/raft/snapshot-auto/config":                regexp.MustCompile(`^/sys/storage/raft/snapshot-auto/config/?$`),
+	"/sys/storage/raft/snapshot-auto/config/{name}":         regexp.MustCompile(`^/sys/storage/raft/snapshot-auto/config/[^/]+$`),
+	"/sys/storage/raft/snapshot-load":                       regexp.MustCompile(`^/sys/storage/raft/snapshot-load/?$`),
+	"/sys/storage/raft/snapshot-load/{snapshot_id}":         regexp.MustCompile(`^/sys/storage/raft/snapshot-load/[^/]+$`),
+	"/sys/storage/raft/snapshot-load/{snapshot_id}/diff":    regexp.MustCompile(`^/sys/storage/raft/snapshot-load/[^/]+/diff$`),
+	"/sys/storage/raft/snapshot-load/{snapshot_id}/restore": regexp.MustCompile(`^/sys/storage/raft/snapshot-load/[^/]+/restore$`),
+	"/sys/reporting/scan":                                   regexp.MustCompile(`^/sys/reporting/scan$`),
 
 	// activation-flags paths requiring sudo
 	"/sys/activation-flags/oauth-resource-server/activate":   regexp.MustCompile(`^/sys/activation-flags/oauth-resource-server/activate$`),
@@ -7,6 +7,10 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
 )
 
 func (c *Sys) Renew(id string, increment int) (*Secret, error) {
@@ -164,3 +168,140 @@ type RevokeOptions struct {
 	Prefix  bool
 	Sync    bool
 }
+
+// LeaseQueryInput holds the filters of a lease query. Empty filters match
+// every lease.
+type LeaseQueryInput struct {
+	EntityID               string `json:"entity_id,omitempty"`
+	AuthAccessor           string `json:"auth_accessor,omitempty"`
+	Role                   string `json:"role,omitempty"`
+	Mount                  string `json:"mount,omitempty"`
+	OlderThan              string `json:"older_than,omitempty"`
+	ExpiringBefore         string `json:"expiring_before,omitempty"`
+	IncludeChildNamespaces bool   `json:"include_child_namespaces,omitempty"`
+
+	// After and Limit paginate the results of QueryLeases, and are ignored
+	// by RevokeLeasesByQuery.
+	After string `json:"-"`
+	Limit int    `json:"-"`
+}
+
+type LeaseQueryOutput struct {
+	LeaseCount int                `mapstructure:"lease_count"`
+	Leases     []*LeaseQueryEntry `mapstructure:"leases"`
+	NextAfter  string             `mapstructure:"next_after"`
+}
+
+type LeaseQueryEntry struct {
+	LeaseID      string    `mapstructure:"lease_id"`
+	Namespace    string    `mapstructure:"namespace"`
+	EntityID     string    `mapstructure:"entity_id"`
+	AuthAccessor string    `mapstructure:"auth_accessor"`
+	Role         string    `mapstructure:"role"`
+	IssueTime    time.Time `mapstructure:"issue_time"`
+	ExpireTime   time.Time `mapstructure:"expire_time"`
+	Irrevocable  bool      `mapstructure:"irrevocable"`
+}
+
+func (c *Sys) QueryLeases(input *LeaseQueryInput) (*LeaseQueryOutput, error) {
+	return c.QueryLeasesWithContext(context.Background(), input)
+}
+
+func (c *Sys) QueryLeasesWithContext(ctx context.Context, input *LeaseQueryInput) (*LeaseQueryOutput, error) {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	if input == nil {
+		input = &LeaseQueryInput{}
+	}
+
+	r := c.c.NewRequest(http.MethodGet, "/v1/sys/leases/query")
+	for name, value := range map[string]string{
+		"entity_id":       input.EntityID,
+		"auth_accessor":   input.AuthAccessor,
+		"role":            input.Role,
+		"mount":           input.Mount,
+		"older_than":      input.OlderThan,
+		"expiring_before": input.ExpiringBefore,
+		"after":           input.After,
+	} {
+		if value != "" {
+			r.Params.Set(name, value)
+		}
+	}
+	if input.IncludeChildNamespaces {
+		r.Params.Set("include_child_namespaces", "true")
+	}
+	if input.Limit > 0 {
+		r.Params.Set("limit", strconv.Itoa(input.Limit))
+	}
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result LeaseQueryOutput
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeHookFunc(time.RFC3339),
+		Result:     &result,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Decode(secret.Data); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeLeasesByQuery queues the revocation of the leases matching the query
+// and returns the number of leases queued.
+func (c *Sys) RevokeLeasesByQuery(input *LeaseQueryInput) (int, error) {
+	return c.RevokeLeasesByQueryWithContext(context.Background(), input)
+}
+
+func (c *Sys) RevokeLeasesByQueryWithContext(ctx context.Context, input *LeaseQueryInput) (int, error) {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	if input == nil {
+		return 0, errors.New("nil query provided")
+	}
+
+	r := c.c.NewRequest(http.MethodPut, "/v1/sys/leases/revoke-query")
+	if err := r.SetJSONBody(input); err != nil {
+		return 0, err
+	}
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if secret == nil || secret.Data == nil {
+		return 0, errors.New("data from server response is empty")
+	}
+
+	var result struct {
+		LeaseCount int `mapstructure:"lease_count"`
+	}
+	if err := mapstructure.Decode(secret.Data, &result); err != nil {
+		return 0, err
+	}
+	return result.LeaseCount, nil
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 )
@@ -128,6 +129,165 @@ func (c *Sys) DeletePolicyWithContext(ctx context.Context, name string) error {
 	return err
 }
 
+// PolicyVersion is a version from the history of an ACL policy.
+type PolicyVersion struct {
+	Version         int       `mapstructure:"version"`
+	Policy          string    `mapstructure:"policy"`
+	Deleted         bool      `mapstructure:"deleted"`
+	CreatedTime     time.Time `mapstructure:"created_time"`
+	EntityID        string    `mapstructure:"entity_id"`
+	Accessor        string    `mapstructure:"accessor"`
+	RestoredVersion int       `mapstructure:"restored_version"`
+	Diff            string    `mapstructure:"diff"`
+}
+
+// PolicyVersionsOutput is the version history of an ACL policy, oldest version
+// first.
+type PolicyVersionsOutput struct {
+	Name          string           `mapstructure:"name"`
+	LatestVersion int              `mapstructure:"latest_version"`
+	Versions      []*PolicyVersion `mapstructure:"versions"`
+}
+
+func (c *Sys) PolicyVersions(name string) (*PolicyVersionsOutput, error) {
+	return c.PolicyVersionsWithContext(context.Background(), name)
+}
+
+// PolicyVersionsWithContext returns the version history of the named ACL
+// policy, or nil if it has none.
+func (c *Sys) PolicyVersionsWithContext(ctx context.Context, name string) (*PolicyVersionsOutput, error) {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	r := c.c.NewRequest(http.MethodGet, fmt.Sprintf("/v1/sys/policies/acl/%s/versions", name))
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if resp != nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result PolicyVersionsOutput
+	if err := decodePolicyVersion(secret.Data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Sys) RollbackPolicy(name string, version int) (*PolicyVersion, error) {
+	return c.RollbackPolicyWithContext(context.Background(), name, version)
+}
+
+// RollbackPolicyWithContext rolls the named ACL policy back to a version from
+// its history, and returns the version recorded for the rollback.
+func (c *Sys) RollbackPolicyWithContext(ctx context.Context, name string, version int) (*PolicyVersion, error) {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	r := c.c.NewRequest(http.MethodPut, fmt.Sprintf("/v1/sys/policies/acl/%s/rollback", name))
+	if err := r.SetJSONBody(map[string]interface{}{"version": version}); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result PolicyVersion
+	if err := decodePolicyVersion(secret.Data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// LintPolicyInput is the ACL policy to lint, given by its rules or the name of
+// an existing policy.
+type LintPolicyInput struct {
+	Policy      string `json:"policy,omitempty"`
+	Name        string `json:"name,omitempty"`
+	CheckMounts bool   `json:"check_mounts"`
+}
+
+// PolicyLintFinding is an issue found in a path rule of an ACL policy.
+type PolicyLintFinding struct {
+	Check    string `json:"check" mapstructure:"check"`
+	Severity string `json:"severity" mapstructure:"severity"`
+	Rule     string `json:"rule" mapstructure:"rule"`
+	Message  string `json:"message" mapstructure:"message"`
+}
+
+func (c *Sys) LintPolicy(input *LintPolicyInput) ([]*PolicyLintFinding, error) {
+	return c.LintPolicyWithContext(context.Background(), input)
+}
+
+// LintPolicyWithContext statically analyzes an ACL policy on the server,
+// against the mounts of the namespace of the client if CheckMounts is set.
+func (c *Sys) LintPolicyWithContext(ctx context.Context, input *LintPolicyInput) ([]*PolicyLintFinding, error) {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	r := c.c.NewRequest(http.MethodPut, "/v1/sys/policies/lint")
+	if err := r.SetJSONBody(input); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result struct {
+		Findings []*PolicyLintFinding `mapstructure:"findings"`
+	}
+	if err := mapstructure.Decode(secret.Data, &result); err != nil {
+		return nil, err
+	}
+	return result.Findings, nil
+}
+
+func decodePolicyVersion(data map[string]interface{}, result interface{}) error {
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeHookFunc(time.RFC3339),
+		Result:     result,
+	})
+	if err != nil {
+		return err
+	}
+	return d.Decode(data)
+}
+
 type getPoliciesResp struct {
 	Rules string `json:"rules"`
 }
@@ -198,6 +198,18 @@ separated by a dash (-) instead of a dot (.) to allow usage in ACL templates.`,
 				},
 			},
 
+			"bind_token_to_certificate": {
+				Type:    framework.TypeBool,
+				Default: false,
+				Description: `If set, tokens issued by this role are bound to the thumbprint of the
+client certificate used to log in. Requests made with such a token must be sent
+over a TLS connection presenting the same client certificate.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Group:       "Tokens",
+					Description: "If set, tokens issued by this role are bound to the client certificate used to log in and can only be used over a TLS connection presenting that certificate.",
+				},
+			},
+
 			"display_name": {
 				Type: framework.TypeString,
 				Description: `The display name to use for clients using this
@@ -333,6 +345,7 @@ func (b *backend) pathCertRead(ctx context.Context, req *logical.Request, d *fra
 		"ocsp_query_all_servers":       cert.OcspQueryAllServers,
 		"ocsp_this_update_max_age":     int64(cert.OcspThisUpdateMaxAge.Seconds()),
 		"ocsp_max_retries":             cert.OcspMaxRetries,
+		"bind_token_to_certificate":    cert.BindTokenToCertificate,
 	}
 	cert.PopulateTokenData(data)
 
@@ -436,6 +449,10 @@ func (b *backend) pathCertWrite(ctx context.Context, req *logical.Request, d *fr
 		cert.AllowedMetadataExtensions = allowedMetadataExtensionsRaw.([]string)
 	}
 
+	if bindTokenRaw, ok := d.GetOk("bind_token_to_certificate"); ok {
+		cert.BindTokenToCertificate = bindTokenRaw.(bool)
+	}
+
 	// Get tokenutil fields
 	if err := cert.ParseTokenFields(req, d); err != nil {
 		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
@@ -489,6 +506,9 @@ func (b *backend) pathCertWrite(ctx context.Context, req *logical.Request, d *fr
 	if cert.TokenMaxTTL != 0 && cert.TokenTTL > cert.TokenMaxTTL {
 		return logical.ErrorResponse("ttl should be shorter than max_ttl"), nil
 	}
+	if cert.BindTokenToCertificate && cert.TokenType == logical.TokenTypeBatch {
+		return logical.ErrorResponse("bind_token_to_certificate is not supported with batch tokens"), nil
+	}
 	if cert.TokenPeriod > systemMaxTTL {
 		resp.AddWarning(fmt.Sprintf("Given period of %d seconds is greater than the backend's maximum TTL of %d seconds", cert.TokenPeriod/time.Second, systemMaxTTL/time.Second))
 	}
@@ -553,6 +573,7 @@ type CertEntry struct {
 	RequiredExtensions         []string
 	AllowedMetadataExtensions  []string
 	BoundCIDRs                 []*sockaddr.SockAddrMarshaler
+	BindTokenToCertificate     bool
 
 	OcspCaCertificates   string
 	OcspEnabled          bool
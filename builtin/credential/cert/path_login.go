@@ -193,6 +193,10 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, data *fra
 
 	matched.Entry.PopulateTokenAuth(auth)
 
+	if matched.Entry.BindTokenToCertificate {
+		auth.BoundCertificateThumbprint = certutil.GetCertificateThumbprint(clientCerts[0])
+	}
+
 	return &logical.Response{
 		Auth: auth,
 	}, nil
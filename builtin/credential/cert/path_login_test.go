@@ -507,3 +507,66 @@ func TestCert_MetadataOnFailure(t *testing.T) {
 		},
 	})
 }
+
+func TestCert_BindTokenToCertificate(t *testing.T) {
+	certTemplate := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: "example.com",
+		},
+		DNSNames:    []string{"example.com"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth,
+			x509.ExtKeyUsageClientAuth,
+		},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement,
+		SerialNumber: big.NewInt(mathrand.Int63()),
+		NotBefore:    time.Now().Add(-30 * time.Second),
+		NotAfter:     time.Now().Add(262980 * time.Hour),
+	}
+
+	tempDir, connState, err := generateTestCertAndConnState(t, certTemplate, nil)
+	if tempDir != "" {
+		defer os.RemoveAll(tempDir)
+	}
+	if err != nil {
+		t.Fatalf("error testing connection state: %v", err)
+	}
+	ca, err := ioutil.ReadFile(filepath.Join(tempDir, "ca_cert.pem"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	expected := certutil.GetCertificateThumbprint(connState.PeerCertificates[0])
+
+	logicaltest.Test(t, logicaltest.TestCase{
+		CredentialBackend: testFactory(t),
+		Steps: []logicaltest.TestStep{
+			testAccStepCert(t, "web", ca, "foo", allowed{dns: "example.com"}, false),
+			testAccStepLoginCheckThumbprint(t, connState, ""),
+			testAccStepCertWithExtraParams(t, "web", ca, "foo", allowed{dns: "example.com"}, false, map[string]interface{}{
+				"bind_token_to_certificate": true,
+			}),
+			testAccStepLoginCheckThumbprint(t, connState, expected),
+			testAccStepCertWithExtraParams(t, "web", ca, "foo", allowed{dns: "example.com"}, true, map[string]interface{}{
+				"bind_token_to_certificate": true,
+				"token_type":                "batch",
+			}),
+		},
+	})
+}
+
+func testAccStepLoginCheckThumbprint(t *testing.T, connState tls.ConnectionState, thumbprint string) logicaltest.TestStep {
+	return logicaltest.TestStep{
+		Operation:       logical.UpdateOperation,
+		Path:            "login",
+		Unauthenticated: true,
+		ConnState:       &connState,
+		Check: func(resp *logical.Response) error {
+			if resp.Auth.BoundCertificateThumbprint != thumbprint {
+				t.Fatalf("expected bound certificate thumbprint %q, got %q", thumbprint, resp.Auth.BoundCertificateThumbprint)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package kerberos
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	configPath     = "config"
+	ldapConfigPath = "config/ldap"
+
+	operationPrefixKerberos = "kerberos"
+)
+
+func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := Backend()
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func Backend() *backend {
+	b := &backend{}
+
+	b.Backend = &framework.Backend{
+		Help:        backendHelp,
+		BackendType: logical.TypeCredential,
+
+		PathsSpecial: &logical.Paths{
+			Unauthenticated: []string{
+				"login",
+			},
+			SealWrapStorage: []string{
+				configPath,
+				ldapConfigPath,
+			},
+		},
+
+		Paths: []*framework.Path{
+			pathConfig(b),
+			pathConfigLDAP(b),
+			pathGroupsList(b),
+			pathGroups(b),
+			pathLogin(b),
+		},
+	}
+
+	return b
+}
+
+type backend struct {
+	*framework.Backend
+}
+
+const backendHelp = `
+The Kerberos credential provider allows authentication using Kerberos
+SPNEGO, as used by browsers and Windows clients through the
+"Authorization: Negotiate" header.
+
+The service keytab is configured with the "config" endpoint. After
+authenticating, the user's groups are looked up in the LDAP directory
+configured with "config/ldap", and mapped to policies with the "groups/"
+endpoints.
+`
@@ -0,0 +1,248 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package kerberos
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/jcmturner/gofork/encoding/asn1"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/nametype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/test/testdata"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/stretchr/testify/require"
+)
+
+func createBackendWithStorage(t *testing.T) (*backend, logical.Storage) {
+	t.Helper()
+
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend()
+	require.NoError(t, b.Setup(context.Background(), config))
+	return b, config.StorageView
+}
+
+func testServiceKeytab(t *testing.T) (*keytab.Keytab, string) {
+	t.Helper()
+
+	raw, err := hex.DecodeString(testdata.HTTP_KEYTAB)
+	require.NoError(t, err)
+	kt := keytab.New()
+	require.NoError(t, kt.Unmarshal(raw))
+	return kt, base64.StdEncoding.EncodeToString(raw)
+}
+
+// testSPNEGOToken forges a SPNEGO token for testuser1 with a service ticket
+// encrypted directly with the service keytab, so no KDC is needed.
+func testSPNEGOToken(t *testing.T, serviceKeytab *keytab.Keytab) string {
+	t.Helper()
+
+	raw, err := hex.DecodeString(testdata.KEYTAB_TESTUSER1_TEST_GOKRB5)
+	require.NoError(t, err)
+	userKeytab := keytab.New()
+	require.NoError(t, userKeytab.Unmarshal(raw))
+	krb5Conf, err := config.NewFromString(testdata.KRB5_CONF)
+	require.NoError(t, err)
+	cl := client.NewWithKeytab("testuser1", "TEST.GOKRB5", userKeytab, krb5Conf)
+
+	sname := types.PrincipalName{
+		NameType:   nametype.KRB_NT_PRINCIPAL,
+		NameString: []string{"HTTP", "host.test.gokrb5"},
+	}
+	now := time.Now().UTC()
+	tkt, sessionKey, err := messages.NewTicket(cl.Credentials.CName(), cl.Credentials.Domain(), sname, "TEST.GOKRB5",
+		types.NewKrbFlags(), serviceKeytab, 18, 1, now, now, now.Add(time.Hour), now.Add(2*time.Hour))
+	require.NoError(t, err)
+
+	krb5Token, err := spnego.NewKRB5TokenAPREQ(cl, tkt, sessionKey, []int{gssapi.ContextFlagInteg, gssapi.ContextFlagConf}, []int{})
+	require.NoError(t, err)
+	mechToken, err := krb5Token.Marshal()
+	require.NoError(t, err)
+
+	token := spnego.SPNEGOToken{
+		Init: true,
+		NegTokenInit: spnego.NegTokenInit{
+			MechTypes:      []asn1.ObjectIdentifier{gssapi.OIDKRB5.OID()},
+			MechTokenBytes: mechToken,
+		},
+	}
+	marshalled, err := token.Marshal()
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(marshalled)
+}
+
+func TestKerberos_Config(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+	_, encodedKeytab := testServiceKeytab(t)
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"keytab":          "not a keytab",
+			"service_account": "HTTP/host.test.gokrb5",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"keytab":          encodedKeytab,
+			"service_account": "HTTP/host.test.gokrb5",
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "config",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/host.test.gokrb5", resp.Data["service_account"])
+	require.NotContains(t, resp.Data, "keytab")
+}
+
+func TestKerberos_Login(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+	ctx := context.Background()
+	serviceKeytab, encodedKeytab := testServiceKeytab(t)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"keytab":          encodedKeytab,
+			"service_account": "HTTP/host.test.gokrb5",
+		},
+	})
+	require.NoError(t, err)
+
+	// The upndomain deliberately doesn't match the realm, so that a login
+	// with a valid SPNEGO token fails before any LDAP server is contacted.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config/ldap",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"url":       "ldap://127.0.0.1:1",
+			"upndomain": "OTHER.REALM",
+		},
+	})
+	require.NoError(t, err)
+
+	login := func(spnegoToken string) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation:   logical.UpdateOperation,
+			Path:        "login",
+			Storage:     storage,
+			Connection:  &logical.Connection{RemoteAddr: "127.0.0.1"},
+			SPNEGOToken: spnegoToken,
+		})
+	}
+
+	// Without a token, the client is challenged to negotiate
+	resp, err := login("")
+	require.Error(t, err)
+	require.Equal(t, []string{"Negotiate"}, resp.Headers["WWW-Authenticate"])
+
+	// A token that doesn't decode is rejected
+	resp, err = login(base64.StdEncoding.EncodeToString([]byte("garbage")))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.Data[logical.HTTPStatusCode])
+
+	// A valid token authenticates testuser1, whose realm is then checked
+	// against the LDAP configuration
+	resp, err = login(testSPNEGOToken(t, serviceKeytab))
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), `identity domain of "TEST.GOKRB5" doesn't match`)
+}
+
+// TestKerberos_PluginStorageCompatibility verifies that mounts created with
+// the external vault-plugin-auth-kerberos plugin keep working, by loading
+// storage entries in the format the plugin wrote them.
+func TestKerberos_PluginStorageCompatibility(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+	ctx := context.Background()
+	serviceKeytab, encodedKeytab := testServiceKeytab(t)
+
+	entries := map[string]string{
+		"config": `{"keytab":"` + encodedKeytab + `","service_account":"HTTP/host.test.gokrb5","add_group_aliases":true,"remove_instance_name":false}`,
+		// The realm is stored in lower case, which must still match
+		"config/ldap":  `{"token_policies":["kerberos"],"token_ttl":3600000000000,"url":"ldap://127.0.0.1:1","userdn":"ou=users,dc=test,dc=gokrb5","userattr":"samaccountname","groupdn":"ou=groups,dc=test,dc=gokrb5","upndomain":"test.gokrb5"}`,
+		"group/admins": `{"policies":["admin"]}`,
+	}
+	for key, value := range entries {
+		require.NoError(t, storage.Put(ctx, &logical.StorageEntry{Key: key, Value: []byte(value)}))
+	}
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "config",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/host.test.gokrb5", resp.Data["service_account"])
+	require.Equal(t, true, resp.Data["add_group_aliases"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "config/ldap",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ldap://127.0.0.1:1", resp.Data["url"])
+	require.Equal(t, "test.gokrb5", resp.Data["upndomain"])
+	require.Equal(t, []string{"kerberos"}, resp.Data["token_policies"])
+	require.Equal(t, int64(3600), resp.Data["token_ttl"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "groups/",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"admins"}, resp.Data["keys"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "groups/admins",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"admin"}, resp.Data["policies"])
+
+	// The realm check passes despite the case difference, and the login
+	// fails looking up the groups in the unreachable LDAP server
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.UpdateOperation,
+		Path:        "login",
+		Storage:     storage,
+		Connection:  &logical.Connection{RemoteAddr: "127.0.0.1"},
+		SPNEGOToken: testSPNEGOToken(t, serviceKeytab),
+	})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "doesn't match")
+}
@@ -0,0 +1,197 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package kerberos
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+type CLIHandler struct{}
+
+func (h *CLIHandler) Auth(c *api.Client, m map[string]string) (*api.Secret, error) {
+	mount, ok := m["mount"]
+	if !ok {
+		mount = "kerberos"
+	}
+
+	loginCfg := &LoginCfg{
+		Username:     m["username"],
+		Service:      m["service"],
+		Realm:        m["realm"],
+		KeytabPath:   m["keytab_path"],
+		Krb5ConfPath: m["krb5conf_path"],
+	}
+	for key, value := range map[string]string{
+		"username":      loginCfg.Username,
+		"service":       loginCfg.Service,
+		"realm":         loginCfg.Realm,
+		"keytab_path":   loginCfg.KeytabPath,
+		"krb5conf_path": loginCfg.Krb5ConfPath,
+	} {
+		if value == "" {
+			return nil, fmt.Errorf("%q is required", key)
+		}
+	}
+
+	if raw := m["disable_fast_negotiation"]; raw != "" {
+		disableFAST, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for disable_fast_negotiation, must be \"true\" or \"false\"", raw)
+		}
+		loginCfg.DisableFASTNegotiation = disableFAST
+	}
+	if raw := m["remove_instance_name"]; raw != "" {
+		removeInstanceName, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for remove_instance_name, must be \"true\" or \"false\"", raw)
+		}
+		loginCfg.RemoveInstanceName = removeInstanceName
+	}
+
+	authHeaderVal, err := GetAuthHeaderVal(loginCfg)
+	if err != nil {
+		return nil, err
+	}
+	c.AddHeader(spnego.HTTPHeaderAuthRequest, authHeaderVal)
+
+	path := fmt.Sprintf("auth/%s/login", mount)
+	secret, err := c.Logical().Write(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, errors.New("empty response from credential provider")
+	}
+
+	return secret, nil
+}
+
+func (h *CLIHandler) Help() string {
+	help := `
+Usage: vault login -method=kerberos [CONFIG K=V...]
+
+  The Kerberos auth method allows users to authenticate using Kerberos
+  SPNEGO, with policies assigned based on their LDAP groups.
+
+  Authenticate using an entry of a keytab:
+
+      $ vault login -method=kerberos \
+            username=grace \
+            service="HTTP/vault.matrix.lan" \
+            realm=MATRIX.LAN \
+            keytab_path=/etc/krb5/krb5.keytab \
+            krb5conf_path=/etc/krb5.conf
+
+Configuration:
+
+  mount=<string>
+      Path where the Kerberos credential method is mounted. This is usually
+      provided via the -path flag in the "vault login" command, but it can be
+      specified here as well. If specified here, it takes precedence over the
+      value for -path. The default value is "kerberos".
+
+  username=<string>
+      The username of the keytab entry to log in to Kerberos with.
+
+  service=<string>
+      The service principal name to request a service ticket for.
+
+  realm=<string>
+      The name of the Kerberos realm.
+
+  keytab_path=<string>
+      The path to the keytab containing the entry to authenticate with.
+
+  krb5conf_path=<string>
+      The path to a krb5.conf file describing the Kerberos environment.
+
+  disable_fast_negotiation=<bool>
+      Disables the FAST pre-authentication framework, which some Kerberos
+      implementations don't support.
+
+  remove_instance_name=<bool>
+      Strips instance names from the principal names in the keytab.
+`
+
+	return strings.TrimSpace(help)
+}
+
+// LoginCfg holds the settings needed to obtain a SPNEGO token for logging in
+// to Vault. The fields are named to avoid mixing up the many string settings.
+type LoginCfg struct {
+	Username, Service, Realm, KeytabPath, Krb5ConfPath string
+
+	// DisableFASTNegotiation disables the FAST pre-authentication
+	// framework, which some common Kerberos implementations don't support.
+	DisableFASTNegotiation bool
+
+	// RemoveInstanceName strips the instance, usually a host name, from
+	// the principal names in the keytab.
+	RemoveInstanceName bool
+}
+
+// GetAuthHeaderVal logs in to Kerberos with the given config and returns the
+// value of the Authorization header to send to Vault's login endpoint.
+func GetAuthHeaderVal(loginCfg *LoginCfg) (string, error) {
+	kt, err := keytab.Load(loginCfg.KeytabPath)
+	if err != nil {
+		return "", fmt.Errorf("couldn't load keytab: %w", err)
+	}
+	if loginCfg.RemoveInstanceName {
+		removeInstanceNameFromKeytab(kt)
+	}
+
+	krb5Conf, err := config.Load(loginCfg.Krb5ConfPath)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse krb5Conf: %w", err)
+	}
+
+	settings := []func(*client.Settings){
+		client.AssumePreAuthentication(true),
+	}
+	if loginCfg.DisableFASTNegotiation {
+		settings = append(settings, client.DisablePAFXFAST(true))
+	}
+
+	cl := client.NewWithKeytab(loginCfg.Username, loginCfg.Realm, kt, krb5Conf, settings...)
+	if err := cl.Login(); err != nil {
+		return "", fmt.Errorf("couldn't log in: %w", err)
+	}
+	defer cl.Destroy()
+
+	spnegoClient := spnego.SPNEGOClient(cl, loginCfg.Service)
+	if err := spnegoClient.AcquireCred(); err != nil {
+		return "", fmt.Errorf("couldn't acquire client credential: %w", err)
+	}
+	spnegoToken, err := spnegoClient.InitSecContext()
+	if err != nil {
+		return "", fmt.Errorf("couldn't initialize context: %w", err)
+	}
+	marshalledToken, err := spnegoToken.Marshal()
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal SPNEGO: %w", err)
+	}
+
+	return negotiateScheme + " " + base64.StdEncoding.EncodeToString(marshalledToken), nil
+}
+
+func removeInstanceNameFromKeytab(kt *keytab.Keytab) {
+	for i := range kt.Entries {
+		principal := &kt.Entries[i].Principal
+		if user, _, ok := strings.Cut(principal.String(), "/"); ok {
+			principal.Components = []string{user}
+			principal.NumComponents = 1
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package kerberos
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+)
+
+func pathConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: configPath + "$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixKerberos,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"keytab": {
+				Type:        framework.TypeString,
+				Description: "Base64 encoded keytab of the service account.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+			"service_account": {
+				Type:        framework.TypeString,
+				Description: "The service principal, from the keytab, that clients request tickets for.",
+			},
+			"add_group_aliases": {
+				Type:        framework.TypeBool,
+				Description: "If set, the LDAP groups of the user are returned as group aliases.",
+			},
+			"remove_instance_name": {
+				Type:        framework.TypeBool,
+				Description: "If set, the instance or host part of principal names is removed before looking up users.",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "configuration",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "configure",
+				},
+			},
+		},
+
+		HelpSynopsis:    pathConfigHelpSyn,
+		HelpDescription: pathConfigHelpDesc,
+	}
+}
+
+func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	// The keytab is intentionally not returned because it is sensitive.
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"service_account":      config.ServiceAccount,
+			"add_group_aliases":    config.AddGroupAliases,
+			"remove_instance_name": config.RemoveInstanceName,
+		},
+	}, nil
+}
+
+func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &kerberosConfig{}
+	}
+
+	if keytabRaw, ok := data.GetOk("keytab"); ok {
+		config.Keytab = keytabRaw.(string)
+	}
+	if config.Keytab == "" {
+		return logical.ErrorResponse("keytab is required"), nil
+	}
+	if _, err := parseKeytab(config.Keytab); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("invalid keytab: %s", err)), nil
+	}
+
+	if serviceAccountRaw, ok := data.GetOk("service_account"); ok {
+		config.ServiceAccount = serviceAccountRaw.(string)
+	}
+	if config.ServiceAccount == "" {
+		return logical.ErrorResponse("service_account is required"), nil
+	}
+
+	if addGroupAliasesRaw, ok := data.GetOk("add_group_aliases"); ok {
+		config.AddGroupAliases = addGroupAliasesRaw.(bool)
+	}
+	if removeInstanceNameRaw, ok := data.GetOk("remove_instance_name"); ok {
+		config.RemoveInstanceName = removeInstanceNameRaw.(bool)
+	}
+
+	entry, err := logical.StorageEntryJSON(configPath, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) config(ctx context.Context, s logical.Storage) (*kerberosConfig, error) {
+	entry, err := s.Get(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	config := new(kerberosConfig)
+	if err := entry.DecodeJSON(config); err != nil {
+		return nil, fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// kerberosConfig is stored in the same format as the external Kerberos
+// plugin so that existing mounts keep working with the builtin.
+type kerberosConfig struct {
+	Keytab             string `json:"keytab"`
+	ServiceAccount     string `json:"service_account"`
+	AddGroupAliases    bool   `json:"add_group_aliases"`
+	RemoveInstanceName bool   `json:"remove_instance_name"`
+}
+
+func parseKeytab(b64EncodedKeytab string) (*keytab.Keytab, error) {
+	decoded, err := base64.StdEncoding.DecodeString(b64EncodedKeytab)
+	if err != nil {
+		return nil, err
+	}
+	kt := new(keytab.Keytab)
+	if err := kt.Unmarshal(decoded); err != nil {
+		return nil, err
+	}
+	return kt, nil
+}
+
+const pathConfigHelpSyn = `
+Configure the Kerberos keytab and service account.
+`
+
+const pathConfigHelpDesc = `
+The keytab must be base64 encoded, for example with the output of
+"base64 vault.keytab". It must contain a key for service_account, which is
+the service principal clients request tickets for, usually of the form
+"HTTP/vault.example.com".
+`
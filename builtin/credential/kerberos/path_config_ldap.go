@@ -0,0 +1,133 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package kerberos
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/helper/tokenutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathConfigLDAP(b *backend) *framework.Path {
+	p := &framework.Path{
+		Pattern: ldapConfigPath + "$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixKerberos,
+		},
+
+		Fields: ldaputil.ConfigFields(),
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigLDAPRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "ldap-configuration",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigLDAPWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "ldap",
+				},
+			},
+		},
+
+		HelpSynopsis:    pathConfigLDAPHelpSyn,
+		HelpDescription: pathConfigLDAPHelpDesc,
+	}
+
+	tokenutil.AddTokenFields(p.Fields)
+	p.Fields["token_policies"].Description += ". This will apply to all tokens generated by this auth method, in addition to any configured for specific groups."
+	return p
+}
+
+func (b *backend) pathConfigLDAPRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.configLDAP(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+
+	data := cfg.PasswordlessMap()
+	cfg.PopulateTokenData(data)
+
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+func (b *backend) pathConfigLDAPWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.configLDAP(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = new(ldapConfigEntry)
+	}
+
+	newConfigEntry, err := ldaputil.NewConfigEntry(cfg.ConfigEntry, d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	cfg.ConfigEntry = newConfigEntry
+
+	if err := cfg.ParseTokenFields(req, d); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	entry, err := logical.StorageEntryJSON(ldapConfigPath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) configLDAP(ctx context.Context, s logical.Storage) (*ldapConfigEntry, error) {
+	entry, err := s.Get(ctx, ldapConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	cfg := new(ldapConfigEntry)
+	if err := entry.DecodeJSON(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+type ldapConfigEntry struct {
+	tokenutil.TokenParams
+	*ldaputil.ConfigEntry
+}
+
+const pathConfigLDAPHelpSyn = `
+Configure the LDAP server used to look up the groups of Kerberos users.
+`
+
+const pathConfigLDAPHelpDesc = `
+After a user has authenticated with Kerberos, its groups are looked up in
+this LDAP server, using the same options as the LDAP auth method. If
+upndomain is set, it must match the realm of the Kerberos principal, ignoring
+case.
+
+The LDAP URL can use either the "ldap://" or "ldaps://" schema. In the former
+case, an unencrypted connection will be made with a default port of 389, unless
+the "starttls" parameter is set to true, in which case TLS will be used. In the
+latter case, a SSL connection will be established with a default port of 636.
+`
@@ -0,0 +1,315 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package kerberos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/cidrutil"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/jcmturner/goidentity/v6"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/service"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+const negotiateScheme = "Negotiate"
+
+func pathLogin(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "login$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixKerberos,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"authorization": {
+				Type:        framework.TypeString,
+				Description: `SPNEGO Authorization header value, of the form "Negotiate <token>". Only needed if the client cannot send the header itself.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathLoginChallenge,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "login2",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathLogin,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "login",
+				},
+			},
+		},
+
+		HelpSynopsis:    pathLoginHelpSyn,
+		HelpDescription: pathLoginHelpDesc,
+	}
+}
+
+// pathLoginChallenge asks the client to start SPNEGO negotiation, as a
+// browser or curl --negotiate would expect.
+func (b *backend) pathLoginChallenge(context.Context, *logical.Request, *framework.FieldData) (*logical.Response, error) {
+	return &logical.Response{
+		Auth: &logical.Auth{},
+		Headers: map[string][]string{
+			"WWW-Authenticate": {negotiateScheme},
+		},
+	}, logical.CodedError(http.StatusUnauthorized, "authentication required")
+}
+
+// spnegoToken returns the SPNEGO token of the request. The HTTP layer
+// extracts it from the Authorization header; the header itself is only
+// available to the backend if it was configured as a passthrough header,
+// and the authorization field exists for clients that can't set headers.
+func spnegoToken(req *logical.Request, d *framework.FieldData) string {
+	if req.SPNEGOToken != "" {
+		return req.SPNEGOToken
+	}
+
+	values := append([]string{}, req.Headers["Authorization"]...)
+	values = append(values, d.Get("authorization").(string))
+	for _, v := range values {
+		scheme, token, ok := strings.Cut(v, " ")
+		if ok && scheme == negotiateScheme {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return logical.ErrorResponse("kerberos backend not configured"), nil
+	}
+
+	ldapCfg, err := b.configLDAP(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if ldapCfg == nil {
+		return logical.ErrorResponse("ldap backend not configured"), nil
+	}
+
+	if len(ldapCfg.TokenBoundCIDRs) > 0 {
+		if req.Connection == nil {
+			b.Logger().Warn("token bound CIDRs found but no connection information available for validation")
+			return nil, logical.ErrPermissionDenied
+		}
+		if !cidrutil.RemoteAddrIsOk(req.Connection.RemoteAddr, ldapCfg.TokenBoundCIDRs) {
+			return nil, logical.ErrPermissionDenied
+		}
+	}
+
+	token := spnegoToken(req, d)
+	if token == "" {
+		return b.pathLoginChallenge(ctx, req, d)
+	}
+
+	kt, err := parseKeytab(config.Keytab)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse keytab: %w", err)
+	}
+	if config.RemoveInstanceName {
+		removeInstanceNameFromKeytab(kt)
+	}
+
+	remoteAddr := ""
+	if req.Connection != nil {
+		remoteAddr = req.Connection.RemoteAddr
+	}
+	identity, failure := b.acceptSPNEGO(kt, config.ServiceAccount, token, remoteAddr)
+	if identity == nil {
+		return logical.RespondWithStatusCode(&logical.Response{
+			Warnings: []string{failure},
+		}, req, http.StatusUnauthorized)
+	}
+
+	username := identity.UserName()
+	if config.RemoveInstanceName {
+		username, _, _ = strings.Cut(username, "/")
+	}
+
+	// Verify that the realm on the LDAP config, if set, is the same as the
+	// identity's. This prevents users of one realm from picking up group
+	// memberships, and therefore policies, from an unrelated directory.
+	if ldapCfg.UPNDomain != "" && !strings.EqualFold(identity.Domain(), ldapCfg.UPNDomain) {
+		return logical.ErrorResponse(fmt.Sprintf("identity domain of %q doesn't match LDAP upndomain of %q", identity.Domain(), ldapCfg.UPNDomain)), nil
+	}
+
+	ldapGroups, err := b.ldapGroups(ldapCfg, username)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []string
+	for _, groupName := range ldapGroups {
+		group, err := b.group(ctx, req.Storage, groupName)
+		if err != nil {
+			b.Logger().Debug("unable to retrieve group", "group", groupName, "error", err)
+			continue
+		}
+		if group == nil {
+			continue
+		}
+		policies = append(policies, group.Policies...)
+	}
+
+	auth := &logical.Auth{
+		Metadata: map[string]string{
+			"user":   identity.UserName(),
+			"domain": identity.Domain(),
+		},
+		DisplayName: identity.UserName(),
+		Alias: &logical.Alias{
+			Name: identity.UserName(),
+		},
+	}
+	ldapCfg.PopulateTokenAuth(auth)
+
+	// Kerberos tokens can't be renewed because the SPNEGO token isn't kept
+	// around to authenticate the user again.
+	auth.LeaseOptions.Renewable = false
+
+	auth.Policies = strutil.RemoveDuplicates(append(auth.Policies, policies...), true)
+
+	if config.AddGroupAliases {
+		for _, groupName := range ldapGroups {
+			if groupName == "" {
+				continue
+			}
+			auth.GroupAliases = append(auth.GroupAliases, &logical.Alias{
+				Name: groupName,
+			})
+		}
+	}
+
+	return &logical.Response{
+		Auth: auth,
+	}, nil
+}
+
+// acceptSPNEGO validates the SPNEGO token against the keytab and returns the
+// authenticated identity, or nil and the reason the token was rejected.
+func (b *backend) acceptSPNEGO(kt *keytab.Keytab, servicePrincipal, token, remoteAddr string) (goidentity.Identity, string) {
+	var identity goidentity.Identity
+	inner := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		identity = goidentity.FromHTTPRequestContext(r)
+	})
+
+	l := b.Logger().StandardLogger(&hclog.StandardLoggerOptions{
+		InferLevels: true,
+	})
+	handler := spnego.SPNEGOKRB5Authenticate(inner, kt, service.Logger(l), service.KeytabPrincipal(servicePrincipal))
+
+	// The SPNEGO handler only looks at the Authorization header and at the
+	// remote address, which it needs in host:port form.
+	r := &http.Request{
+		Header: http.Header{
+			spnego.HTTPHeaderAuthRequest: []string{negotiateScheme + " " + token},
+		},
+		RemoteAddr: net.JoinHostPort(remoteAddr, "0"),
+	}
+	w := &bufferedResponseWriter{header: make(http.Header)}
+	handler.ServeHTTP(w, r)
+
+	if identity == nil {
+		failure := strings.TrimSpace(string(w.body))
+		if failure == "" {
+			failure = "SPNEGO authentication failed"
+		}
+		return nil, failure
+	}
+	return identity, ""
+}
+
+// ldapGroups returns the LDAP groups of the user, searching as the configured
+// bind DN since the user's password is not known.
+func (b *backend) ldapGroups(cfg *ldapConfigEntry, username string) ([]string, error) {
+	ldapClient := ldaputil.Client{
+		Logger: b.Logger(),
+		LDAP:   ldaputil.NewLDAP(),
+	}
+
+	conn, err := ldapClient.DialLDAP(cfg.ConfigEntry)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to LDAP: %w", err)
+	}
+	if conn == nil {
+		return nil, errors.New("invalid connection returned from LDAP dial")
+	}
+	defer conn.Close()
+
+	if cfg.BindPassword != "" {
+		err = conn.Bind(cfg.BindDN, cfg.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind(cfg.BindDN)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("LDAP bind failed: %w", err)
+	}
+
+	userBindDN, err := ldapClient.GetUserBindDN(cfg.ConfigEntry, conn, username)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get user binddn: %w", err)
+	}
+	userDN, err := ldapClient.GetUserDN(cfg.ConfigEntry, conn, userBindDN, username)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get user dn: %w", err)
+	}
+
+	groups, err := ldapClient.GetLdapGroups(cfg.ConfigEntry, conn, userDN, username)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get ldap groups: %w", err)
+	}
+	b.Logger().Debug("groups fetched from server", "num_server_groups", len(groups), "server_groups", groups)
+
+	return groups, nil
+}
+
+type bufferedResponseWriter struct {
+	header     http.Header
+	body       []byte
+	statusCode int
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	w.body = append(w.body, b...)
+	return len(b), nil
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+const pathLoginHelpSyn = `
+Log in with a Kerberos SPNEGO token.
+`
+
+const pathLoginHelpDesc = `
+The SPNEGO token is read from the "Authorization: Negotiate <token>" header
+of the request, or from the authorization field if the client can't send
+the header. A read of this endpoint, or a login without a token, responds
+with a 401 and a "WWW-Authenticate: Negotiate" challenge.
+`
@@ -5,9 +5,11 @@ package ldap
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	goldap "github.com/go-ldap/ldap/v3"
 	"github.com/hashicorp/cap/ldap"
@@ -15,6 +17,7 @@ import (
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/ldaputil"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/patrickmn/go-cache"
 )
 
 const (
@@ -53,14 +56,18 @@ func Backend() *backend {
 			pathUsers(&b),
 			pathUsersList(&b),
 			pathLogin(&b),
+			pathLoginDebug(&b),
 			pathConfigRotateRoot(&b),
 		},
 
 		AuthRenew:        b.pathLoginRenew,
+		Invalidate:       b.invalidate,
 		BackendType:      logical.TypeCredential,
 		RotateCredential: b.rotateRootCredential,
 	}
 
+	b.groupCache = cache.New(cache.NoExpiration, time.Minute)
+
 	return &b
 }
 
@@ -68,6 +75,17 @@ type backend struct {
 	*framework.Backend
 
 	mu sync.RWMutex
+
+	// groupCache holds the LDAP groups resolved for a username, with the
+	// expiration configured by group_cache_ttl
+	groupCache *cache.Cache
+}
+
+func (b *backend) invalidate(_ context.Context, key string) {
+	switch key {
+	case "config":
+		b.groupCache.Flush()
+	}
 }
 
 func (b *backend) maybeLogDebug(msg string, args ...interface{}) {
@@ -100,7 +118,22 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 	// Clean connection
 	defer ldapClient.Close(ctx)
 
-	c, err := ldapClient.Authenticate(ctx, username, password, ldap.WithGroups(), ldap.WithUserAttributes())
+	cs := cfg.CaseSensitiveNames != nil && *cfg.CaseSensitiveNames
+
+	groupCacheKey := username
+	if !cs {
+		groupCacheKey = strings.ToLower(groupCacheKey)
+	}
+	cachedGroups, groupsCached := b.cachedLDAPGroups(cfg, groupCacheKey)
+
+	// Groups are only queried as part of authentication when they are neither
+	// cached nor resolved separately to follow nested memberships
+	authOpts := []ldap.Option{ldap.WithUserAttributes()}
+	if !groupsCached && cfg.NestedGroupDepth == 0 {
+		authOpts = append(authOpts, ldap.WithGroups())
+	}
+
+	c, err := ldapClient.Authenticate(ctx, username, password, authOpts...)
 	if err != nil {
 		if strings.Contains(err.Error(), "discovery of user bind DN failed") ||
 			strings.Contains(err.Error(), "unable to bind user") {
@@ -117,6 +150,20 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 	}
 
 	ldapGroups := c.Groups
+	switch {
+	case groupsCached:
+		b.maybeLogDebug("using cached LDAP groups", "username", username)
+		ldapGroups = cachedGroups
+	case cfg.NestedGroupDepth > 0:
+		ldapGroups, err = b.resolveNestedLDAPGroups(cfg, c.UserDN, username, password)
+		if err != nil {
+			return "", nil, logical.ErrorResponse(err.Error()), nil, nil
+		}
+	}
+	if !groupsCached && cfg.GroupCacheTTL > 0 {
+		b.groupCache.Set(groupCacheKey, ldapGroups, cfg.GroupCacheTTL)
+	}
+
 	ldapResponse := &logical.Response{
 		Data: map[string]interface{}{},
 	}
@@ -132,14 +179,45 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 		b.maybeLogDebug(string(warning))
 	}
 
+	canonicalUsername, err := b.canonicalUsername(cfg, c.UserDN, username, usernameAsAlias)
+	if err != nil {
+		return "", nil, logical.ErrorResponse(err.Error()), nil, nil
+	}
+
+	allGroups, policies := b.groupsAndPolicies(ctx, req.Storage, cs, canonicalUsername, ldapGroups)
+
+	if usernameAsAlias {
+		b.maybeLogDebug("UsernameAlias is set", "canonicalUsername", canonicalUsername, "policies", policies, "groups", allGroups)
+		return canonicalUsername, policies, ldapResponse, allGroups, nil
+	}
+
+	userAttrValues := c.UserAttributes[cfg.UserAttr]
+	if len(userAttrValues) == 0 {
+		b.Logger().Error("missing entity alias attribute value")
+		return "", nil, logical.ErrorResponse("missing entity alias attribute value"), nil, nil
+	}
+
+	effectiveUsername := userAttrValues[0]
+	if effectiveUsername == "" {
+		b.Logger().Error("empty entity alias attribute value")
+		return "", nil, logical.ErrorResponse("empty entity alias attribute value"), nil, nil
+	}
+
+	return effectiveUsername, policies, ldapResponse, allGroups, nil
+}
+
+// canonicalUsername returns the name used to look up the local user and, when
+// usernameAsAlias is set, to name the entity alias of the user with the given
+// DN.
+func (b *backend) canonicalUsername(cfg *ldapConfigEntry, userDN, username string, usernameAsAlias bool) (string, error) {
 	canonicalUsername := username
 	if usernameAsAlias {
 		// expect to get the username from UserDN in the case where we are setting the
 		// entity alias to be the username.
-		parsed, err := goldap.ParseDN(c.UserDN)
+		parsed, err := goldap.ParseDN(userDN)
 		if err != nil {
-			b.Logger().Error("Invalid DN after authentication", "user_dn", c.UserDN, "error", err)
-			return "", nil, logical.ErrorResponse("invalid DN after authentication"), nil, nil
+			b.Logger().Error("Invalid DN after authentication", "user_dn", userDN, "error", err)
+			return "", errors.New("invalid DN after authentication")
 		}
 
 		b.maybeLogDebug("User DN parsed", "parsed", parsed, "username", username)
@@ -161,15 +239,20 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 		}
 	}
 
-	var allGroups []string
-
-	cs := cfg.CaseSensitiveNames != nil && *cfg.CaseSensitiveNames
-	if !cs {
+	if cfg.CaseSensitiveNames == nil || !*cfg.CaseSensitiveNames {
 		canonicalUsername = strings.ToLower(canonicalUsername)
 	}
+	return canonicalUsername, nil
+}
+
+// groupsAndPolicies merges the locally configured groups of the user with
+// the given LDAP groups, and returns them along with the policies attached
+// to the user and to each of those groups.
+func (b *backend) groupsAndPolicies(ctx context.Context, s logical.Storage, caseSensitive bool, canonicalUsername string, ldapGroups []string) ([]string, []string) {
+	var allGroups []string
 
 	// Import the custom added groups from ldap backend
-	user, err := b.User(ctx, req.Storage, canonicalUsername)
+	user, err := b.User(ctx, s, canonicalUsername)
 	if err == nil && user != nil && user.Groups != nil {
 		allGroups = append(allGroups, user.Groups...)
 	}
@@ -178,7 +261,7 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 
 	canonicalGroups := allGroups
 	// If not case-sensitive, lowercase all
-	if !cs {
+	if !caseSensitive {
 		canonicalGroups = make([]string, len(allGroups))
 		for i, v := range allGroups {
 			canonicalGroups[i] = strings.ToLower(v)
@@ -188,7 +271,7 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 	// Retrieve policies
 	var policies []string
 	for _, groupName := range canonicalGroups {
-		group, err := b.Group(ctx, req.Storage, groupName)
+		group, err := b.Group(ctx, s, groupName)
 		if err == nil && group != nil {
 			policies = append(policies, group.Policies...)
 		}
@@ -199,24 +282,50 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 	// Policies from each group may overlap
 	policies = strutil.RemoveDuplicates(policies, true)
 
-	if usernameAsAlias {
-		b.maybeLogDebug("UsernameAlias is set", "canonicalUsername", canonicalUsername, "policies", policies, "groups", allGroups)
-		return canonicalUsername, policies, ldapResponse, allGroups, nil
+	return allGroups, policies
+}
+
+// cachedLDAPGroups returns the LDAP groups cached for the given username, if
+// group caching is enabled.
+func (b *backend) cachedLDAPGroups(cfg *ldapConfigEntry, key string) ([]string, bool) {
+	if cfg.GroupCacheTTL <= 0 {
+		return nil, false
+	}
+	groups, ok := b.groupCache.Get(key)
+	if !ok {
+		return nil, false
 	}
+	return groups.([]string), true
+}
 
-	userAttrValues := c.UserAttributes[cfg.UserAttr]
-	if len(userAttrValues) == 0 {
-		b.Logger().Error("missing entity alias attribute value")
-		return "", nil, logical.ErrorResponse("missing entity alias attribute value"), nil, nil
+// resolveNestedLDAPGroups resolves the direct and nested LDAP groups of the
+// user with the given DN. The search is performed as the configured bind DN
+// when set, and otherwise as the user with the given password.
+func (b *backend) resolveNestedLDAPGroups(cfg *ldapConfigEntry, userDN, username, password string) ([]string, error) {
+	client := ldaputil.Client{
+		Logger: b.Logger(),
+		LDAP:   ldaputil.NewLDAP(),
 	}
 
-	effectiveUsername := userAttrValues[0]
-	if effectiveUsername == "" {
-		b.Logger().Error("empty entity alias attribute value")
-		return "", nil, logical.ErrorResponse("empty entity alias attribute value"), nil, nil
+	conn, err := client.DialLDAP(cfg.ConfigEntry)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	switch {
+	case cfg.BindDN != "" && cfg.BindPassword != "":
+		err = conn.Bind(cfg.BindDN, cfg.BindPassword)
+	case password != "":
+		err = conn.Bind(userDN, password)
+	default:
+		err = conn.UnauthenticatedBind(cfg.BindDN)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("LDAP bind for group search failed: %w", err)
 	}
 
-	return effectiveUsername, policies, ldapResponse, allGroups, nil
+	return client.GetNestedLdapGroups(cfg.ConfigEntry, conn, userDN, username, cfg.NestedGroupFilter, cfg.NestedGroupDepth)
 }
 
 const backendHelp = `
@@ -1575,3 +1575,194 @@ func TestLdapAuthBackend_ConfigUpgrade(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func testLdapAuthBackendRequest(t *testing.T, b *backend, storage logical.Storage, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation:  op,
+		Path:       path,
+		Data:       data,
+		Storage:    storage,
+		Connection: &logical.Connection{},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("%s %s: err:%v resp:%#v", op, path, err, resp)
+	}
+	return resp
+}
+
+// TestLdapAuthBackend_LoginDebug verifies that login-debug resolves the DN,
+// LDAP groups, local groups and policies of a user without authenticating.
+func TestLdapAuthBackend_LoginDebug(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	cleanup, cfg := docker.PrepareLDAPTestContainer(t, docker.DefaultLDAPVersion)
+	defer cleanup()
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "config", map[string]interface{}{
+		"url":            cfg.Url,
+		"userattr":       cfg.UserAttr,
+		"userdn":         cfg.UserDN,
+		"groupdn":        cfg.GroupDN,
+		"groupattr":      cfg.GroupAttr,
+		"binddn":         cfg.BindDN,
+		"bindpass":       cfg.BindPassword,
+		"token_policies": "configpolicy",
+	})
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "groups/admin_staff", map[string]interface{}{
+		"policies": "adminpolicy",
+	})
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "groups/engineers", map[string]interface{}{
+		"policies": "grouppolicy",
+	})
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "users/hermes conrad", map[string]interface{}{
+		"groups": "engineers",
+	})
+
+	resp := testLdapAuthBackendRequest(t, b, storage, logical.ReadOperation, "login-debug/hermes conrad", nil)
+	if userDN := resp.Data["user_dn"].(string); !strings.HasPrefix(strings.ToLower(userDN), "cn=hermes conrad,") {
+		t.Fatalf("bad user_dn: %q", userDN)
+	}
+	if !strutil.StrListContains(resp.Data["ldap_groups"].([]string), "admin_staff") {
+		t.Fatalf("expected admin_staff in ldap_groups: %v", resp.Data["ldap_groups"])
+	}
+	if diff := deep.Equal([]string{"engineers"}, resp.Data["local_groups"]); diff != nil {
+		t.Fatal(diff)
+	}
+	policies := resp.Data["policies"].([]string)
+	for _, policy := range []string{"adminpolicy", "configpolicy", "default", "grouppolicy"} {
+		if !strutil.StrListContains(policies, policy) {
+			t.Fatalf("expected %q in policies: %v", policy, policies)
+		}
+	}
+	if alias := resp.Data["alias"].(string); !strings.EqualFold(alias, "hermes conrad") {
+		t.Fatalf("bad alias: %q", alias)
+	}
+
+	// With username_as_alias, the alias is the canonical name from the DN
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "config", map[string]interface{}{
+		"username_as_alias": true,
+	})
+	resp = testLdapAuthBackendRequest(t, b, storage, logical.ReadOperation, "login-debug/Hermes Conrad", nil)
+	if alias := resp.Data["alias"].(string); alias != "hermes conrad" {
+		t.Fatalf("bad alias: %q", alias)
+	}
+
+	// Users that don't exist in the directory are reported as such
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "login-debug/nobody",
+		Storage:   storage,
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error response, got err:%v resp:%#v", err, resp)
+	}
+}
+
+// TestLdapAuthBackend_LoginDebug_RequiresBindDN verifies that login-debug
+// refuses to search the directory without a bind DN.
+func TestLdapAuthBackend_LoginDebug_RequiresBindDN(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "config", map[string]interface{}{
+		"url": "ldap://127.0.0.1",
+	})
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "login-debug/hermes conrad",
+		Storage:   storage,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || !resp.IsError() || !strings.Contains(resp.Error().Error(), "binddn and bindpass") {
+		t.Fatalf("expected a binddn error, got: %#v", resp)
+	}
+}
+
+// TestLdapAuthBackend_GroupCache verifies that the LDAP groups of a user are
+// cached for group_cache_ttl, and that the cache is flushed when the config
+// changes.
+func TestLdapAuthBackend_GroupCache(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	cleanup, cfg := docker.PrepareLDAPTestContainer(t, docker.DefaultLDAPVersion)
+	defer cleanup()
+	configData := map[string]interface{}{
+		"url":             cfg.Url,
+		"userattr":        cfg.UserAttr,
+		"userdn":          cfg.UserDN,
+		"groupdn":         cfg.GroupDN,
+		"groupattr":       cfg.GroupAttr,
+		"binddn":          cfg.BindDN,
+		"bindpass":        cfg.BindPassword,
+		"group_cache_ttl": "1m",
+	}
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "config", configData)
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "groups/admin_staff", map[string]interface{}{
+		"policies": "adminpolicy",
+	})
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "groups/cachedgroup", map[string]interface{}{
+		"policies": "cachedpolicy",
+	})
+
+	login := func() []string {
+		t.Helper()
+		resp := testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "login/hermes conrad", map[string]interface{}{
+			"password": "hermes",
+		})
+		return resp.Auth.Policies
+	}
+
+	if policies := login(); !strutil.StrListContains(policies, "adminpolicy") {
+		t.Fatalf("expected adminpolicy: %v", policies)
+	}
+	groups, ok := b.groupCache.Get("hermes conrad")
+	if !ok || !strutil.StrListContains(groups.([]string), "admin_staff") {
+		t.Fatalf("expected the groups to be cached, got: %v", groups)
+	}
+
+	// Logins use the cached groups rather than the directory
+	b.groupCache.Set("hermes conrad", []string{"cachedgroup"}, time.Minute)
+	if policies := login(); !strutil.StrListContains(policies, "cachedpolicy") || strutil.StrListContains(policies, "adminpolicy") {
+		t.Fatalf("expected the cached groups to be used: %v", policies)
+	}
+
+	// Updating the config flushes the cache
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "config", configData)
+	if policies := login(); !strutil.StrListContains(policies, "adminpolicy") || strutil.StrListContains(policies, "cachedpolicy") {
+		t.Fatalf("expected the groups to be resolved again: %v", policies)
+	}
+}
+
+// TestLdapAuthBackend_GroupCacheDisabled verifies that cached groups are
+// ignored unless group_cache_ttl is set, and are flushed when the config is
+// invalidated.
+func TestLdapAuthBackend_GroupCacheDisabled(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+	ctx := context.Background()
+
+	testLdapAuthBackendRequest(t, b, storage, logical.UpdateOperation, "config", map[string]interface{}{
+		"url": "ldap://127.0.0.1",
+	})
+	cfg, err := b.Config(ctx, &logical.Request{Storage: storage})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.groupCache.Set("hermes conrad", []string{"cachedgroup"}, time.Minute)
+	if _, ok := b.cachedLDAPGroups(cfg, "hermes conrad"); ok {
+		t.Fatal("expected cached groups to be ignored without group_cache_ttl")
+	}
+
+	cfg.GroupCacheTTL = time.Minute
+	groups, ok := b.cachedLDAPGroups(cfg, "hermes conrad")
+	if !ok || !reflect.DeepEqual(groups, []string{"cachedgroup"}) {
+		t.Fatalf("expected cached groups, got: %v", groups)
+	}
+
+	b.invalidate(ctx, "config")
+	if _, ok := b.cachedLDAPGroups(cfg, "hermes conrad"); ok {
+		t.Fatal("expected the cache to be flushed on invalidation")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
@@ -73,6 +74,24 @@ func pathConfig(b *backend) *framework.Path {
 		Required:    false,
 	}
 
+	p.Fields["nested_group_depth"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: "Maximum number of group-in-group levels to follow when resolving the groups of a user. Requires binddn and bindpass, or a user that can search groups. Set to 0 to disable nested group resolution.",
+		Default:     0,
+	}
+
+	p.Fields["nested_group_filter"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Go template used to find the groups containing a group when resolving nested groups. The DN of the group being expanded is available as {{.GroupDN}}.",
+		Default:     ldaputil.DefaultNestedGroupFilter,
+	}
+
+	p.Fields["group_cache_ttl"] = &framework.FieldSchema{
+		Type:        framework.TypeDurationSecond,
+		Description: "Duration for which the LDAP groups resolved for a user are cached and reused on subsequent logins. Set to 0 to disable caching.",
+		Default:     0,
+	}
+
 	return p
 }
 
@@ -111,7 +130,7 @@ func (b *backend) Config(ctx context.Context, req *logical.Request) (*ldapConfig
 		result.UsePre111GroupCNBehavior = new(bool)
 		*result.UsePre111GroupCNBehavior = false
 
-		return &ldapConfigEntry{ConfigEntry: result}, nil
+		return &ldapConfigEntry{ConfigEntry: result, NestedGroupFilter: ldaputil.DefaultNestedGroupFilter}, nil
 	}
 
 	// Deserialize stored configuration.
@@ -136,6 +155,10 @@ func (b *backend) Config(ctx context.Context, req *logical.Request) (*ldapConfig
 		persistNeeded = true
 	}
 
+	if result.NestedGroupFilter == "" {
+		result.NestedGroupFilter = ldaputil.DefaultNestedGroupFilter
+	}
+
 	// Upgrade path: Set default schema for configs created before schema field was added
 	if result.Schema == "" {
 		result.Schema = ldaputil.SchemaOpenLDAP
@@ -173,6 +196,9 @@ func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, d *f
 
 	data["password_policy"] = cfg.PasswordPolicy
 	data[rootRotationUrlKey] = cfg.RotationUrl
+	data["nested_group_depth"] = cfg.NestedGroupDepth
+	data["nested_group_filter"] = cfg.NestedGroupFilter
+	data["group_cache_ttl"] = int64(cfg.GroupCacheTTL.Seconds())
 
 	resp := &logical.Response{
 		Data: data,
@@ -252,6 +278,24 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 	if rotationUrl, ok := d.GetOk(rootRotationUrlKey); ok {
 		cfg.RotationUrl = rotationUrl.(string)
 	}
+	if nestedGroupDepth, ok := d.GetOk("nested_group_depth"); ok {
+		cfg.NestedGroupDepth = nestedGroupDepth.(int)
+		if cfg.NestedGroupDepth < 0 {
+			return logical.ErrorResponse("nested_group_depth cannot be negative"), nil
+		}
+	}
+	if nestedGroupFilter, ok := d.GetOk("nested_group_filter"); ok {
+		cfg.NestedGroupFilter = nestedGroupFilter.(string)
+	}
+	if cfg.NestedGroupFilter == "" {
+		cfg.NestedGroupFilter = ldaputil.DefaultNestedGroupFilter
+	}
+	if groupCacheTTL, ok := d.GetOk("group_cache_ttl"); ok {
+		cfg.GroupCacheTTL = time.Duration(groupCacheTTL.(int)) * time.Second
+		if cfg.GroupCacheTTL < 0 {
+			return logical.ErrorResponse("group_cache_ttl cannot be negative"), nil
+		}
+	}
 
 	var rotOp string
 	if cfg.ShouldDeregisterRotationJob() {
@@ -308,6 +352,9 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 		return nil, wrappedError
 	}
 
+	// Cached memberships may have been resolved with different settings
+	b.groupCache.Flush()
+
 	if warnings := b.checkConfigUserFilter(cfg); len(warnings) > 0 {
 		return &logical.Response{
 			Warnings: warnings,
@@ -342,8 +389,11 @@ type ldapConfigEntry struct {
 	*ldaputil.ConfigEntry
 	automatedrotationutil.AutomatedRotationParams
 
-	PasswordPolicy string `json:"password_policy"`
-	RotationUrl    string `json:"rotation_url"`
+	PasswordPolicy    string        `json:"password_policy"`
+	RotationUrl       string        `json:"rotation_url"`
+	NestedGroupDepth  int           `json:"nested_group_depth"`
+	NestedGroupFilter string        `json:"nested_group_filter"`
+	GroupCacheTTL     time.Duration `json:"group_cache_ttl"`
 }
 
 const pathConfigHelpSyn = `
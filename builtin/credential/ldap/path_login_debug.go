@@ -0,0 +1,138 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ldap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/helper/policyutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathLoginDebug(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: `login-debug/(?P<username>.+)`,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixLDAP,
+			OperationVerb:   "debug-login",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username to resolve as if it were logging in.",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathLoginDebugRead,
+			},
+		},
+
+		HelpSynopsis:    pathLoginDebugSyn,
+		HelpDescription: pathLoginDebugDesc,
+	}
+}
+
+func (b *backend) pathLoginDebugRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	username := d.Get("username").(string)
+	if username == "" {
+		return logical.ErrorResponse("missing username"), nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	cfg, err := b.Config(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return logical.ErrorResponse("auth method not configured"), nil
+	}
+	if cfg.BindDN == "" || cfg.BindPassword == "" {
+		return logical.ErrorResponse("binddn and bindpass must be configured to resolve users without authenticating"), nil
+	}
+
+	client := ldaputil.Client{
+		Logger: b.Logger(),
+		LDAP:   ldaputil.NewLDAP(),
+	}
+
+	conn, err := client.DialLDAP(cfg.ConfigEntry)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	defer conn.Close()
+
+	// GetUserBindDN performs its search bound as the configured binddn
+	bindDN, err := client.GetUserBindDN(cfg.ConfigEntry, conn, username)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	userDN, err := client.GetUserDN(cfg.ConfigEntry, conn, bindDN, username)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	ldapGroups, err := client.GetNestedLdapGroups(cfg.ConfigEntry, conn, userDN, username, cfg.NestedGroupFilter, cfg.NestedGroupDepth)
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("failed to resolve LDAP groups: %s", err)), nil
+	}
+
+	cs := cfg.CaseSensitiveNames != nil && *cfg.CaseSensitiveNames
+	canonicalUsername, err := b.canonicalUsername(cfg, userDN, username, cfg.UsernameAsAlias)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	// The alias is named the same way as at login
+	alias := canonicalUsername
+	if !cfg.UsernameAsAlias {
+		alias, err = client.GetUserAliasAttributeValue(cfg.ConfigEntry, conn, username)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	_, groupPolicies := b.groupsAndPolicies(ctx, req.Storage, cs, canonicalUsername, ldapGroups)
+
+	var localGroups []string
+	if user, err := b.User(ctx, req.Storage, canonicalUsername); err == nil && user != nil {
+		localGroups = user.Groups
+	}
+
+	allPolicies := append(append([]string{}, cfg.TokenPolicies...), groupPolicies...)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"alias":           alias,
+			"bind_dn":         bindDN,
+			"user_dn":         userDN,
+			"ldap_groups":     ldapGroups,
+			"local_groups":    localGroups,
+			"mapped_policies": groupPolicies,
+			"policies":        policyutil.SanitizePolicies(allPolicies, !cfg.TokenNoDefaultPolicy),
+		},
+	}, nil
+}
+
+const pathLoginDebugSyn = `
+Show how a user would be resolved at login, without authenticating.
+`
+
+const pathLoginDebugDesc = `
+This endpoint looks up the given user in the directory using the configured
+binddn and bindpass, and returns the DN that was found, the name of the entity
+alias a login would use (taking username_as_alias into account), the LDAP
+groups that were resolved for it (including nested groups when
+nested_group_depth is set), the locally configured groups of the user, and the
+policies that a token issued at login would carry. The group cache is neither
+read nor updated.
+`
@@ -0,0 +1,135 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package oauth2
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	cache "github.com/patrickmn/go-cache"
+)
+
+const operationPrefixOAuth2 = "oauth2"
+
+func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := Backend()
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func Backend() *backend {
+	b := &backend{
+		introspectionCache: cache.New(cache.NoExpiration, time.Minute),
+	}
+
+	b.Backend = &framework.Backend{
+		Help:        backendHelp,
+		BackendType: logical.TypeCredential,
+
+		PathsSpecial: &logical.Paths{
+			Unauthenticated: []string{
+				"login",
+			},
+			SealWrapStorage: []string{
+				"config",
+			},
+		},
+
+		Paths: []*framework.Path{
+			pathConfig(b),
+			pathRoleList(b),
+			pathRole(b),
+			pathLogin(b),
+		},
+
+		AuthRenew:  b.pathLoginRenew,
+		Invalidate: b.invalidate,
+	}
+
+	return b
+}
+
+type backend struct {
+	*framework.Backend
+
+	l sync.RWMutex
+
+	// httpClient is built from the stored config on first use and
+	// discarded whenever the config changes.
+	httpClient *http.Client
+
+	// introspectionCache holds introspection results keyed by a hash of the
+	// introspected token. Inactive results are cached as well so that
+	// repeated attempts with a revoked or garbage token don't reach the
+	// authorization server.
+	introspectionCache *cache.Cache
+}
+
+func (b *backend) invalidate(_ context.Context, key string) {
+	switch key {
+	case "config":
+		b.reset()
+	}
+}
+
+// reset drops the cached HTTP client and all cached introspection results.
+func (b *backend) reset() {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	b.httpClient = nil
+	b.introspectionCache.Flush()
+}
+
+func (b *backend) getClient(config *oauth2Config) (*http.Client, error) {
+	b.l.RLock()
+	client := b.httpClient
+	b.l.RUnlock()
+	if client != nil {
+		return client, nil
+	}
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if b.httpClient != nil {
+		return b.httpClient, nil
+	}
+
+	client = cleanhttp.DefaultPooledClient()
+	client.Timeout = 30 * time.Second
+	if config.IntrospectionCAPEM != "" {
+		certPool := x509.NewCertPool()
+		if ok := certPool.AppendCertsFromPEM([]byte(config.IntrospectionCAPEM)); !ok {
+			return nil, errors.New("could not parse introspection_ca_pem")
+		}
+		client.Transport.(*http.Transport).TLSClientConfig = &tls.Config{
+			RootCAs: certPool,
+		}
+	}
+
+	b.httpClient = client
+	return client, nil
+}
+
+const backendHelp = `
+The OAuth2 credential provider allows authentication using opaque OAuth2
+access tokens. Tokens are validated against the token introspection endpoint
+(RFC 7662) of the authorization server that issued them, and the returned
+scope, client_id, sub and other claims are matched against the constraints
+of the role being logged in to.
+
+Configure the introspection endpoint with the "config" endpoint, create
+roles with the "role/" endpoints, and log in with the "login" endpoint.
+`
@@ -0,0 +1,290 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func createBackendWithStorage(t *testing.T) (*backend, logical.Storage) {
+	t.Helper()
+
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend()
+	require.NoError(t, b.Setup(context.Background(), config))
+	return b, config.StorageView
+}
+
+// testIntrospectionServer serves introspection responses for the given
+// tokens and reports every other token as inactive.
+func testIntrospectionServer(t *testing.T, tokens map[string]map[string]interface{}) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "vault" || clientSecret != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		resp, ok := tokens[r.PostForm.Get("token")]
+		if !ok {
+			resp = map[string]interface{}{"active": false}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func TestOAuth2_Login(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+	ctx := context.Background()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	srv, calls := testIntrospectionServer(t, map[string]map[string]interface{}{
+		"good": {
+			"active":    true,
+			"scope":     "read write",
+			"client_id": "partner-app",
+			"sub":       "alice",
+			"exp":       exp,
+			"groups":    []string{"eng", "ops"},
+		},
+		"expiring": {
+			"active":    true,
+			"scope":     "write",
+			"client_id": "partner-app",
+			"sub":       "carol",
+			"exp":       time.Now().Add(500 * time.Millisecond).Unix(),
+			"groups":    []string{"ops"},
+		},
+		"wrong-scope": {
+			"active":    true,
+			"scope":     "read",
+			"client_id": "partner-app",
+			"sub":       "bob",
+			"exp":       exp,
+		},
+	})
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"introspection_url": srv.URL,
+			"client_id":         "vault",
+			"client_secret":     "s3cr3t",
+			"auth_metadata":     "client_id,sub,scope",
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "config",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, resp.Data, "client_secret")
+	require.Equal(t, int64(60), resp.Data["cache_ttl"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "role/partner",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"bound_scopes":     "write",
+			"bound_client_ids": "partner-app",
+			"bound_claims": map[string]interface{}{
+				"groups": []interface{}{"ops", "admins"},
+			},
+			"token_policies": "partner",
+			"token_ttl":      "2h",
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	login := func(token string) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.UpdateOperation,
+			Path:       "login",
+			Storage:    storage,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+			Data: map[string]interface{}{
+				"role":  "partner",
+				"token": token,
+			},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp = login("good")
+	require.False(t, resp.IsError(), resp.Error())
+	require.Equal(t, "alice", resp.Auth.Alias.Name)
+	require.Equal(t, []string{"partner"}, resp.Auth.Policies)
+	require.Equal(t, map[string]string{
+		"client_id": "partner-app",
+		"sub":       "alice",
+		"scope":     "read write",
+	}, resp.Auth.Alias.Metadata)
+	require.LessOrEqual(t, resp.Auth.TTL, time.Hour, "token TTL should be capped at the access token's expiration")
+	require.NotContains(t, resp.Auth.InternalData, "token", "the access token should not be stored")
+
+	require.LessOrEqual(t, resp.Auth.ExplicitMaxTTL, time.Hour)
+
+	// Renewals are capped at the access token's expiration as well, even
+	// when a larger increment is requested
+	renew := func(auth *logical.Auth) time.Duration {
+		t.Helper()
+		auth.TokenPolicies = auth.Policies
+		auth.TTL = 0
+		auth.IssueTime = time.Now()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RenewOperation,
+			Path:      "login",
+			Storage:   storage,
+			Auth:      auth,
+		})
+		require.NoError(t, err)
+		ttl, _, err := framework.CalculateTTL(b.System(), 24*time.Hour, resp.Auth.TTL, resp.Auth.Period, resp.Auth.MaxTTL, resp.Auth.ExplicitMaxTTL, auth.IssueTime)
+		require.NoError(t, err)
+		return ttl
+	}
+	ttl := renew(resp.Auth)
+	require.LessOrEqual(t, ttl, time.Unix(exp, 0).Sub(time.Now().Truncate(time.Second)))
+	require.Greater(t, ttl, time.Duration(0))
+
+	// Periodic tokens are capped too
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "role/partner",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"token_ttl":    0,
+			"token_period": "2h",
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	resp = login("good")
+	require.False(t, resp.IsError(), resp.Error())
+	ttl = renew(resp.Auth)
+	require.LessOrEqual(t, ttl, time.Unix(exp, 0).Sub(time.Now().Truncate(time.Second)))
+	require.Greater(t, ttl, time.Duration(0))
+
+	// The second login is served from the positive cache
+	resp = login("good")
+	require.False(t, resp.IsError(), resp.Error())
+	require.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	resp = login("wrong-scope")
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "write")
+
+	// Inactive tokens are rejected and negatively cached
+	resp = login("revoked")
+	require.True(t, resp.IsError())
+	resp = login("revoked")
+	require.True(t, resp.IsError())
+	require.Equal(t, int32(3), atomic.LoadInt32(calls))
+
+	// Rewriting the config clears the caches
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"cache_ttl": 0,
+		},
+	})
+	require.NoError(t, err)
+	login("good")
+	login("good")
+	require.Equal(t, int32(5), atomic.LoadInt32(calls))
+
+	// A token with less than a second left is rejected rather than issued
+	// with the mount's default TTL
+	resp = login("expiring")
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "expire")
+}
+
+func TestOAuth2_RoleBoundClaims(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "role/bad",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"bound_claims": map[string]interface{}{
+				"groups": 42,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	// Roles must constrain the tokens they accept
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "role/unbound",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"token_policies": "default",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "at least one of")
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "role/good",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"bound_claims": map[string]interface{}{
+				"tenant": "acme",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "role/good",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"tenant": {"acme"}}, resp.Data["bound_claims"])
+	require.Equal(t, "sub", resp.Data["user_claim"])
+}
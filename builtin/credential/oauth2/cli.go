@@ -0,0 +1,107 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package oauth2
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/password"
+	"github.com/hashicorp/vault/api"
+)
+
+type CLIHandler struct {
+	// for tests
+	testStdout io.Writer
+}
+
+func (h *CLIHandler) Auth(c *api.Client, m map[string]string) (*api.Secret, error) {
+	mount, ok := m["mount"]
+	if !ok {
+		mount = "oauth2"
+	}
+
+	role := m["role"]
+	if role == "" {
+		return nil, fmt.Errorf("'role' must be specified")
+	}
+
+	// Extract or prompt for token
+	token := m["token"]
+	if token == "" {
+		token = os.Getenv("VAULT_AUTH_OAUTH2_TOKEN")
+	}
+	if token == "" {
+		// Override the output
+		stdout := h.testStdout
+		if stdout == nil {
+			stdout = os.Stderr
+		}
+
+		var err error
+		fmt.Fprintf(stdout, "OAuth2 access token (will be hidden): ")
+		token, err = password.Read(os.Stdin)
+		fmt.Fprintf(stdout, "\n")
+		if err != nil {
+			if err == password.ErrInterrupted {
+				return nil, fmt.Errorf("user interrupted")
+			}
+
+			return nil, fmt.Errorf("An error occurred attempting to "+
+				"ask for a token. The raw error message is shown below, but usually "+
+				"this is because you attempted to pipe a value into the command or "+
+				"you are executing outside of a terminal (tty). If you want to pipe "+
+				"the value, pass \"-\" as the argument to read from stdin. The raw "+
+				"error was: %w", err)
+		}
+	}
+
+	path := fmt.Sprintf("auth/%s/login", mount)
+	secret, err := c.Logical().Write(path, map[string]interface{}{
+		"role":  role,
+		"token": strings.TrimSpace(token),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("empty response from credential provider")
+	}
+
+	return secret, nil
+}
+
+func (h *CLIHandler) Help() string {
+	help := `
+Usage: vault login -method=oauth2 [CONFIG K=V...]
+
+  The OAuth2 auth method allows users to authenticate using an OAuth2 access
+  token, which Vault validates against the token introspection endpoint of
+  the authorization server that issued it.
+
+  Authenticate using an access token:
+
+      $ vault login -method=oauth2 role=partner token=abcd1234
+
+Configuration:
+
+  mount=<string>
+      Path where the OAuth2 credential method is mounted. This is usually
+      provided via the -path flag in the "vault login" command, but it can be
+      specified here as well. If specified here, it takes precedence over the
+      value for -path. The default value is "oauth2".
+
+  role=<string>
+      Name of the role to log in against. This is required.
+
+  token=<string>
+      OAuth2 access token to use for authentication. If not provided, the
+      VAULT_AUTH_OAUTH2_TOKEN environment variable is used, and otherwise
+      Vault will prompt for the value.
+`
+
+	return strings.TrimSpace(help)
+}
@@ -0,0 +1,179 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package oauth2
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxIntrospectionResponseSize bounds how much of an introspection response
+// is read, to protect against misbehaving endpoints.
+const maxIntrospectionResponseSize = 1 << 20
+
+// introspectionResponse is the decoded body of an RFC 7662 introspection
+// response. Besides the registered members, authorization servers commonly
+// return arbitrary extra claims, so the whole object is kept.
+type introspectionResponse struct {
+	Active bool
+	Claims map[string]interface{}
+}
+
+func (r *introspectionResponse) claimString(name string) string {
+	values := r.claimStrings(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// claimStrings returns the string forms of a claim, flattening lists.
+func (r *introspectionResponse) claimStrings(name string) []string {
+	switch v := r.Claims[name].(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if item == nil {
+				continue
+			}
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// scopes returns the space-delimited scope member as a list.
+func (r *introspectionResponse) scopes() []string {
+	return strings.Fields(r.claimString("scope"))
+}
+
+// timeClaim returns a NumericDate claim such as exp or nbf.
+func (r *introspectionResponse) timeClaim(name string) (time.Time, bool) {
+	n, ok := r.Claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	secs, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(secs), 0), true
+}
+
+// checkValidity verifies that an active response is within its validity
+// window. This matters for cached results, which may outlive the token.
+func (r *introspectionResponse) checkValidity(now time.Time) error {
+	if !r.Active {
+		return fmt.Errorf("token is not active")
+	}
+	if exp, ok := r.timeClaim("exp"); ok && !now.Before(exp) {
+		return fmt.Errorf("token is expired")
+	}
+	if nbf, ok := r.timeClaim("nbf"); ok && now.Before(nbf) {
+		return fmt.Errorf("token is not yet valid")
+	}
+	return nil
+}
+
+func introspectionCacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// introspect returns the introspection result for the token, consulting the
+// cache first. Both active and inactive results are cached according to the
+// config; errors talking to the endpoint are never cached.
+func (b *backend) introspect(ctx context.Context, config *oauth2Config, token string) (*introspectionResponse, error) {
+	key := introspectionCacheKey(token)
+	if cached, ok := b.introspectionCache.Get(key); ok {
+		return cached.(*introspectionResponse), nil
+	}
+
+	resp, err := b.introspectRemote(ctx, config, token)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !resp.Active && config.NegativeCacheTTL > 0:
+		b.introspectionCache.Set(key, resp, config.NegativeCacheTTL)
+	case resp.Active && config.CacheTTL > 0:
+		ttl := config.CacheTTL
+		if exp, ok := resp.timeClaim("exp"); ok {
+			if remaining := time.Until(exp); remaining < ttl {
+				ttl = remaining
+			}
+		}
+		if ttl > 0 {
+			b.introspectionCache.Set(key, resp, ttl)
+		}
+	}
+
+	return resp, nil
+}
+
+func (b *backend) introspectRemote(ctx context.Context, config *oauth2Config, token string) (*introspectionResponse, error) {
+	client, err := b.getClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"token":           []string{token},
+		"token_type_hint": []string{"access_token"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.IntrospectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if config.ClientID != "" {
+		// RFC 6749 section 2.3.1 requires the credentials to be form
+		// encoded before being used for basic authentication.
+		req.SetBasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret))
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling introspection endpoint: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxIntrospectionResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading introspection response: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status %d", httpResp.StatusCode)
+	}
+
+	claims := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("error decoding introspection response: %w", err)
+	}
+
+	active, _ := claims["active"].(bool)
+	return &introspectionResponse{
+		Active: active,
+		Claims: claims,
+	}, nil
+}
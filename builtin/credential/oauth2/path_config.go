@@ -0,0 +1,245 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package oauth2
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/authmetadata"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	defaultCacheTTL         = time.Minute
+	defaultNegativeCacheTTL = 10 * time.Second
+)
+
+// authMetadataFields is the list of introspection response values that may
+// be added to the alias and audit log metadata of a login.
+var authMetadataFields = &authmetadata.Fields{
+	FieldName: "auth_metadata",
+	Default: []string{
+		"client_id",
+		"sub",
+	},
+	AvailableToAdd: []string{
+		"username",
+		"scope",
+		"iss",
+		"aud",
+		"token_type",
+	},
+}
+
+func pathConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixOAuth2,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"introspection_url": {
+				Type:        framework.TypeString,
+				Description: "The URL of the RFC 7662 token introspection endpoint.",
+				Required:    true,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Introspection URL",
+				},
+			},
+			"introspection_ca_pem": {
+				Type:        framework.TypeString,
+				Description: "The CA certificate or chain of certificates, in PEM format, to use to validate connections to the introspection endpoint. If not set, system certificates are used.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Introspection CA PEM",
+				},
+			},
+			"client_id": {
+				Type:        framework.TypeString,
+				Description: "The client ID used to authenticate to the introspection endpoint.",
+			},
+			"client_secret": {
+				Type:        framework.TypeString,
+				Description: "The client secret used to authenticate to the introspection endpoint.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+			"cache_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "How long an active introspection result is reused before the endpoint is consulted again. Results are never reused past the token's expiration. Set to 0 to disable.",
+				Default:     int(defaultCacheTTL.Seconds()),
+			},
+			"negative_cache_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "How long an inactive introspection result is remembered. Set to 0 to disable.",
+				Default:     int(defaultNegativeCacheTTL.Seconds()),
+			},
+			authMetadataFields.FieldName: authmetadata.FieldSchema(authMetadataFields),
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "configuration",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "configure",
+				},
+			},
+		},
+
+		HelpSynopsis:    pathConfigHelpSyn,
+		HelpDescription: pathConfigHelpDesc,
+	}
+}
+
+func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"introspection_url":          config.IntrospectionURL,
+			"introspection_ca_pem":       config.IntrospectionCAPEM,
+			"client_id":                  config.ClientID,
+			"cache_ttl":                  int64(config.CacheTTL.Seconds()),
+			"negative_cache_ttl":         int64(config.NegativeCacheTTL.Seconds()),
+			authMetadataFields.FieldName: config.AuthMetadata.AuthMetadata(),
+		},
+	}, nil
+}
+
+func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &oauth2Config{
+			AuthMetadata:     authmetadata.NewHandler(authMetadataFields),
+			CacheTTL:         defaultCacheTTL,
+			NegativeCacheTTL: defaultNegativeCacheTTL,
+		}
+	}
+
+	if introspectionURLRaw, ok := data.GetOk("introspection_url"); ok {
+		config.IntrospectionURL = introspectionURLRaw.(string)
+	}
+	if config.IntrospectionURL == "" {
+		return logical.ErrorResponse("introspection_url is required"), nil
+	}
+	if u, err := url.Parse(config.IntrospectionURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return logical.ErrorResponse(fmt.Sprintf("invalid introspection_url %q", config.IntrospectionURL)), nil
+	}
+
+	if caPEMRaw, ok := data.GetOk("introspection_ca_pem"); ok {
+		config.IntrospectionCAPEM = caPEMRaw.(string)
+		if config.IntrospectionCAPEM != "" {
+			if ok := x509.NewCertPool().AppendCertsFromPEM([]byte(config.IntrospectionCAPEM)); !ok {
+				return logical.ErrorResponse("could not parse introspection_ca_pem"), nil
+			}
+		}
+	}
+	if clientIDRaw, ok := data.GetOk("client_id"); ok {
+		config.ClientID = clientIDRaw.(string)
+	}
+	if clientSecretRaw, ok := data.GetOk("client_secret"); ok {
+		config.ClientSecret = clientSecretRaw.(string)
+	}
+	if config.ClientSecret != "" && config.ClientID == "" {
+		return logical.ErrorResponse("client_id is required when client_secret is set"), nil
+	}
+
+	if cacheTTLRaw, ok := data.GetOk("cache_ttl"); ok {
+		config.CacheTTL = time.Duration(cacheTTLRaw.(int)) * time.Second
+	}
+	if config.CacheTTL < 0 {
+		return logical.ErrorResponse("cache_ttl cannot be negative"), nil
+	}
+	if negativeCacheTTLRaw, ok := data.GetOk("negative_cache_ttl"); ok {
+		config.NegativeCacheTTL = time.Duration(negativeCacheTTLRaw.(int)) * time.Second
+	}
+	if config.NegativeCacheTTL < 0 {
+		return logical.ErrorResponse("negative_cache_ttl cannot be negative"), nil
+	}
+
+	if err := config.AuthMetadata.ParseAuthMetadata(data); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	entry, err := logical.StorageEntryJSON("config", config)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	b.reset()
+
+	return nil, nil
+}
+
+// config returns the configuration for this backend, or nil if it has not
+// been configured yet.
+func (b *backend) config(ctx context.Context, s logical.Storage) (*oauth2Config, error) {
+	entry, err := s.Get(ctx, "config")
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	config := &oauth2Config{
+		AuthMetadata: authmetadata.NewHandler(authMetadataFields),
+	}
+	if err := entry.DecodeJSON(config); err != nil {
+		return nil, fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+type oauth2Config struct {
+	IntrospectionURL   string                `json:"introspection_url"`
+	IntrospectionCAPEM string                `json:"introspection_ca_pem"`
+	ClientID           string                `json:"client_id"`
+	ClientSecret       string                `json:"client_secret"`
+	CacheTTL           time.Duration         `json:"cache_ttl"`
+	NegativeCacheTTL   time.Duration         `json:"negative_cache_ttl"`
+	AuthMetadata       *authmetadata.Handler `json:"auth_metadata_handler"`
+}
+
+const pathConfigHelpSyn = `
+Configure the OAuth2 token introspection endpoint.
+`
+
+const pathConfigHelpDesc = `
+The OAuth2 auth method validates bearer tokens by presenting them to the
+token introspection endpoint of the authorization server, as described in
+RFC 7662. If client_id and client_secret are set, they are sent to the
+endpoint using HTTP basic authentication.
+
+Introspection results are cached for cache_ttl, bounded by the expiration
+the endpoint reports for the token, and tokens reported as inactive are
+remembered for negative_cache_ttl. Updating this configuration clears both
+caches.
+`
@@ -0,0 +1,257 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/cidrutil"
+	"github.com/hashicorp/vault/sdk/helper/policyutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathLogin(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "login$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixOAuth2,
+			OperationVerb:   "login",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"role": {
+				Type:        framework.TypeLowerCaseString,
+				Description: "The role to log in against.",
+			},
+			"token": {
+				Type:        framework.TypeString,
+				Description: "The OAuth2 access token to validate.",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathLogin,
+			},
+			logical.AliasLookaheadOperation: &framework.PathOperation{
+				Callback: b.pathLoginAliasLookahead,
+			},
+		},
+
+		HelpSynopsis:    pathLoginHelpSyn,
+		HelpDescription: pathLoginHelpDesc,
+	}
+}
+
+func (b *backend) pathLoginAliasLookahead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	verifyResp, err := b.verifyToken(ctx, req, data.Get("role").(string), data.Get("token").(string))
+	if err != nil {
+		return nil, err
+	}
+	if verifyResp.errResp != nil {
+		return verifyResp.errResp, nil
+	}
+
+	return &logical.Response{
+		Auth: &logical.Auth{
+			Alias: &logical.Alias{
+				Name: verifyResp.aliasName,
+			},
+		},
+	}, nil
+}
+
+func (b *backend) pathLogin(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	roleName := data.Get("role").(string)
+	token := data.Get("token").(string)
+
+	verifyResp, err := b.verifyToken(ctx, req, roleName, token)
+	if err != nil {
+		return nil, err
+	}
+	if verifyResp.errResp != nil {
+		return verifyResp.errResp, nil
+	}
+
+	// The access token itself isn't stored, only when it expires so that
+	// renewals can't extend the Vault token past it
+	auth := &logical.Auth{
+		InternalData: map[string]interface{}{
+			"role": roleName,
+		},
+		Metadata: map[string]string{
+			"role": roleName,
+		},
+		DisplayName: verifyResp.aliasName,
+		Alias: &logical.Alias{
+			Name: verifyResp.aliasName,
+		},
+	}
+	verifyResp.role.PopulateTokenAuth(auth)
+	if exp, ok := verifyResp.introspection.timeClaim("exp"); ok {
+		auth.InternalData["expiration"] = exp.Format(time.RFC3339)
+		if err := capTTLToExpiration(auth, time.Now(), exp); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	if err := verifyResp.config.AuthMetadata.PopulateDesiredMetadata(auth, verifyResp.authMetadata()); err != nil {
+		b.Logger().Warn("unable to populate auth metadata", "error", err)
+	}
+
+	return &logical.Response{
+		Auth: auth,
+	}, nil
+}
+
+func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	if req.Auth == nil {
+		return nil, errors.New("request auth was nil")
+	}
+
+	roleNameRaw, ok := req.Auth.InternalData["role"]
+	if !ok {
+		return nil, errors.New("role is missing from internal data")
+	}
+
+	role, err := b.role(ctx, req.Storage, roleNameRaw.(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errors.New("role no longer exists")
+	}
+	if !policyutil.EquivalentPolicies(role.TokenPolicies, req.Auth.TokenPolicies) {
+		return nil, errors.New("policies on role have changed, not renewing")
+	}
+
+	resp := &logical.Response{Auth: req.Auth}
+	resp.Auth.Period = role.TokenPeriod
+	resp.Auth.TTL = role.TokenTTL
+	resp.Auth.MaxTTL = role.TokenMaxTTL
+	if expRaw, ok := req.Auth.InternalData["expiration"]; ok {
+		exp, err := time.Parse(time.RFC3339, expRaw.(string))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse access token expiration: %w", err)
+		}
+		if err := capTTLToExpiration(resp.Auth, resp.Auth.IssueTime, exp); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+type verifyTokenResp struct {
+	config        *oauth2Config
+	role          *roleEntry
+	introspection *introspectionResponse
+	aliasName     string
+
+	// errResp is set when the login should be rejected with a message
+	// rather than failed with an internal error.
+	errResp *logical.Response
+}
+
+// authMetadata returns the introspection values that may be added to the
+// auth and alias metadata.
+func (v *verifyTokenResp) authMetadata() map[string]string {
+	available := make(map[string]string, len(authMetadataFields.Default)+len(authMetadataFields.AvailableToAdd))
+	for _, field := range append(authMetadataFields.Default, authMetadataFields.AvailableToAdd...) {
+		available[field] = strings.Join(v.introspection.claimStrings(field), ",")
+	}
+	return available
+}
+
+func (b *backend) verifyToken(ctx context.Context, req *logical.Request, roleName, token string) (*verifyTokenResp, error) {
+	if roleName == "" {
+		return &verifyTokenResp{errResp: logical.ErrorResponse("missing role")}, nil
+	}
+	if token == "" {
+		return &verifyTokenResp{errResp: logical.ErrorResponse("missing token")}, nil
+	}
+
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return &verifyTokenResp{errResp: logical.ErrorResponse("auth method is not configured")}, nil
+	}
+
+	role, err := b.role(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return &verifyTokenResp{errResp: logical.ErrorResponse(fmt.Sprintf("role %q could not be found", roleName))}, nil
+	}
+
+	if req.Connection != nil && !cidrutil.RemoteAddrIsOk(req.Connection.RemoteAddr, role.TokenBoundCIDRs) {
+		return &verifyTokenResp{errResp: logical.ErrorResponse("login request originated from invalid CIDR")}, nil
+	}
+
+	introspection, err := b.introspect(ctx, config, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := introspection.checkValidity(time.Now()); err != nil {
+		return &verifyTokenResp{errResp: logical.ErrorResponse(err.Error())}, nil
+	}
+	if err := role.validate(introspection); err != nil {
+		return &verifyTokenResp{errResp: logical.ErrorResponse(err.Error())}, nil
+	}
+
+	aliasName := introspection.claimString(role.UserClaim)
+	if aliasName == "" {
+		return &verifyTokenResp{errResp: logical.ErrorResponse(fmt.Sprintf("claim %q not found in introspection response", role.UserClaim))}, nil
+	}
+
+	return &verifyTokenResp{
+		config:        config,
+		role:          role,
+		introspection: introspection,
+		aliasName:     aliasName,
+	}, nil
+}
+
+// capTTLToExpiration bounds the Vault token issued at issueTime so that it
+// can't outlive the access token expiring at exp, whatever the increment
+// requested on renewal or the period of the role.
+func capTTLToExpiration(auth *logical.Auth, issueTime, exp time.Time) error {
+	remaining := time.Until(exp).Truncate(time.Second)
+	if remaining < time.Second {
+		return errors.New("access token is expired or about to expire")
+	}
+	if auth.TTL == 0 || remaining < auth.TTL {
+		auth.TTL = remaining
+	}
+
+	// The explicit max TTL is counted from the issue time, truncated to the
+	// second, both at login and on renewal
+	maxTTL := exp.Sub(issueTime.Truncate(time.Second)).Truncate(time.Second)
+	if auth.ExplicitMaxTTL == 0 || maxTTL < auth.ExplicitMaxTTL {
+		auth.ExplicitMaxTTL = maxTTL
+	}
+	return nil
+}
+
+const pathLoginHelpSyn = `
+Log in with an OAuth2 access token.
+`
+
+const pathLoginHelpDesc = `
+The token is validated against the configured introspection endpoint and
+the constraints of the given role. The access token is not stored: on
+renewal, the role is checked again and the Vault token cannot be renewed
+past the expiration of the access token. Use a token_ttl shorter than the
+access tokens' lifetime to bound how long a revoked access token's Vault
+token remains usable.
+`
@@ -0,0 +1,329 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/tokenutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	rolePrefix       = "role/"
+	defaultUserClaim = "sub"
+)
+
+func pathRoleList(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "role/?",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixOAuth2,
+			OperationSuffix: "roles",
+			Navigation:      true,
+			ItemType:        "Role",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathRoleList,
+			},
+		},
+
+		HelpSynopsis:    pathRoleListHelpSyn,
+		HelpDescription: pathRoleListHelpDesc,
+	}
+}
+
+func pathRole(b *backend) *framework.Path {
+	p := &framework.Path{
+		Pattern: "role/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixOAuth2,
+			OperationSuffix: "role",
+			Action:          "Create",
+			ItemType:        "Role",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeLowerCaseString,
+				Description: "Name of the role.",
+			},
+			"bound_scopes": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Scopes that must all be granted to the token for it to be accepted.",
+			},
+			"bound_client_ids": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "If set, the token must have been issued to one of these client IDs.",
+			},
+			"bound_subjects": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "If set, the token's sub claim must be one of these values.",
+			},
+			"bound_claims": {
+				Type:        framework.TypeMap,
+				Description: "Map of claims to values that must be present in the introspection response. Each value may be a string or a list of strings, any of which is accepted.",
+			},
+			"user_claim": {
+				Type:        framework.TypeString,
+				Description: "The introspection response claim to use as the name of the entity alias.",
+				Default:     defaultUserClaim,
+			},
+		},
+
+		ExistenceCheck: b.pathRoleExistenceCheck,
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathRoleRead,
+			},
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: b.pathRoleWrite,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathRoleWrite,
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathRoleDelete,
+			},
+		},
+
+		HelpSynopsis:    pathRoleHelpSyn,
+		HelpDescription: pathRoleHelpDesc,
+	}
+
+	tokenutil.AddTokenFields(p.Fields)
+	return p
+}
+
+type roleEntry struct {
+	tokenutil.TokenParams
+
+	BoundScopes    []string            `json:"bound_scopes"`
+	BoundClientIDs []string            `json:"bound_client_ids"`
+	BoundSubjects  []string            `json:"bound_subjects"`
+	BoundClaims    map[string][]string `json:"bound_claims"`
+	UserClaim      string              `json:"user_claim"`
+}
+
+// validate checks the introspection response against the role's bindings.
+func (r *roleEntry) validate(resp *introspectionResponse) error {
+	if len(r.BoundScopes) > 0 {
+		scopes := resp.scopes()
+		for _, scope := range r.BoundScopes {
+			if !strutil.StrListContains(scopes, scope) {
+				return fmt.Errorf("token is missing bound scope %q", scope)
+			}
+		}
+	}
+
+	if len(r.BoundClientIDs) > 0 && !strutil.StrListContains(r.BoundClientIDs, resp.claimString("client_id")) {
+		return fmt.Errorf("client_id %q is not in the role's bound_client_ids", resp.claimString("client_id"))
+	}
+
+	if len(r.BoundSubjects) > 0 && !strutil.StrListContains(r.BoundSubjects, resp.claimString("sub")) {
+		return fmt.Errorf("sub %q is not in the role's bound_subjects", resp.claimString("sub"))
+	}
+
+	for claim, allowed := range r.BoundClaims {
+		values := resp.claimStrings(claim)
+		if len(values) == 0 {
+			return fmt.Errorf("claim %q is missing", claim)
+		}
+		matched := false
+		for _, value := range values {
+			if strutil.StrListContains(allowed, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("claim %q does not match any associated bound claim values", claim)
+		}
+	}
+
+	return nil
+}
+
+func (b *backend) role(ctx context.Context, s logical.Storage, name string) (*roleEntry, error) {
+	entry, err := s.Get(ctx, rolePrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	role := new(roleEntry)
+	if err := entry.DecodeJSON(role); err != nil {
+		return nil, err
+	}
+	if role.UserClaim == "" {
+		role.UserClaim = defaultUserClaim
+	}
+
+	return role, nil
+}
+
+func (b *backend) pathRoleExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	role, err := b.role(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+func (b *backend) pathRoleList(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	roles, err := req.Storage.List(ctx, rolePrefix)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(roles), nil
+}
+
+func (b *backend) pathRoleRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	role, err := b.role(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	d := map[string]interface{}{
+		"bound_scopes":     role.BoundScopes,
+		"bound_client_ids": role.BoundClientIDs,
+		"bound_subjects":   role.BoundSubjects,
+		"bound_claims":     role.BoundClaims,
+		"user_claim":       role.UserClaim,
+	}
+	role.PopulateTokenData(d)
+
+	return &logical.Response{
+		Data: d,
+	}, nil
+}
+
+func (b *backend) pathRoleWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if name == "" {
+		return logical.ErrorResponse("missing role name"), nil
+	}
+
+	role, err := b.role(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		role = &roleEntry{
+			UserClaim: defaultUserClaim,
+		}
+	}
+
+	if err := role.ParseTokenFields(req, data); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	if boundScopesRaw, ok := data.GetOk("bound_scopes"); ok {
+		role.BoundScopes = boundScopesRaw.([]string)
+	}
+	if boundClientIDsRaw, ok := data.GetOk("bound_client_ids"); ok {
+		role.BoundClientIDs = boundClientIDsRaw.([]string)
+	}
+	if boundSubjectsRaw, ok := data.GetOk("bound_subjects"); ok {
+		role.BoundSubjects = boundSubjectsRaw.([]string)
+	}
+	if boundClaimsRaw, ok := data.GetOk("bound_claims"); ok {
+		boundClaims, err := parseBoundClaims(boundClaimsRaw.(map[string]interface{}))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		role.BoundClaims = boundClaims
+	}
+	if userClaimRaw, ok := data.GetOk("user_claim"); ok {
+		role.UserClaim = strings.TrimSpace(userClaimRaw.(string))
+	}
+	if role.UserClaim == "" {
+		return logical.ErrorResponse("user_claim cannot be empty"), nil
+	}
+	if len(role.BoundScopes) == 0 && len(role.BoundClientIDs) == 0 && len(role.BoundSubjects) == 0 && len(role.BoundClaims) == 0 {
+		return logical.ErrorResponse("must have at least one of bound_scopes, bound_client_ids, bound_subjects or bound_claims"), nil
+	}
+
+	entry, err := logical.StorageEntryJSON(rolePrefix+name, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathRoleDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if err := req.Storage.Delete(ctx, rolePrefix+data.Get("name").(string)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// parseBoundClaims normalizes the bound_claims input, where each value may be
+// a single string or a list of strings.
+func parseBoundClaims(raw map[string]interface{}) (map[string][]string, error) {
+	boundClaims := make(map[string][]string, len(raw))
+	for claim, value := range raw {
+		switch v := value.(type) {
+		case string:
+			boundClaims[claim] = []string{v}
+		case []interface{}:
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("bound_claims value for %q must be a string or a list of strings", claim)
+				}
+				boundClaims[claim] = append(boundClaims[claim], s)
+			}
+		default:
+			return nil, fmt.Errorf("bound_claims value for %q must be a string or a list of strings", claim)
+		}
+		if len(boundClaims[claim]) == 0 {
+			return nil, fmt.Errorf("bound_claims value for %q cannot be empty", claim)
+		}
+	}
+	return boundClaims, nil
+}
+
+const pathRoleListHelpSyn = `
+Lists all the roles registered with the backend.
+`
+
+const pathRoleListHelpDesc = `
+The list will contain the names of the roles.
+`
+
+const pathRoleHelpSyn = `
+Register a role with the backend.
+`
+
+const pathRoleHelpDesc = `
+A role constrains which tokens may be used to log in through it and sets
+the properties of the Vault tokens issued. A token is accepted only if the
+introspection endpoint reports it as active, it was granted every scope in
+bound_scopes, its client_id and sub are in bound_client_ids and
+bound_subjects when those are set, and every claim in bound_claims matches
+one of its allowed values. At least one of these constraints must be set,
+since the introspection endpoint may report tokens issued to any client.
+
+The value of user_claim in the introspection response is used as the name
+of the entity alias.
+`
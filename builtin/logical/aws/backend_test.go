@@ -940,6 +940,7 @@ func testAccStepReadPolicy(t *testing.T, name string, value string) logicaltest.
 				"mfa_serial_number":        "",
 				"session_tags":             map[string]string(nil),
 				"external_id":              "",
+				"source_identity":          "",
 			}
 			if !reflect.DeepEqual(resp.Data, expected) {
 				return fmt.Errorf("bad: got: %#v\nexpected: %#v", resp.Data, expected)
@@ -1544,6 +1545,7 @@ func testAccStepReadArnPolicy(t *testing.T, name string, value string) logicalte
 				"mfa_serial_number":        "",
 				"session_tags":             map[string]string(nil),
 				"external_id":              "",
+				"source_identity":          "",
 			}
 			if !reflect.DeepEqual(resp.Data, expected) {
 				return fmt.Errorf("bad: got: %#v\nexpected: %#v", resp.Data, expected)
@@ -1618,6 +1620,7 @@ func testAccStepReadIamGroups(t *testing.T, name string, groups []string) logica
 				"mfa_serial_number":        "",
 				"session_tags":             map[string]string(nil),
 				"external_id":              "",
+				"source_identity":          "",
 			}
 			if !reflect.DeepEqual(resp.Data, expected) {
 				return fmt.Errorf("bad: got: %#v\nexpected: %#v", resp.Data, expected)
@@ -1681,6 +1684,7 @@ func testAccStepReadIamTags(t *testing.T, name string, tags map[string]string) l
 				"mfa_serial_number":        "",
 				"session_tags":             map[string]string(nil),
 				"external_id":              "",
+				"source_identity":          "",
 			}
 			if !reflect.DeepEqual(resp.Data, expected) {
 				return fmt.Errorf("bad: got: %#v\nexpected: %#v", resp.Data, expected)
@@ -1849,6 +1853,7 @@ func testAccStepReadSTSSessionTags(t *testing.T, name string, tags any, external
 				"mfa_serial_number":        "",
 				"session_tags":             tags,
 				"external_id":              externalID,
+				"source_identity":          "",
 			}
 			if !reflect.DeepEqual(resp.Data, expected) {
 				return fmt.Errorf("bad: got: %#v\nexpected: %#v", resp.Data, expected)
@@ -119,7 +119,8 @@ delimited key pairs.`,
 				Type: framework.TypeKVPairs,
 				Description: fmt.Sprintf(`Session tags to be set for %q creds created by this role. These must be presented
 as Key-Value pairs. This can be represented as a map or a list of equal sign
-delimited key pairs.`, assumedRoleCred),
+delimited key pairs. Values may contain identity templates, such as
+{{identity.entity.name}}, which are rendered from the requester's entity.`, assumedRoleCred),
 				DisplayAttrs: &framework.DisplayAttributes{
 					Name:  "Session Tags",
 					Value: "[key1=value1, key2=value2]",
@@ -132,6 +133,15 @@ delimited key pairs.`, assumedRoleCred),
 					Name: "External ID",
 				},
 			},
+			"source_identity": {
+				Type: framework.TypeString,
+				Description: fmt.Sprintf(`Source identity to set when assuming the role; only valid when credential_type is %s.
+May contain identity templates, such as {{identity.entity.name}}, which are rendered
+from the requester's entity.`, assumedRoleCred),
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Source Identity",
+				},
+			},
 			"default_sts_ttl": {
 				Type:        framework.TypeDurationSecond,
 				Description: fmt.Sprintf("Default TTL for %s, %s, and %s credential types when no TTL is explicitly requested with the credentials", assumedRoleCred, federationTokenCred, sessionTokenCred),
@@ -375,6 +385,10 @@ func (b *backend) pathRolesWrite(ctx context.Context, req *logical.Request, d *f
 		roleEntry.ExternalID = externalID.(string)
 	}
 
+	if sourceIdentity, ok := d.GetOk("source_identity"); ok {
+		roleEntry.SourceIdentity = sourceIdentity.(string)
+	}
+
 	if legacyRole != "" {
 		roleEntry = upgradeLegacyPolicyEntry(legacyRole)
 		if roleEntry.InvalidData != "" {
@@ -568,6 +582,7 @@ type awsRoleEntry struct {
 	IAMTags                  map[string]string `json:"iam_tags"`                              // IAM tags that will be added to the generated IAM users
 	SessionTags              map[string]string `json:"session_tags"`                          // Session tags that will be added as Tags parameter in AssumedRole calls
 	ExternalID               string            `json:"external_id"`                           // External ID to added as ExternalID in AssumeRole calls
+	SourceIdentity           string            `json:"source_identity,omitempty"`             // Identity template of the SourceIdentity set in AssumeRole calls
 	InvalidData              string            `json:"invalid_data,omitempty"`                // Invalid role data. Exists to support converting the legacy role data into the new format
 	ProhibitFlexibleCredPath bool              `json:"prohibit_flexible_cred_path,omitempty"` // Disallow accessing STS credentials via the creds path and vice verse
 	Version                  int               `json:"version"`                               // Version number of the role format
@@ -588,6 +603,7 @@ func (r *awsRoleEntry) toResponseData() map[string]interface{} {
 		"iam_tags":                 r.IAMTags,
 		"session_tags":             r.SessionTags,
 		"external_id":              r.ExternalID,
+		"source_identity":          r.SourceIdentity,
 		"default_sts_ttl":          int64(r.DefaultSTSTTL.Seconds()),
 		"max_sts_ttl":              int64(r.MaxSTSTTL.Seconds()),
 		"user_path":                r.UserPath,
@@ -663,6 +679,20 @@ func (r *awsRoleEntry) validate() error {
 		errors = multierror.Append(errors, fmt.Errorf("cannot supply external_id when credential_type isn't %s", assumedRoleCred))
 	}
 
+	if r.SourceIdentity != "" && !strutil.StrListContains(r.CredentialTypes, assumedRoleCred) {
+		errors = multierror.Append(errors, fmt.Errorf("cannot supply source_identity when credential_type isn't %s", assumedRoleCred))
+	}
+
+	for key, value := range r.SessionTags {
+		if _, err := framework.ValidateIdentityTemplate(value); err != nil {
+			errors = multierror.Append(errors, fmt.Errorf("invalid template in session tag %q: %w", key, err))
+		}
+	}
+
+	if _, err := framework.ValidateIdentityTemplate(r.SourceIdentity); err != nil {
+		errors = multierror.Append(errors, fmt.Errorf("invalid template in source_identity: %w", err))
+	}
+
 	return errors.ErrorOrNil()
 }
 
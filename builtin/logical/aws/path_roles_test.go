@@ -431,6 +431,14 @@ func TestRoleEntryValidationIamUserCred(t *testing.T) {
 			errors.New(
 				"cannot supply external_id when credential_type isn't assumed_role"),
 		})
+	roleEntry.ExternalID = ""
+
+	roleEntry.SourceIdentity = "{{identity.entity.name}}"
+	assertMultiError(t, roleEntry.validate(),
+		[]error{
+			errors.New(
+				"cannot supply source_identity when credential_type isn't assumed_role"),
+		})
 }
 
 func assertMultiError(t *testing.T, err error, expected []error) {
@@ -465,15 +473,22 @@ func TestRoleEntryValidationAssumedRoleCred(t *testing.T) {
 		ExternalID:      "my-ext-id",
 		SessionTags: map[string]string{
 			"Key1": "Value1",
-			"Key2": "Value2",
+			"Key2": "{{identity.entity.metadata.team}}",
 		},
-		DefaultSTSTTL: 2,
-		MaxSTSTTL:     3,
+		SourceIdentity: "{{identity.entity.name}}",
+		DefaultSTSTTL:  2,
+		MaxSTSTTL:      3,
 	}
 	if err := roleEntry.validate(); err != nil {
 		t.Errorf("bad: valid roleEntry %#v failed validation: %v", roleEntry, err)
 	}
 
+	roleEntry.SessionTags["Key3"] = "{{identity.entity.name"
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: invalid roleEntry with unbalanced session tag template %#v passed validation", roleEntry)
+	}
+	delete(roleEntry.SessionTags, "Key3")
+
 	roleEntry.MaxSTSTTL = 1
 	if roleEntry.validate() == nil {
 		t.Errorf("bad: invalid roleEntry with MaxSTSTTL < DefaultSTSTTL %#v passed validation", roleEntry)
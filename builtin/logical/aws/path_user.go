@@ -6,6 +6,7 @@ package aws
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -18,6 +19,10 @@ import (
 	"github.com/mitchellh/mapstructure"
 )
 
+// sourceIdentityRegex matches the values AWS accepts as the SourceIdentity of
+// a role session.
+var sourceIdentityRegex = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
 func pathUser(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern: "(creds|sts)/" + framework.GenericNameWithAtRegex("name"),
@@ -172,7 +177,22 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 		case !strutil.StrListContains(role.RoleArns, roleArn):
 			return logical.ErrorResponse(fmt.Sprintf("role_arn %q not in allowed role arns for Vault role %q", roleArn, roleName)), nil
 		}
-		return b.assumeRole(ctx, req.Storage, req.DisplayName, roleName, roleArn, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl, roleSessionName, role.SessionTags, role.ExternalID)
+		sessionTags := make(map[string]string, len(role.SessionTags))
+		for key, value := range role.SessionTags {
+			rendered, err := b.renderIdentityTemplate(req, value)
+			if err != nil {
+				return logical.ErrorResponse("unable to render session tag %q: %s", key, err), nil
+			}
+			sessionTags[key] = rendered
+		}
+		sourceIdentity, err := b.renderIdentityTemplate(req, role.SourceIdentity)
+		if err != nil {
+			return logical.ErrorResponse("unable to render source_identity: %s", err), nil
+		}
+		if sourceIdentity != "" && !sourceIdentityRegex.MatchString(sourceIdentity) {
+			return logical.ErrorResponse("rendered source_identity %q must match %q", sourceIdentity, sourceIdentityRegex.String()), nil
+		}
+		return b.assumeRole(ctx, req.Storage, req.DisplayName, roleName, roleArn, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl, roleSessionName, sessionTags, role.ExternalID, sourceIdentity)
 	case federationTokenCred:
 		return b.getFederationToken(ctx, req.Storage, req.DisplayName, roleName, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl)
 	case sessionTokenCred:
@@ -182,6 +202,22 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 	}
 }
 
+// renderIdentityTemplate populates the identity templates in tpl from the
+// entity of the request. Strings without templates are returned as is.
+func (b *backend) renderIdentityTemplate(req *logical.Request, tpl string) (string, error) {
+	hasTemplating, err := framework.ValidateIdentityTemplate(tpl)
+	if err != nil {
+		return "", err
+	}
+	if !hasTemplating {
+		return tpl, nil
+	}
+	if req.EntityID == "" {
+		return "", fmt.Errorf("template %q requires a token with an entity", tpl)
+	}
+	return framework.PopulateIdentityTemplate(tpl, req.EntityID, b.System())
+}
+
 func (b *backend) pathUserRollback(ctx context.Context, req *logical.Request, _kind string, data interface{}) error {
 	var entry walUser
 	if err := mapstructure.Decode(data, &entry); err != nil {
@@ -0,0 +1,57 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackend_renderIdentityTemplate(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.System = &logical.StaticSystemView{
+		EntityVal: &logical.Entity{
+			ID:   "entity-id",
+			Name: "alice",
+			Metadata: map[string]string{
+				"team": "payments",
+			},
+		},
+		GroupsVal: []*logical.Group{
+			{ID: "group-id", Name: "admins"},
+		},
+	}
+	b := Backend(config)
+	require.NoError(t, b.Setup(context.Background(), config))
+
+	req := &logical.Request{EntityID: "entity-id"}
+	for tpl, expected := range map[string]string{
+		"static":                                "static",
+		"{{identity.entity.name}}":              "alice",
+		"{{identity.entity.metadata.team}}":     "payments",
+		"vault-{{identity.entity.name}}":        "vault-alice",
+		`{{identity.groups.ids.group-id.name}}`: "admins",
+	} {
+		rendered, err := b.renderIdentityTemplate(req, tpl)
+		require.NoError(t, err, tpl)
+		require.Equal(t, expected, rendered, tpl)
+	}
+
+	_, err := b.renderIdentityTemplate(req, "{{identity.entity.metadata.missing}}")
+	require.Error(t, err)
+
+	// Templates can't be rendered for tokens without an entity
+	rendered, err := b.renderIdentityTemplate(&logical.Request{}, "static")
+	require.NoError(t, err)
+	require.Equal(t, "static", rendered)
+	_, err = b.renderIdentityTemplate(&logical.Request{}, "{{identity.entity.name}}")
+	require.Error(t, err)
+
+	require.True(t, sourceIdentityRegex.MatchString("alice@example.com"))
+	require.False(t, sourceIdentityRegex.MatchString("alice smith"))
+}
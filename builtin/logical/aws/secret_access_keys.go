@@ -238,7 +238,7 @@ func (b *backend) getSessionToken(ctx context.Context, s logical.Storage, serial
 
 func (b *backend) assumeRole(ctx context.Context, s logical.Storage,
 	displayName, roleName, roleArn, policy string, policyARNs []string,
-	iamGroups []string, lifeTimeInSeconds int64, roleSessionName string, sessionTags map[string]string, externalID string) (*logical.Response, error,
+	iamGroups []string, lifeTimeInSeconds int64, roleSessionName string, sessionTags map[string]string, externalID, sourceIdentity string) (*logical.Response, error,
 ) {
 	// grab any IAM group policies associated with the vault role, both inline
 	// and managed
@@ -298,6 +298,9 @@ func (b *backend) assumeRole(ctx context.Context, s logical.Storage,
 	if externalID != "" {
 		assumeRoleInput.SetExternalId(externalID)
 	}
+	if sourceIdentity != "" {
+		assumeRoleInput.SetSourceIdentity(sourceIdentity)
+	}
 	var tags []*sts.Tag
 	for k, v := range sessionTags {
 		tags = append(tags,
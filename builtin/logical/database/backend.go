@@ -36,6 +36,8 @@ const (
 	databaseConfigPath      = "config/"
 	databaseRolePath        = "role/"
 	databaseStaticRolePath  = "static-role/"
+	databaseLibraryPath     = "library/"
+	databaseCheckOutPath    = "library-checkout/"
 	minRootCredRollbackAge  = 1 * time.Minute
 )
 
@@ -81,6 +83,7 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 	b.credRotationQueue = queue.New()
 	// Load queue and kickoff new periodic ticker
 	go b.initQueue(b.queueCtx, conf)
+	go b.runHealthChecks(b.queueCtx, conf.StorageView)
 
 	// collect metrics on number of plugin instances
 	var err error
@@ -125,10 +128,16 @@ func Backend(conf *logical.BackendConfig) *databaseBackend {
 			pathRoles(&b),
 			pathCredsCreate(&b),
 			pathRotateRootCredentials(&b),
+			[]*framework.Path{
+				pathListLibrarySets(&b),
+				pathLibrarySets(&b),
+			},
+			pathLibraryCheckOut(&b),
 		),
 
 		Secrets: []*framework.Secret{
 			secretCreds(&b),
+			secretLibraryCreds(&b),
 		},
 		Clean:             b.clean,
 		Invalidate:        b.invalidate,
@@ -142,6 +151,7 @@ func Backend(conf *logical.BackendConfig) *databaseBackend {
 	b.connections = syncmap.NewSyncMap[string, *dbPluginInstance]()
 	b.queueCtx, b.cancelQueueCtx = context.WithCancel(context.Background())
 	b.roleLocks = locksutil.CreateLocks()
+	b.health = make(map[string]*connectionHealth)
 	b.schedule = &schedule.DefaultSchedule{}
 
 	return &b
@@ -193,6 +203,15 @@ type databaseBackend struct {
 	// issues with the priority queue.
 	roleLocks []*locksutil.LockEntry
 
+	// health tracks the health of connections with periodic health checks
+	// by config name, and healthLock guards it.
+	health     map[string]*connectionHealth
+	healthLock sync.Mutex
+
+	// libraryLock serializes changes to library sets and check-outs, so that
+	// an account can't be handed out twice.
+	libraryLock sync.Mutex
+
 	// the running gauge collection process
 	gaugeCollectionProcess     *metricsutil.GaugeCollectionProcess
 	gaugeCollectionProcessStop sync.Once
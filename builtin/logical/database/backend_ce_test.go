@@ -90,6 +90,8 @@ func TestBackend_config_connection(t *testing.T) {
 			"plugin_version":                     "",
 			"verify_connection":                  false,
 			"skip_static_role_import_rotation":   false,
+			"health_check_interval":              int64(0),
+			"circuit_breaker_threshold":          3,
 			"rotation_schedule":                  "",
 			"rotation_policy":                    "",
 			"rotation_period":                    time.Duration(0).Seconds(),
@@ -151,6 +153,8 @@ func TestBackend_config_connection(t *testing.T) {
 			"plugin_version":                     "",
 			"verify_connection":                  false,
 			"skip_static_role_import_rotation":   false,
+			"health_check_interval":              int64(0),
+			"circuit_breaker_threshold":          3,
 			"rotation_schedule":                  "",
 			"rotation_policy":                    "",
 			"rotation_period":                    time.Duration(0).Seconds(),
@@ -202,6 +206,8 @@ func TestBackend_config_connection(t *testing.T) {
 			"plugin_version":                     "",
 			"verify_connection":                  false,
 			"skip_static_role_import_rotation":   false,
+			"health_check_interval":              int64(0),
+			"circuit_breaker_threshold":          3,
 			"rotation_schedule":                  "",
 			"rotation_policy":                    "",
 			"rotation_period":                    time.Duration(0).Seconds(),
@@ -353,6 +359,8 @@ func TestBackend_connectionCrud(t *testing.T) {
 		"plugin_version":                     "",
 		"verify_connection":                  false,
 		"skip_static_role_import_rotation":   false,
+		"health_check_interval":              json.Number("0"),
+		"circuit_breaker_threshold":          json.Number("3"),
 		"rotation_schedule":                  "",
 		"rotation_policy":                    "",
 		"rotation_period":                    json.Number("0"),
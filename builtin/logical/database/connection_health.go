@@ -0,0 +1,281 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/armon/go-metrics"
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const defaultCircuitBreakerThreshold = 3
+
+// newHealthCheckDatabase creates the plugin instance used to ping the database.
+// It's a variable so tests can substitute a mock.
+var newHealthCheckDatabase = newDatabaseWrapper
+
+// healthCheckTickInterval is how often connections are looked at to see if
+// their health check is due, which bounds the effective health_check_interval.
+var healthCheckTickInterval = 5 * time.Second
+
+// connectionHealth is the in-memory health of a database connection, as seen
+// by the periodic health checks of this node. It isn't stored or replicated:
+// every node that serves the mount checks the database on its own and keeps
+// its own circuit, which starts closed when the mount is loaded.
+type connectionHealth struct {
+	Healthy             bool
+	ConsecutiveFailures int
+	LastError           string
+	LastCheck           time.Time
+	LastHealthy         time.Time
+	CircuitOpenedAt     time.Time
+
+	// deferredRotations are static roles whose scheduled rotation was held
+	// back by the open circuit, and that are retried as soon as it closes.
+	deferredRotations map[string]struct{}
+}
+
+func (h *connectionHealth) circuitOpen() bool {
+	return h != nil && !h.CircuitOpenedAt.IsZero()
+}
+
+func (h *connectionHealth) responseData() map[string]interface{} {
+	state := "closed"
+	if h.circuitOpen() {
+		state = "open"
+	}
+	data := map[string]interface{}{
+		"healthy":              h.Healthy,
+		"circuit_state":        state,
+		"consecutive_failures": h.ConsecutiveFailures,
+		"last_error":           h.LastError,
+		"last_check":           h.LastCheck.Format(time.RFC3339),
+	}
+	if !h.LastHealthy.IsZero() {
+		data["last_healthy"] = h.LastHealthy.Format(time.RFC3339)
+	}
+	if h.circuitOpen() {
+		data["circuit_opened_at"] = h.CircuitOpenedAt.Format(time.RFC3339)
+	}
+	return data
+}
+
+// connectionHealthData returns the health of the named connection for
+// responses, or nil if it hasn't been checked.
+func (b *databaseBackend) connectionHealthData(name string) map[string]interface{} {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	h, ok := b.health[name]
+	if !ok {
+		return nil
+	}
+	return h.responseData()
+}
+
+// clearConnectionHealth forgets the health of the named connection, which
+// closes its circuit. It's called when the connection is reconfigured.
+func (b *databaseBackend) clearConnectionHealth(name string) {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	delete(b.health, name)
+}
+
+// checkCircuit returns a 503 if the circuit of the named connection is open,
+// so that requests fail fast instead of waiting on connection timeouts.
+func (b *databaseBackend) checkCircuit(name string) error {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	h := b.health[name]
+	if !h.circuitOpen() {
+		return nil
+	}
+	return logical.CodedError(http.StatusServiceUnavailable,
+		fmt.Sprintf("database connection %q is unavailable after %d failed health checks: %s", name, h.ConsecutiveFailures, h.LastError))
+}
+
+// deferRotationForCircuit reports whether the circuit of the named connection
+// is open, in which case the static role is retried once it closes.
+func (b *databaseBackend) deferRotationForCircuit(name, roleName string) bool {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	h := b.health[name]
+	if !h.circuitOpen() {
+		return false
+	}
+	if h.deferredRotations == nil {
+		h.deferredRotations = make(map[string]struct{})
+	}
+	h.deferredRotations[roleName] = struct{}{}
+	return true
+}
+
+// runHealthChecks periodically checks the health of the connections that have
+// a health_check_interval, until the context is canceled.
+func (b *databaseBackend) runHealthChecks(ctx context.Context, s logical.Storage) {
+	tick := time.NewTicker(healthCheckTickInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			b.checkConnectionsHealth(ctx, s)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkConnectionsHealth checks the health of every connection whose health
+// check is due.
+func (b *databaseBackend) checkConnectionsHealth(ctx context.Context, s logical.Storage) {
+	names, err := s.List(ctx, databaseConfigPath)
+	if err != nil {
+		b.Logger().Warn("unable to list connections for health checks", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return
+		}
+
+		config, err := b.DatabaseConfig(ctx, s, name)
+		if err != nil {
+			b.Logger().Warn("unable to read connection for health check", "name", name, "error", err)
+			continue
+		}
+		if config.HealthCheckInterval <= 0 {
+			continue
+		}
+
+		b.healthLock.Lock()
+		h := b.health[name]
+		due := h == nil || now.Sub(h.LastCheck) >= config.HealthCheckInterval
+		b.healthLock.Unlock()
+		if due {
+			b.checkConnectionHealth(ctx, s, name, config)
+		}
+	}
+}
+
+// checkConnectionHealth pings the database and updates the health and circuit
+// of the connection.
+func (b *databaseBackend) checkConnectionHealth(ctx context.Context, s logical.Storage, name string, config *DatabaseConfig) {
+	err := b.pingConnection(ctx, s, name)
+
+	b.healthLock.Lock()
+	h, ok := b.health[name]
+	if !ok {
+		h = &connectionHealth{}
+		b.health[name] = h
+	}
+
+	now := time.Now()
+	h.LastCheck = now
+	wasOpen := h.circuitOpen()
+	var retry []string
+	if err == nil {
+		h.Healthy = true
+		h.LastHealthy = now
+		h.LastError = ""
+		h.ConsecutiveFailures = 0
+		h.CircuitOpenedAt = time.Time{}
+		for roleName := range h.deferredRotations {
+			retry = append(retry, roleName)
+		}
+		h.deferredRotations = nil
+	} else {
+		h.Healthy = false
+		h.LastError = err.Error()
+		h.ConsecutiveFailures++
+		if !wasOpen && config.CircuitBreakerThreshold > 0 && h.ConsecutiveFailures >= config.CircuitBreakerThreshold {
+			h.CircuitOpenedAt = now
+		}
+	}
+	isOpen := h.circuitOpen()
+	b.healthLock.Unlock()
+
+	labels := []metrics.Label{{Name: "name", Value: name}}
+	var healthy float32
+	if err == nil {
+		healthy = 1
+	}
+	metrics.SetGaugeWithLabels([]string{"secrets", "database", "connection", "healthy"}, healthy, labels)
+
+	switch {
+	case !wasOpen && isOpen:
+		b.Logger().Warn("database connection circuit opened", "name", name, "failures", config.CircuitBreakerThreshold, "error", err)
+		metrics.IncrCounterWithLabels([]string{"secrets", "database", "connection", "circuit_open"}, 1, labels)
+		b.dbEvent(ctx, "connection-circuit-open", "", name, false)
+	case wasOpen && !isOpen:
+		b.Logger().Info("database connection recovered, circuit closed", "name", name, "deferred_rotations", len(retry))
+		b.dbEvent(ctx, "connection-circuit-close", "", name, false)
+		b.retryDeferredRotations(retry)
+	case err != nil:
+		b.Logger().Debug("database connection health check failed", "name", name, "error", err)
+	}
+}
+
+// pingConnection connects to the database through a plugin instance of its
+// own, initialized with the stored configuration and connection verification,
+// since the dbplugin.Database interface has no ping. The instance serving
+// requests is left alone, so health checks never wait on or hold its lock.
+func (b *databaseBackend) pingConnection(ctx context.Context, s logical.Storage, name string) error {
+	config, err := b.DatabaseConfig(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	// The running instance is only looked up for its plugin version
+	dbi, err := b.GetConnectionWithConfig(ctx, name, config)
+	if err != nil {
+		return err
+	}
+
+	dbw, err := newHealthCheckDatabase(ctx, config.PluginName, dbi.runningPluginVersion, b.System(), b.logger)
+	if err != nil {
+		return fmt.Errorf("unable to create database instance: %w", err)
+	}
+
+	_, err = b.initializeConnection(ctx, dbw, v5.InitializeRequest{
+		Config:           config.ConnectionDetails,
+		VerifyConnection: true,
+	})
+	if err != nil {
+		b.closeDatabaseWrapperAfterInitError(dbw, err)
+		return err
+	}
+	return dbw.Close()
+}
+
+// retryDeferredRotations moves the static roles whose rotation was deferred by
+// an open circuit to the front of the rotation queue.
+func (b *databaseBackend) retryDeferredRotations(roleNames []string) {
+	if b.credRotationQueue == nil {
+		return
+	}
+	now := time.Now().Unix()
+	for _, roleName := range roleNames {
+		item, err := b.popFromRotationQueueByKey(roleName)
+		if err != nil {
+			continue
+		}
+		if item.Priority > now {
+			item.Priority = now
+		}
+		if err := b.pushItem(item); err != nil {
+			b.Logger().Warn("unable to push item on to queue", "role", roleName, "error", err)
+		}
+	}
+}
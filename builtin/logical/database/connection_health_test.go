@@ -0,0 +1,135 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/helper/pluginutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setHealthCheckDatabase makes health checks ping the database through the
+// returned mock.
+func setHealthCheckDatabase(t *testing.T) *mockNewDatabase {
+	t.Helper()
+	pingDB := &mockNewDatabase{}
+	pingDB.On("Close").Return(nil)
+
+	orig := newHealthCheckDatabase
+	t.Cleanup(func() { newHealthCheckDatabase = orig })
+	newHealthCheckDatabase = func(context.Context, string, string, pluginutil.LookRunnerUtil, log.Logger) (databaseVersionWrapper, error) {
+		return databaseVersionWrapper{v5: pingDB}, nil
+	}
+	return pingDB
+}
+
+// setMockInitialize replaces the Initialize expectation of the mock, which the
+// health checks use to ping the database.
+func setMockInitialize(mockDB *mockNewDatabase, err error) {
+	var calls []*mock.Call
+	for _, call := range mockDB.ExpectedCalls {
+		if call.Method != "Initialize" {
+			calls = append(calls, call)
+		}
+	}
+	mockDB.ExpectedCalls = calls
+	mockDB.On("Initialize", mock.Anything, mock.Anything).Return(v5.InitializeResponse{}, err)
+}
+
+func TestConnectionHealth_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	pingDB := setHealthCheckDatabase(t)
+
+	config := &DatabaseConfig{
+		AllowedRoles:            []string{"*"},
+		HealthCheckInterval:     time.Minute,
+		CircuitBreakerThreshold: 2,
+	}
+	require.NoError(t, storeConfig(ctx, storage, mockv5, config))
+	createRole(t, b, storage, mockDB, "alpha")
+
+	role, err := b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	password := role.StaticAccount.Password
+
+	readHealth := func() map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "config/" + mockv5,
+			Storage:   storage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(60), resp.Data["health_check_interval"])
+		return resp.Data["health"].(map[string]interface{})
+	}
+
+	// The circuit opens after the threshold of failed health checks
+	setMockInitialize(pingDB, errors.New("connection refused"))
+	b.checkConnectionsHealth(ctx, storage)
+	require.Equal(t, "closed", readHealth()["circuit_state"])
+	require.NoError(t, b.checkCircuit(mockv5))
+
+	// Health checks aren't repeated before the interval is up
+	b.checkConnectionsHealth(ctx, storage)
+	require.Equal(t, 1, readHealth()["consecutive_failures"])
+
+	b.checkConnectionHealth(ctx, storage, mockv5, config)
+	health := readHealth()
+	require.Equal(t, "open", health["circuit_state"])
+	require.Equal(t, false, health["healthy"])
+	require.Contains(t, health["last_error"], "connection refused")
+
+	// Requests fail fast with a 503
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-role/alpha",
+		Storage:   storage,
+	})
+	var codedErr logical.HTTPCodedError
+	require.ErrorAs(t, err, &codedErr)
+	require.Equal(t, http.StatusServiceUnavailable, codedErr.Code())
+
+	// Scheduled rotations are held back
+	item, err := b.popFromRotationQueueByKey("alpha")
+	require.NoError(t, err)
+	item.Priority = time.Now().Unix()
+	require.NoError(t, b.pushItem(item))
+	require.True(t, b.rotateCredential(ctx, storage))
+	role, err = b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	require.Equal(t, password, role.StaticAccount.Password)
+
+	// Once the database recovers, the circuit closes and the held back
+	// rotation runs on the next tick
+	setMockInitialize(pingDB, nil)
+	b.checkConnectionHealth(ctx, storage, mockv5, config)
+	health = readHealth()
+	require.Equal(t, "closed", health["circuit_state"])
+	require.Equal(t, true, health["healthy"])
+	require.NoError(t, b.checkCircuit(mockv5))
+
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+	require.True(t, b.rotateCredential(ctx, storage))
+	role, err = b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	require.NotEqual(t, password, role.StaticAccount.Password)
+
+	// The health checks never touched the instance serving requests
+	mockDB.AssertNotCalled(t, "Initialize", mock.Anything, mock.Anything)
+	pingDB.AssertNumberOfCalls(t, "Close", 3)
+}
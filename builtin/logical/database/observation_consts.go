@@ -57,4 +57,12 @@ const (
 	ObservationTypeDatabaseStaticRoleDelete = "database/static-role/delete"
 
 	ObservationTypeDatabaseStaticCredentialRead = "database/static-credential/read"
+
+	// Library observations
+
+	ObservationTypeDatabaseLibraryCheckOut = "database/library/check-out"
+	// ObservationTypeDatabaseLibraryCheckIn is emitted whenever an account is
+	// checked in on request. Note that this observation does not include a
+	// connection_name, to avoid doing an additional storage read.
+	ObservationTypeDatabaseLibraryCheckIn = "database/library/check-in"
 )
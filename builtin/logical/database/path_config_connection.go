@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -46,6 +47,14 @@ type DatabaseConfig struct {
 	// false. Enterprise only.
 	SkipStaticRoleImportRotation bool `json:"skip_static_role_import_rotation" structs:"skip_static_role_import_rotation" mapstructure:"skip_static_role_import_rotation"`
 
+	// HealthCheckInterval is how often the connection is pinged to track its
+	// health. Zero disables health checks, and with them the circuit breaker.
+	HealthCheckInterval time.Duration `json:"health_check_interval" structs:"health_check_interval" mapstructure:"health_check_interval"`
+	// CircuitBreakerThreshold is the number of consecutive failed health
+	// checks after which requests needing the database fail fast, until a
+	// health check succeeds again. Zero never opens the circuit.
+	CircuitBreakerThreshold int `json:"circuit_breaker_threshold" structs:"circuit_breaker_threshold" mapstructure:"circuit_breaker_threshold"`
+
 	automatedrotationutil.AutomatedRotationParams
 }
 
@@ -271,6 +280,19 @@ func pathConfigurePluginConnection(b *databaseBackend) *framework.Path {
 			Type:        framework.TypeString,
 			Description: `Password policy to use when generating passwords.`,
 		},
+		"health_check_interval": {
+			Type: framework.TypeDurationSecond,
+			Description: `How often to ping the database to track the health of
+				the connection. Defaults to 0, which disables health checks.`,
+		},
+		"circuit_breaker_threshold": {
+			Type:    framework.TypeInt,
+			Default: defaultCircuitBreakerThreshold,
+			Description: `Number of consecutive failed health checks after
+				which requests that need the database fail fast with a 503,
+				until a health check succeeds. 0 disables the circuit breaker.
+				Defaults to 3. The circuit is tracked by each node separately.`,
+		},
 	}
 	AddConnectionFieldsEnt(fields)
 	automatedrotationutil.AddAutomatedRotationFields(fields)
@@ -387,6 +409,7 @@ func (b *databaseBackend) connectionDeleteHandler() framework.OperationFunc {
 		if err := b.ClearConnection(name); err != nil {
 			return nil, err
 		}
+		b.clearConnectionHealth(name)
 
 		b.dbEvent(ctx, "config-delete", req.Path, name, true)
 		recordDatabaseObservation(ctx, b, req, name, ObservationTypeDatabaseConfigDelete)
@@ -444,6 +467,18 @@ accepts:
 	* "verify_connection" (default: true) - A boolean value denoting if the plugin should verify
 	   it is able to connect to the database using the provided connection
        details.
+
+	* "health_check_interval" (default: 0) - How often to ping the database. The
+	   health of the connection is returned when reading this path.
+
+	* "circuit_breaker_threshold" (default: 3) - The number of consecutive failed
+	   health checks after which credential requests and rotations for the
+	   connection fail fast, until a health check succeeds again. Static role
+	   rotations held back in the meantime are retried once it does.
+
+	The health and circuit of a connection are kept in memory by each node
+	that serves the mount, which runs its own health checks. They aren't
+	replicated between nodes, and start over when the mount is reloaded.
 `
 
 const pathResetConnectionHelpSyn = `
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"time"
 
 	"github.com/fatih/structs"
 	"github.com/hashicorp/go-uuid"
@@ -96,6 +97,22 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 			config.SkipStaticRoleImportRotation = skipImportRotationRaw.(bool)
 		}
 
+		if healthCheckIntervalRaw, ok := data.GetOk("health_check_interval"); ok {
+			config.HealthCheckInterval = time.Duration(healthCheckIntervalRaw.(int)) * time.Second
+		}
+		if config.HealthCheckInterval < 0 {
+			return logical.ErrorResponse("health_check_interval cannot be negative"), nil
+		}
+
+		if thresholdRaw, ok := data.GetOk("circuit_breaker_threshold"); ok {
+			config.CircuitBreakerThreshold = thresholdRaw.(int)
+		} else if req.Operation == logical.CreateOperation {
+			config.CircuitBreakerThreshold = data.Get("circuit_breaker_threshold").(int)
+		}
+		if config.CircuitBreakerThreshold < 0 {
+			return logical.ErrorResponse("circuit_breaker_threshold cannot be negative"), nil
+		}
+
 		if err := config.ParseAutomatedRotationFields(data); err != nil {
 			return logical.ErrorResponse(err.Error()), nil
 		}
@@ -110,6 +127,8 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 		delete(data.Raw, "root_rotation_statements")
 		delete(data.Raw, "password_policy")
 		delete(data.Raw, "skip_static_role_import_rotation")
+		delete(data.Raw, "health_check_interval")
+		delete(data.Raw, "circuit_breaker_threshold")
 		delete(data.Raw, "rotation_schedule")
 		delete(data.Raw, "rotation_window")
 		delete(data.Raw, "rotation_period")
@@ -166,6 +185,7 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 		if oldConn != nil {
 			oldConn.Close()
 		}
+		b.clearConnectionHealth(name)
 
 		var performedRotationManagerOpern string
 		if config.ShouldDeregisterRotationJob() {
@@ -381,6 +401,10 @@ func (b *databaseBackend) connectionReadHandler() framework.OperationFunc {
 		}
 
 		resp.Data = structs.New(config).Map()
+		resp.Data["health_check_interval"] = int64(config.HealthCheckInterval.Seconds())
+		if health := b.connectionHealthData(name); health != nil {
+			resp.Data["health"] = health
+		}
 		config.PopulateAutomatedRotationData(resp.Data)
 		// remove extra nested AutomatedRotationParams key
 		// before returning response
@@ -119,6 +119,10 @@ func (b *databaseBackend) pathCredsCreateRead() framework.OperationFunc {
 				role.CredentialType.String()), nil
 		}
 
+		if err := b.checkCircuit(role.DBName); err != nil {
+			return nil, err
+		}
+
 		// Get the Database object
 		dbi, err := b.GetConnection(ctx, req.Storage, role.DBName)
 		if err != nil {
@@ -252,8 +256,18 @@ func (b *databaseBackend) pathStaticCredsRead() framework.OperationFunc {
 			return nil, fmt.Errorf("%q is not an allowed role", name)
 		}
 
+		// The credential of a checked out account is only returned to its
+		// borrower, on check-out
+		co, err := b.checkOut(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if co != nil {
+			return logical.ErrorResponse("static role %q is checked out of library set %q", name, co.SetName), nil
+		}
+
 		respData := map[string]interface{}{
-			"username": role.StaticAccount.Username,
+			"username": role.StaticAccount.CurrentUsername(),
 			"ttl":      role.StaticAccount.CredentialTTL().Seconds(),
 		}
 		if !role.StaticAccount.LastVaultRotation.IsZero() {
@@ -273,9 +287,13 @@ func (b *databaseBackend) pathStaticCredsRead() framework.OperationFunc {
 
 		switch role.CredentialType {
 		case v5.CredentialTypePassword:
-			respData["password"] = role.StaticAccount.Password
+			respData["password"] = role.StaticAccount.CurrentPassword()
 		case v5.CredentialTypeRSAPrivateKey:
-			respData["rsa_private_key"] = string(role.StaticAccount.PrivateKey)
+			respData["rsa_private_key"] = string(role.StaticAccount.CurrentPrivateKey())
+		}
+
+		if role.StaticAccount.IsDualAccount() {
+			respData["accounts"] = dualAccountCreds(role)
 		}
 
 		recordDatabaseObservation(ctx, b, req, role.DBName, ObservationTypeDatabaseStaticCredentialRead,
@@ -288,6 +306,41 @@ func (b *databaseBackend) pathStaticCredsRead() framework.OperationFunc {
 	}
 }
 
+// dualAccountCreds returns both users of a dual-account static role with
+// their credentials and the time until which each is expected to remain
+// valid. A user that Vault has not set a credential for yet is left out.
+func dualAccountCreds(role *roleEntry) []map[string]interface{} {
+	s := role.StaticAccount
+	accounts := []struct {
+		username   string
+		password   string
+		privateKey []byte
+	}{
+		{s.Username, s.Password, s.PrivateKey},
+		{s.DualAccount.Username, s.DualAccount.Password, s.DualAccount.PrivateKey},
+	}
+
+	var creds []map[string]interface{}
+	for _, account := range accounts {
+		if account.password == "" && len(account.privateKey) == 0 {
+			continue
+		}
+		cred := map[string]interface{}{
+			"username":    account.username,
+			"current":     account.username == s.CurrentUsername(),
+			"valid_until": s.ValidUntil(account.username),
+		}
+		switch role.CredentialType {
+		case v5.CredentialTypePassword:
+			cred["password"] = account.password
+		case v5.CredentialTypeRSAPrivateKey:
+			cred["rsa_private_key"] = string(account.privateKey)
+		}
+		creds = append(creds, cred)
+	}
+	return creds
+}
+
 const pathCredsCreateReadHelpSyn = `
 Request database credentials for a certain role.
 `
@@ -307,4 +360,8 @@ const pathStaticCredsReadHelpDesc = `
 This path reads database credentials for a certain static role. The database
 credentials are rotated periodically according to their configuration, and will
 return the same password until they are rotated.
+
+For static roles with a "dual_account_username", the username and credential
+are those of the current account, and "accounts" lists both accounts with the
+time until which each credential remains valid.
 `
@@ -0,0 +1,290 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	defaultLibraryTTL    = 24 * time.Hour
+	defaultLibraryMaxTTL = 24 * time.Hour
+)
+
+func pathListLibrarySets(b *databaseBackend) *framework.Path {
+	return &framework.Path{
+		Pattern: "library/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixDatabase,
+			OperationVerb:   "list",
+			OperationSuffix: "library-sets",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathLibrarySetList,
+		},
+
+		HelpSynopsis:    pathLibrarySetHelpSyn,
+		HelpDescription: pathLibrarySetHelpDesc,
+	}
+}
+
+func pathLibrarySets(b *databaseBackend) *framework.Path {
+	return &framework.Path{
+		Pattern: "library/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixDatabase,
+			OperationSuffix: "library-set",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the library set.",
+			},
+			"static_roles": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `Static roles whose accounts make up the library set.
+	Each static role can only belong to one library set.`,
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Default and maximum time an account may be checked out for before renewal. Defaults to 24 hours.",
+			},
+			"max_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Maximum time an account may be checked out for, including renewals. Defaults to 24 hours.",
+			},
+			"disable_check_in_enforcement": {
+				Type:        framework.TypeBool,
+				Description: "Allow any caller to check in accounts, rather than only the entity or token that checked them out.",
+			},
+		},
+
+		ExistenceCheck: b.pathLibrarySetExistenceCheck,
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathLibrarySetRead,
+			logical.CreateOperation: b.pathLibrarySetCreateUpdate,
+			logical.UpdateOperation: b.pathLibrarySetCreateUpdate,
+			logical.DeleteOperation: b.pathLibrarySetDelete,
+		},
+
+		HelpSynopsis:    pathLibrarySetHelpSyn,
+		HelpDescription: pathLibrarySetHelpDesc,
+	}
+}
+
+// librarySet is a pool of static role accounts that can be checked out
+// exclusively.
+type librarySet struct {
+	StaticRoles               []string      `json:"static_roles"`
+	TTL                       time.Duration `json:"ttl"`
+	MaxTTL                    time.Duration `json:"max_ttl"`
+	DisableCheckInEnforcement bool          `json:"disable_check_in_enforcement"`
+}
+
+func (b *databaseBackend) librarySet(ctx context.Context, s logical.Storage, name string) (*librarySet, error) {
+	entry, err := s.Get(ctx, databaseLibraryPath+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var set librarySet
+	if err := entry.DecodeJSON(&set); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+func (b *databaseBackend) pathLibrarySetExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	set, err := b.librarySet(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return set != nil, nil
+}
+
+func (b *databaseBackend) pathLibrarySetList(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, databaseLibraryPath)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(entries), nil
+}
+
+func (b *databaseBackend) pathLibrarySetRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	set, err := b.librarySet(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"static_roles":                 set.StaticRoles,
+			"ttl":                          int64(set.TTL.Seconds()),
+			"max_ttl":                      int64(set.MaxTTL.Seconds()),
+			"disable_check_in_enforcement": set.DisableCheckInEnforcement,
+		},
+	}, nil
+}
+
+func (b *databaseBackend) pathLibrarySetCreateUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	set, err := b.librarySet(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		set = &librarySet{
+			TTL:    defaultLibraryTTL,
+			MaxTTL: defaultLibraryMaxTTL,
+		}
+	}
+
+	if staticRolesRaw, ok := data.GetOk("static_roles"); ok {
+		staticRoles := strutil.RemoveDuplicates(staticRolesRaw.([]string), false)
+
+		// Accounts can't be dropped from the set while they're checked out,
+		// since they could then never be checked back in.
+		for _, roleName := range set.StaticRoles {
+			if strutil.StrListContains(staticRoles, roleName) {
+				continue
+			}
+			co, err := b.checkOut(ctx, req.Storage, roleName)
+			if err != nil {
+				return nil, err
+			}
+			if co != nil {
+				return logical.ErrorResponse("static role %q is checked out and cannot be removed from the library set", roleName), nil
+			}
+		}
+
+		for _, roleName := range staticRoles {
+			role, err := b.StaticRole(ctx, req.Storage, roleName)
+			if err != nil {
+				return nil, err
+			}
+			if role == nil {
+				return logical.ErrorResponse("static role %q does not exist", roleName), nil
+			}
+
+			owner, err := b.librarySetForStaticRole(ctx, req.Storage, roleName)
+			if err != nil {
+				return nil, err
+			}
+			if owner != "" && owner != name {
+				return logical.ErrorResponse("static role %q already belongs to library set %q", roleName, owner), nil
+			}
+		}
+		set.StaticRoles = staticRoles
+	}
+	if len(set.StaticRoles) == 0 {
+		return logical.ErrorResponse("static_roles is required"), nil
+	}
+
+	if ttlRaw, ok := data.GetOk("ttl"); ok {
+		set.TTL = time.Duration(ttlRaw.(int)) * time.Second
+	}
+	if maxTTLRaw, ok := data.GetOk("max_ttl"); ok {
+		set.MaxTTL = time.Duration(maxTTLRaw.(int)) * time.Second
+	}
+	if set.MaxTTL > 0 && set.TTL > set.MaxTTL {
+		return logical.ErrorResponse("ttl cannot be greater than max_ttl"), nil
+	}
+	if disableRaw, ok := data.GetOk("disable_check_in_enforcement"); ok {
+		set.DisableCheckInEnforcement = disableRaw.(bool)
+	}
+
+	entry, err := logical.StorageEntryJSON(databaseLibraryPath+name, set)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	b.dbEvent(ctx, fmt.Sprintf("library-set-%s", req.Operation), req.Path, name, true)
+	return nil, nil
+}
+
+func (b *databaseBackend) pathLibrarySetDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	set, err := b.librarySet(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, nil
+	}
+
+	for _, roleName := range set.StaticRoles {
+		co, err := b.checkOut(ctx, req.Storage, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if co != nil {
+			return logical.ErrorResponse("static role %q is checked out; check it in before deleting the library set", roleName), nil
+		}
+	}
+
+	if err := req.Storage.Delete(ctx, databaseLibraryPath+name); err != nil {
+		return nil, err
+	}
+
+	b.dbEvent(ctx, "library-set-delete", req.Path, name, true)
+	return nil, nil
+}
+
+// librarySetForStaticRole returns the name of the library set the static role
+// belongs to, or an empty string if it isn't part of one.
+func (b *databaseBackend) librarySetForStaticRole(ctx context.Context, s logical.Storage, roleName string) (string, error) {
+	names, err := s.List(ctx, databaseLibraryPath)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		set, err := b.librarySet(ctx, s, name)
+		if err != nil {
+			return "", err
+		}
+		if set != nil && strutil.StrListContains(set.StaticRoles, roleName) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+const pathLibrarySetHelpSyn = `
+Manage library sets of static role accounts that can be checked out.
+`
+
+const pathLibrarySetHelpDesc = `
+A library set is a pool of static roles whose accounts can be checked out
+exclusively through "library/<name>/check-out" and returned through
+"library/<name>/check-in". Checked out accounts are not rotated on their
+schedule; instead, their credentials are rotated when they are checked in,
+either explicitly or when the check-out lease expires or is revoked.
+`
@@ -0,0 +1,563 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/go-uuid"
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/queue"
+)
+
+func pathLibraryCheckOut(b *databaseBackend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "library/" + framework.GenericNameRegex("name") + "/check-out$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationVerb:   "check-out",
+				OperationSuffix: "library-account",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the library set.",
+				},
+				"ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Time the account is checked out for. Capped at the ttl of the library set.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:                    b.pathLibraryCheckOut,
+					ForwardPerformanceStandby:   true,
+					ForwardPerformanceSecondary: true,
+				},
+			},
+
+			HelpSynopsis:    pathLibraryCheckOutHelpSyn,
+			HelpDescription: pathLibraryCheckOutHelpDesc,
+		},
+		{
+			Pattern: "library/" + framework.GenericNameRegex("name") + "/check-in$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationVerb:   "check-in",
+				OperationSuffix: "library-accounts",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the library set.",
+				},
+				"static_roles": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "Static roles to check in. Defaults to all accounts of the set checked out by the caller.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:                    b.pathLibraryCheckIn(false),
+					ForwardPerformanceStandby:   true,
+					ForwardPerformanceSecondary: true,
+				},
+			},
+
+			HelpSynopsis:    pathLibraryCheckInHelpSyn,
+			HelpDescription: pathLibraryCheckInHelpDesc,
+		},
+		{
+			Pattern: "library/manage/" + framework.GenericNameRegex("name") + "/check-in$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationVerb:   "force-check-in",
+				OperationSuffix: "library-accounts",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the library set.",
+				},
+				"static_roles": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "Static roles to check in, regardless of who checked them out.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:                    b.pathLibraryCheckIn(true),
+					ForwardPerformanceStandby:   true,
+					ForwardPerformanceSecondary: true,
+				},
+			},
+
+			HelpSynopsis:    pathLibraryManageCheckInHelpSyn,
+			HelpDescription: pathLibraryManageCheckInHelpDesc,
+		},
+		{
+			Pattern: "library/" + framework.GenericNameRegex("name") + "/status$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationVerb:   "read",
+				OperationSuffix: "library-status",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the library set.",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation: b.pathLibraryStatus,
+			},
+
+			HelpSynopsis:    pathLibraryStatusHelpSyn,
+			HelpDescription: pathLibraryStatusHelpDesc,
+		},
+	}
+}
+
+// checkOut records that a static role account is checked out of a library
+// set, and by whom. It's stored under the name of the static role so that
+// an account can never be checked out twice.
+type checkOut struct {
+	// ID ties the check-out to its lease, so that revoking a lease that
+	// outlived its check-out doesn't check in a later one.
+	ID                          string    `json:"id"`
+	SetName                     string    `json:"set_name"`
+	BorrowerEntityID            string    `json:"borrower_entity_id"`
+	BorrowerClientTokenAccessor string    `json:"borrower_client_token_accessor"`
+	CheckedOutAt                time.Time `json:"checked_out_at"`
+	Expiration                  time.Time `json:"expiration"`
+
+	// PendingRotation is set when the account was checked in but rotating
+	// its credential failed. The account stays unavailable until the static
+	// role rotation queue rotates it.
+	PendingRotation bool `json:"pending_rotation,omitempty"`
+}
+
+// borrowedBy returns true if the caller of the request checked out the
+// account.
+func (c *checkOut) borrowedBy(req *logical.Request) bool {
+	if c.BorrowerEntityID != "" && c.BorrowerEntityID == req.EntityID {
+		return true
+	}
+	return c.BorrowerClientTokenAccessor != "" && c.BorrowerClientTokenAccessor == req.ClientTokenAccessor
+}
+
+func (b *databaseBackend) checkOut(ctx context.Context, s logical.Storage, roleName string) (*checkOut, error) {
+	entry, err := s.Get(ctx, databaseCheckOutPath+roleName)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var co checkOut
+	if err := entry.DecodeJSON(&co); err != nil {
+		return nil, err
+	}
+	return &co, nil
+}
+
+func (b *databaseBackend) storeCheckOut(ctx context.Context, s logical.Storage, roleName string, co *checkOut) error {
+	entry, err := logical.StorageEntryJSON(databaseCheckOutPath+roleName, co)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func (b *databaseBackend) pathLibraryCheckOut(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	setName := data.Get("name").(string)
+
+	set, err := b.librarySet(ctx, req.Storage, setName)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return logical.ErrorResponse("unknown library set: %s", setName), nil
+	}
+
+	ttl := set.TTL
+	if ttlRaw, ok := data.GetOk("ttl"); ok {
+		if requested := time.Duration(ttlRaw.(int)) * time.Second; requested > 0 && (ttl == 0 || requested < ttl) {
+			ttl = requested
+		}
+	}
+
+	for _, roleName := range set.StaticRoles {
+		resp, err := b.checkOutRole(ctx, req, setName, roleName, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil {
+			return resp, nil
+		}
+	}
+
+	return logical.ErrorResponse("no accounts available for check-out in library set %q", setName), nil
+}
+
+// checkOutRole checks out the account of a static role of the library set if
+// it's available, and returns nil otherwise. The role lock is held so that
+// the credential isn't rotated while it's handed out, and is taken before the
+// library lock like on check-in.
+func (b *databaseBackend) checkOutRole(ctx context.Context, req *logical.Request, setName, roleName string, ttl time.Duration) (*logical.Response, error) {
+	lock := locksutil.LockForKey(b.roleLocks, roleName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	// The set may have changed before the locks were taken
+	set, err := b.librarySet(ctx, req.Storage, setName)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil || !strutil.StrListContains(set.StaticRoles, roleName) {
+		return nil, nil
+	}
+
+	co, err := b.checkOut(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if co != nil {
+		return nil, nil
+	}
+
+	role, err := b.StaticRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		b.Logger().Warn("static role of library set not found", "set", setName, "role", roleName)
+		return nil, nil
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	co = &checkOut{
+		ID:                          id,
+		SetName:                     setName,
+		BorrowerEntityID:            req.EntityID,
+		BorrowerClientTokenAccessor: req.ClientTokenAccessor,
+		CheckedOutAt:                now,
+		Expiration:                  now.Add(ttl),
+	}
+	if err := b.storeCheckOut(ctx, req.Storage, roleName, co); err != nil {
+		return nil, err
+	}
+
+	respData := map[string]interface{}{
+		"static_role": roleName,
+		"username":    role.StaticAccount.CurrentUsername(),
+	}
+	switch role.CredentialType {
+	case v5.CredentialTypePassword:
+		respData["password"] = role.StaticAccount.CurrentPassword()
+	case v5.CredentialTypeRSAPrivateKey:
+		respData["rsa_private_key"] = string(role.StaticAccount.CurrentPrivateKey())
+	}
+
+	resp := b.Secret(SecretLibraryCredsType).Response(respData, map[string]interface{}{
+		"set_name":     setName,
+		"static_role":  roleName,
+		"check_out_id": id,
+	})
+	resp.Secret.TTL = ttl
+	resp.Secret.MaxTTL = set.MaxTTL
+
+	b.dbEvent(ctx, "library-check-out", req.Path, roleName, true, "set_name", setName)
+	recordDatabaseObservation(ctx, b, req, role.DBName, ObservationTypeDatabaseLibraryCheckOut,
+		AdditionalDatabaseMetadata{key: "set_name", value: setName},
+		AdditionalDatabaseMetadata{key: "role_name", value: roleName},
+		AdditionalDatabaseMetadata{key: "ttl", value: ttl.String()})
+	return resp, nil
+}
+
+func (b *databaseBackend) pathLibraryCheckIn(force bool) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+		setName := data.Get("name").(string)
+
+		set, err := b.librarySet(ctx, req.Storage, setName)
+		if err != nil {
+			return nil, err
+		}
+		if set == nil {
+			return logical.ErrorResponse("unknown library set: %s", setName), nil
+		}
+
+		requested := data.Get("static_roles").([]string)
+		if force && len(requested) == 0 {
+			return logical.ErrorResponse("static_roles is required"), nil
+		}
+		for _, roleName := range requested {
+			if !strutil.StrListContains(set.StaticRoles, roleName) {
+				return logical.ErrorResponse("static role %q does not belong to library set %q", roleName, setName), nil
+			}
+		}
+
+		// Work out which accounts to check in before rotating any of them, so
+		// that a request naming someone else's account has no effect.
+		var toCheckIn []string
+		for _, roleName := range set.StaticRoles {
+			if len(requested) > 0 && !strutil.StrListContains(requested, roleName) {
+				continue
+			}
+			co, err := b.checkOut(ctx, req.Storage, roleName)
+			if err != nil {
+				return nil, err
+			}
+			if co == nil {
+				continue
+			}
+			if !force && !co.borrowedBy(req) {
+				if len(requested) == 0 {
+					continue
+				}
+				if !set.DisableCheckInEnforcement {
+					return logical.ErrorResponse("static role %q was checked out by another caller", roleName), nil
+				}
+			}
+			toCheckIn = append(toCheckIn, roleName)
+		}
+
+		for _, roleName := range toCheckIn {
+			if err := b.checkIn(ctx, req.Storage, roleName, ""); err != nil {
+				return nil, fmt.Errorf("unable to check in static role %q: %w", roleName, err)
+			}
+			b.dbEvent(ctx, "library-check-in", req.Path, roleName, true, "set_name", setName)
+			recordDatabaseObservation(ctx, b, req, "", ObservationTypeDatabaseLibraryCheckIn,
+				AdditionalDatabaseMetadata{key: "set_name", value: setName},
+				AdditionalDatabaseMetadata{key: "role_name", value: roleName})
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"check_ins": toCheckIn,
+			},
+		}, nil
+	}
+}
+
+// checkIn rotates the credential of a checked out static role and then makes
+// the account available again. If checkOutID is set, the account is only
+// checked in if it's still under that check-out. If the rotation fails, the
+// check-in is left pending and the static role rotation queue retries the
+// rotation, making the account available once it succeeds.
+func (b *databaseBackend) checkIn(ctx context.Context, s logical.Storage, roleName, checkOutID string) error {
+	lock := locksutil.LockForKey(b.roleLocks, roleName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	co, err := b.checkOut(ctx, s, roleName)
+	if err != nil {
+		return err
+	}
+	if co == nil || (checkOutID != "" && co.ID != checkOutID) {
+		return nil
+	}
+
+	role, err := b.StaticRole(ctx, s, roleName)
+	if err != nil {
+		return err
+	}
+	if role != nil {
+		item, err := b.popFromRotationQueueByKey(roleName)
+		if err != nil {
+			item = &queue.Item{
+				Key: roleName,
+			}
+		}
+
+		input := &setStaticAccountInput{
+			RoleName: roleName,
+			Role:     role,
+		}
+		if walID, ok := item.Value.(string); ok {
+			input.WALID = walID
+		}
+		resp, rotateErr := b.setStaticAccount(ctx, s, input)
+		if rotateErr != nil {
+			item.Priority = time.Now().Add(10 * time.Second).Unix()
+			if resp != nil && resp.WALID != "" {
+				item.Value = resp.WALID
+			}
+		} else {
+			item.Priority = role.StaticAccount.NextRotationTimeFromInput(resp.RotationTime).Unix()
+			item.Value = ""
+		}
+		if err := b.pushItem(item); err != nil {
+			return err
+		}
+		if rotateErr != nil {
+			b.Logger().Warn("unable to rotate credential of checked in account, retrying in the background", "role", roleName, "error", rotateErr)
+			co.PendingRotation = true
+
+			b.libraryLock.Lock()
+			defer b.libraryLock.Unlock()
+			return b.storeCheckOut(ctx, s, roleName, co)
+		}
+	}
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+	return s.Delete(ctx, databaseCheckOutPath+roleName)
+}
+
+// completeCheckIn makes the account of a static role available again if its
+// check-in was pending the rotation of its credential.
+func (b *databaseBackend) completeCheckIn(ctx context.Context, s logical.Storage, roleName string) error {
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	co, err := b.checkOut(ctx, s, roleName)
+	if err != nil {
+		return err
+	}
+	if co == nil || !co.PendingRotation {
+		return nil
+	}
+	return s.Delete(ctx, databaseCheckOutPath+roleName)
+}
+
+func (b *databaseBackend) pathLibraryStatus(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	setName := data.Get("name").(string)
+
+	set, err := b.librarySet(ctx, req.Storage, setName)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, nil
+	}
+
+	status := make(map[string]interface{}, len(set.StaticRoles))
+	for _, roleName := range set.StaticRoles {
+		co, err := b.checkOut(ctx, req.Storage, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if co == nil {
+			status[roleName] = map[string]interface{}{
+				"available": true,
+			}
+			continue
+		}
+
+		accountStatus := map[string]interface{}{
+			"available":      false,
+			"checked_out_at": co.CheckedOutAt,
+			"expiration":     co.Expiration,
+		}
+		if co.PendingRotation {
+			accountStatus["pending_rotation"] = true
+		}
+		if co.BorrowerEntityID != "" {
+			accountStatus["borrower_entity_id"] = co.BorrowerEntityID
+		}
+		if co.BorrowerClientTokenAccessor != "" {
+			accountStatus["borrower_client_token_accessor"] = co.BorrowerClientTokenAccessor
+		}
+		status[roleName] = accountStatus
+	}
+
+	return &logical.Response{
+		Data: status,
+	}, nil
+}
+
+// deferCheckedOutRotation returns the time until which a scheduled rotation
+// of the static role must wait because its account is checked out, or the
+// zero time if it can go ahead.
+func (b *databaseBackend) deferCheckedOutRotation(ctx context.Context, s logical.Storage, roleName string) (time.Time, error) {
+	co, err := b.checkOut(ctx, s, roleName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if co == nil || co.PendingRotation {
+		return time.Time{}, nil
+	}
+
+	// The check-in rotates the account. If the lease has already expired but
+	// hasn't been revoked yet, look again shortly.
+	until := co.Expiration
+	if minimum := time.Now().Add(10 * time.Second); until.Before(minimum) {
+		until = minimum
+	}
+	return until, nil
+}
+
+const pathLibraryCheckOutHelpSyn = `
+Check out an account from a library set.
+`
+
+const pathLibraryCheckOutHelpDesc = `
+This path checks out the first available static role account of the library
+set for the caller, and returns its current credential along with a lease.
+The account is checked back in, and its credential rotated, when the lease
+expires or is revoked. While checked out, the credential can't be read from
+static-creds nor rotated with rotate-role.
+`
+
+const pathLibraryCheckInHelpSyn = `
+Check in accounts checked out from a library set.
+`
+
+const pathLibraryCheckInHelpDesc = `
+This path checks in the given accounts, or all accounts of the set checked out
+by the caller, and rotates their credentials. If a rotation fails, it is
+retried in the background and the account becomes available once it succeeds.
+Unless the library set has
+disable_check_in_enforcement set, only the entity or token that checked out an
+account can check it in.
+`
+
+const pathLibraryManageCheckInHelpSyn = `
+Force the check-in of accounts of a library set.
+`
+
+const pathLibraryManageCheckInHelpDesc = `
+This path checks in the given accounts and rotates their credentials,
+regardless of who checked them out. It is intended for operators.
+`
+
+const pathLibraryStatusHelpSyn = `
+Show which accounts of a library set are checked out, and by whom.
+`
+
+const pathLibraryStatusHelpDesc = `
+This path returns, for each static role of the library set, whether its
+account is available. For checked out accounts, the entity ID and token
+accessor of the borrower and the time of the check-out and of its expiration
+are included. Accounts checked in whose credential is still to be rotated are
+reported with pending_rotation set.
+`
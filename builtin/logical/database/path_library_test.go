@@ -0,0 +1,342 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibrary_CheckOutCheckIn(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+
+	createRole(t, b, storage, mockDB, "alpha")
+	createRole(t, b, storage, mockDB, "beta")
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/ops",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"static_roles": "alpha,missing",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/ops",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"static_roles": "alpha,beta",
+			"ttl":          "1h",
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	checkOut := func(entityID string) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "library/ops/check-out",
+			Storage:   storage,
+			EntityID:  entityID,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	status := func() map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "library/ops/status",
+			Storage:   storage,
+		})
+		require.NoError(t, err)
+		return resp.Data
+	}
+
+	alphaResp := checkOut("entity-1")
+	require.False(t, alphaResp.IsError(), alphaResp.Error())
+	require.Equal(t, "alpha", alphaResp.Data["static_role"])
+	require.Equal(t, time.Hour, alphaResp.Secret.TTL)
+	alphaPassword := alphaResp.Data["password"]
+	require.NotEmpty(t, alphaPassword)
+
+	betaResp := checkOut("entity-2")
+	require.False(t, betaResp.IsError(), betaResp.Error())
+	require.Equal(t, "beta", betaResp.Data["static_role"])
+
+	resp = checkOut("entity-3")
+	require.True(t, resp.IsError())
+
+	require.Equal(t, "entity-1", status()["alpha"].(map[string]interface{})["borrower_entity_id"])
+
+	// The credential of a checked out account can't be read nor rotated
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "static-creds/alpha",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-role/alpha",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	// Scheduled rotations wait until the account is checked in
+	item, err := b.popFromRotationQueueByKey("alpha")
+	require.NoError(t, err)
+	item.Priority = time.Now().Unix()
+	require.NoError(t, b.pushItem(item))
+	require.True(t, b.rotateCredential(ctx, storage))
+	role, err := b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	require.Equal(t, alphaPassword, role.StaticAccount.Password)
+
+	// Only the borrower can check an account in
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/ops/check-in",
+		Storage:   storage,
+		EntityID:  "entity-2",
+		Data: map[string]interface{}{
+			"static_roles": "alpha",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	// Checking in rotates the credential and makes the account available
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/ops/check-in",
+		Storage:   storage,
+		EntityID:  "entity-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"alpha"}, resp.Data["check_ins"])
+	require.Equal(t, true, status()["alpha"].(map[string]interface{})["available"])
+
+	role, err = b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	require.NotEqual(t, alphaPassword, role.StaticAccount.Password)
+
+	// Revoking a lease whose check-out is over has no effect
+	resp = checkOut("entity-3")
+	require.Equal(t, "alpha", resp.Data["static_role"])
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RevokeOperation,
+		Storage:   storage,
+		Secret:    alphaResp.Secret,
+	})
+	require.NoError(t, err)
+	require.Equal(t, false, status()["alpha"].(map[string]interface{})["available"])
+
+	// Revoking the current lease checks the account in
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RevokeOperation,
+		Storage:   storage,
+		Secret:    betaResp.Secret,
+	})
+	require.NoError(t, err)
+	require.Equal(t, true, status()["beta"].(map[string]interface{})["available"])
+
+	// Static roles of a set, and sets with accounts checked out, can't be deleted
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "static-roles/alpha",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "library/ops",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/manage/ops/check-in",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"static_roles": "alpha",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"alpha"}, resp.Data["check_ins"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "library/ops",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+func TestLibrary_CheckInRotationFailure(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+
+	createRole(t, b, storage, mockDB, "alpha")
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/ops",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"static_roles": "alpha",
+			"ttl":          "1h",
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/ops/check-out",
+		Storage:   storage,
+		EntityID:  "entity-1",
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), resp.Error())
+	password := resp.Data["password"]
+
+	status := func() map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "library/ops/status",
+			Storage:   storage,
+		})
+		require.NoError(t, err)
+		return resp.Data["alpha"].(map[string]interface{})
+	}
+
+	// A failed rotation still checks the account in, but it stays unavailable
+	// until its credential is rotated
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, errors.New("connection refused")).
+		Once()
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/ops/check-in",
+		Storage:   storage,
+		EntityID:  "entity-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"alpha"}, resp.Data["check_ins"])
+	require.Equal(t, false, status()["available"])
+	require.Equal(t, true, status()["pending_rotation"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/ops/check-out",
+		Storage:   storage,
+		EntityID:  "entity-2",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	// The rotation queue rotates the credential rather than deferring it, and
+	// the account becomes available
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+	item, err := b.popFromRotationQueueByKey("alpha")
+	require.NoError(t, err)
+	item.Priority = time.Now().Unix()
+	require.NoError(t, b.pushItem(item))
+	require.True(t, b.rotateCredential(ctx, storage))
+	require.Equal(t, true, status()["available"])
+
+	role, err := b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	require.NotEqual(t, password, role.StaticAccount.Password)
+}
+
+// TestLibrary_CheckOutWaitsForRotation verifies that an account isn't checked
+// out while its credential is being rotated.
+func TestLibrary_CheckOutWaitsForRotation(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+
+	createRole(t, b, storage, mockDB, "alpha")
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/ops",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"static_roles": "alpha",
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	// Rotations hold the lock of the role
+	lock := locksutil.LockForKey(b.roleLocks, "alpha")
+	lock.Lock()
+
+	done := make(chan *logical.Response)
+	go func() {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "library/ops/check-out",
+			Storage:   storage,
+			EntityID:  "entity-1",
+		})
+		if err != nil {
+			resp = logical.ErrorResponse(err.Error())
+		}
+		done <- resp
+	}()
+
+	select {
+	case <-done:
+		lock.Unlock()
+		t.Fatal("expected the check-out to wait for the rotation")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	lock.Unlock()
+	resp = <-done
+	require.False(t, resp.IsError(), resp.Error())
+	require.Equal(t, "alpha", resp.Data["static_role"])
+}
@@ -198,6 +198,13 @@ func staticFields() map[string]*framework.FieldSchema {
 			Type: framework.TypeString,
 			Description: `Name of the static user account for Vault to manage.
 	Requires "rotation_period" to be specified`,
+		},
+		"dual_account_username": {
+			Type: framework.TypeString,
+			Description: `Name of a second static user account for Vault to
+	manage. When set, rotations alternate between "username" and this account:
+	the inactive account is rotated and then becomes the current one, so the
+	previous credential stays valid until the following rotation.`,
 		},
 		"rotation_period": {
 			Type: framework.TypeDurationSecond,
@@ -222,6 +229,30 @@ func staticFields() map[string]*framework.FieldSchema {
 	rotate the accounts credentials. Not every plugin type will support
 	this functionality. See the plugin's API page for more information on
 	support and formatting for this parameter.`,
+		},
+		"verify_rotation": {
+			Type: framework.TypeBool,
+			Description: `If true, a new password is only stored after Vault has
+	connected to the database as the static account with it and run the
+	"verification_statements". If the verification fails, the previous
+	password is restored with the "rollback_statements". If there is no
+	previous password, as on the first rotation of an imported account, the
+	rotation fails instead. Only valid for the password credential type.`,
+		},
+		"verification_statements": {
+			Type: framework.TypeStringSlice,
+			Description: `Specifies the database statements to be executed on
+	a connection made as the static account to verify a new password when
+	"verify_rotation" is set. If none are given, the connection itself
+	verifies the password. The statements are run the way the plugin runs
+	custom renew statements, so they aren't supported by plugins that ignore
+	those, such as MySQL, MSSQL, Cassandra, InfluxDB and MongoDB.`,
+		},
+		"rollback_statements": {
+			Type: framework.TypeStringSlice,
+			Description: `Specifies the database statements to be executed to
+	restore the previous password when the verification of a new one fails.
+	Defaults to the "rotation_statements".`,
 		},
 		// Deprecated: use 'password' instead
 		"self_managed_password": {
@@ -266,6 +297,14 @@ func (b *databaseBackend) pathRoleDelete(ctx context.Context, req *logical.Reque
 func (b *databaseBackend) pathStaticRoleDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	name := data.Get("name").(string)
 
+	setName, err := b.librarySetForStaticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if setName != "" {
+		return logical.ErrorResponse("static role %q belongs to library set %q; remove it from the set first", name, setName), nil
+	}
+
 	// Grab the exclusive lock
 	lock := locksutil.LockForKey(b.roleLocks, name)
 	lock.Lock()
@@ -274,7 +313,7 @@ func (b *databaseBackend) pathStaticRoleDelete(ctx context.Context, req *logical
 	// Remove the item from the queue
 	_, _ = b.popFromRotationQueueByKey(name)
 
-	err := req.Storage.Delete(ctx, databaseStaticRolePath+name)
+	err = req.Storage.Delete(ctx, databaseStaticRolePath+name)
 	if err != nil {
 		return nil, err
 	}
@@ -326,7 +365,15 @@ func (b *databaseBackend) pathStaticRoleRead(ctx context.Context, req *logical.R
 	// guard against nil StaticAccount; shouldn't happen but we'll be safe
 	if role.StaticAccount != nil {
 		data["username"] = role.StaticAccount.Username
+		if role.StaticAccount.IsDualAccount() {
+			data["dual_account_username"] = role.StaticAccount.DualAccount.Username
+		}
 		data["rotation_statements"] = role.Statements.Rotation
+		data["verify_rotation"] = role.StaticAccount.VerifyRotation
+		data["verification_statements"] = role.StaticAccount.VerificationStatements
+		if len(role.StaticAccount.VerificationStatements) == 0 {
+			data["verification_statements"] = []string{}
+		}
 		if !role.StaticAccount.LastVaultRotation.IsZero() {
 			data["last_vault_rotation"] = role.StaticAccount.LastVaultRotation
 		}
@@ -349,6 +396,10 @@ func (b *databaseBackend) pathStaticRoleRead(ctx context.Context, req *logical.R
 	if len(role.Statements.Rotation) == 0 {
 		data["rotation_statements"] = []string{}
 	}
+	data["rollback_statements"] = role.Statements.Rollback
+	if len(role.Statements.Rollback) == 0 {
+		data["rollback_statements"] = []string{}
+	}
 
 	recordDatabaseObservation(ctx, b, req, role.DBName, ObservationTypeDatabaseStaticRoleRead,
 		AdditionalDatabaseMetadata{key: "role_name", value: roleName})
@@ -578,6 +629,9 @@ func (b *databaseBackend) pathStaticRoleRecover(ctx context.Context, req *logica
 	if role.StaticAccount.Password != "" {
 		data.Raw["password"] = role.StaticAccount.Password
 	}
+	if role.StaticAccount.IsDualAccount() {
+		data.Raw["dual_account_username"] = role.StaticAccount.DualAccount.Username
+	}
 	req.Operation = logical.CreateOperation
 	defer func() {
 		req.Operation = logical.RecoverOperation
@@ -645,6 +699,18 @@ func (b *databaseBackend) pathStaticRoleCreateUpdate(ctx context.Context, req *l
 	}
 	role.StaticAccount.Username = username
 
+	if dualUsernameRaw, ok := data.GetOk("dual_account_username"); ok {
+		dualUsername := dualUsernameRaw.(string)
+		switch {
+		case !createRole && role.StaticAccount.dualAccountUsername() != dualUsername:
+			return logical.ErrorResponse("cannot update static account dual_account_username"), nil
+		case dualUsername == username:
+			return logical.ErrorResponse("dual_account_username must differ from username"), nil
+		case dualUsername != "" && createRole:
+			role.StaticAccount.DualAccount = &dualAccount{Username: dualUsername}
+		}
+	}
+
 	rotationPeriodSecondsRaw, rotationPeriodOk := data.GetOk("rotation_period")
 	rotationScheduleRaw, rotationScheduleOk := data.GetOk("rotation_schedule")
 	rotationWindowSecondsRaw, rotationWindowOk := data.GetOk("rotation_window")
@@ -704,6 +770,21 @@ func (b *databaseBackend) pathStaticRoleCreateUpdate(ctx context.Context, req *l
 		role.Statements.Rotation = data.Get("rotation_statements").([]string)
 	}
 
+	if rollbackStmtsRaw, ok := data.GetOk("rollback_statements"); ok {
+		role.Statements.Rollback = rollbackStmtsRaw.([]string)
+	} else if req.Operation == logical.CreateOperation {
+		role.Statements.Rollback = data.Get("rollback_statements").([]string)
+	}
+
+	if verifyRaw, ok := data.GetOk("verify_rotation"); ok {
+		role.StaticAccount.VerifyRotation = verifyRaw.(bool)
+	}
+	if verificationStmtsRaw, ok := data.GetOk("verification_statements"); ok {
+		role.StaticAccount.VerificationStatements = verificationStmtsRaw.([]string)
+	} else if req.Operation == logical.CreateOperation {
+		role.StaticAccount.VerificationStatements = data.Get("verification_statements").([]string)
+	}
+
 	var credentialType string
 	if credentialTypeRaw, ok := data.GetOk("credential_type"); ok {
 		credentialType = credentialTypeRaw.(string)
@@ -712,11 +793,31 @@ func (b *databaseBackend) pathStaticRoleCreateUpdate(ctx context.Context, req *l
 		}
 	}
 
+	// Verification connects to the database as the static account, which
+	// needs a password.
+	if role.StaticAccount.VerifyRotation && role.CredentialType != v5.CredentialTypePassword {
+		return logical.ErrorResponse("verify_rotation is only supported for the %q credential type", v5.CredentialTypePassword.String()), nil
+	}
+
 	dbConfig, err := b.DatabaseConfig(ctx, req.Storage, role.DBName)
 	if err != nil {
 		return nil, err
 	}
 
+	if role.StaticAccount.VerifyRotation && len(role.StaticAccount.VerificationStatements) > 0 {
+		dbi, err := b.GetConnectionWithConfig(ctx, role.DBName, dbConfig)
+		if err != nil {
+			return nil, err
+		}
+		dbType, err := dbi.database.Type()
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine database type: %w", err)
+		}
+		if err := checkVerificationStatements(dbType, role.StaticAccount.VerificationStatements); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
 	lastVaultRotation := role.StaticAccount.LastVaultRotation
 	updateAllowed := lastVaultRotation.IsZero()
 
@@ -756,6 +857,12 @@ func (b *databaseBackend) pathStaticRoleCreateUpdate(ctx context.Context, req *l
 		}
 	}
 
+	// A self-managed connection authenticates as the static account, which
+	// can't follow a credential that alternates between two users.
+	if role.StaticAccount.IsDualAccount() && role.StaticAccount.SelfManagedPassword != "" {
+		return logical.ErrorResponse("dual_account_username cannot be used with a self-managed static account"), nil
+	}
+
 	if skipImportRotationRaw, ok := data.GetOk("skip_import_rotation"); ok {
 		if !createRole {
 			response.AddWarning("skip_import_rotation has no effect on updates")
@@ -1036,6 +1143,116 @@ type staticAccount struct {
 	// RevokeUser is a boolean flag to indicate if Vault should revoke the
 	// database user when the role is deleted
 	RevokeUserOnDelete bool `json:"revoke_user_on_delete"`
+
+	// DualAccount is the second database user of a dual-account ("blue/green")
+	// static role. Rotations alternate between Username and this account, so
+	// that the previous credential remains valid while clients pick up the
+	// new one. Nil for single-account roles.
+	DualAccount *dualAccount `json:"dual_account,omitempty"`
+
+	// VerifyRotation makes rotations connect to the database with the new
+	// password and run the VerificationStatements before it is stored, and
+	// roll back to the previous password if that fails.
+	VerifyRotation bool `json:"verify_rotation,omitempty"`
+
+	// VerificationStatements are run as the static account to verify a new
+	// password when VerifyRotation is set.
+	VerificationStatements []string `json:"verification_statements,omitempty"`
+}
+
+// dualAccount holds the credential of the second user of a dual-account
+// static role. The credential of the first user is kept in the Password and
+// PrivateKey fields of the staticAccount, as for single-account roles.
+type dualAccount struct {
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	PrivateKey []byte `json:"private_key"`
+
+	// Active is true when this account, rather than the role's Username,
+	// holds the current credential.
+	Active bool `json:"active"`
+}
+
+// IsDualAccount returns true if the static account alternates between two
+// database users.
+func (s *staticAccount) IsDualAccount() bool {
+	return s.DualAccount != nil
+}
+
+func (s *staticAccount) dualAccountUsername() string {
+	if s.DualAccount == nil {
+		return ""
+	}
+	return s.DualAccount.Username
+}
+
+// RotatesDualAccount returns true if the next rotation sets the credential of
+// the dual account rather than that of Username. The inactive account is
+// always the one rotated, except when the current account has no credential
+// yet, as is the case when the role is created.
+func (s *staticAccount) RotatesDualAccount() bool {
+	if !s.IsDualAccount() || s.DualAccount.Active {
+		return false
+	}
+	return s.Password != "" || len(s.PrivateKey) > 0
+}
+
+// RotationUsername returns the user whose credential the next rotation sets.
+func (s *staticAccount) RotationUsername() string {
+	if s.RotatesDualAccount() {
+		return s.DualAccount.Username
+	}
+	return s.Username
+}
+
+// CurrentUsername returns the user whose credential is returned as the
+// current one.
+func (s *staticAccount) CurrentUsername() string {
+	if s.IsDualAccount() && s.DualAccount.Active {
+		return s.DualAccount.Username
+	}
+	return s.Username
+}
+
+// CurrentPassword returns the password of the current user.
+func (s *staticAccount) CurrentPassword() string {
+	if s.IsDualAccount() && s.DualAccount.Active {
+		return s.DualAccount.Password
+	}
+	return s.Password
+}
+
+// CurrentPrivateKey returns the private key of the current user.
+func (s *staticAccount) CurrentPrivateKey() []byte {
+	if s.IsDualAccount() && s.DualAccount.Active {
+		return s.DualAccount.PrivateKey
+	}
+	return s.PrivateKey
+}
+
+// setCredential sets the password or private key of the given user and, for
+// dual-account roles, makes that user the current one.
+func (s *staticAccount) setCredential(username, password string, privateKey []byte) {
+	if s.IsDualAccount() && username == s.DualAccount.Username {
+		if password != "" {
+			s.DualAccount.Password = password
+		}
+		if privateKey != nil {
+			s.DualAccount.PrivateKey = privateKey
+		}
+		s.DualAccount.Active = true
+		return
+	}
+
+	if password != "" {
+		s.Password = password
+	}
+	if privateKey != nil {
+		s.PrivateKey = privateKey
+	}
+	if s.IsDualAccount() {
+		s.DualAccount.Active = false
+	}
 }
 
 // NextRotationTime calculates the next rotation for period and schedule-based
@@ -1122,6 +1339,18 @@ func (s *staticAccount) CredentialTTL() time.Duration {
 	return ttl
 }
 
+// ValidUntil returns the time until which the credential of the given user
+// is expected to remain valid. A single account is rotated at the next
+// rotation. With dual accounts, only the inactive account is rotated then;
+// the current account is rotated at the rotation after that.
+func (s *staticAccount) ValidUntil(username string) time.Time {
+	next := s.NextRotationTime()
+	if s.IsDualAccount() && username == s.CurrentUsername() {
+		return s.NextRotationTimeFromInput(next)
+	}
+	return next
+}
+
 const pathRoleHelpSyn = `
 Manage the roles that can be created with this backend.
 `
@@ -1176,6 +1405,21 @@ This path lets you manage the static roles that can be created with this
 backend. Static Roles are associated with a single database user, and manage the
 credential based on a rotation period, automatically rotating the credential.
 
+If "dual_account_username" is set, the static role manages two database users
+and alternates between them. Each rotation sets a new credential on the inactive
+user and then makes it the current one, so the previous credential keeps working
+until the following rotation. Reads of the static credentials return the current
+user as well as both accounts and the time until which each remains valid.
+
+If "verify_rotation" is set, each new password is verified before it is stored:
+Vault connects to the database as the static account with the new password and
+runs the "verification_statements" on that connection, which plugins that
+ignore custom renew statements, such as MySQL and MSSQL, don't support. If the
+verification fails, the previous password is restored with the
+"rollback_statements", or the "rotation_statements" if there are none, and the
+rotation is retried later. If the account has no previous password, as on its
+first rotation, the rotation fails without storing the new password.
+
 The "db_name" parameter is required and configures the name of the database
 connection to use.
 
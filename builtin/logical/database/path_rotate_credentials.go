@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/vault/helper/versions"
 	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/database/helper/dbutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/queue"
@@ -58,6 +59,11 @@ func pathRotateRootCredentials(b *databaseBackend) []*framework.Path {
 					Type:        framework.TypeString,
 					Description: "Name of the static role",
 				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "If true, return the statements the rotation would run, without running them.",
+					Query:       true,
+				},
 			},
 
 			Operations: map[logical.Operation]framework.OperationHandler{
@@ -66,6 +72,13 @@ func pathRotateRootCredentials(b *databaseBackend) []*framework.Path {
 					ForwardPerformanceStandby:   true,
 					ForwardPerformanceSecondary: true,
 				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathRotateRoleCredentialsRead(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "dry-run",
+						OperationSuffix: "static-role-rotation",
+					},
+				},
 			},
 
 			HelpSynopsis:    pathRotateRoleCredentialsUpdateHelpSyn,
@@ -134,6 +147,10 @@ func (b *databaseBackend) performRootRotation(ctx context.Context, req *logical.
 		return nil, fmt.Errorf("unable to rotate root credentials: no username in configuration")
 	}
 
+	if err := b.checkCircuit(name); err != nil {
+		return nil, err
+	}
+
 	dbi, err := b.GetConnection(ctx, req.Storage, name)
 	if err != nil {
 		return nil, err
@@ -263,6 +280,10 @@ func (b *databaseBackend) performRootRotation(ctx context.Context, req *logical.
 func (b *databaseBackend) pathRotateRoleCredentialsUpdate() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (_ *logical.Response, err error) {
 		name := data.Get("name").(string)
+		if data.Get("dry_run").(bool) {
+			return b.rotateRoleDryRun(ctx, req, name)
+		}
+
 		modified := false
 		defer func() {
 			if err == nil {
@@ -283,6 +304,16 @@ func (b *databaseBackend) pathRotateRoleCredentialsUpdate() framework.OperationF
 			return logical.ErrorResponse("no static role found for role name"), nil
 		}
 
+		// Accounts checked out of a library set are rotated when they're
+		// checked in, so that the borrower's credential keeps working
+		co, err := b.checkOut(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if co != nil && !co.PendingRotation {
+			return logical.ErrorResponse("static role %q is checked out of library set %q; check it in to rotate its credential", name, co.SetName), nil
+		}
+
 		// We defer after we've found that the static role exists, otherwise it's not really fair to say
 		// that the rotation failed.
 		defer func(s *staticAccount, credType *v5.CredentialType) {
@@ -301,6 +332,10 @@ func (b *databaseBackend) pathRotateRoleCredentialsUpdate() framework.OperationF
 			}
 		}(role.StaticAccount, &role.CredentialType) // argument is evaluated now, but since it's a pointer should refer correctly to updated values
 
+		if err := b.checkCircuit(role.DBName); err != nil {
+			return nil, err
+		}
+
 		// In create/update of static accounts, we only care if the operation
 		// err'd , and this call does not return credentials
 		item, err := b.popFromRotationQueueByKey(name)
@@ -338,6 +373,12 @@ func (b *databaseBackend) pathRotateRoleCredentialsUpdate() framework.OperationF
 			// Clear any stored WAL ID as we must have successfully deleted our WAL to get here.
 			item.Value = ""
 			modified = true
+
+			if co != nil {
+				if err := b.completeCheckIn(ctx, req.Storage, name); err != nil {
+					return nil, err
+				}
+			}
 		}
 
 		// Add their rotation to the queue
@@ -362,10 +403,88 @@ const pathRotateCredentialsUpdateHelpDesc = `
 This path attempts to rotate the root credentials for the given database. 
 `
 
+// pathRotateRoleCredentialsRead serves rotate-role/:name?dry_run=true, since
+// query parameters are only passed to read requests.
+func (b *databaseBackend) pathRotateRoleCredentialsRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+		if !data.Get("dry_run").(bool) {
+			return logical.ErrorResponse("only dry runs can be read, write to this path to rotate the credentials"), nil
+		}
+		return b.rotateRoleDryRun(ctx, req, data.Get("name").(string))
+	}
+}
+
+// rotateRoleDryRun returns the statements a rotation of the static role would
+// run, rendered for its account, without touching the database or the
+// rotation queue. Passwords aren't generated, so placeholders stand in for
+// them.
+func (b *databaseBackend) rotateRoleDryRun(ctx context.Context, req *logical.Request, name string) (*logical.Response, error) {
+	if name == "" {
+		return logical.ErrorResponse("empty role name attribute given"), nil
+	}
+	role, err := b.StaticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("no static role found for role name"), nil
+	}
+
+	username := role.StaticAccount.RotationUsername()
+	render := func(statements []string, values map[string]string) []string {
+		values["name"] = username
+		values["username"] = username
+		rendered := make([]string, 0, len(statements))
+		for _, stmt := range statements {
+			rendered = append(rendered, dbutil.QueryHelper(stmt, values))
+		}
+		return rendered
+	}
+
+	rotationValues := map[string]string{}
+	switch role.CredentialType {
+	case v5.CredentialTypePassword:
+		rotationValues["password"] = "[new password]"
+	case v5.CredentialTypeRSAPrivateKey:
+		rotationValues["public_key"] = "[new public key]"
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"username":            username,
+			"credential_type":     role.CredentialType.String(),
+			"rotation_statements": render(role.Statements.Rotation, rotationValues),
+			"verify_rotation":     role.StaticAccount.VerifyRotation,
+		},
+	}
+	if len(role.Statements.Rotation) == 0 {
+		resp.AddWarning("the role has no rotation_statements, so the default statements of the database plugin would be run")
+	}
+
+	if role.StaticAccount.VerifyRotation {
+		resp.Data["verification_statements"] = render(role.StaticAccount.VerificationStatements, map[string]string{})
+
+		rollback := role.Statements.Rollback
+		if len(rollback) == 0 {
+			rollback = role.Statements.Rotation
+		}
+		resp.Data["rollback_statements"] = render(rollback, map[string]string{
+			"password": "[previous password]",
+		})
+	}
+
+	return resp, nil
+}
+
 const pathRotateRoleCredentialsUpdateHelpSyn = `
 Request to rotate the credentials for a static user account.
 `
 
 const pathRotateRoleCredentialsUpdateHelpDesc = `
 This path attempts to rotate the credentials for the given static user account.
+
+If "dry_run" is set, the rotation statements, and the verification and rollback
+statements of roles with "verify_rotation", are returned rendered for the account
+instead of being run. Placeholders stand in for the credentials. A dry run can
+also be read, as in GET /v1/database/rotate-role/:name?dry_run=true.
 `
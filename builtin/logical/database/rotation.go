@@ -268,6 +268,34 @@ func (b *databaseBackend) rotateCredential(ctx context.Context, s logical.Storag
 		return false
 	}
 
+	// Accounts checked out of a library set are rotated when they're checked
+	// in rather than on their schedule, which would pull the credential out
+	// from under the borrower.
+	deferUntil, err := b.deferCheckedOutRotation(ctx, s, roleName)
+	if err != nil {
+		logger.Error("unable to load library check-out", "error", err)
+		deferUntil = now.Add(10 * time.Second)
+	}
+	if !deferUntil.IsZero() {
+		logger.Debug("deferring rotation of checked out account", "until", deferUntil)
+		item.Priority = deferUntil.Unix()
+		if err := b.pushItem(item); err != nil {
+			logger.Error("unable to push item on to queue", "error", err)
+		}
+		return true
+	}
+
+	// Rotations against a database that is known to be down are held back
+	// until its health checks succeed again, rather than piling up WALs.
+	if b.deferRotationForCircuit(role.DBName, roleName) {
+		logger.Debug("deferring rotation until the database connection recovers")
+		item.Priority = now.Add(time.Minute).Unix()
+		if err := b.pushItem(item); err != nil {
+			logger.Error("unable to push item on to queue", "error", err)
+		}
+		return true
+	}
+
 	// send an event indicating if the rotation was a success or failure
 	rotated := false
 	defer func(s *staticAccount, credType *v5.CredentialType) {
@@ -329,6 +357,10 @@ func (b *databaseBackend) rotateCredential(ctx context.Context, s logical.Storag
 		logger.Warn("unable to push item on to queue", "error", err)
 	}
 
+	if err := b.completeCheckIn(ctx, s, roleName); err != nil {
+		logger.Error("unable to complete library check-in", "error", err)
+	}
+
 	rotated = true
 	return true
 }
@@ -405,6 +437,10 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 	// Re-use WAL ID if present, otherwise PUT a new WAL
 	output := &setStaticAccountOutput{WALID: input.WALID}
 
+	// Keep the account as it was, to restore if the new credential fails
+	// verification
+	previousAccount := input.Role.StaticAccount.clone()
+
 	dbConfig, err := b.DatabaseConfig(ctx, s, input.Role.DBName)
 	if err != nil {
 		return output, err
@@ -434,8 +470,9 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 	dbi.RLock()
 	defer dbi.RUnlock()
 
+	// Dual-account roles rotate whichever user is currently inactive
 	updateReq := v5.UpdateUserRequest{
-		Username: input.Role.StaticAccount.Username,
+		Username: input.Role.StaticAccount.RotationUsername(),
 	}
 	statements := v5.Statements{
 		Commands: input.Role.Statements.Rotation,
@@ -471,21 +508,23 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 			output.WALID = ""
 		case wal.CredentialType == v5.CredentialTypePassword:
 			// Roll forward by using the credential in the existing WAL entry
+			updateReq.Username = walUsername(input.Role.StaticAccount, wal)
 			updateReq.CredentialType = v5.CredentialTypePassword
 			updateReq.Password = &v5.ChangePassword{
 				NewPassword: wal.NewPassword,
 				Statements:  statements,
 			}
-			input.Role.StaticAccount.Password = wal.NewPassword
+			input.Role.StaticAccount.setCredential(updateReq.Username, wal.NewPassword, nil)
 			usedCredentialFromPreviousRotation = true
 		case wal.CredentialType == v5.CredentialTypeRSAPrivateKey:
 			// Roll forward by using the credential in the existing WAL entry
+			updateReq.Username = walUsername(input.Role.StaticAccount, wal)
 			updateReq.CredentialType = v5.CredentialTypeRSAPrivateKey
 			updateReq.PublicKey = &v5.ChangePublicKey{
 				NewPublicKey: wal.NewPublicKey,
 				Statements:   statements,
 			}
-			input.Role.StaticAccount.PrivateKey = wal.NewPrivateKey
+			input.Role.StaticAccount.setCredential(updateReq.Username, "", wal.NewPrivateKey)
 			usedCredentialFromPreviousRotation = true
 		}
 	}
@@ -494,7 +533,7 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 	if output.WALID == "" {
 		walEntry := &setCredentialsWAL{
 			RoleName:          input.RoleName,
-			Username:          input.Role.StaticAccount.Username,
+			Username:          updateReq.Username,
 			LastVaultRotation: input.Role.StaticAccount.LastVaultRotation,
 		}
 
@@ -514,7 +553,7 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 			}
 
 			// Set new credential in static account
-			input.Role.StaticAccount.Password = newPassword
+			input.Role.StaticAccount.setCredential(updateReq.Username, newPassword, nil)
 		case v5.CredentialTypeRSAPrivateKey:
 			public, private, err := b.generateNewKeypair(input.Role.CredentialConfig)
 			if err != nil {
@@ -530,7 +569,7 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 			}
 
 			// Set new credential in static account
-			input.Role.StaticAccount.PrivateKey = private
+			input.Role.StaticAccount.setCredential(updateReq.Username, "", private)
 		}
 
 		output.WALID, err = framework.PutWAL(ctx, s, staticWALKey, walEntry)
@@ -574,6 +613,41 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 		return output, fmt.Errorf("error setting credentials: %w", err)
 	}
 
+	if input.Role.StaticAccount.VerifyRotation && updateReq.CredentialType == v5.CredentialTypePassword {
+		newPassword := updateReq.Password.NewPassword
+		verifyErr := b.verifyStaticCredential(ctx, dbConfig, dbi.runningPluginVersion, updateReq.Username, newPassword, input.Role.StaticAccount.VerificationStatements)
+		previousPassword := previousAccount.passwordOf(updateReq.Username)
+		if verifyErr != nil && previousPassword == "" {
+			// There is nothing to roll back to, so rather than keep a
+			// password that failed verification the rotation fails, and the
+			// next one sets a new password
+			b.Logger().Error("new credential failed verification and there is no previous password to restore", "role", input.RoleName, "error", verifyErr)
+			if err := framework.DeleteWAL(ctx, s, output.WALID); err != nil {
+				b.Logger().Warn("failed to delete WAL", "error", err, "WAL ID", output.WALID)
+			}
+			output.WALID = ""
+			*input.Role.StaticAccount = *previousAccount
+			return output, fmt.Errorf("new credential failed verification and there is no previous password to restore: %w", verifyErr)
+		}
+		if verifyErr != nil {
+			b.Logger().Warn("new credential failed verification, rolling back", "role", input.RoleName, "error", verifyErr)
+			rollbackErr := b.rollbackStaticCredential(ctx, dbi, input.Role, updateReq.Username, newPassword, previousPassword)
+			if rollbackErr == nil {
+				// Drop the WAL so that the credential isn't rolled forward
+				if err := framework.DeleteWAL(ctx, s, output.WALID); err != nil {
+					b.Logger().Warn("failed to delete WAL", "error", err, "WAL ID", output.WALID)
+				}
+				output.WALID = ""
+				*input.Role.StaticAccount = *previousAccount
+				return output, fmt.Errorf("new credential failed verification and was rolled back: %w", verifyErr)
+			}
+
+			// The database has the new password, so it's stored like that of
+			// any other rotation
+			b.Logger().Error("unable to roll back credential that failed verification, keeping it", "role", input.RoleName, "error", rollbackErr)
+		}
+	}
+
 	modified = true
 
 	// static user password successfully updated in external system
@@ -604,6 +678,16 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 	return &setStaticAccountOutput{RotationTime: lvr}, nil
 }
 
+// walUsername returns the user a WAL entry was written for, so that
+// dual-account roles roll forward the credential of the user that was being
+// rotated.
+func walUsername(s *staticAccount, wal *setCredentialsWAL) string {
+	if s.IsDualAccount() && (wal.Username == s.Username || wal.Username == s.DualAccount.Username) {
+		return wal.Username
+	}
+	return s.RotationUsername()
+}
+
 // Returns a new password, error.
 func (b *databaseBackend) generateNewPassword(ctx context.Context, credentialConfig map[string]interface{}, passwordPolicy string, dbi *dbPluginInstance) (string, error) {
 	generator, err := newPasswordGenerator(credentialConfig)
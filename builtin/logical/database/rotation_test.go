@@ -1737,6 +1737,108 @@ func TestRotateRole_BlockedUpdateUser_TimesOut(t *testing.T) {
 	require.Less(t, time.Since(start), time.Second, "rotate-role should return promptly on update timeout")
 }
 
+// TestStaticRole_DualAccountRotation verifies that a dual-account static role
+// rotates the inactive user, makes it the current one, and reports both
+// accounts on static-creds reads.
+func TestStaticRole_DualAccountRotation(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+
+	lastUpdatedUser := func() string {
+		t.Helper()
+		call := mockDB.Calls[len(mockDB.Calls)-1]
+		require.Equal(t, "UpdateUser", call.Method)
+		return call.Arguments.Get(1).(v5.UpdateUserRequest).Username
+	}
+	readCreds := func() map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "static-creds/hashicorp",
+			Storage:   storage,
+		})
+		require.NoError(t, err)
+		require.False(t, resp.IsError(), resp.Error())
+		return resp.Data
+	}
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-roles/hashicorp",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"username":              "blue",
+			"dual_account_username": "blue",
+			"db_name":               "mockv5",
+			"rotation_period":       "10m",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	// The first rotation sets the credential of the current user, since it
+	// doesn't have one yet
+	createRoleWithData(t, b, storage, mockDB, "hashicorp", map[string]interface{}{
+		"username":              "blue",
+		"dual_account_username": "green",
+		"db_name":               "mockv5",
+		"rotation_period":       "10m",
+	})
+	require.Equal(t, "blue", lastUpdatedUser())
+	creds := readCreds()
+	require.Equal(t, "blue", creds["username"])
+	require.Len(t, creds["accounts"], 1)
+	bluePassword := creds["password"]
+
+	// Rotations then alternate, leaving the previous credential in place
+	rotateRole(t, b, storage, mockDB, "hashicorp")
+	require.Equal(t, "green", lastUpdatedUser())
+	creds = readCreds()
+	require.Equal(t, "green", creds["username"])
+	require.NotEqual(t, bluePassword, creds["password"])
+	greenPassword := creds["password"]
+
+	accounts := creds["accounts"].([]map[string]interface{})
+	require.Len(t, accounts, 2)
+	require.Equal(t, "blue", accounts[0]["username"])
+	require.Equal(t, bluePassword, accounts[0]["password"])
+	require.False(t, accounts[0]["current"].(bool))
+	require.Equal(t, "green", accounts[1]["username"])
+	require.True(t, accounts[1]["current"].(bool))
+	require.True(t, accounts[1]["valid_until"].(time.Time).After(accounts[0]["valid_until"].(time.Time)))
+
+	rotateRole(t, b, storage, mockDB, "hashicorp")
+	require.Equal(t, "blue", lastUpdatedUser())
+	creds = readCreds()
+	require.Equal(t, "blue", creds["username"])
+	require.NotEqual(t, bluePassword, creds["password"])
+	accounts = creds["accounts"].([]map[string]interface{})
+	require.Equal(t, greenPassword, accounts[1]["password"])
+
+	// The second user can't be changed once the role exists
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "static-roles/hashicorp",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"username":              "blue",
+			"dual_account_username": "red",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "static-roles/hashicorp",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "green", resp.Data["dual_account_username"])
+}
+
 func generateWALFromFailedRotation(t *testing.T, b *databaseBackend, storage logical.Storage, mockDB *mockNewDatabase, roleName string) {
 	t.Helper()
 	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
@@ -0,0 +1,157 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/helper/pluginconsts"
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+// newVerificationDatabase creates the plugin instance that connects to the
+// database as a static account to verify its new password. It's a variable so
+// tests can substitute a mock.
+var newVerificationDatabase = newDatabaseWrapper
+
+// renewStatementsIgnored are the types of the builtin database plugins that
+// don't run custom renew statements, which is how verification statements are
+// sent, so they would be skipped without an error.
+var renewStatementsIgnored = map[string]struct{}{
+	pluginconsts.DbCassandraPluginType: {},
+	pluginconsts.DbInfluxDBPluginType:  {},
+	pluginconsts.DbMongoDBPluginType:   {},
+	pluginconsts.DbMsSQLPluginType:     {},
+	pluginconsts.DbMySQLPluginType:     {},
+}
+
+// checkVerificationStatements returns an error if verification statements are
+// given for a database plugin that wouldn't run them.
+func checkVerificationStatements(dbType string, statements []string) error {
+	if len(statements) == 0 {
+		return nil
+	}
+	if _, ok := renewStatementsIgnored[dbType]; ok {
+		return fmt.Errorf("the %s database plugin doesn't run verification_statements, leave them empty to verify new passwords by connecting with them", dbType)
+	}
+	return nil
+}
+
+// verifyStaticCredential connects to the database as the given user with its
+// new password, through a plugin instance of its own, and runs the
+// verification statements on that connection.
+func (b *databaseBackend) verifyStaticCredential(ctx context.Context, dbConfig *DatabaseConfig, pluginVersion, username, password string, statements []string) error {
+	dbw, err := newVerificationDatabase(ctx, dbConfig.PluginName, pluginVersion, b.System(), b.logger)
+	if err != nil {
+		return fmt.Errorf("unable to create database instance: %w", err)
+	}
+
+	// The connection details are those of the connection, with the
+	// credentials of the static account in place of the root credentials
+	config := make(map[string]interface{}, len(dbConfig.ConnectionDetails))
+	for k, v := range dbConfig.ConnectionDetails {
+		config[k] = v
+	}
+	config["username"] = username
+	config["password"] = password
+	delete(config, "private_key")
+	delete(config, "self_managed")
+
+	_, err = b.initializeConnection(ctx, dbw, v5.InitializeRequest{
+		Config:           config,
+		VerifyConnection: true,
+	})
+	if err != nil {
+		b.closeDatabaseWrapperAfterInitError(dbw, err)
+		return fmt.Errorf("unable to connect as %q: %w", username, err)
+	}
+	defer dbw.Close()
+
+	if len(statements) == 0 {
+		return nil
+	}
+
+	dbType, err := dbw.Type()
+	if err != nil {
+		return fmt.Errorf("unable to determine database type: %w", err)
+	}
+	if err := checkVerificationStatements(dbType, statements); err != nil {
+		return err
+	}
+
+	// dbplugin.Database has no way to run arbitrary statements, but plugins
+	// run custom renew statements as they are given, so the verification
+	// statements are sent as such on the connection of the static account.
+	timeoutCtx, cancel := context.WithTimeout(ctx, staticUpdateUserTimeout)
+	defer cancel()
+	_, err = dbw.UpdateUser(timeoutCtx, v5.UpdateUserRequest{
+		Username: username,
+		Expiration: &v5.ChangeExpiration{
+			NewExpiration: time.Now(),
+			Statements: v5.Statements{
+				Commands: statements,
+			},
+		},
+	}, false)
+	if err != nil {
+		return fmt.Errorf("verification statements failed: %w", err)
+	}
+	return nil
+}
+
+// rollbackStaticCredential restores the previous password of the given user
+// after its new one failed verification. The rollback statements are used if
+// the role has any, and the rotation statements otherwise.
+func (b *databaseBackend) rollbackStaticCredential(ctx context.Context, dbi *dbPluginInstance, role *roleEntry, username, newPassword, previousPassword string) error {
+	statements := role.Statements.Rollback
+	if len(statements) == 0 {
+		statements = role.Statements.Rotation
+	}
+
+	updateReq := v5.UpdateUserRequest{
+		Username:       username,
+		CredentialType: v5.CredentialTypePassword,
+		Password: &v5.ChangePassword{
+			NewPassword: previousPassword,
+			Statements: v5.Statements{
+				Commands: statements,
+			},
+		},
+	}
+
+	// A self-managed connection has to log in with the password that is
+	// now set
+	if role.StaticAccount.SelfManagedPassword != "" {
+		updateReq.SelfManagedPassword = newPassword
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, staticUpdateUserTimeout)
+	defer cancel()
+	_, err := dbi.database.UpdateUser(timeoutCtx, updateReq, false)
+	if err != nil {
+		b.CloseIfShutdown(dbi, err)
+	}
+	return err
+}
+
+// clone returns a copy of the static account that doesn't share its dual
+// account.
+func (s *staticAccount) clone() *staticAccount {
+	c := *s
+	if s.DualAccount != nil {
+		da := *s.DualAccount
+		c.DualAccount = &da
+	}
+	return &c
+}
+
+// passwordOf returns the password of the given user of the static account.
+func (s *staticAccount) passwordOf(username string) string {
+	if s.IsDualAccount() && username == s.DualAccount.Username {
+		return s.DualAccount.Password
+	}
+	return s.Password
+}
@@ -0,0 +1,207 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/helper/pluginutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setVerificationDatabase makes rotation verification connect through the
+// returned mock.
+func setVerificationDatabase(t *testing.T) *mockNewDatabase {
+	t.Helper()
+	verifyDB := &mockNewDatabase{}
+	verifyDB.On("Close").Return(nil)
+	verifyDB.On("Type").Return("mock", nil)
+
+	orig := newVerificationDatabase
+	t.Cleanup(func() { newVerificationDatabase = orig })
+	newVerificationDatabase = func(context.Context, string, string, pluginutil.LookRunnerUtil, log.Logger) (databaseVersionWrapper, error) {
+		return databaseVersionWrapper{v5: verifyDB}, nil
+	}
+	return verifyDB
+}
+
+func TestStaticRole_VerifyRotation(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+	verifyDB := setVerificationDatabase(t)
+
+	// The role is verified when it's created
+	var verifyReq v5.UpdateUserRequest
+	verifyDB.On("Initialize", mock.Anything, mock.Anything).
+		Return(v5.InitializeResponse{}, nil).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(v5.InitializeRequest)
+			require.Equal(t, "alpha", req.Config["username"])
+			require.NotEmpty(t, req.Config["password"])
+			require.True(t, req.VerifyConnection)
+		})
+	verifyDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Run(func(args mock.Arguments) {
+			verifyReq = args.Get(1).(v5.UpdateUserRequest)
+		}).
+		Once()
+	createRoleWithData(t, b, storage, mockDB, "alpha", map[string]interface{}{
+		"username":                "alpha",
+		"db_name":                 mockv5,
+		"rotation_period":         "86400s",
+		"rotation_statements":     []string{`ALTER USER "{{name}}" WITH PASSWORD '{{password}}'`},
+		"verify_rotation":         true,
+		"verification_statements": []string{"SELECT 1"},
+		"rollback_statements":     []string{`ALTER USER "{{name}}" WITH PASSWORD '{{password}}' VALID UNTIL 'infinity'`},
+	})
+	require.Equal(t, "alpha", verifyReq.Username)
+	require.Equal(t, []string{"SELECT 1"}, verifyReq.Expiration.Statements.Commands)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "static-roles/alpha",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, true, resp.Data["verify_rotation"])
+	require.Equal(t, []string{"SELECT 1"}, resp.Data["verification_statements"])
+
+	role, err := b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	password := role.StaticAccount.Password
+
+	// A dry run renders the statements without running them
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-role/alpha",
+		Storage:   storage,
+		Data:      map[string]interface{}{"dry_run": true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{`ALTER USER "alpha" WITH PASSWORD '[new password]'`}, resp.Data["rotation_statements"])
+	require.Equal(t, []string{"SELECT 1"}, resp.Data["verification_statements"])
+	require.Equal(t, []string{`ALTER USER "alpha" WITH PASSWORD '[previous password]' VALID UNTIL 'infinity'`}, resp.Data["rollback_statements"])
+
+	// as does reading the path with dry_run=true
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "rotate-role/alpha",
+		Storage:   storage,
+		Data:      map[string]interface{}{"dry_run": "true"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{`ALTER USER "alpha" WITH PASSWORD '[new password]'`}, resp.Data["rotation_statements"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "rotate-role/alpha",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	// A failed verification rolls back to the previous password
+	verifyDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, errors.New("permission denied")).
+		Once()
+	var rollbackReq v5.UpdateUserRequest
+	mockDB.On("UpdateUser", mock.Anything, mock.MatchedBy(func(req v5.UpdateUserRequest) bool {
+		return req.Password != nil && req.Password.NewPassword == password
+	})).
+		Return(v5.UpdateUserResponse{}, nil).
+		Run(func(args mock.Arguments) {
+			rollbackReq = args.Get(1).(v5.UpdateUserRequest)
+		}).
+		Once()
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-role/alpha",
+		Storage:   storage,
+	})
+	require.ErrorContains(t, err, "permission denied")
+	require.Equal(t, "alpha", rollbackReq.Username)
+	require.Equal(t, []string{`ALTER USER "{{name}}" WITH PASSWORD '{{password}}' VALID UNTIL 'infinity'`}, rollbackReq.Password.Statements.Commands)
+
+	role, err = b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	require.Equal(t, password, role.StaticAccount.Password)
+
+	requireWALs(t, storage, 0)
+
+	// Verification is only supported for passwords
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-roles/beta",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"username":        "beta",
+			"db_name":         mockv5,
+			"rotation_period": "86400s",
+			"credential_type": "rsa_private_key",
+			"verify_rotation": true,
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+}
+
+// TestStaticRole_VerifyFirstRotation checks that a new password that fails
+// verification isn't kept when there is no previous password to restore.
+func TestStaticRole_VerifyFirstRotation(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+	verifyDB := setVerificationDatabase(t)
+
+	verifyDB.On("Initialize", mock.Anything, mock.Anything).
+		Return(v5.InitializeResponse{}, nil)
+	verifyDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, errors.New("permission denied")).
+		Once()
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-roles/alpha",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"username":                "alpha",
+			"db_name":                 mockv5,
+			"rotation_period":         "86400s",
+			"verify_rotation":         true,
+			"verification_statements": []string{"SELECT 1"},
+		},
+	})
+	require.ErrorContains(t, err, "no previous password")
+
+	// The rollback was never attempted and the role wasn't created
+	mockDB.AssertNumberOfCalls(t, "UpdateUser", 1)
+	role, err := b.StaticRole(ctx, storage, "alpha")
+	require.NoError(t, err)
+	require.Nil(t, role)
+	requireWALs(t, storage, 0)
+}
+
+func TestCheckVerificationStatements(t *testing.T) {
+	require.NoError(t, checkVerificationStatements("mysql", nil))
+	require.NoError(t, checkVerificationStatements("pgx", []string{"SELECT 1"}))
+	require.ErrorContains(t, checkVerificationStatements("mysql", []string{"SELECT 1"}), "doesn't run verification_statements")
+	require.ErrorContains(t, checkVerificationStatements("mssql", []string{"SELECT 1"}), "doesn't run verification_statements")
+}
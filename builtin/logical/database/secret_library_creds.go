@@ -0,0 +1,96 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const SecretLibraryCredsType = "library_creds"
+
+func secretLibraryCreds(b *databaseBackend) *framework.Secret {
+	return &framework.Secret{
+		Type:   SecretLibraryCredsType,
+		Fields: map[string]*framework.FieldSchema{},
+
+		Renew:  b.secretLibraryCredsRenew,
+		Revoke: b.secretLibraryCredsRevoke,
+	}
+}
+
+// libraryCheckOutFromSecret returns the check-out internal data of a library
+// credential lease.
+func libraryCheckOutFromSecret(secret *logical.Secret) (setName, roleName, checkOutID string, err error) {
+	setName, _ = secret.InternalData["set_name"].(string)
+	roleName, _ = secret.InternalData["static_role"].(string)
+	checkOutID, _ = secret.InternalData["check_out_id"].(string)
+	if setName == "" || roleName == "" || checkOutID == "" {
+		return "", "", "", errors.New("secret is missing library check-out internal data")
+	}
+	return setName, roleName, checkOutID, nil
+}
+
+func (b *databaseBackend) secretLibraryCredsRenew(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	setName, roleName, checkOutID, err := libraryCheckOutFromSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	co, err := b.checkOut(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if co == nil || co.ID != checkOutID {
+		return nil, fmt.Errorf("static role %q is no longer checked out under this lease", roleName)
+	}
+
+	set, err := b.librarySet(ctx, req.Storage, setName)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, fmt.Errorf("library set %q no longer exists", setName)
+	}
+
+	resp := &logical.Response{Secret: req.Secret}
+	resp.Secret.TTL = set.TTL
+	resp.Secret.MaxTTL = set.MaxTTL
+
+	co.Expiration = time.Now().Add(set.TTL)
+	if set.MaxTTL > 0 {
+		if maxExpiration := req.Secret.IssueTime.Add(set.MaxTTL); co.Expiration.After(maxExpiration) {
+			co.Expiration = maxExpiration
+		}
+	}
+	if err := b.storeCheckOut(ctx, req.Storage, roleName, co); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// secretLibraryCredsRevoke checks the account back in when its check-out
+// lease expires or is revoked.
+func (b *databaseBackend) secretLibraryCredsRevoke(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	setName, roleName, checkOutID, err := libraryCheckOutFromSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.checkIn(ctx, req.Storage, roleName, checkOutID); err != nil {
+		return nil, fmt.Errorf("unable to check in static role %q: %w", roleName, err)
+	}
+	b.dbEvent(ctx, "library-check-in", req.Path, roleName, true, "set_name", setName)
+
+	return nil, nil
+}
@@ -23,6 +23,17 @@ type backend struct {
 	saltMutex             sync.RWMutex
 	backendUUID           string
 	sshCertificateCounter logical.CertificateCounter
+
+	// revocationLock serializes changes to the revocations and the KRL
+	// version.
+	revocationLock sync.Mutex
+
+	// issuersLock serializes changes to the issuers and the default issuer.
+	issuersLock sync.Mutex
+
+	// signingRequestsLock serializes the review of signing requests, so that
+	// each is signed at most once.
+	signingRequestsLock sync.Mutex
 }
 
 func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
@@ -46,6 +57,7 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 			Unauthenticated: []string{
 				"verify",
 				"public_key",
+				"krl",
 			},
 
 			LocalStorage: []string{
@@ -56,6 +68,7 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 				caPrivateKey,
 				caPrivateKeyStoragePath,
 				keysStoragePrefix,
+				issuersStoragePrefix,
 			},
 
 			AllowSnapshotRead: []string{
@@ -71,10 +84,22 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 			pathLookup(&b),
 			pathVerify(&b),
 			pathConfigCA(&b),
+			pathConfigIssuers(&b),
+			pathListIssuers(&b),
+			pathIssuer(&b),
 			pathSign(&b),
 			pathIssue(&b),
 			pathFetchPublicKey(&b),
 			pathCleanupKeys(&b),
+			pathListCerts(&b),
+			pathFetchCert(&b),
+			pathRevoke(&b),
+			pathFetchKRL(&b),
+			pathTidyCertificates(&b),
+			pathListSigningRequests(&b),
+			pathSigningRequest(&b),
+			pathApproveSigningRequest(&b),
+			pathDenySigningRequest(&b),
 		},
 
 		Secrets: []*framework.Secret{
@@ -255,6 +255,8 @@ func TestBackend_ReadRolesReturnsAllFields(t *testing.T) {
 				"allow_bare_domains", "allowed_domains", "allowed_critical_options",
 				"allow_user_certificates", "allow_user_key_ids", "algorithm_signer",
 				"not_before_duration", "max_ttl", "default_extensions", "allowed_users_template", "key_id_format",
+				"issuer", "require_approval", "approval_ttl", "ticket_id_extension", "justification_extension",
+				"no_store",
 			},
 		},
 		{
@@ -3154,18 +3156,30 @@ func TestProperAuthing(t *testing.T) {
 	// key := resp.Data["key"].(string)
 
 	paths := map[string]pathAuthChecker{
-		"config/ca":          shouldBeAuthed,
-		"config/zeroaddress": shouldBeAuthed,
-		"creds/test-otp":     shouldBeAuthed,
-		"issue/test-ca":      shouldBeAuthed,
-		"lookup":             shouldBeAuthed,
-		"public_key":         shouldBeUnauthedReadList,
-		"roles/test-ca":      shouldBeAuthed,
-		"roles/test-otp":     shouldBeAuthed,
-		"roles/":             shouldBeAuthed,
-		"sign/test-ca":       shouldBeAuthed,
-		"tidy/dynamic-keys":  shouldBeAuthed,
-		"verify":             shouldBeUnauthedWriteOnly,
+		"cert/1":                    shouldBeAuthed,
+		"certs/":                    shouldBeAuthed,
+		"config/ca":                 shouldBeAuthed,
+		"config/issuers":            shouldBeAuthed,
+		"config/zeroaddress":        shouldBeAuthed,
+		"creds/test-otp":            shouldBeAuthed,
+		"issue/test-ca":             shouldBeAuthed,
+		"issuer/legacy":             shouldBeAuthed,
+		"issuers/":                  shouldBeAuthed,
+		"krl":                       shouldBeUnauthedReadList,
+		"lookup":                    shouldBeAuthed,
+		"public_key":                shouldBeUnauthedReadList,
+		"revoke":                    shouldBeAuthed,
+		"roles/test-ca":             shouldBeAuthed,
+		"roles/test-otp":            shouldBeAuthed,
+		"roles/":                    shouldBeAuthed,
+		"sign/test-ca":              shouldBeAuthed,
+		"signing-request/1":         shouldBeAuthed,
+		"signing-request/1/approve": shouldBeAuthed,
+		"signing-request/1/deny":    shouldBeAuthed,
+		"signing-requests/":         shouldBeAuthed,
+		"tidy/certificates":         shouldBeAuthed,
+		"tidy/dynamic-keys":         shouldBeAuthed,
+		"verify":                    shouldBeUnauthedWriteOnly,
 	}
 	for path, checkerType := range paths {
 		checker := pathAuthChckerMap[checkerType]
@@ -3203,6 +3217,15 @@ func TestProperAuthing(t *testing.T) {
 		if strings.Contains(raw_path, "{role}") && strings.Contains(raw_path, "creds") {
 			raw_path = strings.ReplaceAll(raw_path, "{role}", "test-otp")
 		}
+		if strings.Contains(raw_path, "{serial}") {
+			raw_path = strings.ReplaceAll(raw_path, "{serial}", "1")
+		}
+		if strings.Contains(raw_path, "{request_id}") {
+			raw_path = strings.ReplaceAll(raw_path, "{request_id}", "1")
+		}
+		if strings.Contains(raw_path, "{issuer_name}") {
+			raw_path = strings.ReplaceAll(raw_path, "{issuer_name}", "legacy")
+		}
 
 		handler, present := paths[raw_path]
 		if !present {
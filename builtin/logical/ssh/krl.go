@@ -0,0 +1,87 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Constants of the OpenSSH Key Revocation List format, described in
+// PROTOCOL.krl of the OpenSSH sources.
+const (
+	krlMagic         uint64 = 0x5353484b524c0a00 // "SSHKRL\n\0"
+	krlFormatVersion uint32 = 1
+
+	krlSectionCertificates byte = 1
+
+	krlSectionCertSerialList byte = 0x20
+	krlSectionCertKeyID      byte = 0x23
+)
+
+// krlCertificates are the certificates revoked under a single CA key.
+type krlCertificates struct {
+	CAKey   ssh.PublicKey
+	Serials []uint64
+	KeyIDs  []string
+}
+
+// marshalKRL encodes a KRL in the binary format read by sshd's RevokedKeys
+// and ssh-keygen -Q.
+func marshalKRL(version uint64, generated time.Time, comment string, certs []*krlCertificates) []byte {
+	var buf bytes.Buffer
+	buf.Write(binary.BigEndian.AppendUint64(nil, krlMagic))
+	buf.Write(binary.BigEndian.AppendUint32(nil, krlFormatVersion))
+	buf.Write(binary.BigEndian.AppendUint64(nil, version))
+	buf.Write(binary.BigEndian.AppendUint64(nil, uint64(generated.Unix())))
+	buf.Write(binary.BigEndian.AppendUint64(nil, 0)) // flags
+	writeKRLString(&buf, nil)                        // reserved
+	writeKRLString(&buf, []byte(comment))
+
+	for _, c := range certs {
+		if len(c.Serials) == 0 && len(c.KeyIDs) == 0 {
+			continue
+		}
+
+		var section bytes.Buffer
+		writeKRLString(&section, c.CAKey.Marshal())
+		writeKRLString(&section, nil) // reserved
+
+		if len(c.Serials) > 0 {
+			serials := slices.Clone(c.Serials)
+			slices.Sort(serials)
+			var list []byte
+			for _, serial := range slices.Compact(serials) {
+				list = binary.BigEndian.AppendUint64(list, serial)
+			}
+			section.WriteByte(krlSectionCertSerialList)
+			writeKRLString(&section, list)
+		}
+
+		if len(c.KeyIDs) > 0 {
+			keyIDs := slices.Clone(c.KeyIDs)
+			slices.Sort(keyIDs)
+			var list bytes.Buffer
+			for _, keyID := range slices.Compact(keyIDs) {
+				writeKRLString(&list, []byte(keyID))
+			}
+			section.WriteByte(krlSectionCertKeyID)
+			writeKRLString(&section, list.Bytes())
+		}
+
+		buf.WriteByte(krlSectionCertificates)
+		writeKRLString(&buf, section.Bytes())
+	}
+
+	return buf.Bytes()
+}
+
+func writeKRLString(buf *bytes.Buffer, s []byte) {
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(s))))
+	buf.Write(s)
+}
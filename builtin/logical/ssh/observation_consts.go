@@ -14,12 +14,14 @@ const (
 	// ObservationTypeSSHRoleRead - Metadata: role_name, key_type, and for CA roles: ttl, max_ttl,
 	// allow_user_certificates, allow_host_certificates, allow_bare_domains, allow_subdomains,
 	// allow_user_key_ids, allowed_users_template, allowed_domains_template, default_user_template,
-	// default_extensions_template, algorithm_signer, not_before_duration, allow_empty_principals
+	// default_extensions_template, algorithm_signer, not_before_duration, allow_empty_principals, issuer,
+	// require_approval
 	ObservationTypeSSHRoleRead = "ssh/role/read"
 	// ObservationTypeSSHRoleWrite - Metadata: role_name, key_type, and for CA roles: ttl, max_ttl,
 	// allow_user_certificates, allow_host_certificates, allow_bare_domains, allow_subdomains,
 	// allow_user_key_ids, allowed_users_template, allowed_domains_template, default_user_template,
-	// default_extensions_template, algorithm_signer, not_before_duration, allow_empty_principals
+	// default_extensions_template, algorithm_signer, not_before_duration, allow_empty_principals, issuer,
+	// require_approval
 	ObservationTypeSSHRoleWrite = "ssh/role/write"
 	// ObservationTypeSSHRoleDelete - Metadata: role_name
 	ObservationTypeSSHRoleDelete = "ssh/role/delete"
@@ -27,7 +29,8 @@ const (
 	// ObservationTypeSSHOTPCreate - Metadata: role_name, key_type, and for CA roles: ttl, max_ttl,
 	// allow_user_certificates, allow_host_certificates, allow_bare_domains, allow_subdomains,
 	// allow_user_key_ids, allowed_users_template, allowed_domains_template, default_user_template,
-	// default_extensions_template, algorithm_signer, not_before_duration, allow_empty_principals
+	// default_extensions_template, algorithm_signer, not_before_duration, allow_empty_principals, issuer,
+	// require_approval
 	ObservationTypeSSHOTPCreate = "ssh/otp/create"
 	// ObservationTypeSSHOTPRevoke - Metadata: none
 	ObservationTypeSSHOTPRevoke = "ssh/otp/revoke"
@@ -45,19 +48,42 @@ const (
 	// ObservationTypeSSHConfigCADelete - Metadata: none
 	ObservationTypeSSHConfigCADelete = "ssh/config/ca/delete"
 
+	// ObservationTypeSSHIssuerWrite - Metadata: issuer_name, and conditionally:
+	// managed_key_name, managed_key_id (if using managed key), or key_type, key_bits (if generating)
+	ObservationTypeSSHIssuerWrite = "ssh/issuer/write"
+	// ObservationTypeSSHIssuerDelete - Metadata: issuer_name
+	ObservationTypeSSHIssuerDelete = "ssh/issuer/delete"
+	// ObservationTypeSSHConfigIssuersWrite - Metadata: default
+	ObservationTypeSSHConfigIssuersWrite = "ssh/config/issuers/write"
+
 	// ObservationTypeSSHSign - Metadata: role_name, key_type, certificate_type, ttl, serial_number,
 	// key_id, and for CA roles: max_ttl, allow_user_certificates, allow_host_certificates,
 	// allow_bare_domains, allow_subdomains, allow_user_key_ids, allowed_users_template,
 	// allowed_domains_template, default_user_template, default_extensions_template,
-	// algorithm_signer, not_before_duration, allow_empty_principals
+	// algorithm_signer, not_before_duration, allow_empty_principals, issuer,
+	// require_approval
 	ObservationTypeSSHSign = "ssh/certificate/sign"
 	// ObservationTypeSSHIssue - Metadata: role_name, key_type (from keySpecs), key_bits,
 	// certificate_type, ttl, serial_number, key_id, and for CA roles: max_ttl,
 	// allow_user_certificates, allow_host_certificates, allow_bare_domains, allow_subdomains,
 	// allow_user_key_ids, allowed_users_template, allowed_domains_template, default_user_template,
-	// default_extensions_template, algorithm_signer, not_before_duration, allow_empty_principals
+	// default_extensions_template, algorithm_signer, not_before_duration, allow_empty_principals, issuer,
+	// require_approval
 	ObservationTypeSSHIssue = "ssh/certificate/issue"
+	// ObservationTypeSSHRevoke - Metadata: serial_number, key_id (one of them is empty)
+	ObservationTypeSSHRevoke = "ssh/certificate/revoke"
+	// ObservationTypeSSHSigningRequestCreate - Metadata: request_id, ticket_id, role_name, key_type,
+	// and the CA role metadata of ObservationTypeSSHRoleWrite
+	ObservationTypeSSHSigningRequestCreate = "ssh/signing-request/create"
+	// ObservationTypeSSHSigningRequestApprove - Metadata: request_id, ticket_id, certificate_type, ttl,
+	// serial_number, key_id, role_name, key_type, and the CA role metadata of ObservationTypeSSHRoleWrite
+	ObservationTypeSSHSigningRequestApprove = "ssh/signing-request/approve"
+	// ObservationTypeSSHSigningRequestDeny - Metadata: request_id, role_name, ticket_id
+	ObservationTypeSSHSigningRequestDeny = "ssh/signing-request/deny"
 
 	// ObservationTypeSSHTidyDynamicKeys - Metadata: keys_deleted (int)
 	ObservationTypeSSHTidyDynamicKeys = "ssh/tidy/dynamic-keys"
+	// ObservationTypeSSHTidyCertificates - Metadata: certificates_deleted (int), revocations_deleted (int),
+	// requests_deleted (int)
+	ObservationTypeSSHTidyCertificates = "ssh/tidy/certificates"
 )
@@ -0,0 +1,201 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
+)
+
+const certsStoragePrefix = "certs/"
+
+// sshCertificateEntry is the issuance record of a certificate signed by
+// sign/:role or issue/:role, kept until it is tidied after expiring.
+type sshCertificateEntry struct {
+	SerialNumber    string    `json:"serial_number"`
+	KeyID           string    `json:"key_id"`
+	CertificateType string    `json:"certificate_type"`
+	ValidPrincipals []string  `json:"valid_principals"`
+	Role            string    `json:"role"`
+	Issuer          string    `json:"issuer,omitempty"`
+	CAPublicKey     string    `json:"ca_public_key"`
+	SignedKey       string    `json:"signed_key"`
+	IssuedAt        time.Time `json:"issued_at"`
+	ValidAfter      time.Time `json:"valid_after"`
+	ValidBefore     time.Time `json:"valid_before"`
+}
+
+func pathListCerts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "certs/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "certificates",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathCertList,
+		},
+
+		HelpSynopsis:    pathCertListHelpSyn,
+		HelpDescription: pathCertListHelpDesc,
+	}
+}
+
+func pathFetchCert(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "cert/" + framework.GenericNameRegex("serial"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "certificate",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"serial": {
+				Type:        framework.TypeString,
+				Description: "[Required] Serial number of the certificate, in hexadecimal.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathCertRead,
+		},
+
+		HelpSynopsis:    pathCertReadHelpSyn,
+		HelpDescription: pathCertReadHelpDesc,
+	}
+}
+
+func (b *backend) pathCertList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	serials, err := req.Storage.List(ctx, certsStoragePrefix)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(serials), nil
+}
+
+func (b *backend) pathCertRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	serial, err := normalizeSerial(d.Get("serial").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry, err := getCertificateEntry(ctx, req.Storage, serial)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	revocation, err := getRevocationEntry(ctx, req.Storage, revokedSerialsStoragePrefix+serial)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"serial_number":    entry.SerialNumber,
+		"key_id":           entry.KeyID,
+		"certificate_type": entry.CertificateType,
+		"valid_principals": entry.ValidPrincipals,
+		"role":             entry.Role,
+		"issuer":           entry.Issuer,
+		"ca_public_key":    entry.CAPublicKey,
+		"signed_key":       entry.SignedKey,
+		"issued_at":        entry.IssuedAt.Format(time.RFC3339),
+		"valid_after":      entry.ValidAfter.Format(time.RFC3339),
+		"valid_before":     entry.ValidBefore.Format(time.RFC3339),
+		"revoked":          revocation != nil,
+	}
+	if revocation != nil {
+		data["revocation_time"] = revocation.RevokedAt.Format(time.RFC3339)
+	}
+
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+// normalizeSerial returns the serial number in the lowercase hexadecimal form
+// returned when certificates are signed, also accepting colon separators.
+func normalizeSerial(serial string) (string, error) {
+	if serial == "" {
+		return "", fmt.Errorf("missing serial number")
+	}
+	n, err := strconv.ParseUint(strings.ReplaceAll(serial, ":", ""), 16, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid serial number %q: must be hexadecimal", serial)
+	}
+	return strconv.FormatUint(n, 16), nil
+}
+
+// storeCertificateEntry writes the issuance record of a signed certificate.
+func storeCertificateEntry(ctx context.Context, s logical.Storage, roleName, issuerName string, certificate *ssh.Certificate) error {
+	certType := "user"
+	if certificate.CertType == ssh.HostCert {
+		certType = "host"
+	}
+
+	serial := strconv.FormatUint(certificate.Serial, 16)
+	entry, err := logical.StorageEntryJSON(certsStoragePrefix+serial, &sshCertificateEntry{
+		SerialNumber:    serial,
+		KeyID:           certificate.KeyId,
+		CertificateType: certType,
+		ValidPrincipals: certificate.ValidPrincipals,
+		Role:            roleName,
+		Issuer:          issuerName,
+		CAPublicKey:     strings.TrimSpace(string(ssh.MarshalAuthorizedKey(certificate.SignatureKey))),
+		SignedKey:       strings.TrimSpace(string(ssh.MarshalAuthorizedKey(certificate))),
+		IssuedAt:        time.Now().UTC(),
+		ValidAfter:      time.Unix(int64(certificate.ValidAfter), 0).UTC(),
+		ValidBefore:     time.Unix(int64(certificate.ValidBefore), 0).UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error storing certificate record: %w", err)
+	}
+	return nil
+}
+
+func getCertificateEntry(ctx context.Context, s logical.Storage, serial string) (*sshCertificateEntry, error) {
+	entry, err := s.Get(ctx, certsStoragePrefix+serial)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result sshCertificateEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+const pathCertListHelpSyn = `List the serial numbers of the certificates issued by this mount.`
+
+const pathCertListHelpDesc = `
+This path lists the serial numbers, in hexadecimal, of the certificates signed
+through "sign/<role>" and "issue/<role>" whose records haven't been tidied.
+`
+
+const pathCertReadHelpSyn = `Read the record of an issued certificate.`
+
+const pathCertReadHelpDesc = `
+This path returns the issuance record of the certificate with the given serial
+number: its key ID, principals, validity, the role and issuer it was signed by, the
+signed certificate itself and whether it has been revoked.
+`
@@ -53,39 +53,7 @@ func pathConfigCA(b *backend) *framework.Path {
 			OperationPrefix: operationPrefixSSH,
 		},
 
-		Fields: map[string]*framework.FieldSchema{
-			"private_key": {
-				Type:        framework.TypeString,
-				Description: `Private half of the SSH key that will be used to sign certificates.`,
-			},
-			"public_key": {
-				Type:        framework.TypeString,
-				Description: `Public half of the SSH key that will be used to sign certificates.`,
-			},
-			"generate_signing_key": {
-				Type:        framework.TypeBool,
-				Description: `Generate SSH key pair internally rather than use the private_key and public_key fields. If managed key config is provided, this field is ignored.`,
-				Default:     true,
-			},
-			"key_type": {
-				Type:        framework.TypeString,
-				Description: `Specifies the desired key type when generating; could be a OpenSSH key type identifier (ssh-rsa, ecdsa-sha2-nistp256, ecdsa-sha2-nistp384, ecdsa-sha2-nistp521, or ssh-ed25519) or an algorithm (rsa, ec, ed25519).`,
-				Default:     "ssh-rsa",
-			},
-			"key_bits": {
-				Type:        framework.TypeInt,
-				Description: `Specifies the desired key bits when generating variable-length keys (such as when key_type="ssh-rsa") or which NIST P-curve to use when key_type="ec" (256, 384, or 521).`,
-				Default:     0,
-			},
-			"managed_key_name": {
-				Type:        framework.TypeString,
-				Description: `The name of the managed key to use. When using a managed key, this field or managed_key_id is required.`,
-			},
-			"managed_key_id": {
-				Type:        framework.TypeString,
-				Description: `The id of the managed key to use. When using a managed key, this field or managed_key_name is required.`,
-			},
-		},
+		Fields: caKeyFields(),
 
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.UpdateOperation: &framework.PathOperation{
@@ -122,6 +90,44 @@ Read operations will return the public key, if already stored/generated.`,
 	}
 }
 
+// caKeyFields returns the fields that configure the signing key of a CA,
+// shared by config/ca and the named issuers.
+func caKeyFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"private_key": {
+			Type:        framework.TypeString,
+			Description: `Private half of the SSH key that will be used to sign certificates.`,
+		},
+		"public_key": {
+			Type:        framework.TypeString,
+			Description: `Public half of the SSH key that will be used to sign certificates.`,
+		},
+		"generate_signing_key": {
+			Type:        framework.TypeBool,
+			Description: `Generate SSH key pair internally rather than use the private_key and public_key fields. If managed key config is provided, this field is ignored.`,
+			Default:     true,
+		},
+		"key_type": {
+			Type:        framework.TypeString,
+			Description: `Specifies the desired key type when generating; could be a OpenSSH key type identifier (ssh-rsa, ecdsa-sha2-nistp256, ecdsa-sha2-nistp384, ecdsa-sha2-nistp521, or ssh-ed25519) or an algorithm (rsa, ec, ed25519).`,
+			Default:     "ssh-rsa",
+		},
+		"key_bits": {
+			Type:        framework.TypeInt,
+			Description: `Specifies the desired key bits when generating variable-length keys (such as when key_type="ssh-rsa") or which NIST P-curve to use when key_type="ec" (256, 384, or 521).`,
+			Default:     0,
+		},
+		"managed_key_name": {
+			Type:        framework.TypeString,
+			Description: `The name of the managed key to use. When using a managed key, this field or managed_key_id is required.`,
+		},
+		"managed_key_id": {
+			Type:        framework.TypeString,
+			Description: `The id of the managed key to use. When using a managed key, this field or managed_key_name is required.`,
+		},
+	}
+}
+
 func (b *backend) pathConfigCARead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	// prevent migration from deprecated paths on snapshot read as writes to a loaded snapshot storage are forbidden
 	allowMigration := !req.IsSnapshotReadOrList()
@@ -242,6 +248,57 @@ func (b *backend) pathConfigCAUpdate(ctx context.Context, req *logical.Request,
 		return logical.ErrorResponse("keys are already configured; delete them before reconfiguring"), nil
 	}
 
+	key, errResp, err := b.readCAKeyInput(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	if key.ManagedKey != nil {
+		entry, err := logical.StorageEntryJSON(caManagedKeyStoragePath, key.ManagedKey)
+		if err != nil {
+			return nil, fmt.Errorf("error creating storage entry: %s", err)
+		}
+		if err := req.Storage.Put(ctx, entry); err != nil {
+			return nil, fmt.Errorf("error writing key entry to storage: %s", err)
+		}
+	} else {
+		err = createStoredKey(ctx, req.Storage, key.PublicKey, key.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b.Backend.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHConfigCAWrite, key.Metadata)
+
+	if key.Generated {
+		response := &logical.Response{
+			Data: map[string]interface{}{
+				"public_key": key.PublicKey,
+			},
+		}
+
+		return response, nil
+	}
+
+	return nil, nil
+}
+
+// caKeyInput is the signing key of a CA, as imported, generated or referenced
+// as a managed key through the caKeyFields.
+type caKeyInput struct {
+	PublicKey  string
+	PrivateKey string
+	ManagedKey *managedKeyStorageEntry
+	Generated  bool
+	Metadata   map[string]interface{}
+}
+
+// readCAKeyInput parses, generates or looks up the signing key described by
+// the caKeyFields of the request.
+func (b *backend) readCAKeyInput(ctx context.Context, data *framework.FieldData) (*caKeyInput, *logical.Response, error) {
 	publicKey := data.Get("public_key").(string)
 	privateKey := data.Get("private_key").(string)
 
@@ -252,60 +309,54 @@ func (b *backend) pathConfigCAUpdate(ctx context.Context, req *logical.Request,
 
 	generateSigningKey := data.Get("generate_signing_key").(bool)
 
-	metadata := make(map[string]interface{})
+	key := &caKeyInput{
+		Metadata: make(map[string]interface{}),
+	}
 
 	if useManagedKey {
-		generateSigningKey = false
-		err = b.createManagedKey(ctx, req.Storage, managedKeyName, managedKeyID)
+		managedKey, err := b.lookupManagedKey(ctx, managedKeyName, managedKeyID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		metadata["managed_key_name"] = managedKeyName
-		metadata["managed_key_id"] = managedKeyID
-	} else {
-		if publicKey != "" && privateKey != "" {
-			_, err := ssh.ParsePrivateKey([]byte(privateKey))
-			if err != nil {
-				return logical.ErrorResponse(fmt.Sprintf("Unable to parse private_key as an SSH private key: %v", err)), nil
-			}
-
-			_, err = parsePublicSSHKey(publicKey)
-			if err != nil {
-				return logical.ErrorResponse(fmt.Sprintf("Unable to parse public_key as an SSH public key: %v", err)), nil
-			}
-		} else if generateSigningKey {
-			keyType := data.Get("key_type").(string)
-			keyBits := data.Get("key_bits").(int)
+		key.ManagedKey = managedKey
+		key.PublicKey = managedKey.PublicKey
+		key.Metadata["managed_key_name"] = managedKeyName
+		key.Metadata["managed_key_id"] = managedKeyID
+		return key, nil, nil
+	}
 
-			publicKey, privateKey, err = generateSSHKeyPair(b.Backend.GetRandomReader(), keyType, keyBits)
-			if err != nil {
-				return nil, err
-			}
-			metadata["key_type"] = keyType
-			metadata["key_bits"] = keyBits
-		} else {
-			return logical.ErrorResponse("if generate_signing_key is false, either both public_key and private_key or a managed key must be provided"), nil
+	if publicKey != "" && privateKey != "" {
+		_, err := ssh.ParsePrivateKey([]byte(privateKey))
+		if err != nil {
+			return nil, logical.ErrorResponse(fmt.Sprintf("Unable to parse private_key as an SSH private key: %v", err)), nil
 		}
 
-		err = createStoredKey(ctx, req.Storage, publicKey, privateKey)
+		_, err = parsePublicSSHKey(publicKey)
 		if err != nil {
-			return nil, err
+			return nil, logical.ErrorResponse(fmt.Sprintf("Unable to parse public_key as an SSH public key: %v", err)), nil
 		}
-	}
+	} else if generateSigningKey {
+		keyType := data.Get("key_type").(string)
+		keyBits := data.Get("key_bits").(int)
 
-	b.Backend.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHConfigCAWrite, metadata)
-
-	if generateSigningKey {
-		response := &logical.Response{
-			Data: map[string]interface{}{
-				"public_key": publicKey,
-			},
+		var err error
+		publicKey, privateKey, err = generateSSHKeyPair(b.Backend.GetRandomReader(), keyType, keyBits)
+		if err != nil {
+			return nil, nil, err
 		}
-
-		return response, nil
+		key.Generated = true
+		key.Metadata["key_type"] = keyType
+		key.Metadata["key_bits"] = keyBits
+	} else {
+		return nil, logical.ErrorResponse("if generate_signing_key is false, either both public_key and private_key or a managed key must be provided"), nil
 	}
 
-	return nil, nil
+	if publicKey == "" || privateKey == "" {
+		return nil, nil, fmt.Errorf("failed to generate or parse the keys")
+	}
+	key.PublicKey = publicKey
+	key.PrivateKey = privateKey
+	return key, nil, nil
 }
 
 func createStoredKey(ctx context.Context, s logical.Storage, publicKey, privateKey string) error {
@@ -453,39 +504,48 @@ func generateSSHKeyPair(randomSource io.Reader, keyType string, keyBits int) (st
 }
 
 func (b *backend) createManagedKey(ctx context.Context, s logical.Storage, managedKeyName, managedKeyId string) error {
-	var keyId managed_key.UUIDKey
-	var keyName managed_key.NameKey
+	managedKey, err := b.lookupManagedKey(ctx, managedKeyName, managedKeyId)
+	if err != nil {
+		return err
+	}
+
+	entry, err := logical.StorageEntryJSON(caManagedKeyStoragePath, managedKey)
+	if err != nil {
+		return fmt.Errorf("error creating storage entry: %s", err)
+	}
+
+	// Save the public key
+	err = s.Put(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("error writing key entry to storage: %s", err)
+	}
+
+	return nil
+}
+
+// lookupManagedKey returns the entry referencing the managed key with the
+// given ID or, if no ID is given, name.
+func (b *backend) lookupManagedKey(ctx context.Context, managedKeyName, managedKeyId string) (*managedKeyStorageEntry, error) {
 	var keyInfo *managed_key.ManagedKeyInfo
 	var err error
 
 	if managedKeyId != "" {
-		keyId = managed_key.UUIDKey(managedKeyId)
+		keyId := managed_key.UUIDKey(managedKeyId)
 		keyInfo, err = managed_key.GetManagedKeyInfo(ctx, b, keyId)
 	} else if managedKeyName != "" {
-		keyName = managed_key.NameKey(managedKeyName)
+		keyName := managed_key.NameKey(managedKeyName)
 		keyInfo, err = managed_key.GetManagedKeyInfo(ctx, b, keyName)
 	}
 
 	if err != nil {
-		return fmt.Errorf("error retrieving public key: %s", err)
+		return nil, fmt.Errorf("error retrieving public key: %s", err)
 	}
 
-	entry, err := logical.StorageEntryJSON(caManagedKeyStoragePath, &managedKeyStorageEntry{
+	return &managedKeyStorageEntry{
 		PublicKey: string(ssh.MarshalAuthorizedKey(keyInfo.PublicKey())),
 		KeyName:   keyInfo.Name,
 		KeyId:     keyInfo.Uuid,
-	})
-	if err != nil {
-		return fmt.Errorf("error creating storage entry: %s", err)
-	}
-
-	// Save the public key
-	err = s.Put(ctx, entry)
-	if err != nil {
-		return fmt.Errorf("error writing key entry to storage: %s", err)
-	}
-
-	return nil
+	}, nil
 }
 
 func getCAPublicKey(ctx context.Context, storage logical.Storage, allowMigration bool) (string, error) {
@@ -5,6 +5,7 @@ package ssh
 
 import (
 	"context"
+	"strings"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -24,20 +25,29 @@ func pathFetchPublicKey(b *backend) *framework.Path {
 		},
 
 		HelpSynopsis:    `Retrieve the public key.`,
-		HelpDescription: `This allows the public key of the SSH CA certificate that this backend has been configured with to be fetched. This is a raw response endpoint without JSON encoding; use -format=raw or an external tool (e.g., curl) to fetch this value.`,
+		HelpDescription: `This allows the public keys of the SSH CAs that this backend has been configured with to be fetched, one per line with the default issuer first, in the format of sshd's TrustedUserCAKeys file. This is a raw response endpoint without JSON encoding; use -format=raw or an external tool (e.g., curl) to fetch this value.`,
 	}
 }
 
 func (b *backend) pathFetchPublicKey(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	const allowMigration = true // only paths that support snapshot reads are
-	publicKey, err := getCAPublicKey(ctx, req.Storage, allowMigration)
+	publicKeys, err := getCAPublicKeys(ctx, req.Storage, allowMigration)
 	if err != nil {
 		return nil, err
 	}
-	if publicKey == "" {
+	if len(publicKeys) == 0 {
 		return nil, nil
 	}
 
+	// A single key is returned as it was configured
+	publicKey := publicKeys[0]
+	if len(publicKeys) > 1 {
+		for i := range publicKeys {
+			publicKeys[i] = strings.TrimSpace(publicKeys[i])
+		}
+		publicKey = strings.Join(publicKeys, "\n") + "\n"
+	}
+
 	response := &logical.Response{
 		Data: map[string]interface{}{
 			logical.HTTPContentType: "text/plain",
@@ -48,3 +58,38 @@ func (b *backend) pathFetchPublicKey(ctx context.Context, req *logical.Request,
 
 	return response, nil
 }
+
+func pathFetchKRL(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: `krl`,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "key-revocation-list",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathFetchKRL,
+		},
+
+		HelpSynopsis:    `Retrieve the Key Revocation List.`,
+		HelpDescription: `This allows the OpenSSH Key Revocation List (KRL) of the certificates revoked through the revoke endpoint to be fetched, in the binary format read by sshd's RevokedKeys option and ssh-keygen -Q. This is a raw response endpoint without JSON encoding; use -format=raw or an external tool (e.g., curl) to fetch this value.`,
+	}
+}
+
+func (b *backend) pathFetchKRL(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	krl, err := buildKRL(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: "application/octet-stream",
+			logical.HTTPRawBody:     krl,
+			logical.HTTPStatusCode:  200,
+		},
+	}
+
+	return response, nil
+}
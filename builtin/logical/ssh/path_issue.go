@@ -78,6 +78,14 @@ be later than the role max TTL.`,
 				Type:        framework.TypeMap,
 				Description: `Extensions that the certificate should be signed for.`,
 			},
+			"ticket_id": {
+				Type:        framework.TypeString,
+				Description: `Ticket ID of the change or incident the certificate is requested for. Embedded in the certificate when the role sets ticket_id_extension.`,
+			},
+			"justification": {
+				Type:        framework.TypeString,
+				Description: `Reason the certificate is requested. Required by roles that require approval, and embedded in the certificate when the role sets justification_extension.`,
+			},
 		},
 		HelpSynopsis:    pathIssueHelpSyn,
 		HelpDescription: pathIssueHelpDesc,
@@ -99,6 +107,10 @@ func (b *backend) pathIssue(ctx context.Context, req *logical.Request, data *fra
 		return logical.ErrorResponse("role key type '%s' not allowed to issue key pairs", role.KeyType), nil
 	}
 
+	if role.RequireApproval {
+		return logical.ErrorResponse("role requires approval; key pairs can't be issued, sign a public key instead"), nil
+	}
+
 	// Validate and extract key specifications
 	keySpecs, err := extractKeySpecs(role, data)
 	if err != nil {
@@ -121,7 +133,7 @@ func (b *backend) pathIssueCertificate(ctx context.Context, req *logical.Request
 		return logical.ErrorResponse(fmt.Sprintf("failed to parse public_key as SSH key: %s", err)), nil
 	}
 
-	response, certMetadata, err := b.pathSignIssueCertificateHelper(ctx, req, data, role, userPublicKey)
+	response, certMetadata, err := b.pathSignIssueCertificateHelper(ctx, req, data, role, roleName, userPublicKey)
 	if err != nil {
 		return nil, err
 	}
@@ -14,6 +14,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"regexp"
 	"strconv"
 	"strings"
@@ -54,70 +55,103 @@ type creationBundle struct {
 	Extensions      map[string]string
 }
 
-func (b *backend) pathSignIssueCertificateHelper(ctx context.Context, req *logical.Request, data *framework.FieldData, role *sshRole, publicKey ssh.PublicKey) (*logical.Response, map[string]interface{}, error) {
+func (b *backend) pathSignIssueCertificateHelper(ctx context.Context, req *logical.Request, data *framework.FieldData, role *sshRole, roleName string, publicKey ssh.PublicKey) (*logical.Response, map[string]interface{}, error) {
+	cBundle, addExtTemplatingWarning, errResp, err := b.newCreationBundle(req, data, role, publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if errResp != nil {
+		return errResp, nil, nil
+	}
+
+	response, metadata, err := b.signCreationBundle(ctx, req.Storage, cBundle, roleName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if addExtTemplatingWarning {
+		response.AddWarning("default_extension templating enabled with at least one extension requiring identity templating. However, this request lacked identity entity information, causing one or more extensions to be skipped from the generated certificate.")
+	}
+
+	return response, metadata, nil
+}
+
+// newCreationBundle validates a signing request against its role and resolves
+// the properties of the certificate, leaving the signer to be set when it is
+// signed.
+func (b *backend) newCreationBundle(req *logical.Request, data *framework.FieldData, role *sshRole, publicKey ssh.PublicKey) (*creationBundle, bool, *logical.Response, error) {
 	// Note that these various functions always return "user errors" so we pass
 	// them as 4xx values
 	keyID, err := b.calculateKeyID(data, req, role, publicKey)
 	if err != nil {
-		return logical.ErrorResponse(err.Error()), nil, nil
+		return nil, false, logical.ErrorResponse(err.Error()), nil
 	}
 
 	certificateType, err := b.calculateCertificateType(data, role)
 	if err != nil {
-		return logical.ErrorResponse(err.Error()), nil, nil
+		return nil, false, logical.ErrorResponse(err.Error()), nil
 	}
 
 	var parsedPrincipals []string
 	if certificateType == ssh.HostCert {
 		parsedPrincipals, err = b.calculateValidPrincipals(data, req, role, "", role.AllowedDomains, role.AllowedDomainsTemplate, validateValidPrincipalForHosts(role))
 		if err != nil {
-			return logical.ErrorResponse(err.Error()), nil, nil
+			return nil, false, logical.ErrorResponse(err.Error()), nil
 		}
 	} else {
 		defaultPrincipal := role.DefaultUser
 		if role.DefaultUserTemplate {
 			defaultPrincipal, err = b.renderPrincipal(role.DefaultUser, req)
 			if err != nil {
-				return nil, nil, err
+				return nil, false, nil, err
 			}
 		}
 		parsedPrincipals, err = b.calculateValidPrincipals(data, req, role, defaultPrincipal, role.AllowedUsers, role.AllowedUsersTemplate, strutil.StrListContains)
 		if err != nil {
-			return logical.ErrorResponse(err.Error()), nil, nil
+			return nil, false, logical.ErrorResponse(err.Error()), nil
 		}
 	}
 
 	ttl, err := b.calculateTTL(data, role)
 	if err != nil {
-		return logical.ErrorResponse(err.Error()), nil, nil
+		return nil, false, logical.ErrorResponse(err.Error()), nil
 	}
 
 	criticalOptions, err := b.calculateCriticalOptions(data, role)
 	if err != nil {
-		return logical.ErrorResponse(err.Error()), nil, nil
+		return nil, false, logical.ErrorResponse(err.Error()), nil
 	}
 
 	extensions, addExtTemplatingWarning, err := b.calculateExtensions(data, req, role)
 	if err != nil {
-		return logical.ErrorResponse(err.Error()), nil, nil
+		return nil, false, logical.ErrorResponse(err.Error()), nil
 	}
 
-	signer, err := b.getCASigner(ctx, req.Storage)
+	extensions, err = b.calculateSessionExtensions(data, role, extensions)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error creating signer: %w", err)
+		return nil, false, logical.ErrorResponse(err.Error()), nil
 	}
 
-	cBundle := creationBundle{
+	return &creationBundle{
 		KeyID:           keyID,
 		PublicKey:       publicKey,
-		Signer:          signer,
 		ValidPrincipals: parsedPrincipals,
 		TTL:             ttl,
 		CertificateType: certificateType,
 		Role:            role,
 		CriticalOptions: criticalOptions,
 		Extensions:      extensions,
+	}, addExtTemplatingWarning, nil, nil
+}
+
+// signCreationBundle signs the certificate with the issuer of its role and
+// keeps a record of it.
+func (b *backend) signCreationBundle(ctx context.Context, s logical.Storage, cBundle *creationBundle, roleName string) (*logical.Response, map[string]interface{}, error) {
+	signer, issuerName, err := b.getIssuerSigner(ctx, s, cBundle.Role.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating signer: %w", err)
 	}
+	cBundle.Signer = signer
 
 	certificate, err := cBundle.sign()
 	if err != nil {
@@ -129,7 +163,14 @@ func (b *backend) pathSignIssueCertificateHelper(ctx context.Context, req *logic
 		return nil, nil, errors.New("error marshaling signed certificate")
 	}
 
-	b.sshCertificateCounter.Increment().AddSSHCertificate(ttl)
+	// Keep a record of the certificate, so that it can be revoked
+	if !cBundle.Role.NoStore {
+		if err := storeCertificateEntry(ctx, s, roleName, issuerName, certificate); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	b.sshCertificateCounter.Increment().AddSSHCertificate(cBundle.TTL)
 
 	response := &logical.Response{
 		Data: map[string]interface{}{
@@ -138,15 +179,11 @@ func (b *backend) pathSignIssueCertificateHelper(ctx context.Context, req *logic
 		},
 	}
 
-	if addExtTemplatingWarning {
-		response.AddWarning("default_extension templating enabled with at least one extension requiring identity templating. However, this request lacked identity entity information, causing one or more extensions to be skipped from the generated certificate.")
-	}
-
 	metadata := map[string]interface{}{
-		"certificate_type": certificateType,
-		"ttl":              ttl.String(),
+		"certificate_type": cBundle.CertificateType,
+		"ttl":              cBundle.TTL.String(),
 		"serial_number":    strconv.FormatUint(certificate.Serial, 16),
-		"key_id":           keyID,
+		"key_id":           cBundle.KeyID,
 	}
 
 	return response, metadata, nil
@@ -370,6 +407,40 @@ func (b *backend) calculateExtensions(data *framework.FieldData, req *logical.Re
 	return extensions, haveMissingEntityInfoWithTemplatedExt, nil
 }
 
+// calculateSessionExtensions adds the ticket ID and justification of the
+// request to the extensions, under the names chosen by the role.
+func (b *backend) calculateSessionExtensions(data *framework.FieldData, role *sshRole, extensions map[string]string) (map[string]string, error) {
+	ticketID := data.Get("ticket_id").(string)
+	justification := data.Get("justification").(string)
+
+	if role.RequireApproval && justification == "" {
+		return nil, fmt.Errorf("role requires approval; a justification must be provided")
+	}
+	if ticketID != "" && role.TicketIDExtension == "" {
+		return nil, fmt.Errorf("role does not accept a ticket_id")
+	}
+	if justification != "" && role.JustificationExtension == "" && !role.RequireApproval {
+		return nil, fmt.Errorf("role does not accept a justification")
+	}
+
+	if role.TicketIDExtension == "" && role.JustificationExtension == "" {
+		return extensions, nil
+	}
+
+	// The extensions may be the role's defaults, which must not be modified
+	result := maps.Clone(extensions)
+	if result == nil {
+		result = make(map[string]string)
+	}
+	if ticketID != "" {
+		result[role.TicketIDExtension] = ticketID
+	}
+	if justification != "" && role.JustificationExtension != "" {
+		result[role.JustificationExtension] = justification
+	}
+	return result, nil
+}
+
 func (b *backend) calculateTTL(data *framework.FieldData, role *sshRole) (time.Duration, error) {
 	var ttl, maxTTL time.Duration
 	var err error
@@ -0,0 +1,562 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/builtin/logical/ssh/managed_key"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	issuersStoragePrefix     = "issuers/"
+	issuersConfigStoragePath = "config/issuers"
+
+	// legacyIssuerName refers to the key pair configured through config/ca,
+	// which signs certificates until another default issuer is chosen.
+	legacyIssuerName = "legacy"
+	// defaultIssuerRef refers to whichever issuer is the default of the mount.
+	defaultIssuerRef = "default"
+)
+
+// sshIssuerEntry is a named CA key pair. Several issuers can be trusted by
+// hosts at once, which allows the signing key to be rotated without a flag
+// day.
+type sshIssuerEntry struct {
+	Name       string                  `json:"name"`
+	PublicKey  string                  `json:"public_key"`
+	PrivateKey string                  `json:"private_key,omitempty"`
+	ManagedKey *managedKeyStorageEntry `json:"managed_key,omitempty"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
+
+type sshIssuersConfig struct {
+	DefaultIssuer string `json:"default"`
+}
+
+func pathListIssuers(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "issuers/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "issuers",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathIssuerList,
+		},
+
+		HelpSynopsis:    pathListIssuersHelpSyn,
+		HelpDescription: pathListIssuersHelpDesc,
+	}
+}
+
+func pathIssuer(b *backend) *framework.Path {
+	fields := caKeyFields()
+	fields["issuer_name"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: `[Required] Name of the issuer. "legacy" refers to the key pair of config/ca and "default" to the default issuer of the mount.`,
+	}
+
+	return &framework.Path{
+		Pattern: "issuer/" + framework.GenericNameRegex("issuer_name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "issuer",
+		},
+
+		Fields: fields,
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathIssuerWrite,
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathIssuerRead,
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathIssuerDelete,
+			},
+		},
+
+		HelpSynopsis:    pathIssuerHelpSyn,
+		HelpDescription: pathIssuerHelpDesc,
+	}
+}
+
+func pathConfigIssuers(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/issuers",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "issuers-configuration",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"default": {
+				Type:        framework.TypeString,
+				Description: `Name of the issuer that signs certificates for roles that don't choose one. Set to "legacy" to use the key pair of config/ca.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigIssuersRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigIssuersWrite,
+			},
+		},
+
+		HelpSynopsis:    pathConfigIssuersHelpSyn,
+		HelpDescription: pathConfigIssuersHelpDesc,
+	}
+}
+
+func (b *backend) pathIssuerList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	defaultIssuer, err := resolveIssuerName(ctx, req.Storage, defaultIssuerRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	keyInfo := make(map[string]interface{})
+
+	legacyKey, err := getCAPublicKey(ctx, req.Storage, !req.IsSnapshotReadOrList())
+	if err != nil {
+		return nil, err
+	}
+	if legacyKey != "" {
+		keys = append(keys, legacyIssuerName)
+		keyInfo[legacyIssuerName] = map[string]interface{}{
+			"public_key": legacyKey,
+			"is_default": defaultIssuer == legacyIssuerName,
+		}
+	}
+
+	issuers, err := listIssuers(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	for _, issuer := range issuers {
+		keys = append(keys, issuer.Name)
+		keyInfo[issuer.Name] = map[string]interface{}{
+			"public_key": issuer.PublicKey,
+			"is_default": defaultIssuer == issuer.Name,
+		}
+	}
+
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
+}
+
+func (b *backend) pathIssuerRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name, err := resolveIssuerName(ctx, req.Storage, d.Get("issuer_name").(string))
+	if err != nil {
+		return nil, err
+	}
+	defaultIssuer, err := resolveIssuerName(ctx, req.Storage, defaultIssuerRef)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"issuer_name": name,
+		"is_default":  name == defaultIssuer,
+	}
+
+	if name == legacyIssuerName {
+		publicKey, err := getCAPublicKey(ctx, req.Storage, !req.IsSnapshotReadOrList())
+		if err != nil {
+			return nil, err
+		}
+		if publicKey == "" {
+			return nil, nil
+		}
+		data["public_key"] = publicKey
+		return &logical.Response{
+			Data: data,
+		}, nil
+	}
+
+	issuer, err := getIssuer(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if issuer == nil {
+		return nil, nil
+	}
+
+	data["public_key"] = issuer.PublicKey
+	data["created_at"] = issuer.CreatedAt.Format(time.RFC3339)
+	if issuer.ManagedKey != nil {
+		data["managed_key_name"] = issuer.ManagedKey.KeyName
+		data["managed_key_id"] = issuer.ManagedKey.KeyId
+	}
+
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+func (b *backend) pathIssuerWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("issuer_name").(string)
+	switch name {
+	case legacyIssuerName:
+		return logical.ErrorResponse("the %q issuer is configured through config/ca", legacyIssuerName), nil
+	case defaultIssuerRef:
+		return logical.ErrorResponse("%q is reserved to refer to the default issuer", defaultIssuerRef), nil
+	}
+
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	existing, err := getIssuer(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return logical.ErrorResponse("issuer %q already exists; issuer keys can't be changed, create a new issuer to rotate keys", name), nil
+	}
+
+	key, errResp, err := b.readCAKeyInput(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	issuer := &sshIssuerEntry{
+		Name:       name,
+		PublicKey:  key.PublicKey,
+		PrivateKey: key.PrivateKey,
+		ManagedKey: key.ManagedKey,
+		CreatedAt:  time.Now().UTC(),
+	}
+	entry, err := logical.StorageEntryJSON(issuersStoragePrefix+name, issuer)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, fmt.Errorf("error writing issuer to storage: %w", err)
+	}
+
+	metadata := key.Metadata
+	metadata["issuer_name"] = name
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHIssuerWrite, metadata)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"issuer_name": name,
+			"public_key":  issuer.PublicKey,
+		},
+	}, nil
+}
+
+func (b *backend) pathIssuerDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("issuer_name").(string)
+	switch name {
+	case legacyIssuerName:
+		return logical.ErrorResponse("the %q issuer is deleted through config/ca", legacyIssuerName), nil
+	case defaultIssuerRef:
+		return logical.ErrorResponse("the default issuer must be referenced by name to be deleted"), nil
+	}
+
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	defaultIssuer, err := resolveIssuerName(ctx, req.Storage, defaultIssuerRef)
+	if err != nil {
+		return nil, err
+	}
+	if name == defaultIssuer {
+		return logical.ErrorResponse("issuer %q is the default issuer; choose another default through config/issuers before deleting it", name), nil
+	}
+
+	roles, err := b.rolesUsingIssuer(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) > 0 {
+		return logical.ErrorResponse("issuer %q is used by roles %s; change their issuer before deleting it", name, strings.Join(roles, ", ")), nil
+	}
+
+	if err := req.Storage.Delete(ctx, issuersStoragePrefix+name); err != nil {
+		return nil, err
+	}
+
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHIssuerDelete, map[string]interface{}{
+		"issuer_name": name,
+	})
+
+	return nil, nil
+}
+
+// rolesUsingIssuer returns the names of the roles that sign with the named
+// issuer. Roles that use the default issuer refer to it as "default", so they
+// aren't included.
+func (b *backend) rolesUsingIssuer(ctx context.Context, s logical.Storage, name string) ([]string, error) {
+	roleNames, err := s.List(ctx, "roles/")
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []string
+	for _, roleName := range roleNames {
+		role, err := b.getRole(ctx, s, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil && role.Issuer == name {
+			roles = append(roles, roleName)
+		}
+	}
+	return roles, nil
+}
+
+func (b *backend) pathConfigIssuersRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	defaultIssuer, err := resolveIssuerName(ctx, req.Storage, defaultIssuerRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"default": defaultIssuer,
+		},
+	}, nil
+}
+
+func (b *backend) pathConfigIssuersWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("default").(string)
+	if name == "" {
+		return logical.ErrorResponse("missing default"), nil
+	}
+
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	name, err := resolveIssuerName(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := issuerExists(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return logical.ErrorResponse("issuer %q does not exist", name), nil
+	}
+
+	entry, err := logical.StorageEntryJSON(issuersConfigStoragePath, &sshIssuersConfig{
+		DefaultIssuer: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHConfigIssuersWrite, map[string]interface{}{
+		"default": name,
+	})
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"default": name,
+		},
+	}, nil
+}
+
+// resolveIssuerName returns the name of the issuer that a reference points
+// to, resolving "default" and the empty reference to the default issuer of
+// the mount.
+func resolveIssuerName(ctx context.Context, s logical.Storage, ref string) (string, error) {
+	if ref != "" && ref != defaultIssuerRef {
+		return ref, nil
+	}
+
+	entry, err := s.Get(ctx, issuersConfigStoragePath)
+	if err != nil {
+		return "", err
+	}
+	if entry == nil {
+		return legacyIssuerName, nil
+	}
+
+	var config sshIssuersConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return "", err
+	}
+	if config.DefaultIssuer == "" {
+		return legacyIssuerName, nil
+	}
+	return config.DefaultIssuer, nil
+}
+
+// issuerExists returns whether the named issuer has a key pair.
+func issuerExists(ctx context.Context, s logical.Storage, name string) (bool, error) {
+	if name == legacyIssuerName {
+		return caKeysConfigured(ctx, s)
+	}
+
+	issuer, err := getIssuer(ctx, s, name)
+	if err != nil {
+		return false, err
+	}
+	return issuer != nil, nil
+}
+
+func getIssuer(ctx context.Context, s logical.Storage, name string) (*sshIssuerEntry, error) {
+	entry, err := s.Get(ctx, issuersStoragePrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result sshIssuerEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// listIssuers returns the named issuers, sorted by name.
+func listIssuers(ctx context.Context, s logical.Storage) ([]*sshIssuerEntry, error) {
+	names, err := s.List(ctx, issuersStoragePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*sshIssuerEntry, 0, len(names))
+	for _, name := range names {
+		issuer, err := getIssuer(ctx, s, name)
+		if err != nil {
+			return nil, err
+		}
+		if issuer != nil {
+			result = append(result, issuer)
+		}
+	}
+	return result, nil
+}
+
+// getIssuerSigner returns the signer of the issuer that a reference points
+// to, along with the name of that issuer.
+func (b *backend) getIssuerSigner(ctx context.Context, s logical.Storage, ref string) (ssh.Signer, string, error) {
+	name, err := resolveIssuerName(ctx, s, ref)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if name == legacyIssuerName {
+		signer, err := b.getCASigner(ctx, s)
+		return signer, name, err
+	}
+
+	issuer, err := getIssuer(ctx, s, name)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading issuer: %w", err)
+	}
+	if issuer == nil {
+		return nil, "", fmt.Errorf("issuer %q does not exist", name)
+	}
+
+	var signer ssh.Signer
+	if issuer.ManagedKey != nil {
+		signer, err = managed_key.GetManagedKeyInfo(ctx, b, issuer.ManagedKey.KeyId)
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting managed key info: %w", err)
+		}
+	} else {
+		if issuer.PrivateKey == "" {
+			return nil, "", errors.New("stored private key was empty")
+		}
+		signer, err = ssh.ParsePrivateKey([]byte(issuer.PrivateKey))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse private key of issuer %q: %w", name, err)
+		}
+	}
+
+	return signer, name, nil
+}
+
+// getCAPublicKeys returns the public keys of every issuer, the default issuer
+// first, so that hosts trusting all of them keep accepting certificates while
+// the signing key is rotated.
+func getCAPublicKeys(ctx context.Context, s logical.Storage, allowMigration bool) ([]string, error) {
+	defaultIssuer, err := resolveIssuerName(ctx, s, defaultIssuerRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	addKey := func(name, publicKey string) {
+		if name == defaultIssuer {
+			keys = append([]string{publicKey}, keys...)
+		} else {
+			keys = append(keys, publicKey)
+		}
+	}
+
+	legacyKey, err := getCAPublicKey(ctx, s, allowMigration)
+	if err != nil {
+		return nil, err
+	}
+	if legacyKey != "" {
+		addKey(legacyIssuerName, legacyKey)
+	}
+
+	issuers, err := listIssuers(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	for _, issuer := range issuers {
+		addKey(issuer.Name, issuer.PublicKey)
+	}
+
+	return keys, nil
+}
+
+const pathListIssuersHelpSyn = `List the issuers of this mount.`
+
+const pathListIssuersHelpDesc = `
+This path lists the issuers that can sign certificates, with their public keys
+and whether they are the default issuer. The key pair of config/ca is listed as
+the "legacy" issuer.
+`
+
+const pathIssuerHelpSyn = `Create, read or delete a named CA key pair.`
+
+const pathIssuerHelpDesc = `
+This path creates an issuer: a CA key pair that roles can sign certificates
+with. Like config/ca, the key pair can be generated, imported through
+"private_key" and "public_key", or be a managed key. The keys of an issuer
+can't be changed; to rotate the signing key, create a new issuer, distribute
+the keys returned by "public_key" to hosts, make the new issuer the default
+through config/issuers, and delete the old issuer once the certificates it
+signed have expired. The default issuer, and issuers that roles sign with
+through their "issuer" parameter, can't be deleted.
+`
+
+const pathConfigIssuersHelpSyn = `Configure the default issuer of this mount.`
+
+const pathConfigIssuersHelpDesc = `
+This path sets the issuer that signs certificates for roles that don't choose
+one through their "issuer" field. Until it is set, the key pair of config/ca,
+referred to as the "legacy" issuer, is the default.
+`
@@ -0,0 +1,156 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSH_Issuers(t *testing.T) {
+	ctx := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Backend(config)
+	require.NoError(t, err)
+	require.NoError(t, b.Setup(ctx, config))
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   config.StorageView,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	publicKey := func() string {
+		t.Helper()
+		resp := request(logical.ReadOperation, "public_key", nil)
+		return string(resp.Data[logical.HTTPRawBody].([]byte))
+	}
+	// sign returns the CA key that signed a certificate of the role, and
+	// the issuer in the record of that certificate
+	sign := func(role string) (string, string) {
+		t.Helper()
+		resp := request(logical.UpdateOperation, "sign/"+role, map[string]interface{}{
+			"public_key":       publicKey2,
+			"valid_principals": "alice",
+		})
+		require.False(t, resp.IsError(), resp)
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(resp.Data["signed_key"].(string)))
+		require.NoError(t, err)
+		caKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key.(*ssh.Certificate).SignatureKey)))
+
+		resp = request(logical.ReadOperation, "cert/"+resp.Data["serial_number"].(string), nil)
+		return caKey, resp.Data["issuer"].(string)
+	}
+
+	resp := request(logical.UpdateOperation, "config/ca", map[string]interface{}{
+		"public_key":  testCAPublicKey,
+		"private_key": testCAPrivateKey,
+	})
+	require.False(t, resp != nil && resp.IsError(), resp)
+	legacyKey := strings.TrimSpace(testCAPublicKey)
+	parsedLegacyKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(testCAPublicKey))
+	require.NoError(t, err)
+	legacyCAKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(parsedLegacyKey)))
+
+	resp = request(logical.ListOperation, "issuers/", nil)
+	require.Equal(t, []string{legacyIssuerName}, resp.Data["keys"])
+	require.Equal(t, true, resp.Data["key_info"].(map[string]interface{})[legacyIssuerName].(map[string]interface{})["is_default"])
+
+	resp = request(logical.UpdateOperation, "issuer/next", map[string]interface{}{
+		"key_type": "ed25519",
+	})
+	require.False(t, resp.IsError(), resp)
+	nextKey := strings.TrimSpace(resp.Data["public_key"].(string))
+
+	// The keys of an issuer can't be replaced, and the reserved names can't
+	// be used
+	for _, name := range []string{"next", legacyIssuerName, defaultIssuerRef} {
+		resp = request(logical.UpdateOperation, "issuer/"+name, nil)
+		require.True(t, resp.IsError(), name)
+	}
+
+	resp = request(logical.ReadOperation, "issuer/next", nil)
+	require.Equal(t, false, resp.Data["is_default"])
+	require.NotEmpty(t, resp.Data["created_at"])
+
+	// Hosts are given every key, the default issuer's first
+	require.Equal(t, legacyKey+"\n"+nextKey+"\n", publicKey())
+
+	request(logical.UpdateOperation, "roles/ca", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+	})
+	request(logical.UpdateOperation, "roles/ca-next", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"issuer":                  "next",
+	})
+	resp = request(logical.UpdateOperation, "roles/ca-missing", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"issuer":                  "missing",
+	})
+	require.True(t, resp.IsError())
+
+	caKey, issuer := sign("ca")
+	require.Equal(t, legacyCAKey, caKey)
+	require.Equal(t, legacyIssuerName, issuer)
+	caKey, issuer = sign("ca-next")
+	require.Equal(t, nextKey, caKey)
+	require.Equal(t, "next", issuer)
+
+	// Changing the default issuer moves roles without an issuer over
+	resp = request(logical.UpdateOperation, "config/issuers", map[string]interface{}{
+		"default": "missing",
+	})
+	require.True(t, resp.IsError())
+	resp = request(logical.UpdateOperation, "config/issuers", map[string]interface{}{
+		"default": "next",
+	})
+	require.False(t, resp.IsError(), resp)
+	resp = request(logical.ReadOperation, "config/issuers", nil)
+	require.Equal(t, "next", resp.Data["default"])
+	resp = request(logical.ReadOperation, "issuer/default", nil)
+	require.Equal(t, "next", resp.Data["issuer_name"])
+
+	require.Equal(t, nextKey+"\n"+legacyKey+"\n", publicKey())
+	caKey, issuer = sign("ca")
+	require.Equal(t, nextKey, caKey)
+	require.Equal(t, "next", issuer)
+
+	// The default issuer can't be deleted
+	resp = request(logical.DeleteOperation, "issuer/next", nil)
+	require.True(t, resp.IsError())
+
+	request(logical.UpdateOperation, "config/issuers", map[string]interface{}{
+		"default": legacyIssuerName,
+	})
+
+	// nor can an issuer that roles sign with
+	resp = request(logical.DeleteOperation, "issuer/next", nil)
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "ca-next")
+
+	request(logical.DeleteOperation, "roles/ca-next", nil)
+	resp = request(logical.DeleteOperation, "issuer/next", nil)
+	require.Nil(t, resp)
+
+	// With a single key left, it is served as configured
+	require.Equal(t, testCAPublicKey, publicKey())
+	resp = request(logical.ListOperation, "issuers/", nil)
+	require.Equal(t, []string{legacyIssuerName}, resp.Data["keys"])
+}
@@ -0,0 +1,322 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	revokedSerialsStoragePrefix = "revoked/serials/"
+	revokedKeyIDsStoragePrefix  = "revoked/key-ids/"
+	krlVersionStoragePath       = "krl/version"
+)
+
+// sshRevocationEntry revokes either the certificate with a serial number or
+// all certificates with a key ID, signed by the given CAs. It's kept in the
+// KRL until Expiration, after which every certificate it covers has expired.
+type sshRevocationEntry struct {
+	SerialNumber string    `json:"serial_number,omitempty"`
+	KeyID        string    `json:"key_id,omitempty"`
+	CAPublicKeys []string  `json:"ca_public_keys"`
+	RevokedAt    time.Time `json:"revoked_at"`
+	Expiration   time.Time `json:"expiration"`
+}
+
+func pathRevoke(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "revoke",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationVerb:   "revoke",
+			OperationSuffix: "certificate",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"serial_number": {
+				Type:        framework.TypeString,
+				Description: "Serial number, in hexadecimal, of the certificate to revoke. Mutually exclusive with key_id.",
+			},
+			"key_id": {
+				Type:        framework.TypeString,
+				Description: "Key ID of the certificates to revoke. Mutually exclusive with serial_number.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRevokeWrite,
+		},
+
+		HelpSynopsis:    pathRevokeHelpSyn,
+		HelpDescription: pathRevokeHelpDesc,
+	}
+}
+
+func (b *backend) pathRevokeWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	serialRaw := d.Get("serial_number").(string)
+	keyID := d.Get("key_id").(string)
+	if (serialRaw == "") == (keyID == "") {
+		return logical.ErrorResponse("exactly one of serial_number or key_id must be provided"), nil
+	}
+
+	b.revocationLock.Lock()
+	defer b.revocationLock.Unlock()
+
+	now := time.Now().UTC()
+	var path string
+	var revocation *sshRevocationEntry
+	if serialRaw != "" {
+		serial, err := normalizeSerial(serialRaw)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		cert, err := getCertificateEntry(ctx, req.Storage, serial)
+		if err != nil {
+			return nil, err
+		}
+		if cert == nil {
+			return logical.ErrorResponse("no certificate with serial number %q was issued by this mount", serial), nil
+		}
+		if !cert.ValidBefore.After(now) {
+			return logical.ErrorResponse("certificate with serial number %q has already expired", serial), nil
+		}
+
+		path = revokedSerialsStoragePrefix + serial
+		revocation = &sshRevocationEntry{
+			SerialNumber: serial,
+			CAPublicKeys: []string{cert.CAPublicKey},
+			RevokedAt:    now,
+			Expiration:   cert.ValidBefore,
+		}
+	} else {
+		certs, err := listCertificateEntries(ctx, req.Storage)
+		if err != nil {
+			return nil, err
+		}
+
+		revocation = &sshRevocationEntry{
+			KeyID:     keyID,
+			RevokedAt: now,
+		}
+		for _, cert := range certs {
+			if cert.KeyID != keyID || !cert.ValidBefore.After(now) {
+				continue
+			}
+			if !slices.Contains(revocation.CAPublicKeys, cert.CAPublicKey) {
+				revocation.CAPublicKeys = append(revocation.CAPublicKeys, cert.CAPublicKey)
+			}
+			if cert.ValidBefore.After(revocation.Expiration) {
+				revocation.Expiration = cert.ValidBefore
+			}
+		}
+		if len(revocation.CAPublicKeys) == 0 {
+			return logical.ErrorResponse("no unexpired certificate with key ID %q was issued by this mount", keyID), nil
+		}
+
+		path = revokedKeyIDsStoragePrefix + base64.RawURLEncoding.EncodeToString([]byte(keyID))
+	}
+
+	existing, err := getRevocationEntry(ctx, req.Storage, path)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		// Keep the original revocation time, but cover certificates issued
+		// with the key ID since
+		revocation.RevokedAt = existing.RevokedAt
+		for _, caKey := range existing.CAPublicKeys {
+			if !slices.Contains(revocation.CAPublicKeys, caKey) {
+				revocation.CAPublicKeys = append(revocation.CAPublicKeys, caKey)
+			}
+		}
+		if existing.Expiration.After(revocation.Expiration) {
+			revocation.Expiration = existing.Expiration
+		}
+	}
+
+	entry, err := logical.StorageEntryJSON(path, revocation)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+	if err := incrementKRLVersion(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{
+		"serial_number": revocation.SerialNumber,
+		"key_id":        revocation.KeyID,
+	}
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHRevoke, metadata)
+
+	data := map[string]interface{}{
+		"revocation_time": revocation.RevokedAt.Format(time.RFC3339),
+		"expiration":      revocation.Expiration.Format(time.RFC3339),
+	}
+	if revocation.SerialNumber != "" {
+		data["serial_number"] = revocation.SerialNumber
+	} else {
+		data["key_id"] = revocation.KeyID
+	}
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+// buildKRL returns the KRL of the unexpired revocations, grouped by the CA
+// that signed the revoked certificates.
+func buildKRL(ctx context.Context, s logical.Storage) ([]byte, error) {
+	version, err := getKRLVersion(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	revocations, err := listRevocationEntries(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var sections []*krlCertificates
+	byCA := make(map[string]*krlCertificates)
+	for _, revocation := range revocations {
+		if !revocation.Expiration.After(now) {
+			continue
+		}
+		for _, caKey := range revocation.CAPublicKeys {
+			section, ok := byCA[caKey]
+			if !ok {
+				key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(caKey))
+				if err != nil {
+					return nil, fmt.Errorf("error parsing CA public key of revocation: %w", err)
+				}
+				section = &krlCertificates{CAKey: key}
+				byCA[caKey] = section
+				sections = append(sections, section)
+			}
+
+			if revocation.SerialNumber != "" {
+				serial, err := strconv.ParseUint(revocation.SerialNumber, 16, 64)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing serial number of revocation: %w", err)
+				}
+				section.Serials = append(section.Serials, serial)
+			} else {
+				section.KeyIDs = append(section.KeyIDs, revocation.KeyID)
+			}
+		}
+	}
+
+	return marshalKRL(version, now, "", sections), nil
+}
+
+func getRevocationEntry(ctx context.Context, s logical.Storage, path string) (*sshRevocationEntry, error) {
+	entry, err := s.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result sshRevocationEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// listRevocationEntries returns every revocation, by storage path.
+func listRevocationEntries(ctx context.Context, s logical.Storage) (map[string]*sshRevocationEntry, error) {
+	result := make(map[string]*sshRevocationEntry)
+	for _, prefix := range []string{revokedSerialsStoragePrefix, revokedKeyIDsStoragePrefix} {
+		keys, err := s.List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			revocation, err := getRevocationEntry(ctx, s, prefix+key)
+			if err != nil {
+				return nil, err
+			}
+			if revocation != nil {
+				result[prefix+key] = revocation
+			}
+		}
+	}
+	return result, nil
+}
+
+// listCertificateEntries returns every issuance record, by serial number.
+func listCertificateEntries(ctx context.Context, s logical.Storage) (map[string]*sshCertificateEntry, error) {
+	serials, err := s.List(ctx, certsStoragePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*sshCertificateEntry, len(serials))
+	for _, serial := range serials {
+		cert, err := getCertificateEntry(ctx, s, serial)
+		if err != nil {
+			return nil, err
+		}
+		if cert != nil {
+			result[serial] = cert
+		}
+	}
+	return result, nil
+}
+
+func getKRLVersion(ctx context.Context, s logical.Storage) (uint64, error) {
+	entry, err := s.Get(ctx, krlVersionStoragePath)
+	if err != nil {
+		return 0, err
+	}
+	if entry == nil {
+		return 0, nil
+	}
+	return strconv.ParseUint(string(entry.Value), 10, 64)
+}
+
+// incrementKRLVersion bumps the version of the KRL, which sshd and ssh-keygen
+// use to tell KRLs apart, whenever the revocations change.
+func incrementKRLVersion(ctx context.Context, s logical.Storage) error {
+	version, err := getKRLVersion(ctx, s)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, &logical.StorageEntry{
+		Key:   krlVersionStoragePath,
+		Value: []byte(strconv.FormatUint(version+1, 10)),
+	})
+}
+
+const pathRevokeHelpSyn = `Revoke a certificate issued by this mount.`
+
+const pathRevokeHelpDesc = `
+This path revokes either the certificate with the given "serial_number", or
+every unexpired certificate with the given "key_id". Revoked certificates are
+listed in the Key Revocation List served at "krl", which sshd reads through its
+RevokedKeys option. Revocations are kept until the certificates they cover have
+expired, after which "tidy/certificates" removes them.
+
+A revoked key ID is listed under the CA keys that signed the certificates it
+covers, so a certificate issued later with the same key ID by one of those CA
+keys is also rejected, but only until the revocation is removed. Revoke the key
+ID again to extend the revocation to certificates issued since. Certificates of
+roles with "no_store" aren't recorded, so they can't be revoked through this
+path.
+`
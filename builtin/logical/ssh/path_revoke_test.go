@@ -0,0 +1,228 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"encoding/binary"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// parseKRL reads back the certificate sections of a KRL, by CA key.
+func parseKRL(t *testing.T, krl []byte) (uint64, map[string]*krlCertificates) {
+	t.Helper()
+
+	readString := func(b []byte) ([]byte, []byte) {
+		require.GreaterOrEqual(t, len(b), 4)
+		n := binary.BigEndian.Uint32(b)
+		require.GreaterOrEqual(t, len(b), int(4+n))
+		return b[4 : 4+n], b[4+n:]
+	}
+
+	require.Equal(t, krlMagic, binary.BigEndian.Uint64(krl))
+	require.Equal(t, krlFormatVersion, binary.BigEndian.Uint32(krl[8:]))
+	version := binary.BigEndian.Uint64(krl[12:])
+	rest := krl[36:]
+	_, rest = readString(rest) // reserved
+	_, rest = readString(rest) // comment
+
+	result := make(map[string]*krlCertificates)
+	for len(rest) > 0 {
+		require.Equal(t, krlSectionCertificates, rest[0])
+		var section []byte
+		section, rest = readString(rest[1:])
+
+		caKey, section := readString(section)
+		key, err := ssh.ParsePublicKey(caKey)
+		require.NoError(t, err)
+		_, section = readString(section) // reserved
+
+		certs := &krlCertificates{CAKey: key}
+		for len(section) > 0 {
+			sectionType := section[0]
+			var data []byte
+			data, section = readString(section[1:])
+			switch sectionType {
+			case krlSectionCertSerialList:
+				for ; len(data) > 0; data = data[8:] {
+					certs.Serials = append(certs.Serials, binary.BigEndian.Uint64(data))
+				}
+			case krlSectionCertKeyID:
+				for len(data) > 0 {
+					var keyID []byte
+					keyID, data = readString(data)
+					certs.KeyIDs = append(certs.KeyIDs, string(keyID))
+				}
+			default:
+				t.Fatalf("unexpected certificate section type %x", sectionType)
+			}
+		}
+		result[string(ssh.MarshalAuthorizedKey(key))] = certs
+	}
+	return version, result
+}
+
+func TestSSH_RevokeCertificates(t *testing.T) {
+	ctx := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Backend(config)
+	require.NoError(t, err)
+	require.NoError(t, b.Setup(ctx, config))
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   config.StorageView,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := request(logical.UpdateOperation, "config/ca", map[string]interface{}{
+		"public_key":  testCAPublicKey,
+		"private_key": testCAPrivateKey,
+	})
+	require.False(t, resp != nil && resp.IsError(), resp)
+	request(logical.UpdateOperation, "roles/ca", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"allow_user_key_ids":      true,
+		"ttl":                     "1h",
+	})
+
+	sign := func(keyID string) string {
+		t.Helper()
+		resp := request(logical.UpdateOperation, "sign/ca", map[string]interface{}{
+			"public_key":       publicKey2,
+			"valid_principals": "alice",
+			"key_id":           keyID,
+		})
+		require.False(t, resp.IsError(), resp)
+		return resp.Data["serial_number"].(string)
+	}
+	serial1 := sign("alice")
+	serial2 := sign("bob")
+	sign("bob")
+
+	// Certificates of roles with no_store aren't recorded
+	request(logical.UpdateOperation, "roles/ephemeral", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"ttl":                     "5m",
+		"no_store":                true,
+	})
+	resp = request(logical.ReadOperation, "roles/ephemeral", nil)
+	require.Equal(t, true, resp.Data["no_store"])
+	resp = request(logical.UpdateOperation, "sign/ephemeral", map[string]interface{}{
+		"public_key":       publicKey2,
+		"valid_principals": "alice",
+	})
+	require.False(t, resp.IsError(), resp)
+	require.NotEmpty(t, resp.Data["signed_key"])
+	cert, err := getCertificateEntry(ctx, config.StorageView, resp.Data["serial_number"].(string))
+	require.NoError(t, err)
+	require.Nil(t, cert)
+
+	resp = request(logical.ListOperation, "certs/", nil)
+	require.Len(t, resp.Data["keys"], 3)
+
+	resp = request(logical.ReadOperation, "cert/"+serial1, nil)
+	require.Equal(t, "alice", resp.Data["key_id"])
+	require.Equal(t, "ca", resp.Data["role"])
+	require.Equal(t, false, resp.Data["revoked"])
+
+	// An empty KRL is served before anything is revoked
+	resp = request(logical.ReadOperation, "krl", nil)
+	version, sections := parseKRL(t, resp.Data[logical.HTTPRawBody].([]byte))
+	require.Equal(t, uint64(0), version)
+	require.Empty(t, sections)
+
+	resp = request(logical.UpdateOperation, "revoke", map[string]interface{}{
+		"serial_number": serial1,
+	})
+	require.False(t, resp.IsError(), resp)
+	resp = request(logical.UpdateOperation, "revoke", map[string]interface{}{
+		"key_id": "bob",
+	})
+	require.False(t, resp.IsError(), resp)
+
+	resp = request(logical.UpdateOperation, "revoke", map[string]interface{}{
+		"serial_number": "abc",
+	})
+	require.True(t, resp.IsError())
+	resp = request(logical.UpdateOperation, "revoke", map[string]interface{}{
+		"key_id": "carol",
+	})
+	require.True(t, resp.IsError())
+
+	resp = request(logical.ReadOperation, "cert/"+serial1, nil)
+	require.Equal(t, true, resp.Data["revoked"])
+	resp = request(logical.ReadOperation, "cert/"+serial2, nil)
+	require.Equal(t, false, resp.Data["revoked"])
+
+	resp = request(logical.ReadOperation, "krl", nil)
+	require.Equal(t, "application/octet-stream", resp.Data[logical.HTTPContentType])
+	version, sections = parseKRL(t, resp.Data[logical.HTTPRawBody].([]byte))
+	require.Equal(t, uint64(2), version)
+	require.Len(t, sections, 1)
+	caKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(testCAPublicKey))
+	require.NoError(t, err)
+	section := sections[string(ssh.MarshalAuthorizedKey(caKey))]
+	require.NotNil(t, section)
+	serial, err := strconv.ParseUint(serial1, 16, 64)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{serial}, section.Serials)
+	require.Equal(t, []string{"bob"}, section.KeyIDs)
+
+	// Nothing has expired yet, so tidy keeps everything
+	resp = request(logical.UpdateOperation, "tidy/certificates", nil)
+	require.Contains(t, resp.Data["message"], "Removed 0 expired certificate records, 0 expired revocations")
+
+	// Once the certificates have expired, tidy removes them along with
+	// their revocations
+	resp = request(logical.UpdateOperation, "tidy/certificates", map[string]interface{}{
+		"safety_buffer": -int((2 * time.Hour).Seconds()),
+	})
+	require.True(t, resp.IsError())
+	for _, serial := range []string{serial1, serial2} {
+		cert, err := getCertificateEntry(ctx, config.StorageView, serial)
+		require.NoError(t, err)
+		cert.ValidBefore = time.Now().Add(-time.Hour)
+		entry, err := logical.StorageEntryJSON(certsStoragePrefix+serial, cert)
+		require.NoError(t, err)
+		require.NoError(t, config.StorageView.Put(ctx, entry))
+	}
+	revocations, err := listRevocationEntries(ctx, config.StorageView)
+	require.NoError(t, err)
+	for path, revocation := range revocations {
+		revocation.Expiration = time.Now().Add(-time.Hour)
+		entry, err := logical.StorageEntryJSON(path, revocation)
+		require.NoError(t, err)
+		require.NoError(t, config.StorageView.Put(ctx, entry))
+	}
+
+	resp = request(logical.UpdateOperation, "tidy/certificates", map[string]interface{}{
+		"safety_buffer": 0,
+	})
+	require.Contains(t, resp.Data["message"], "Removed 2 expired certificate records, 2 expired revocations")
+	resp = request(logical.ListOperation, "certs/", nil)
+	require.Len(t, resp.Data["keys"], 1)
+
+	resp = request(logical.ReadOperation, "krl", nil)
+	version, sections = parseKRL(t, resp.Data[logical.HTTPRawBody].([]byte))
+	require.Equal(t, uint64(3), version)
+	require.Empty(t, sections)
+}
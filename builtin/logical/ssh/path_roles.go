@@ -68,6 +68,12 @@ type sshRole struct {
 	Version                    int               `mapstructure:"role_version" json:"role_version"`
 	NotBeforeDuration          time.Duration     `mapstructure:"not_before_duration" json:"not_before_duration"`
 	AllowEmptyPrincipals       bool              `mapstructure:"allow_empty_principals" json:"allow_empty_principals"`
+	Issuer                     string            `mapstructure:"issuer" json:"issuer"`
+	RequireApproval            bool              `mapstructure:"require_approval" json:"require_approval"`
+	ApprovalTTL                time.Duration     `mapstructure:"approval_ttl" json:"approval_ttl"`
+	TicketIDExtension          string            `mapstructure:"ticket_id_extension" json:"ticket_id_extension"`
+	JustificationExtension     string            `mapstructure:"justification_extension" json:"justification_extension"`
+	NoStore                    bool              `mapstructure:"no_store" json:"no_store"`
 }
 
 func pathListRoles(b *backend) *framework.Path {
@@ -389,6 +395,57 @@ func pathRoles(b *backend) *framework.Path {
 				Description: `Whether to allow issuing certificates with no valid principals (meaning any valid principal).  Exists for backwards compatibility only, the default of false is highly recommended.`,
 				Default:     false,
 			},
+			"issuer": {
+				Type: framework.TypeString,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				Name of the issuer that signs the certificates of this role. Defaults
+				to the default issuer of the mount, set through config/issuers.`,
+			},
+			"require_approval": {
+				Type: framework.TypeBool,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				If set, requests to sign/<role> must include a justification and
+				are parked until another identity approves them through
+				signing-request/<id>/approve. Roles that require approval can't be
+				used with issue/<role>.`,
+				Default: false,
+			},
+			"approval_ttl": {
+				Type: framework.TypeDurationSecond,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				How long a parked signing request can wait for approval. Defaults
+				to 1 hour.`,
+			},
+			"ticket_id_extension": {
+				Type: framework.TypeString,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				Name of the certificate extension that the ticket_id of a signing
+				request is embedded in, such as "ticket-id@example.com". If unset,
+				requests can't set a ticket_id.`,
+			},
+			"justification_extension": {
+				Type: framework.TypeString,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				Name of the certificate extension that the justification of a
+				signing request is embedded in. If unset, requests can only set a
+				justification when the role requires approval.`,
+			},
+			"no_store": {
+				Type: framework.TypeBool,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				If set, certificates signed against this role are not stored in
+				the storage backend. This can improve performance when signing
+				large numbers of certificates. However, certificates signed in
+				this way cannot be listed or revoked by serial number, and
+				revoking their key ID doesn't cover them, so this option is
+				recommended only for short-lived certificates.`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -486,6 +543,20 @@ func (b *backend) pathRoleWrite(ctx context.Context, req *logical.Request, d *fr
 		if errorResponse != nil {
 			return errorResponse, nil
 		}
+		if role.Issuer != "" && role.Issuer != defaultIssuerRef {
+			// Hold the issuers lock until the role is stored, so that the
+			// issuer can't be deleted in the meantime
+			b.issuersLock.Lock()
+			defer b.issuersLock.Unlock()
+
+			exists, err := issuerExists(ctx, req.Storage, role.Issuer)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return logical.ErrorResponse("issuer %q does not exist", role.Issuer), nil
+			}
+		}
 		roleEntry = *role
 	} else {
 		return logical.ErrorResponse("invalid key type"), nil
@@ -529,6 +600,12 @@ func (b *backend) createCARole(allowedUsers, defaultUser, signer string, data *f
 		Version:                   roleEntryVersion,
 		NotBeforeDuration:         time.Duration(data.Get("not_before_duration").(int)) * time.Second,
 		AllowEmptyPrincipals:      data.Get("allow_empty_principals").(bool),
+		Issuer:                    data.Get("issuer").(string),
+		RequireApproval:           data.Get("require_approval").(bool),
+		ApprovalTTL:               time.Duration(data.Get("approval_ttl").(int)) * time.Second,
+		TicketIDExtension:         data.Get("ticket_id_extension").(string),
+		JustificationExtension:    data.Get("justification_extension").(string),
+		NoStore:                   data.Get("no_store").(bool),
 	}
 
 	if !role.AllowUserCertificates && !role.AllowHostCertificates {
@@ -723,6 +800,12 @@ func (b *backend) parseRole(role *sshRole) (map[string]interface{}, error) {
 			"algorithm_signer":            role.AlgorithmSigner,
 			"not_before_duration":         int64(role.NotBeforeDuration.Seconds()),
 			"allow_empty_principals":      role.AllowEmptyPrincipals,
+			"issuer":                      role.Issuer,
+			"require_approval":            role.RequireApproval,
+			"approval_ttl":                int64(role.ApprovalTTL.Seconds()),
+			"ticket_id_extension":         role.TicketIDExtension,
+			"justification_extension":     role.JustificationExtension,
+			"no_store":                    role.NoStore,
 		}
 	case KeyTypeDynamic:
 		return nil, fmt.Errorf("dynamic key type roles are no longer supported")
@@ -855,6 +938,9 @@ func (r *sshRole) observationMetadata(roleName string) map[string]interface{} {
 		metadata["algorithm_signer"] = r.AlgorithmSigner
 		metadata["not_before_duration"] = r.NotBeforeDuration.String()
 		metadata["allow_empty_principals"] = r.AllowEmptyPrincipals
+		metadata["issuer"] = r.Issuer
+		metadata["require_approval"] = r.RequireApproval
+		metadata["no_store"] = r.NoStore
 	}
 	return metadata
 }
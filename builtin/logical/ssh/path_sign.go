@@ -64,6 +64,14 @@ be later than the role max TTL.`,
 				Type:        framework.TypeMap,
 				Description: `Extensions that the certificate should be signed for.`,
 			},
+			"ticket_id": {
+				Type:        framework.TypeString,
+				Description: `Ticket ID of the change or incident the certificate is requested for. Embedded in the certificate when the role sets ticket_id_extension.`,
+			},
+			"justification": {
+				Type:        framework.TypeString,
+				Description: `Reason the certificate is requested. Required by roles that require approval, and embedded in the certificate when the role sets justification_extension.`,
+			},
 		},
 
 		HelpSynopsis:    `Request signing an SSH key using a certain role with the provided details.`,
@@ -102,7 +110,11 @@ func (b *backend) pathSignCertificate(ctx context.Context, req *logical.Request,
 		return logical.ErrorResponse(fmt.Sprintf("public_key failed to meet the key requirements: %s", err)), nil
 	}
 
-	response, certMetadata, err := b.pathSignIssueCertificateHelper(ctx, req, data, role, userPublicKey)
+	if role.RequireApproval {
+		return b.createSigningRequest(ctx, req, data, role, roleName, userPublicKey)
+	}
+
+	response, certMetadata, err := b.pathSignIssueCertificateHelper(ctx, req, data, role, roleName, userPublicKey)
 	if err != nil {
 		return nil, err
 	}
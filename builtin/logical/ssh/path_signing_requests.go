@@ -0,0 +1,592 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	uuid "github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	signingRequestsStoragePrefix = "signing-requests/"
+
+	defaultApprovalTTL = time.Hour
+
+	signingRequestPending  = "pending"
+	signingRequestApproved = "approved"
+	signingRequestDenied   = "denied"
+	signingRequestExpired  = "expired"
+)
+
+// sshSigningRequest is a request to sign/:role against a role that requires
+// approval. The certificate is resolved against the role when the request is
+// made, and signed once another identity approves it.
+type sshSigningRequest struct {
+	ID            string `json:"id"`
+	Role          string `json:"role"`
+	Status        string `json:"status"`
+	Requester     string `json:"requester"`
+	RequesterName string `json:"requester_name"`
+	TicketID      string `json:"ticket_id,omitempty"`
+	Justification string `json:"justification"`
+
+	PublicKey       string            `json:"public_key"`
+	KeyID           string            `json:"key_id"`
+	CertificateType uint32            `json:"certificate_type"`
+	ValidPrincipals []string          `json:"valid_principals"`
+	TTL             time.Duration     `json:"ttl"`
+	CriticalOptions map[string]string `json:"critical_options"`
+	Extensions      map[string]string `json:"extensions"`
+
+	RequestedAt time.Time `json:"requested_at"`
+	// ExpiresAt is the deadline for approval while the request is pending,
+	// and the expiration of the certificate once it is approved.
+	ExpiresAt    time.Time `json:"expires_at"`
+	Reviewer     string    `json:"reviewer,omitempty"`
+	ReviewerName string    `json:"reviewer_name,omitempty"`
+	ReviewedAt   time.Time `json:"reviewed_at"`
+	DenialReason string    `json:"denial_reason,omitempty"`
+	SerialNumber string    `json:"serial_number,omitempty"`
+	SignedKey    string    `json:"signed_key,omitempty"`
+}
+
+// currentStatus returns the status of the request, which is expired once a
+// pending request is past its deadline.
+func (r *sshSigningRequest) currentStatus(now time.Time) string {
+	if r.Status == signingRequestPending && !r.ExpiresAt.After(now) {
+		return signingRequestExpired
+	}
+	return r.Status
+}
+
+func pathListSigningRequests(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "signing-requests/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "signing-requests",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathSigningRequestList,
+		},
+
+		HelpSynopsis:    pathListSigningRequestsHelpSyn,
+		HelpDescription: pathListSigningRequestsHelpDesc,
+	}
+}
+
+func pathSigningRequest(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "signing-request/" + framework.GenericNameRegex("request_id"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "signing-request",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"request_id": {
+				Type:        framework.TypeString,
+				Description: "[Required] ID of the signing request.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathSigningRequestRead,
+		},
+
+		HelpSynopsis:    pathSigningRequestHelpSyn,
+		HelpDescription: pathSigningRequestHelpDesc,
+	}
+}
+
+func pathApproveSigningRequest(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "signing-request/" + framework.GenericNameRegex("request_id") + "/approve",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationVerb:   "approve",
+			OperationSuffix: "signing-request",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"request_id": {
+				Type:        framework.TypeString,
+				Description: "[Required] ID of the signing request.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathSigningRequestApprove,
+		},
+
+		HelpSynopsis:    pathApproveSigningRequestHelpSyn,
+		HelpDescription: pathApproveSigningRequestHelpDesc,
+	}
+}
+
+func pathDenySigningRequest(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "signing-request/" + framework.GenericNameRegex("request_id") + "/deny",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationVerb:   "deny",
+			OperationSuffix: "signing-request",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"request_id": {
+				Type:        framework.TypeString,
+				Description: "[Required] ID of the signing request.",
+			},
+			"reason": {
+				Type:        framework.TypeString,
+				Description: "Reason the request is denied, returned to the requester.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathSigningRequestDeny,
+		},
+
+		HelpSynopsis:    pathDenySigningRequestHelpSyn,
+		HelpDescription: pathDenySigningRequestHelpDesc,
+	}
+}
+
+const signingRequestIdentityPrefix = "entity:"
+
+// requestIdentity identifies the caller of a request by its entity. Tokens
+// without an entity can't make or review signing requests, since nothing
+// would tell a token apart from the tokens it created.
+func requestIdentity(req *logical.Request) (string, *logical.Response) {
+	if req.EntityID == "" {
+		return "", logical.ErrorResponse("signing requests can only be made and reviewed by tokens with an identity entity")
+	}
+	return signingRequestIdentityPrefix + req.EntityID, nil
+}
+
+// createSigningRequest parks a request to sign/:role until it is approved.
+func (b *backend) createSigningRequest(ctx context.Context, req *logical.Request, data *framework.FieldData, role *sshRole, roleName string, publicKey ssh.PublicKey) (*logical.Response, error) {
+	requester, errResp := requestIdentity(req)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	cBundle, addExtTemplatingWarning, errResp, err := b.newCreationBundle(req, data, role, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	approvalTTL := role.ApprovalTTL
+	if approvalTTL <= 0 {
+		approvalTTL = defaultApprovalTTL
+	}
+	now := time.Now().UTC()
+
+	signingRequest := &sshSigningRequest{
+		ID:              id,
+		Role:            roleName,
+		Status:          signingRequestPending,
+		Requester:       requester,
+		RequesterName:   req.DisplayName,
+		TicketID:        data.Get("ticket_id").(string),
+		Justification:   data.Get("justification").(string),
+		PublicKey:       strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		KeyID:           cBundle.KeyID,
+		CertificateType: cBundle.CertificateType,
+		ValidPrincipals: cBundle.ValidPrincipals,
+		TTL:             cBundle.TTL,
+		CriticalOptions: cBundle.CriticalOptions,
+		Extensions:      cBundle.Extensions,
+		RequestedAt:     now,
+		ExpiresAt:       now.Add(approvalTTL),
+	}
+	if err := putSigningRequest(ctx, req.Storage, signingRequest); err != nil {
+		return nil, err
+	}
+
+	metadata := role.observationMetadata(roleName)
+	metadata["request_id"] = id
+	metadata["ticket_id"] = signingRequest.TicketID
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHSigningRequestCreate, metadata)
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"request_id": id,
+			"status":     signingRequest.Status,
+			"expiration": signingRequest.ExpiresAt.Format(time.RFC3339),
+		},
+	}
+	if addExtTemplatingWarning {
+		resp.AddWarning("default_extension templating enabled with at least one extension requiring identity templating. However, this request lacked identity entity information, causing one or more extensions to be skipped from the generated certificate.")
+	}
+	return resp, nil
+}
+
+func (b *backend) pathSigningRequestList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	ids, err := req.Storage.List(ctx, signingRequestsStoragePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	keyInfo := make(map[string]interface{}, len(ids))
+	for _, id := range ids {
+		signingRequest, err := getSigningRequest(ctx, req.Storage, id)
+		if err != nil {
+			return nil, err
+		}
+		if signingRequest == nil {
+			continue
+		}
+		keyInfo[id] = map[string]interface{}{
+			"role":           signingRequest.Role,
+			"status":         signingRequest.currentStatus(now),
+			"requester_name": signingRequest.RequesterName,
+			"ticket_id":      signingRequest.TicketID,
+		}
+	}
+
+	return logical.ListResponseWithInfo(ids, keyInfo), nil
+}
+
+func (b *backend) pathSigningRequestRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	signingRequest, err := getSigningRequest(ctx, req.Storage, d.Get("request_id").(string))
+	if err != nil {
+		return nil, err
+	}
+	if signingRequest == nil {
+		return nil, nil
+	}
+
+	data := map[string]interface{}{
+		"request_id":       signingRequest.ID,
+		"role":             signingRequest.Role,
+		"status":           signingRequest.currentStatus(time.Now()),
+		"requester_name":   signingRequest.RequesterName,
+		"ticket_id":        signingRequest.TicketID,
+		"justification":    signingRequest.Justification,
+		"public_key":       signingRequest.PublicKey,
+		"key_id":           signingRequest.KeyID,
+		"valid_principals": signingRequest.ValidPrincipals,
+		"ttl":              int64(signingRequest.TTL.Seconds()),
+		"requested_at":     signingRequest.RequestedAt.Format(time.RFC3339),
+		"expiration":       signingRequest.ExpiresAt.Format(time.RFC3339),
+	}
+	if signingRequest.Status != signingRequestPending {
+		data["reviewer_name"] = signingRequest.ReviewerName
+		data["reviewed_at"] = signingRequest.ReviewedAt.Format(time.RFC3339)
+	}
+	switch signingRequest.Status {
+	case signingRequestApproved:
+		data["serial_number"] = signingRequest.SerialNumber
+		data["signed_key"] = signingRequest.SignedKey
+	case signingRequestDenied:
+		data["denial_reason"] = signingRequest.DenialReason
+	}
+
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+func (b *backend) pathSigningRequestApprove(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.signingRequestsLock.Lock()
+	defer b.signingRequestsLock.Unlock()
+
+	signingRequest, errResp, err := b.getReviewableSigningRequest(ctx, req, d.Get("request_id").(string))
+	if err != nil {
+		return nil, err
+	}
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	// The certificate is signed against the role as it is now, so that a
+	// role that was deleted or changed since the request can't be used
+	role, err := b.getRole(ctx, req.Storage, signingRequest.Role)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("role %q of the signing request no longer exists", signingRequest.Role), nil
+	}
+	if role.KeyType != KeyTypeCA {
+		return logical.ErrorResponse("role %q of the signing request is no longer a CA role", signingRequest.Role), nil
+	}
+
+	publicKey, err := parsePublicSSHKey(signingRequest.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing public key of signing request: %w", err)
+	}
+	if err := b.checkSigningRequestAllowed(signingRequest, publicKey, role); err != nil {
+		return logical.ErrorResponse("signing request %q is no longer allowed by role %q: %s", signingRequest.ID, signingRequest.Role, err), nil
+	}
+
+	cBundle := &creationBundle{
+		KeyID:           signingRequest.KeyID,
+		PublicKey:       publicKey,
+		ValidPrincipals: signingRequest.ValidPrincipals,
+		TTL:             signingRequest.TTL,
+		CertificateType: signingRequest.CertificateType,
+		Role:            role,
+		CriticalOptions: signingRequest.CriticalOptions,
+		Extensions:      signingRequest.Extensions,
+	}
+	response, certMetadata, err := b.signCreationBundle(ctx, req.Storage, cBundle, signingRequest.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	signingRequest.Status = signingRequestApproved
+	signingRequest.Reviewer, _ = requestIdentity(req)
+	signingRequest.ReviewerName = req.DisplayName
+	signingRequest.ReviewedAt = now
+	signingRequest.SerialNumber = response.Data["serial_number"].(string)
+	signingRequest.SignedKey = response.Data["signed_key"].(string)
+	signingRequest.ExpiresAt = now.Add(signingRequest.TTL)
+	if err := putSigningRequest(ctx, req.Storage, signingRequest); err != nil {
+		return nil, err
+	}
+
+	metadata := role.observationMetadata(signingRequest.Role)
+	maps.Copy(metadata, certMetadata)
+	metadata["request_id"] = signingRequest.ID
+	metadata["ticket_id"] = signingRequest.TicketID
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHSigningRequestApprove, metadata)
+
+	response.Data["request_id"] = signingRequest.ID
+	return response, nil
+}
+
+// checkSigningRequestAllowed checks the public key, certificate type,
+// principals, TTL, critical options and extensions of a signing request
+// against the current configuration of its role, which may have been narrowed
+// since the request.
+func (b *backend) checkSigningRequestAllowed(signingRequest *sshSigningRequest, publicKey ssh.PublicKey, role *sshRole) error {
+	if err := b.validateSignedKeyRequirements(publicKey, role); err != nil {
+		return err
+	}
+
+	// Templated principals are rendered for the requester, not the reviewer
+	req := &logical.Request{EntityID: strings.TrimPrefix(signingRequest.Requester, signingRequestIdentityPrefix)}
+
+	var allowedPrincipals string
+	var enableTemplating bool
+	var validatePrincipal func([]string, string) bool
+	switch signingRequest.CertificateType {
+	case ssh.UserCert:
+		if !role.AllowUserCertificates {
+			return errors.New("cert_type 'user' is not allowed by role")
+		}
+		allowedPrincipals, enableTemplating, validatePrincipal = role.AllowedUsers, role.AllowedUsersTemplate, strutil.StrListContains
+	case ssh.HostCert:
+		if !role.AllowHostCertificates {
+			return errors.New("cert_type 'host' is not allowed by role")
+		}
+		allowedPrincipals, enableTemplating, validatePrincipal = role.AllowedDomains, role.AllowedDomainsTemplate, validateValidPrincipalForHosts(role)
+	default:
+		return errors.New("cert_type must be either 'user' or 'host'")
+	}
+
+	if len(signingRequest.ValidPrincipals) == 0 {
+		if !role.AllowEmptyPrincipals {
+			return errors.New("empty valid principals not allowed by role")
+		}
+	} else if allowedPrincipals != "*" {
+		if enableTemplating {
+			rendered, err := b.renderPrincipal(allowedPrincipals, req)
+			if err != nil {
+				return err
+			}
+			allowedPrincipals = rendered
+		}
+		allowed := strutil.RemoveDuplicates(strutil.ParseStringSlice(allowedPrincipals, ","), false)
+		for _, principal := range signingRequest.ValidPrincipals {
+			if !validatePrincipal(allowed, principal) {
+				return fmt.Errorf("%v is not a valid value for valid_principals", principal)
+			}
+		}
+	}
+
+	maxTTL, err := parseutil.ParseDurationSecond(role.MaxTTL)
+	if err != nil {
+		return err
+	}
+	if maxTTL == 0 {
+		maxTTL = b.System().MaxLeaseTTL()
+	}
+	if signingRequest.TTL > maxTTL {
+		return fmt.Errorf("ttl is larger than maximum allowed %d", maxTTL/time.Second)
+	}
+
+	if role.AllowedCriticalOptions != "" {
+		allowedCriticalOptions := strings.Split(role.AllowedCriticalOptions, ",")
+		for option := range signingRequest.CriticalOptions {
+			if _, ok := role.DefaultCriticalOptions[option]; ok {
+				continue
+			}
+			if !strutil.StrListContains(allowedCriticalOptions, option) {
+				return fmt.Errorf("critical option %q is not on allowed list", option)
+			}
+		}
+	}
+
+	if role.AllowedExtensions != "*" {
+		allowedExtensions := strings.Split(role.AllowedExtensions, ",")
+		for extension := range signingRequest.Extensions {
+			if _, ok := role.DefaultExtensions[extension]; ok {
+				continue
+			}
+			if extension == role.TicketIDExtension || extension == role.JustificationExtension {
+				continue
+			}
+			if !strutil.StrListContains(allowedExtensions, extension) {
+				return fmt.Errorf("extension %q is not on allowed list", extension)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *backend) pathSigningRequestDeny(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.signingRequestsLock.Lock()
+	defer b.signingRequestsLock.Unlock()
+
+	signingRequest, errResp, err := b.getReviewableSigningRequest(ctx, req, d.Get("request_id").(string))
+	if err != nil {
+		return nil, err
+	}
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	signingRequest.Status = signingRequestDenied
+	signingRequest.Reviewer, _ = requestIdentity(req)
+	signingRequest.ReviewerName = req.DisplayName
+	signingRequest.ReviewedAt = time.Now().UTC()
+	signingRequest.DenialReason = d.Get("reason").(string)
+	if err := putSigningRequest(ctx, req.Storage, signingRequest); err != nil {
+		return nil, err
+	}
+
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHSigningRequestDeny, map[string]interface{}{
+		"request_id": signingRequest.ID,
+		"role_name":  signingRequest.Role,
+		"ticket_id":  signingRequest.TicketID,
+	})
+
+	return nil, nil
+}
+
+// getReviewableSigningRequest returns the signing request if it is pending
+// and the caller has an entity other than the one that made it.
+func (b *backend) getReviewableSigningRequest(ctx context.Context, req *logical.Request, id string) (*sshSigningRequest, *logical.Response, error) {
+	reviewer, errResp := requestIdentity(req)
+	if errResp != nil {
+		return nil, errResp, nil
+	}
+
+	signingRequest, err := getSigningRequest(ctx, req.Storage, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if signingRequest == nil {
+		return nil, logical.ErrorResponse("signing request %q not found", id), nil
+	}
+	if status := signingRequest.currentStatus(time.Now()); status != signingRequestPending {
+		return nil, logical.ErrorResponse("signing request %q is %s", id, status), nil
+	}
+	if signingRequest.Requester == reviewer {
+		return nil, logical.ErrorResponse("signing requests can't be reviewed by the identity that made them"), nil
+	}
+	return signingRequest, nil, nil
+}
+
+func getSigningRequest(ctx context.Context, s logical.Storage, id string) (*sshSigningRequest, error) {
+	entry, err := s.Get(ctx, signingRequestsStoragePrefix+id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result sshSigningRequest
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func putSigningRequest(ctx context.Context, s logical.Storage, signingRequest *sshSigningRequest) error {
+	entry, err := logical.StorageEntryJSON(signingRequestsStoragePrefix+signingRequest.ID, signingRequest)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error storing signing request: %w", err)
+	}
+	return nil
+}
+
+const pathListSigningRequestsHelpSyn = `List the signing requests awaiting or given approval.`
+
+const pathListSigningRequestsHelpDesc = `
+This path lists the IDs of the requests to sign certificates against roles that
+require approval, with their role, status, requester and ticket ID.
+`
+
+const pathSigningRequestHelpSyn = `Read a signing request.`
+
+const pathSigningRequestHelpDesc = `
+This path returns a signing request and its status: "pending", "approved",
+"denied" or "expired". Once approved, it includes the signed certificate, so
+the requester can poll it; request response wrapping on the read to receive
+the certificate wrapped.
+`
+
+const pathApproveSigningRequestHelpSyn = `Approve a pending signing request.`
+
+const pathApproveSigningRequestHelpDesc = `
+This path signs the certificate of a pending signing request, against the
+current configuration of its role, and returns it. The principals, TTL,
+critical options and extensions of the request are checked again against the
+role, so a request made before the role was narrowed can't be approved. A
+request can't be approved by the entity that made it, and tokens without an
+entity can't review requests.
+`
+
+const pathDenySigningRequestHelpSyn = `Deny a pending signing request.`
+
+const pathDenySigningRequestHelpDesc = `
+This path denies a pending signing request, with an optional reason returned to
+the requester. A request can't be denied by the entity that made it.
+`
@@ -0,0 +1,215 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSH_SigningRequests(t *testing.T) {
+	ctx := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Backend(config)
+	require.NoError(t, err)
+	require.NoError(t, b.Setup(ctx, config))
+
+	request := func(entityID string, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:   op,
+			Path:        path,
+			Storage:     config.StorageView,
+			Data:        data,
+			EntityID:    entityID,
+			DisplayName: entityID,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	parseCert := func(signedKey string) *ssh.Certificate {
+		t.Helper()
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(signedKey))
+		require.NoError(t, err)
+		return key.(*ssh.Certificate)
+	}
+
+	resp := request("", logical.UpdateOperation, "config/ca", map[string]interface{}{
+		"public_key":  testCAPublicKey,
+		"private_key": testCAPrivateKey,
+	})
+	require.False(t, resp != nil && resp.IsError(), resp)
+	writePrivilegedRole := func(allowedUsers, ttl, maxTTL string) {
+		t.Helper()
+		resp := request("", logical.UpdateOperation, "roles/privileged", map[string]interface{}{
+			"key_type":                "ca",
+			"allow_user_certificates": true,
+			"allowed_users":           allowedUsers,
+			"ttl":                     ttl,
+			"max_ttl":                 maxTTL,
+			"require_approval":        true,
+			"ticket_id_extension":     "ticket-id@example.com",
+			"justification_extension": "justification@example.com",
+		})
+		require.False(t, resp != nil && resp.IsError(), resp)
+	}
+	writePrivilegedRole("*", "1h", "0")
+	sign := func(data map[string]interface{}) *logical.Response {
+		t.Helper()
+		data["public_key"] = publicKey2
+		data["valid_principals"] = "root"
+		return request("alice", logical.UpdateOperation, "sign/privileged", data)
+	}
+
+	// A justification is required, and key pairs can't be issued
+	resp = sign(map[string]interface{}{})
+	require.True(t, resp.IsError())
+	resp = request("alice", logical.UpdateOperation, "issue/privileged", map[string]interface{}{
+		"justification": "outage",
+	})
+	require.True(t, resp.IsError())
+
+	resp = sign(map[string]interface{}{
+		"ticket_id":     "CHG-1",
+		"justification": "outage",
+	})
+	require.False(t, resp.IsError(), resp)
+	require.Equal(t, signingRequestPending, resp.Data["status"])
+	require.NotContains(t, resp.Data, "signed_key")
+	requestID := resp.Data["request_id"].(string)
+
+	resp = request("alice", logical.ReadOperation, "signing-request/"+requestID, nil)
+	require.Equal(t, signingRequestPending, resp.Data["status"])
+	require.Equal(t, "outage", resp.Data["justification"])
+	resp = request("bob", logical.ListOperation, "signing-requests/", nil)
+	require.Equal(t, []string{requestID}, resp.Data["keys"])
+
+	// Requesters can't approve their own requests
+	resp = request("alice", logical.UpdateOperation, "signing-request/"+requestID+"/approve", nil)
+	require.True(t, resp.IsError())
+
+	resp = request("bob", logical.UpdateOperation, "signing-request/"+requestID+"/approve", nil)
+	require.False(t, resp.IsError(), resp)
+	cert := parseCert(resp.Data["signed_key"].(string))
+	require.Equal(t, []string{"root"}, cert.ValidPrincipals)
+	require.Equal(t, "CHG-1", cert.Extensions["ticket-id@example.com"])
+	require.Equal(t, "outage", cert.Extensions["justification@example.com"])
+	signedKey := resp.Data["signed_key"]
+
+	// A request is signed at most once
+	resp = request("carol", logical.UpdateOperation, "signing-request/"+requestID+"/approve", nil)
+	require.True(t, resp.IsError())
+
+	// The requester polls for the certificate
+	resp = request("alice", logical.ReadOperation, "signing-request/"+requestID, nil)
+	require.Equal(t, signingRequestApproved, resp.Data["status"])
+	require.Equal(t, "bob", resp.Data["reviewer_name"])
+	require.Equal(t, signedKey, resp.Data["signed_key"])
+	resp = request("alice", logical.ReadOperation, "cert/"+resp.Data["serial_number"].(string), nil)
+	require.Equal(t, "privileged", resp.Data["role"])
+
+	resp = sign(map[string]interface{}{
+		"justification": "curiosity",
+	})
+	deniedID := resp.Data["request_id"].(string)
+	resp = request("bob", logical.UpdateOperation, "signing-request/"+deniedID+"/deny", map[string]interface{}{
+		"reason": "no ticket",
+	})
+	require.Nil(t, resp)
+	resp = request("alice", logical.ReadOperation, "signing-request/"+deniedID, nil)
+	require.Equal(t, signingRequestDenied, resp.Data["status"])
+	require.Equal(t, "no ticket", resp.Data["denial_reason"])
+	require.NotContains(t, resp.Data, "signed_key")
+
+	// Tokens without an entity can neither make nor review requests
+	resp = request("", logical.UpdateOperation, "sign/privileged", map[string]interface{}{
+		"public_key":       publicKey2,
+		"valid_principals": "root",
+		"justification":    "no entity",
+	})
+	require.True(t, resp.IsError())
+	resp = sign(map[string]interface{}{
+		"justification": "narrowed",
+	})
+	narrowedID := resp.Data["request_id"].(string)
+	resp = request("", logical.UpdateOperation, "signing-request/"+narrowedID+"/approve", nil)
+	require.True(t, resp.IsError())
+
+	// Requests are checked again against the role as it is on approval
+	writePrivilegedRole("admin", "1h", "0")
+	resp = request("bob", logical.UpdateOperation, "signing-request/"+narrowedID+"/approve", nil)
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "root is not a valid value for valid_principals")
+	writePrivilegedRole("*", "30m", "30m")
+	resp = request("bob", logical.UpdateOperation, "signing-request/"+narrowedID+"/approve", nil)
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "ttl is larger than maximum allowed")
+	resp = request("", logical.UpdateOperation, "roles/privileged", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"ttl":                     "1h",
+		"require_approval":        true,
+		"allowed_user_key_lengths": map[string]interface{}{
+			"rsa": 4096,
+		},
+	})
+	require.False(t, resp != nil && resp.IsError(), resp)
+	resp = request("bob", logical.UpdateOperation, "signing-request/"+narrowedID+"/approve", nil)
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "key is of an invalid size")
+	writePrivilegedRole("*", "1h", "0")
+	resp = request("bob", logical.UpdateOperation, "signing-request/"+narrowedID+"/approve", nil)
+	require.False(t, resp.IsError(), resp)
+
+	// Requests that aren't reviewed in time expire
+	resp = sign(map[string]interface{}{
+		"justification": "later",
+	})
+	expiredID := resp.Data["request_id"].(string)
+	signingRequest, err := getSigningRequest(ctx, config.StorageView, expiredID)
+	require.NoError(t, err)
+	signingRequest.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, putSigningRequest(ctx, config.StorageView, signingRequest))
+	resp = request("alice", logical.ReadOperation, "signing-request/"+expiredID, nil)
+	require.Equal(t, signingRequestExpired, resp.Data["status"])
+	resp = request("bob", logical.UpdateOperation, "signing-request/"+expiredID+"/approve", nil)
+	require.True(t, resp.IsError())
+
+	resp = request("", logical.UpdateOperation, "tidy/certificates", map[string]interface{}{
+		"safety_buffer": 0,
+	})
+	require.Contains(t, resp.Data["message"], "and 1 expired signing requests")
+	resp = request("", logical.ReadOperation, "signing-request/"+expiredID, nil)
+	require.Nil(t, resp)
+
+	// Roles without approval embed the session metadata directly, and only
+	// accept what they embed
+	request("", logical.UpdateOperation, "roles/tracked", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"ticket_id_extension":     "ticket-id@example.com",
+	})
+	resp = request("alice", logical.UpdateOperation, "sign/tracked", map[string]interface{}{
+		"public_key":       publicKey2,
+		"valid_principals": "root",
+		"ticket_id":        "INC-2",
+	})
+	require.False(t, resp.IsError(), resp)
+	cert = parseCert(resp.Data["signed_key"].(string))
+	require.Equal(t, "INC-2", cert.Extensions["ticket-id@example.com"])
+	resp = request("alice", logical.UpdateOperation, "sign/tracked", map[string]interface{}{
+		"public_key":       publicKey2,
+		"valid_principals": "root",
+		"justification":    "outage",
+	})
+	require.True(t, resp.IsError())
+}
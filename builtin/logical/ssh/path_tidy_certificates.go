@@ -0,0 +1,126 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathTidyCertificates(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "tidy/certificates",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationVerb:   "tidy",
+			OperationSuffix: "certificates",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"safety_buffer": {
+				Type:        framework.TypeDurationSecond,
+				Description: "The amount of time that must pass after expiration before a record is removed. Defaults to 72h.",
+				Default:     int((72 * time.Hour).Seconds()),
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathTidyCertificatesWrite,
+		},
+
+		HelpSynopsis:    pathTidyCertificatesHelpSyn,
+		HelpDescription: pathTidyCertificatesHelpDesc,
+	}
+}
+
+func (b *backend) pathTidyCertificatesWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	safetyBuffer := time.Duration(d.Get("safety_buffer").(int)) * time.Second
+	if safetyBuffer < 0 {
+		return logical.ErrorResponse("safety_buffer must be non-negative"), nil
+	}
+	cutoff := time.Now().Add(-safetyBuffer)
+
+	b.revocationLock.Lock()
+	defer b.revocationLock.Unlock()
+
+	certs, err := listCertificateEntries(ctx, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list certificates for tidying: %w", err)
+	}
+	var certsDeleted int
+	for serial, cert := range certs {
+		if cert.ValidBefore.After(cutoff) {
+			continue
+		}
+		if err := req.Storage.Delete(ctx, certsStoragePrefix+serial); err != nil {
+			return nil, fmt.Errorf("unable to delete certificate %q: %w", serial, err)
+		}
+		certsDeleted++
+	}
+
+	revocations, err := listRevocationEntries(ctx, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list revocations for tidying: %w", err)
+	}
+	var revocationsDeleted int
+	for path, revocation := range revocations {
+		if revocation.Expiration.After(cutoff) {
+			continue
+		}
+		if err := req.Storage.Delete(ctx, path); err != nil {
+			return nil, fmt.Errorf("unable to delete revocation %q: %w", path, err)
+		}
+		revocationsDeleted++
+	}
+	if revocationsDeleted > 0 {
+		if err := incrementKRLVersion(ctx, req.Storage); err != nil {
+			return nil, err
+		}
+	}
+
+	requestIDs, err := req.Storage.List(ctx, signingRequestsStoragePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list signing requests for tidying: %w", err)
+	}
+	var requestsDeleted int
+	for _, id := range requestIDs {
+		signingRequest, err := getSigningRequest(ctx, req.Storage, id)
+		if err != nil {
+			return nil, err
+		}
+		if signingRequest == nil || signingRequest.ExpiresAt.After(cutoff) {
+			continue
+		}
+		if err := req.Storage.Delete(ctx, signingRequestsStoragePrefix+id); err != nil {
+			return nil, fmt.Errorf("unable to delete signing request %q: %w", id, err)
+		}
+		requestsDeleted++
+	}
+
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeSSHTidyCertificates, map[string]interface{}{
+		"certificates_deleted": certsDeleted,
+		"revocations_deleted":  revocationsDeleted,
+		"requests_deleted":     requestsDeleted,
+	})
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"message": fmt.Sprintf("Removed %v expired certificate records, %v expired revocations and %v expired signing requests.", certsDeleted, revocationsDeleted, requestsDeleted),
+		},
+	}, nil
+}
+
+const pathTidyCertificatesHelpSyn = `Remove the records of expired certificates and revocations.`
+
+const pathTidyCertificatesHelpDesc = `
+This path removes the issuance records of certificates, and the revocations
+listed in the KRL, once the certificates they cover have been expired for
+longer than "safety_buffer". Signing requests are removed once they have gone
+unreviewed, or their certificate has been expired, for as long.
+`
@@ -19,6 +19,7 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/mitchellh/mapstructure"
 	otplib "github.com/pquerna/otp"
+	hotplib "github.com/pquerna/otp/hotp"
 	totplib "github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/require"
 )
@@ -1144,6 +1145,161 @@ func TestBackend_generatedKeyExportedFalse(t *testing.T) {
 	})
 }
 
+func TestBackend_hotpKey(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Factory(context.Background(), config)
+	require.NoError(t, err)
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   config.StorageView,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	key, err := createKey()
+	require.NoError(t, err)
+	hotpCode := func(counter uint64) string {
+		t.Helper()
+		code, err := hotplib.GenerateCode(key, counter)
+		require.NoError(t, err)
+		return code
+	}
+	validate := func(code string) bool {
+		t.Helper()
+		resp := request(logical.UpdateOperation, "code/test", map[string]interface{}{
+			"code": code,
+		})
+		require.False(t, resp.IsError(), resp)
+		return resp.Data["valid"].(bool)
+	}
+
+	resp := request(logical.UpdateOperation, "keys/test", map[string]interface{}{
+		"key":        key,
+		"type":       "hotp",
+		"counter":    5,
+		"look_ahead": 2,
+	})
+	require.False(t, resp != nil && resp.IsError(), resp)
+	resp = request(logical.ReadOperation, "keys/test", nil)
+	require.Equal(t, "hotp", resp.Data["type"])
+	require.Equal(t, uint64(5), resp.Data["counter"])
+
+	resp = request(logical.ReadOperation, "code/test", nil)
+	require.Equal(t, hotpCode(5), resp.Data["code"])
+
+	// Accepted codes move the counter past them, so they can't be replayed
+	require.True(t, validate(hotpCode(5)))
+	require.False(t, validate(hotpCode(5)))
+
+	// Codes are accepted up to look_ahead counters past the current one
+	require.True(t, validate(hotpCode(8)))
+	require.False(t, validate(hotpCode(7)))
+	require.False(t, validate(hotpCode(20)))
+
+	// Resynchronizing finds two consecutive codes further ahead
+	resp = request(logical.UpdateOperation, "keys/test/resync", map[string]interface{}{
+		"code":      hotpCode(30),
+		"next_code": hotpCode(32),
+	})
+	require.True(t, resp.IsError())
+	resp = request(logical.UpdateOperation, "keys/test/resync", map[string]interface{}{
+		"code":      hotpCode(30),
+		"next_code": hotpCode(31),
+	})
+	require.False(t, resp.IsError(), resp)
+	require.Equal(t, uint64(32), resp.Data["counter"])
+	require.True(t, validate(hotpCode(32)))
+
+	// The counter values searched are bounded
+	resp = request(logical.UpdateOperation, "keys/test/resync", map[string]interface{}{
+		"code":      hotpCode(40),
+		"next_code": hotpCode(41),
+		"window":    maxResyncWindow + 1,
+	})
+	require.True(t, resp.IsError())
+	resp = request(logical.UpdateOperation, "keys/bounded", map[string]interface{}{
+		"key":        key,
+		"type":       "hotp",
+		"look_ahead": maxLookAhead + 1,
+	})
+	require.True(t, resp.IsError())
+
+	// Generated keys carry their counter in their url
+	resp = request(logical.UpdateOperation, "keys/generated", map[string]interface{}{
+		"generate":     true,
+		"type":         "hotp",
+		"counter":      3,
+		"issuer":       "Vault",
+		"account_name": "Test",
+		"qr_size":      0,
+	})
+	require.False(t, resp.IsError(), resp)
+	generated, err := otplib.NewKeyFromURL(resp.Data["url"].(string))
+	require.NoError(t, err)
+	require.Equal(t, "hotp", generated.Type())
+	require.Contains(t, resp.Data["url"], "counter=3")
+
+	resp = request(logical.UpdateOperation, "keys/invalid", map[string]interface{}{
+		"key":  key,
+		"type": "motp",
+	})
+	require.True(t, resp.IsError())
+}
+
+func TestBackend_codeReplayAcrossNodes(t *testing.T) {
+	// Two backends sharing storage stand in for the nodes of a cluster
+	storage := &logical.InmemStorage{}
+	var nodes []logical.Backend
+	for i := 0; i < 2; i++ {
+		config := logical.TestBackendConfig()
+		config.StorageView = storage
+		b, err := Factory(context.Background(), config)
+		require.NoError(t, err)
+		nodes = append(nodes, b)
+	}
+	validate := func(b logical.Backend, code string) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "code/test",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"code": code,
+			},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	key, err := createKey()
+	require.NoError(t, err)
+	_, err = nodes[0].HandleRequest(namespace.RootContext(nil), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "keys/test",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"key": key,
+		},
+	})
+	require.NoError(t, err)
+
+	code, err := generateCode(key, 30, otplib.DigitsSix, otplib.AlgorithmSHA1)
+	require.NoError(t, err)
+	resp := validate(nodes[0], code)
+	require.False(t, resp.IsError(), resp)
+	require.Equal(t, true, resp.Data["valid"])
+
+	resp = validate(nodes[1], code)
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "code already used")
+}
+
 func testAccStepCreateKey(t *testing.T, name string, keyData map[string]interface{}, expectFail bool, obsRecorder *observations.TestObservationRecorder) logicaltest.TestStep {
 	return logicaltest.TestStep{
 		Operation: logical.UpdateOperation,
@@ -7,6 +7,7 @@ const (
 	ObservationTypeTOTPKeyRead      = "totp/key/read"
 	ObservationTypeTOTPKeyCreate    = "totp/key/create"
 	ObservationTypeTOTPKeyDelete    = "totp/key/delete"
+	ObservationTypeTOTPKeyResync    = "totp/key/resync"
 	ObservationTypeTOTPCodeGenerate = "totp/code/generate"
 	ObservationTypeTOTPCodeValidate = "totp/code/validate"
 )
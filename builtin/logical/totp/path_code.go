@@ -6,12 +6,15 @@ package totp
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	otplib "github.com/pquerna/otp"
+	hotplib "github.com/pquerna/otp/hotp"
 	totplib "github.com/pquerna/otp/totp"
 )
 
@@ -67,12 +70,21 @@ func (b *backend) pathReadCode(ctx context.Context, req *logical.Request, data *
 		return logical.ErrorResponse(fmt.Sprintf("unknown key: %s", name)), nil
 	}
 
-	// Generate password using totp library
-	totpToken, err := totplib.GenerateCodeCustom(key.Key, time.Now(), totplib.ValidateOpts{
-		Period:    key.Period,
-		Digits:    key.Digits,
-		Algorithm: key.Algorithm,
-	})
+	var totpToken string
+	if key.keyType() == keyTypeHOTP {
+		// The counter only advances once a code is validated
+		totpToken, err = hotplib.GenerateCodeCustom(key.Key, key.Counter, hotplib.ValidateOpts{
+			Digits:    key.Digits,
+			Algorithm: key.Algorithm,
+		})
+	} else {
+		// Generate password using totp library
+		totpToken, err = totplib.GenerateCodeCustom(key.Key, time.Now(), totplib.ValidateOpts{
+			Period:    key.Period,
+			Digits:    key.Digits,
+			Algorithm: key.Algorithm,
+		})
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +110,10 @@ func (b *backend) pathValidateCode(ctx context.Context, req *logical.Request, da
 		return logical.ErrorResponse("the code value is required"), nil
 	}
 
+	lock := locksutil.LockForKey(b.keyLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Get the key's stored values
 	key, err := b.Key(ctx, req.Storage, name)
 	if err != nil {
@@ -107,6 +123,10 @@ func (b *backend) pathValidateCode(ctx context.Context, req *logical.Request, da
 		return logical.ErrorResponse(fmt.Sprintf("unknown key: %s", name)), nil
 	}
 
+	if key.keyType() == keyTypeHOTP {
+		return b.validateHOTPCode(ctx, req, name, key, code)
+	}
+
 	usedName := fmt.Sprintf("%s_%s", name, code)
 
 	_, ok := b.usedCodes.Get(usedName)
@@ -114,16 +134,25 @@ func (b *backend) pathValidateCode(ctx context.Context, req *logical.Request, da
 		return logical.ErrorResponse("code already used; wait until the next time period"), nil
 	}
 
-	valid, err := totplib.ValidateCustom(code, key.Key, time.Now(), totplib.ValidateOpts{
-		Period:    key.Period,
-		Skew:      key.Skew,
-		Digits:    key.Digits,
-		Algorithm: key.Algorithm,
-	})
-	if err != nil && err != otplib.ErrValidateInputInvalidLength {
+	now := time.Now()
+	valid, timeStep, err := validateTOTPCode(code, key, now)
+	if err != nil {
 		return logical.ErrorResponse("an error occurred while validating the code"), err
 	}
 
+	if valid {
+		// The time steps of accepted codes are kept in storage rather than
+		// the cache, so that a code can't be replayed against another node;
+		// on a standby, the write forwards the request to the active node
+		used, err := b.recordTimeStep(ctx, req.Storage, name, key, timeStep, now)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			return logical.ErrorResponse("code already used; wait until the next time period"), nil
+		}
+	}
+
 	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeTOTPCodeValidate, map[string]interface{}{
 		"key_name": name,
 		"valid":    valid,
@@ -146,6 +175,136 @@ func (b *backend) pathValidateCode(ctx context.Context, req *logical.Request, da
 	}, nil
 }
 
+// validateHOTPCode accepts a code generated from the current counter of the
+// key or one of the look_ahead counters past it, and moves the counter past
+// the code, so that neither it nor any earlier code can be used again.
+func (b *backend) validateHOTPCode(ctx context.Context, req *logical.Request, name string, key *keyEntry, code string) (*logical.Response, error) {
+	counter, valid, err := findHOTPCounter(key, key.Counter, uint64(key.LookAhead), code, "")
+	if err != nil {
+		return logical.ErrorResponse("an error occurred while validating the code"), err
+	}
+
+	if valid {
+		key.Counter = counter + 1
+		if err := b.putKey(ctx, req.Storage, name, key); err != nil {
+			return nil, err
+		}
+	}
+
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeTOTPCodeValidate, map[string]interface{}{
+		"key_name": name,
+		"valid":    valid,
+	})
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"valid": valid,
+		},
+	}, nil
+}
+
+// findHOTPCounter returns the first counter from start to start+window for
+// which the key generates code and, if nextCode is set, nextCode for the
+// following counter.
+func findHOTPCounter(key *keyEntry, start, window uint64, code, nextCode string) (uint64, bool, error) {
+	opts := hotplib.ValidateOpts{
+		Digits:    key.Digits,
+		Algorithm: key.Algorithm,
+	}
+
+	for counter := start; counter <= start+window; counter++ {
+		valid, err := hotplib.ValidateCustom(code, counter, key.Key, opts)
+		if err == otplib.ErrValidateInputInvalidLength {
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		if !valid {
+			continue
+		}
+		if nextCode != "" {
+			valid, err = hotplib.ValidateCustom(nextCode, counter+1, key.Key, opts)
+			if err != nil && err != otplib.ErrValidateInputInvalidLength {
+				return 0, false, err
+			}
+			if !valid {
+				continue
+			}
+		}
+		return counter, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// validateTOTPCode returns whether the code is valid for one of the time steps
+// within the skew of now, and which.
+func validateTOTPCode(code string, key *keyEntry, now time.Time) (bool, uint64, error) {
+	opts := hotplib.ValidateOpts{
+		Digits:    key.Digits,
+		Algorithm: key.Algorithm,
+	}
+
+	current := uint64(now.Unix()) / uint64(key.Period)
+	for step := current - uint64(key.Skew); step <= current+uint64(key.Skew); step++ {
+		valid, err := hotplib.ValidateCustom(code, step, key.Key, opts)
+		if err == otplib.ErrValidateInputInvalidLength {
+			return false, 0, nil
+		}
+		if err != nil {
+			return false, 0, err
+		}
+		if valid {
+			return true, step, nil
+		}
+	}
+
+	return false, 0, nil
+}
+
+const usedTimeStepsPrefix = "used/"
+
+// usedTimeSteps are the time steps for which a code of a TOTP key has been
+// accepted, while they are within the skew of the current time step.
+type usedTimeSteps struct {
+	TimeSteps []uint64 `json:"time_steps"`
+}
+
+// recordTimeStep marks the time step of an accepted code as used, returning
+// whether it already was.
+func (b *backend) recordTimeStep(ctx context.Context, s logical.Storage, name string, key *keyEntry, timeStep uint64, now time.Time) (bool, error) {
+	var used usedTimeSteps
+	entry, err := s.Get(ctx, usedTimeStepsPrefix+name)
+	if err != nil {
+		return false, err
+	}
+	if entry != nil {
+		if err := entry.DecodeJSON(&used); err != nil {
+			return false, err
+		}
+	}
+	if slices.Contains(used.TimeSteps, timeStep) {
+		return true, nil
+	}
+
+	// Drop the time steps whose codes can no longer be accepted
+	oldest := uint64(now.Unix())/uint64(key.Period) - uint64(key.Skew)
+	used.TimeSteps = slices.DeleteFunc(used.TimeSteps, func(step uint64) bool {
+		return step < oldest
+	})
+	used.TimeSteps = append(used.TimeSteps, timeStep)
+
+	entry, err = logical.StorageEntryJSON(usedTimeStepsPrefix+name, &used)
+	if err != nil {
+		return false, err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
 const pathCodeHelpSyn = `
 Request time-based one-time use password or validate a password for a certain key .
 `
@@ -153,4 +312,7 @@ Request time-based one-time use password or validate a password for a certain ke
 const pathCodeHelpDesc = `
 This path generates and validates time-based one-time use passwords for a certain key. 
 
+For HOTP keys, codes are generated from the current counter of the key, which
+moves past a code once it is validated. A validated code of either type of key
+is rejected if it is presented again, on any node of the cluster.
 `
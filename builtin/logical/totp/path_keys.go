@@ -15,8 +15,10 @@ import (
 	"strings"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	otplib "github.com/pquerna/otp"
+	hotplib "github.com/pquerna/otp/hotp"
 	totplib "github.com/pquerna/otp/totp"
 )
 
@@ -120,6 +122,24 @@ func pathKeys(b *backend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: `A TOTP url string containing all of the parameters for key setup. Only used if generate is false.`,
 			},
+
+			"type": {
+				Type:        framework.TypeString,
+				Default:     keyTypeTOTP,
+				Description: `The type of one-time passwords of the key: "totp" for time-based (RFC 6238) or "hotp" for counter-based (RFC 4226) passwords.`,
+			},
+
+			"counter": {
+				Type:        framework.TypeInt,
+				Default:     0,
+				Description: `The initial counter of a HOTP key. Only used if type is hotp.`,
+			},
+
+			"look_ahead": {
+				Type:        framework.TypeInt,
+				Default:     10,
+				Description: `The number of counter values past the current one that are accepted when validating a HOTP code, to allow for codes generated but never used. At most 100. Only used if type is hotp.`,
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -167,10 +187,18 @@ func (b *backend) Key(ctx context.Context, s logical.Storage, n string) (*keyEnt
 
 func (b *backend) pathKeyDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	name := data.Get("name").(string)
+
+	lock := locksutil.LockForKey(b.keyLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
 	err := req.Storage.Delete(ctx, "key/"+name)
 	if err != nil {
 		return nil, err
 	}
+	if err := req.Storage.Delete(ctx, usedTimeStepsPrefix+name); err != nil {
+		return nil, err
+	}
 
 	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeTOTPKeyDelete, map[string]interface{}{
 		"key_name": name,
@@ -194,6 +222,7 @@ func (b *backend) pathKeyRead(ctx context.Context, req *logical.Request, data *f
 
 	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeTOTPKeyRead, map[string]interface{}{
 		"key_name":  name,
+		"type":      key.keyType(),
 		"period":    key.Period,
 		"algorithm": algorithm,
 		"digits":    key.Digits,
@@ -201,15 +230,22 @@ func (b *backend) pathKeyRead(ctx context.Context, req *logical.Request, data *f
 	})
 
 	// Return values of key
-	return &logical.Response{
+	resp := &logical.Response{
 		Data: map[string]interface{}{
+			"type":         key.keyType(),
 			"issuer":       key.Issuer,
 			"account_name": key.AccountName,
 			"period":       key.Period,
 			"algorithm":    algorithm,
 			"digits":       key.Digits,
 		},
-	}, nil
+	}
+	if key.keyType() == keyTypeHOTP {
+		resp.Data["counter"] = key.Counter
+		resp.Data["look_ahead"] = key.LookAhead
+	}
+
+	return resp, nil
 }
 
 func (b *backend) pathKeyList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -235,6 +271,9 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 	qrSize := data.Get("qr_size").(int)
 	keySize := data.Get("key_size").(int)
 	inputURL := data.Get("url").(string)
+	keyType := data.Get("type").(string)
+	counter := data.Get("counter").(int)
+	lookAhead := data.Get("look_ahead").(int)
 
 	if generate {
 		if keyString != "" {
@@ -253,6 +292,11 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 			return logical.ErrorResponse("an error occurred while parsing url string"), err
 		}
 
+		// Read type
+		if urlObject.Host != "" {
+			keyType = urlObject.Host
+		}
+
 		// Set up query object
 		urlQuery := urlObject.Query()
 		path := strings.TrimPrefix(urlObject.Path, "/")
@@ -303,6 +347,22 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 		if algorithmQuery != "" {
 			algorithm = algorithmQuery
 		}
+
+		// Read counter
+		counterQuery := urlQuery.Get("counter")
+		if counterQuery != "" {
+			counterInt, err := strconv.Atoi(counterQuery)
+			if err != nil {
+				return logical.ErrorResponse("an error occurred while parsing counter value in url"), err
+			}
+			counter = counterInt
+		}
+	}
+
+	switch keyType {
+	case keyTypeTOTP, keyTypeHOTP:
+	default:
+		return logical.ErrorResponse("the type value must be totp or hotp"), nil
 	}
 
 	// Translate digits and algorithm to a format the totp library understands
@@ -349,6 +409,17 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 		return logical.ErrorResponse("the key_size value must be greater than zero"), nil
 	}
 
+	if counter < 0 {
+		return logical.ErrorResponse("the counter value must be greater than or equal to zero"), nil
+	}
+
+	if lookAhead < 0 {
+		return logical.ErrorResponse("the look_ahead value must be greater than or equal to zero"), nil
+	}
+	if lookAhead > maxLookAhead {
+		return logical.ErrorResponse(fmt.Sprintf("the look_ahead value must be less than or equal to %d", maxLookAhead)), nil
+	}
+
 	// Period, Skew and Key Size need to be unsigned ints
 	uintPeriod := uint(period)
 	uintSkew := uint(skew)
@@ -368,15 +439,31 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 		}
 
 		// Generate a new key
-		keyObject, err := totplib.Generate(totplib.GenerateOpts{
-			Issuer:      issuer,
-			AccountName: accountName,
-			Period:      uintPeriod,
-			Digits:      keyDigits,
-			Algorithm:   keyAlgorithm,
-			SecretSize:  uintKeySize,
-			Rand:        b.GetRandomReader(),
-		})
+		var keyObject *otplib.Key
+		var err error
+		if keyType == keyTypeHOTP {
+			keyObject, err = hotplib.Generate(hotplib.GenerateOpts{
+				Issuer:      issuer,
+				AccountName: accountName,
+				Digits:      keyDigits,
+				Algorithm:   keyAlgorithm,
+				SecretSize:  uintKeySize,
+				Rand:        b.GetRandomReader(),
+			})
+			if err == nil {
+				keyObject, err = withCounter(keyObject, uint64(counter))
+			}
+		} else {
+			keyObject, err = totplib.Generate(totplib.GenerateOpts{
+				Issuer:      issuer,
+				AccountName: accountName,
+				Period:      uintPeriod,
+				Digits:      keyDigits,
+				Algorithm:   keyAlgorithm,
+				SecretSize:  uintKeySize,
+				Rand:        b.GetRandomReader(),
+			})
+		}
 		if err != nil {
 			return logical.ErrorResponse("an error occurred while generating a key"), err
 		}
@@ -429,8 +516,7 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 		}
 	}
 
-	// Store it
-	entry, err := logical.StorageEntryJSON("key/"+name, &keyEntry{
+	key := &keyEntry{
 		Key:         keyString,
 		Issuer:      issuer,
 		AccountName: accountName,
@@ -438,16 +524,28 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 		Algorithm:   keyAlgorithm,
 		Digits:      keyDigits,
 		Skew:        uintSkew,
-	})
-	if err != nil {
+	}
+	if keyType == keyTypeHOTP {
+		key.Type = keyTypeHOTP
+		key.Counter = uint64(counter)
+		key.LookAhead = uint(lookAhead)
+	}
+
+	lock := locksutil.LockForKey(b.keyLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Store it, forgetting the codes used with any previous key of the name
+	if err := b.putKey(ctx, req.Storage, name, key); err != nil {
 		return nil, err
 	}
-	if err := req.Storage.Put(ctx, entry); err != nil {
+	if err := req.Storage.Delete(ctx, usedTimeStepsPrefix+name); err != nil {
 		return nil, err
 	}
 
 	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeTOTPKeyCreate, map[string]interface{}{
 		"key_name":  name,
+		"type":      keyType,
 		"period":    period,
 		"algorithm": algorithm,
 		"digits":    digits,
@@ -460,6 +558,36 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 	return response, nil
 }
 
+func (b *backend) putKey(ctx context.Context, s logical.Storage, name string, key *keyEntry) error {
+	entry, err := logical.StorageEntryJSON("key/"+name, key)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// withCounter adds the initial counter to the url of a HOTP key, which
+// authenticator apps require.
+func withCounter(key *otplib.Key, counter uint64) (*otplib.Key, error) {
+	u, err := url.Parse(key.String())
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set("counter", strconv.FormatUint(counter, 10))
+	u.RawQuery = query.Encode()
+	return otplib.NewKeyFromURL(u.String())
+}
+
+const (
+	keyTypeTOTP = "totp"
+	keyTypeHOTP = "hotp"
+
+	// maxLookAhead bounds the counter values a HOTP code is accepted for,
+	// since every one of them makes a guessed code more likely to match
+	maxLookAhead = 100
+)
+
 type keyEntry struct {
 	Key         string           `json:"key" mapstructure:"key" structs:"key"`
 	Issuer      string           `json:"issuer" mapstructure:"issuer" structs:"issuer"`
@@ -468,6 +596,18 @@ type keyEntry struct {
 	Algorithm   otplib.Algorithm `json:"algorithm" mapstructure:"algorithm" structs:"algorithm"`
 	Digits      otplib.Digits    `json:"digits" mapstructure:"digits" structs:"digits"`
 	Skew        uint             `json:"skew" mapstructure:"skew" structs:"skew"`
+
+	// Type is empty for TOTP keys, which predate HOTP keys
+	Type      string `json:"type,omitempty" mapstructure:"type" structs:"type"`
+	Counter   uint64 `json:"counter,omitempty" mapstructure:"counter" structs:"counter"`
+	LookAhead uint   `json:"look_ahead,omitempty" mapstructure:"look_ahead" structs:"look_ahead"`
+}
+
+func (k *keyEntry) keyType() string {
+	if k.Type == "" {
+		return keyTypeTOTP
+	}
+	return k.Type
 }
 
 const pathKeyHelpSyn = `
@@ -0,0 +1,125 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package totp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// maxResyncWindow bounds the counter values a resync searches, since it
+// generates codes for each of them while holding the lock of the key.
+const maxResyncWindow = 10000
+
+func pathResync(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/" + framework.GenericNameWithAtRegex("name") + "/resync",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTOTP,
+			OperationVerb:   "resync",
+			OperationSuffix: "key",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the key.",
+			},
+			"code": {
+				Type:        framework.TypeString,
+				Description: "A HOTP code generated by the token.",
+			},
+			"next_code": {
+				Type:        framework.TypeString,
+				Description: "The code generated by the token right after code.",
+			},
+			"window": {
+				Type:        framework.TypeInt,
+				Default:     100,
+				Description: "The number of counter values past the current one that are searched for the codes. At most 10000.",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathResync,
+			},
+		},
+
+		HelpSynopsis:    pathResyncHelpSyn,
+		HelpDescription: pathResyncHelpDesc,
+	}
+}
+
+func (b *backend) pathResync(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	code := strings.TrimSpace(data.Get("code").(string))
+	nextCode := strings.TrimSpace(data.Get("next_code").(string))
+	window := data.Get("window").(int)
+
+	if code == "" || nextCode == "" {
+		return logical.ErrorResponse("the code and next_code values are required"), nil
+	}
+	if window <= 0 {
+		return logical.ErrorResponse("the window value must be greater than zero"), nil
+	}
+	if window > maxResyncWindow {
+		return logical.ErrorResponse(fmt.Sprintf("the window value must be less than or equal to %d", maxResyncWindow)), nil
+	}
+
+	lock := locksutil.LockForKey(b.keyLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	key, err := b.Key(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown key: %s", name)), nil
+	}
+	if key.keyType() != keyTypeHOTP {
+		return logical.ErrorResponse("only hotp keys can be resynchronized"), nil
+	}
+
+	counter, found, err := findHOTPCounter(key, key.Counter, uint64(window), code, nextCode)
+	if err != nil {
+		return logical.ErrorResponse("an error occurred while validating the codes"), err
+	}
+	if !found {
+		return logical.ErrorResponse("the codes don't match consecutive counters within the window"), nil
+	}
+
+	key.Counter = counter + 2
+	if err := b.putKey(ctx, req.Storage, name, key); err != nil {
+		return nil, err
+	}
+
+	b.TryRecordObservationWithRequest(ctx, req, ObservationTypeTOTPKeyResync, map[string]interface{}{
+		"key_name": name,
+		"counter":  key.Counter,
+	})
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"counter": key.Counter,
+		},
+	}, nil
+}
+
+const pathResyncHelpSyn = `
+Resynchronize the counter of a HOTP key with its token.
+`
+
+const pathResyncHelpDesc = `
+This path moves the counter of a HOTP key forward when its token has generated
+more codes than look_ahead without them being validated. Given two consecutive
+codes from the token, the counter is set past the second of them.
+`
@@ -11,8 +11,8 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
-	kerberos "github.com/hashicorp/vault-plugin-auth-kerberos"
 	"github.com/hashicorp/vault/api"
+	kerberos "github.com/hashicorp/vault/builtin/credential/kerberos"
 	"github.com/hashicorp/vault/command/agentproxyshared/auth"
 	"github.com/jcmturner/gokrb5/v8/spnego"
 )
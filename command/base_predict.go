@@ -114,6 +114,7 @@ func (b *BaseCommand) PredictVaultAvailableAuths() complete.Predictor {
 		"gcp",
 		"github",
 		"ldap",
+		"oauth2",
 		"okta",
 		"plugin",
 		"radius",
@@ -353,6 +353,7 @@ func TestPredict_Plugins(t *testing.T) {
 				"cassandra-database-plugin",
 				"cert",
 				"cf",
+				"clickhouse-database-plugin",
 				"consul",
 				"couchbase-database-plugin",
 				"elasticsearch-database-plugin",
@@ -377,6 +378,7 @@ func TestPredict_Plugins(t *testing.T) {
 				"mysql-legacy-database-plugin",
 				"mysql-rds-database-plugin",
 				"nomad",
+				"oauth2",
 				"oci",
 				"oidc",
 				"okta",
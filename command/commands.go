@@ -437,6 +437,11 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) map[string]cli.Co
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"operator raft snapshot diff": func() (cli.Command, error) {
+			return &OperatorRaftSnapshotDiffCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"operator raft snapshot inspect": func() (cli.Command, error) {
 			return &OperatorRaftSnapshotInspectCommand{
 				BaseCommand: getBaseCommand(),
@@ -615,6 +620,16 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) map[string]cli.Co
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"policy history": func() (cli.Command, error) {
+			return &PolicyHistoryCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
+		"policy lint": func() (cli.Command, error) {
+			return &PolicyLintCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"policy list": func() (cli.Command, error) {
 			return &PolicyListCommand{
 				BaseCommand: getBaseCommand(),
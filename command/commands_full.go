@@ -11,11 +11,12 @@ import (
 	credAliCloud "github.com/hashicorp/vault-plugin-auth-alicloud"
 	credCF "github.com/hashicorp/vault-plugin-auth-cf"
 	credGcp "github.com/hashicorp/vault-plugin-auth-gcp/plugin"
-	credKerb "github.com/hashicorp/vault-plugin-auth-kerberos"
 	credOCI "github.com/hashicorp/vault-plugin-auth-oci"
 	credAws "github.com/hashicorp/vault/builtin/credential/aws"
 	credGitHub "github.com/hashicorp/vault/builtin/credential/github"
+	credKerb "github.com/hashicorp/vault/builtin/credential/kerberos"
 	credLdap "github.com/hashicorp/vault/builtin/credential/ldap"
+	credOAuth2 "github.com/hashicorp/vault/builtin/credential/oauth2"
 	credOkta "github.com/hashicorp/vault/builtin/credential/okta"
 	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
 	_ "github.com/hashicorp/vault/helper/builtinplugins"
@@ -77,6 +78,7 @@ func newFullAddonHandlers() (map[string]physical.Factory, map[string]LoginHandle
 		"github":   &credGitHub.CLIHandler{},
 		"kerberos": &credKerb.CLIHandler{},
 		"ldap":     &credLdap.CLIHandler{},
+		"oauth2":   &credOAuth2.CLIHandler{},
 		"oci":      &credOCI.CLIHandler{},
 		"okta":     &credOkta.CLIHandler{},
 		"pcf":      &credCF.CLIHandler{}, // Deprecated.
@@ -12,6 +12,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/cli"
@@ -24,7 +25,6 @@ import (
 	"github.com/hashicorp/vault/physical/raft"
 	"github.com/hashicorp/vault/sdk/helper/logging"
 	"github.com/hashicorp/vault/sdk/physical"
-	"github.com/hashicorp/vault/vault"
 	"github.com/pkg/errors"
 	"github.com/posener/complete"
 	"golang.org/x/sync/errgroup"
@@ -40,14 +40,19 @@ var errAbort = errors.New("Migration aborted")
 type OperatorMigrateCommand struct {
 	*BaseCommand
 
-	PhysicalBackends map[string]physical.Factory
-	flagConfig       string
-	flagLogLevel     string
-	flagStart        string
-	flagReset        bool
-	flagMaxParallel  int
-	logger           log.Logger
-	ShutdownCh       chan struct{}
+	PhysicalBackends     map[string]physical.Factory
+	flagConfig           string
+	flagLogLevel         string
+	flagStart            string
+	flagReset            bool
+	flagMaxParallel      int
+	flagOnline           bool
+	flagCutoverThreshold int
+	logger               log.Logger
+	ShutdownCh           chan struct{}
+
+	// copied counts the keys copied by migrateAll
+	copied atomic.Uint64
 }
 
 type migratorConfig struct {
@@ -72,6 +77,18 @@ Usage: vault operator migrate [options]
 
       $ vault operator migrate -config=migrate.hcl
 
+  Start a migration while Vault is running on the source storage, with
+  enable_online_storage_migration set in its configuration. Vault records
+  the keys changed during the copy, which are copied again until no more than
+  -cutover-threshold are left. The cutover then disables the writes to the
+  source storage, copies the last changed keys and verifies the destination
+  storage by comparing the key counts and the hashes of the values. Keys that
+  differ are copied again, and keys missing from the source storage are deleted
+  from the destination storage. Vault rejects writes until it is restarted with
+  the destination storage, or the migration lock is reset:
+
+      $ vault operator migrate -config=migrate.hcl -online
+
   For more information, please see the documentation.
 
 ` + c.Flags().Help()
@@ -113,6 +130,23 @@ func (c *OperatorMigrateCommand) Flags() *FlagSets {
 			"This can speed up the migration process on slow backends but uses more resources.",
 	})
 
+	f.BoolVar(&BoolVar{
+		Name:   "online",
+		Target: &c.flagOnline,
+		Usage: "Migrate while Vault is running on the source storage, with a " +
+			"short cutover during which Vault rejects writes. Vault needs to run " +
+			"with enable_online_storage_migration set. Raft source storage is not " +
+			"supported.",
+	})
+
+	f.IntVar(&IntVar{
+		Name:    "cutover-threshold",
+		Default: 100,
+		Target:  &c.flagCutoverThreshold,
+		Usage: "Number of keys changed during the last copy of an online migration " +
+			"below which the cutover starts.",
+	})
+
 	f.StringVar(&StringVar{
 		Name:       "log-level",
 		Target:     &c.flagLogLevel,
@@ -154,6 +188,11 @@ func (c *OperatorMigrateCommand) Run(args []string) int {
 		return 1
 	}
 
+	if c.flagOnline && c.flagStart != "" {
+		c.UI.Error("Flag -start cannot be used with -online")
+		return 1
+	}
+
 	if c.flagConfig == "" {
 		c.UI.Error("Must specify exactly one config path using -config")
 		return 1
@@ -173,9 +212,14 @@ func (c *OperatorMigrateCommand) Run(args []string) int {
 		return 2
 	}
 
-	if c.flagReset {
+	switch {
+	case c.flagReset:
 		c.UI.Output("Success! Migration lock reset (if it was set).")
-	} else {
+	case c.flagOnline:
+		c.UI.Output("Success! All of the keys have been migrated. Vault rejects " +
+			"writes to the source storage until it is restarted with the destination " +
+			"storage, or the migration lock is reset.")
+	default:
 		c.UI.Output("Success! All of the keys have been migrated.")
 	}
 
@@ -194,9 +238,16 @@ func (c *OperatorMigrateCommand) migrate(config *migratorConfig) error {
 		if err := SetStorageMigration(from, false); err != nil {
 			return fmt.Errorf("error resetting migration lock: %w", err)
 		}
+		if err := clearStorageMigrationJournal(context.Background(), from); err != nil {
+			return fmt.Errorf("error clearing migration journal: %w", err)
+		}
 		return nil
 	}
 
+	if c.flagOnline && config.StorageSource.Type == "raft" {
+		return errors.New("online migration is not supported from raft storage")
+	}
+
 	to, err := c.createDestinationBackend(config.StorageDestination.Type, config.StorageDestination.Config, config)
 	if err != nil {
 		return fmt.Errorf("error mounting 'storage_destination': %w", err)
@@ -211,8 +262,11 @@ func (c *OperatorMigrateCommand) migrate(config *migratorConfig) error {
 		return fmt.Errorf("storage migration in progress (started: %s)", migrationStatus.Start.Format(time.RFC3339))
 	}
 
-	switch config.StorageSource.Type {
-	case "raft":
+	switch {
+	case c.flagOnline:
+		// Online migrations set the migration lock themselves, as Vault keeps
+		// running on the source storage.
+	case config.StorageSource.Type == "raft":
 		// Raft storage cannot be written to when shutdown. Also the boltDB file
 		// already uses file locking to ensure two processes are not accessing
 		// it.
@@ -228,6 +282,10 @@ func (c *OperatorMigrateCommand) migrate(config *migratorConfig) error {
 
 	doneCh := make(chan error)
 	go func() {
+		if c.flagOnline {
+			doneCh <- c.migrateOnline(ctx, from, to)
+			return
+		}
 		doneCh <- c.migrateAll(ctx, from, to, c.flagMaxParallel)
 	}()
 
@@ -246,7 +304,7 @@ func (c *OperatorMigrateCommand) migrate(config *migratorConfig) error {
 // migrateAll copies all keys in lexicographic order.
 func (c *OperatorMigrateCommand) migrateAll(ctx context.Context, from physical.Backend, to physical.Backend, maxParallel int) error {
 	return dfsScan(ctx, from, maxParallel, func(ctx context.Context, path string) error {
-		if path < c.flagStart || path == storageMigrationLock || path == vault.CoreLockPath {
+		if path < c.flagStart || storageMigrationKeySkipped(path) {
 			return nil
 		}
 
@@ -262,6 +320,7 @@ func (c *OperatorMigrateCommand) migrateAll(ctx context.Context, from physical.B
 		if err := to.Put(ctx, entry); err != nil {
 			return fmt.Errorf("error writing entry: %w", err)
 		}
+		c.copied.Add(1)
 		c.logger.Info("copied key", "path", path)
 		return nil
 	})
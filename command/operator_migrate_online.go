@@ -0,0 +1,348 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/physical"
+	"github.com/hashicorp/vault/vault"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	// storageMigrationAckTimeout is how long an online migration waits for
+	// Vault to apply the migration status.
+	storageMigrationAckTimeout = time.Minute
+
+	// storageMigrationProgressInterval is how often the progress of the copy
+	// is output.
+	storageMigrationProgressInterval = 10 * time.Second
+)
+
+// storageMigrationKeySkipped reports whether the key is left out of
+// migrations.
+func storageMigrationKeySkipped(key string) bool {
+	return key == storageMigrationLock || key == vault.CoreLockPath ||
+		strings.HasPrefix(key, storageMigrationJournalPrefix)
+}
+
+// migrateOnline copies the storage while Vault is running. Vault records the
+// keys changed during the copy, which are copied again until few are left.
+// The cutover then disables the writes to the source storage, copies the last
+// changes and verifies the destination storage.
+//
+// Once done, Vault keeps rejecting writes to the source storage until it is
+// restarted with the destination storage, or the migration lock is reset.
+func (c *OperatorMigrateCommand) migrateOnline(ctx context.Context, from physical.Backend, to physical.Backend) (retErr error) {
+	if err := clearStorageMigrationJournal(ctx, from); err != nil {
+		return fmt.Errorf("error clearing migration journal: %w", err)
+	}
+
+	status := &StorageMigrationStatus{
+		Start:  time.Now(),
+		Online: true,
+	}
+	if err := setStorageMigrationStatus(from, status); err != nil {
+		return fmt.Errorf("error setting migration lock: %w", err)
+	}
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		// Let Vault write to the source storage again
+		if err := SetStorageMigration(from, false); err != nil {
+			c.logger.Error("error resetting migration lock", "error", err)
+		}
+		if err := clearStorageMigrationJournal(context.Background(), from); err != nil {
+			c.logger.Error("error clearing migration journal", "error", err)
+		}
+	}()
+
+	c.UI.Output("==> Waiting for Vault to record the changes to the storage")
+	if err := waitForStorageMigrationAck(ctx, from, status); err != nil {
+		return err
+	}
+
+	c.UI.Output("==> Copying the storage")
+	stopProgress := c.reportMigrationProgress(ctx)
+	err := c.migrateAll(ctx, from, to, c.flagMaxParallel)
+	stopProgress()
+	if err != nil {
+		return err
+	}
+	c.UI.Output(fmt.Sprintf("Copied %d keys", c.copied.Load()))
+
+	c.UI.Output("==> Copying the keys changed during the copy")
+	for {
+		n, err := c.replayChanges(ctx, from, to, c.flagMaxParallel)
+		if err != nil {
+			return err
+		}
+		c.UI.Output(fmt.Sprintf("Copied %d changed keys", n))
+		if n <= c.flagCutoverThreshold {
+			break
+		}
+	}
+
+	c.UI.Output("==> Starting the cutover, Vault rejects writes from now on")
+	status.Cutover = true
+	if err := setStorageMigrationStatus(from, status); err != nil {
+		return fmt.Errorf("error setting migration lock: %w", err)
+	}
+	if err := waitForStorageMigrationAck(ctx, from, status); err != nil {
+		return err
+	}
+	n, err := c.replayChanges(ctx, from, to, c.flagMaxParallel)
+	if err != nil {
+		return err
+	}
+	c.UI.Output(fmt.Sprintf("Copied %d changed keys", n))
+
+	c.UI.Output("==> Verifying the destination storage")
+	verification, err := c.verifyMigration(ctx, from, to, c.flagMaxParallel)
+	if err != nil {
+		return err
+	}
+	c.UI.Output(fmt.Sprintf("Source keys: %d, destination keys: %d, keys differing: %d",
+		verification.SourceKeys, verification.DestinationKeys, len(verification.Differences)))
+	if len(verification.Differences) > 0 {
+		// Vault doesn't write to the source storage anymore, so the keys
+		// can be copied again safely
+		for _, key := range verification.Differences {
+			if err := copyStorageKey(ctx, from, to, key); err != nil {
+				return err
+			}
+		}
+		c.UI.Warn(fmt.Sprintf("Copied the %d differing keys again", len(verification.Differences)))
+	}
+
+	return nil
+}
+
+// waitForStorageMigrationAck waits for the active node of Vault to apply the
+// migration status.
+func waitForStorageMigrationAck(ctx context.Context, from physical.Backend, status *StorageMigrationStatus) error {
+	timeout := time.NewTimer(storageMigrationAckTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(storageMigrationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		entry, err := from.Get(ctx, storageMigrationAckPath)
+		if err != nil {
+			return fmt.Errorf("error reading migration acknowledgement: %w", err)
+		}
+		if entry != nil {
+			var ack storageMigrationAck
+			if err := jsonutil.DecodeJSON(entry.Value, &ack); err != nil {
+				return fmt.Errorf("error decoding migration acknowledgement: %w", err)
+			}
+			if ack.Start.Equal(status.Start) && ack.Cutover == status.Cutover {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C:
+			return fmt.Errorf("Vault didn't acknowledge the migration within %s, make sure it is running with the source storage, enable_online_storage_migration set and an active node", storageMigrationAckTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportMigrationProgress periodically outputs the number of keys copied,
+// until the returned function is called.
+func (c *OperatorMigrateCommand) reportMigrationProgress(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		ticker := time.NewTicker(storageMigrationProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.UI.Output(fmt.Sprintf("Copied %d keys", c.copied.Load()))
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-doneCh
+	}
+}
+
+// replayChanges copies the keys of the changes recorded by Vault, and returns
+// the number of keys copied.
+func (c *OperatorMigrateCommand) replayChanges(ctx context.Context, from physical.Backend, to physical.Backend, maxParallel int) (int, error) {
+	names, err := from.List(ctx, storageMigrationChangesPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("error listing changes: %w", err)
+	}
+
+	keys := make(map[string]struct{})
+	for _, name := range names {
+		entry, err := from.Get(ctx, storageMigrationChangesPrefix+name)
+		if err != nil {
+			return 0, fmt.Errorf("error reading change: %w", err)
+		}
+		if entry == nil {
+			continue
+		}
+		var change storageMigrationChange
+		if err := jsonutil.DecodeJSON(entry.Value, &change); err != nil {
+			return 0, fmt.Errorf("error decoding change: %w", err)
+		}
+		for _, key := range change.Keys {
+			keys[key] = struct{}{}
+		}
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(maxParallel)
+	for key := range keys {
+		eg.Go(func() error {
+			return copyStorageKey(egCtx, from, to, key)
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return 0, err
+	}
+
+	// The changes recorded since listing them are copied by the next call
+	for _, name := range names {
+		if err := from.Delete(ctx, storageMigrationChangesPrefix+name); err != nil {
+			return 0, fmt.Errorf("error removing change: %w", err)
+		}
+	}
+
+	return len(keys), nil
+}
+
+// copyStorageKey copies the current value of the key, deleting it from the
+// destination if it was deleted.
+func copyStorageKey(ctx context.Context, from physical.Backend, to physical.Backend, key string) error {
+	entry, err := from.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error reading entry: %w", err)
+	}
+	if entry == nil {
+		if err := to.Delete(ctx, key); err != nil {
+			return fmt.Errorf("error deleting entry: %w", err)
+		}
+		return nil
+	}
+	if err := to.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error writing entry: %w", err)
+	}
+	return nil
+}
+
+// storageMigrationVerification is the comparison of the source and the
+// destination storage of a migration.
+type storageMigrationVerification struct {
+	SourceKeys      int
+	DestinationKeys int
+
+	// Differences are the keys missing from either storage, or that have
+	// different values
+	Differences []string
+}
+
+// verifyMigration compares the key count and the hashes of the values of the
+// source and destination storage.
+func (c *OperatorMigrateCommand) verifyMigration(ctx context.Context, from physical.Backend, to physical.Backend, maxParallel int) (*storageMigrationVerification, error) {
+	fromHashes, err := hashStorage(ctx, from, maxParallel)
+	if err != nil {
+		return nil, fmt.Errorf("error reading source storage: %w", err)
+	}
+	toHashes, err := hashStorage(ctx, to, maxParallel)
+	if err != nil {
+		return nil, fmt.Errorf("error reading destination storage: %w", err)
+	}
+
+	verification := &storageMigrationVerification{
+		SourceKeys:      len(fromHashes),
+		DestinationKeys: len(toHashes),
+	}
+	for key, sum := range fromHashes {
+		if toSum, ok := toHashes[key]; !ok || toSum != sum {
+			verification.Differences = append(verification.Differences, key)
+		}
+	}
+	for key := range toHashes {
+		if _, ok := fromHashes[key]; !ok {
+			verification.Differences = append(verification.Differences, key)
+		}
+	}
+	sort.Strings(verification.Differences)
+
+	return verification, nil
+}
+
+// hashStorage returns the hashes of the values of the storage, leaving out the
+// keys that aren't migrated.
+func hashStorage(ctx context.Context, b physical.Backend, maxParallel int) (map[string][sha256.Size]byte, error) {
+	var l sync.Mutex
+	hashes := make(map[string][sha256.Size]byte)
+
+	err := dfsScan(ctx, b, maxParallel, func(ctx context.Context, path string) error {
+		if storageMigrationKeySkipped(path) {
+			return nil
+		}
+		entry, err := b.Get(ctx, path)
+		if err != nil {
+			return fmt.Errorf("error reading entry: %w", err)
+		}
+		if entry == nil {
+			return nil
+		}
+
+		h := sha256.New()
+		h.Write(entry.Value)
+		if entry.SealWrap {
+			h.Write([]byte{1})
+		}
+		var sum [sha256.Size]byte
+		copy(sum[:], h.Sum(nil))
+
+		l.Lock()
+		defer l.Unlock()
+		hashes[path] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// clearStorageMigrationJournal removes the changes recorded for an online
+// migration and the acknowledgement of its status.
+func clearStorageMigrationJournal(ctx context.Context, b physical.Backend) error {
+	names, err := b.List(ctx, storageMigrationChangesPrefix)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := b.Delete(ctx, storageMigrationChangesPrefix+name); err != nil {
+			return err
+		}
+	}
+	return b.Delete(ctx, storageMigrationAckPath)
+}
@@ -0,0 +1,190 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/cli"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/physical"
+	"github.com/hashicorp/vault/sdk/physical/inmem"
+)
+
+func testOnlineMigration(t *testing.T) {
+	t.Helper()
+
+	pollInterval, ackTimeout := storageMigrationPollInterval, storageMigrationAckTimeout
+	storageMigrationPollInterval, storageMigrationAckTimeout = 10*time.Millisecond, time.Second
+	t.Cleanup(func() {
+		storageMigrationPollInterval, storageMigrationAckTimeout = pollInterval, ackTimeout
+	})
+}
+
+func TestMigrateOnline(t *testing.T) {
+	testOnlineMigration(t)
+
+	t.Run("Default", func(t *testing.T) {
+		ctx := context.Background()
+		from, err := inmem.NewTransactionalInmem(nil, log.NewNullLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := storeData(from, generateData()); err != nil {
+			t.Fatal(err)
+		}
+		to, err := inmem.NewInmem(nil, log.NewNullLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := to.Put(ctx, &physical.Entry{Key: "extra", Value: []byte("extra")}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Vault keeps writing to the source storage until the cutover
+		storage, journal := NewStorageMigrationJournal(from, log.NewNullLogger())
+		journal.SetActiveCheck(func() bool { return true })
+		shutdownCh := make(chan struct{})
+		defer close(shutdownCh)
+		go journal.Run(shutdownCh)
+
+		var wg sync.WaitGroup
+		var writeErr error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				key := fmt.Sprintf("written/%d", i%50)
+				if i%3 == 0 {
+					writeErr = storage.Delete(ctx, key)
+				} else {
+					writeErr = storage.Put(ctx, &physical.Entry{Key: key, Value: []byte(fmt.Sprint(i))})
+				}
+				if writeErr != nil {
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+
+		ui := cli.NewMockUi()
+		cmd := &OperatorMigrateCommand{
+			BaseCommand:          &BaseCommand{UI: ui},
+			logger:               log.NewNullLogger(),
+			flagMaxParallel:      4,
+			flagCutoverThreshold: 100,
+		}
+		if err := cmd.migrateOnline(ctx, from, to); err != nil {
+			t.Fatal(err, ui.ErrorWriter.String())
+		}
+		wg.Wait()
+		if !errors.Is(writeErr, errStorageMigrationCutover) {
+			t.Fatalf("expected writes to be rejected after the cutover, got %v", writeErr)
+		}
+
+		fromHashes, err := hashStorage(ctx, from, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		toHashes, err := hashStorage(ctx, to, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fromHashes, toHashes) {
+			t.Fatalf("expected the destination storage to match the source storage")
+		}
+		if keys := recordedKeys(t, from); len(keys) != 0 {
+			t.Fatalf("expected all changes to be copied, got %v", keys)
+		}
+
+		status, err := CheckStorageMigration(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status == nil || !status.Online || !status.Cutover {
+			t.Fatalf("expected the cutover to remain in place, got %#v", status)
+		}
+	})
+
+	t.Run("Not acknowledged", func(t *testing.T) {
+		ctx := context.Background()
+		from, err := inmem.NewInmem(nil, log.NewNullLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, err := inmem.NewInmem(nil, log.NewNullLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := &OperatorMigrateCommand{
+			BaseCommand:     &BaseCommand{UI: cli.NewMockUi()},
+			logger:          log.NewNullLogger(),
+			flagMaxParallel: 1,
+		}
+		if err := cmd.migrateOnline(ctx, from, to); err == nil {
+			t.Fatal("expected the migration to fail without Vault running")
+		}
+
+		status, err := CheckStorageMigration(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != nil {
+			t.Fatalf("expected the migration lock to be reset, got %#v", status)
+		}
+	})
+}
+
+func TestVerifyMigration(t *testing.T) {
+	ctx := context.Background()
+	from, err := inmem.NewInmem(nil, log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := inmem.NewInmem(nil, log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range []*physical.Entry{
+		{Key: "same", Value: []byte("same")},
+		{Key: "changed", Value: []byte("from")},
+		{Key: "missing", Value: []byte("missing")},
+		{Key: storageMigrationLock, Value: []byte("{}")},
+	} {
+		if err := from.Put(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, entry := range []*physical.Entry{
+		{Key: "same", Value: []byte("same")},
+		{Key: "changed", Value: []byte("to")},
+		{Key: "extra", Value: []byte("extra")},
+	} {
+		if err := to.Put(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := &OperatorMigrateCommand{}
+	verification, err := cmd.verifyMigration(ctx, from, to, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &storageMigrationVerification{
+		SourceKeys:      3,
+		DestinationKeys: 3,
+		Differences:     []string{"changed", "extra", "missing"},
+	}
+	if !reflect.DeepEqual(expected, verification) {
+		t.Fatalf("expected %#v, got %#v", expected, verification)
+	}
+}
@@ -39,6 +39,10 @@ Usage: vault operator raft snapshot <subcommand> [options] [args]
 
       $ vault operator raft snapshot inspect raft.snap
 
+  Compares the storage of two snapshot files:
+
+      $ vault operator raft snapshot diff monday.snap tuesday.snap
+
   Please see the individual subcommand help for detailed usage information.
 `
 
@@ -0,0 +1,497 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hashicorp/cli"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/sdk/plugin/pb"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*OperatorRaftSnapshotDiffCommand)(nil)
+	_ cli.CommandAutocomplete = (*OperatorRaftSnapshotDiffCommand)(nil)
+)
+
+const (
+	snapshotDiffAdded   = "added"
+	snapshotDiffRemoved = "removed"
+	snapshotDiffChanged = "changed"
+
+	snapshotDiffRootNamespace = "root"
+)
+
+type OperatorRaftSnapshotDiffCommand struct {
+	*BaseCommand
+	details       bool
+	filter        string
+	resolveMounts bool
+}
+
+func (c *OperatorRaftSnapshotDiffCommand) Synopsis() string {
+	return "Compares the storage of two raft snapshots"
+}
+
+func (c *OperatorRaftSnapshotDiffCommand) Help() string {
+	helpText := `
+Usage: vault operator raft snapshot diff [options] <from_snapshot_file> <to_snapshot_file>
+
+  Reports the storage keys added, removed and changed between two snapshot
+  files, with the namespace and the mount, or other storage area, each key
+  belongs to and the change in size.
+
+  The snapshots are read offline, without unsealing. Values are compared as
+  they are stored, encrypted, so a key rewritten with the same value is
+  reported as changed. Mounts are identified by their storage prefix, such as
+  logical/<uuid>/, unless -resolve-mounts is set.
+
+  Compare two snapshots:
+
+      $ vault operator raft snapshot diff monday.snap tuesday.snap
+
+  Only report the totals of each mount:
+
+      $ vault operator raft snapshot diff -details=false monday.snap tuesday.snap
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorRaftSnapshotDiffCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+	f := set.NewFlagSet("Command Options")
+
+	f.BoolVar(&BoolVar{
+		Name:    "details",
+		Target:  &c.details,
+		Default: true,
+		Usage:   "Lists every added, removed and changed key in addition to the totals of each mount.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:    "filter",
+		Target:  &c.filter,
+		Default: "",
+		Usage:   "Limits the comparison to the storage keys with this prefix.",
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:    "resolve-mounts",
+		Target:  &c.resolveMounts,
+		Default: false,
+		Usage: "Resolves the storage prefixes of mounts to their paths, and namespace " +
+			"IDs to their paths, by listing them from the Vault server. This requires " +
+			"a token allowed to list the mounts, but the snapshots are still read " +
+			"offline.",
+	})
+
+	return set
+}
+
+func (c *OperatorRaftSnapshotDiffCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *OperatorRaftSnapshotDiffCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+// SnapshotDiffOutput holds the differences between two snapshots
+type SnapshotDiffOutput struct {
+	From      *MetadataInfo
+	To        *MetadataInfo
+	Added     int
+	Removed   int
+	Changed   int
+	SizeDelta int
+	Mounts    []*snapshotMountDiff
+	Keys      []*snapshotKeyDiff `json:",omitempty"`
+}
+
+type snapshotMountDiff struct {
+	Namespace string
+	Mount     string
+	Path      string `json:",omitempty"`
+	Added     int
+	Removed   int
+	Changed   int
+	SizeDelta int
+}
+
+type snapshotKeyDiff struct {
+	Key       string
+	Change    string
+	Namespace string
+	Mount     string
+	Path      string `json:",omitempty"`
+	SizeDelta int
+}
+
+// snapshotDiffEntry is what is kept of a storage entry to compare it
+type snapshotDiffEntry struct {
+	size int
+	sum  [sha256.Size]byte
+}
+
+func (c *OperatorRaftSnapshotDiffCommand) Run(args []string) int {
+	flags := c.Flags()
+
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = c.flags.Args()
+	switch {
+	case len(args) < 2:
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected 2, got %d)", len(args)))
+		return 1
+	case len(args) > 2:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 2, got %d)", len(args)))
+		return 1
+	}
+
+	logger := hclog.New(nil)
+	fromEntries, fromMeta, err := c.readEntries(logger, args[0])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading snapshot %q: %s", args[0], err))
+		return 1
+	}
+	toEntries, toMeta, err := c.readEntries(logger, args[1])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading snapshot %q: %s", args[1], err))
+		return 1
+	}
+
+	var names *snapshotMountNames
+	if c.resolveMounts {
+		client, err := c.Client()
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 2
+		}
+		names, err = resolveSnapshotMountNames(client)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error resolving mounts: %s", err))
+			return 2
+		}
+	}
+
+	data := diffSnapshotEntries(fromEntries, toEntries, names)
+	data.From = snapshotMetadataInfo(fromMeta)
+	data.To = snapshotMetadataInfo(toMeta)
+	if !c.details {
+		data.Keys = nil
+	}
+
+	if Format(c.UI) != "table" {
+		return OutputData(c.UI, data)
+	}
+
+	tableData, err := formatSnapshotDiffTable(data)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	c.UI.Output(tableData)
+
+	return 0
+}
+
+// readEntries reads the storage entries of a snapshot file that match the
+// filter
+func (c *OperatorRaftSnapshotDiffCommand) readEntries(logger hclog.Logger, file string) (map[string]snapshotDiffEntry, *raft.SnapshotMeta, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	entries := make(map[string]snapshotDiffEntry)
+	meta, err := readSnapshot(logger, f, func(entry *pb.StorageEntry, size int) {
+		if entry.Key == "" || !strings.HasPrefix(entry.Key, c.filter) {
+			return
+		}
+		h := sha256.New()
+		h.Write(entry.Value)
+		if entry.SealWrap {
+			h.Write([]byte{1})
+		}
+		e := snapshotDiffEntry{size: size}
+		h.Sum(e.sum[:0])
+		entries[entry.Key] = e
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return entries, meta, nil
+}
+
+func snapshotMetadataInfo(meta *raft.SnapshotMeta) *MetadataInfo {
+	return &MetadataInfo{
+		ID:      meta.ID,
+		Size:    meta.Size,
+		Index:   meta.Index,
+		Term:    meta.Term,
+		Version: meta.Version,
+	}
+}
+
+// diffSnapshotEntries compares the storage entries of two snapshots, and
+// totals the differences by namespace and mount
+func diffSnapshotEntries(from, to map[string]snapshotDiffEntry, names *snapshotMountNames) *SnapshotDiffOutput {
+	data := &SnapshotDiffOutput{
+		Mounts: []*snapshotMountDiff{},
+		Keys:   []*snapshotKeyDiff{},
+	}
+	mounts := make(map[[2]string]*snapshotMountDiff)
+
+	add := func(key, change string, sizeDelta int) {
+		ns, mount := snapshotKeyLocation(key)
+		nsName, path := names.resolve(ns, mount)
+
+		m, ok := mounts[[2]string{ns, mount}]
+		if !ok {
+			m = &snapshotMountDiff{
+				Namespace: nsName,
+				Mount:     mount,
+				Path:      path,
+			}
+			mounts[[2]string{ns, mount}] = m
+			data.Mounts = append(data.Mounts, m)
+		}
+
+		switch change {
+		case snapshotDiffAdded:
+			m.Added++
+			data.Added++
+		case snapshotDiffRemoved:
+			m.Removed++
+			data.Removed++
+		case snapshotDiffChanged:
+			m.Changed++
+			data.Changed++
+		}
+		m.SizeDelta += sizeDelta
+		data.SizeDelta += sizeDelta
+
+		data.Keys = append(data.Keys, &snapshotKeyDiff{
+			Key:       key,
+			Change:    change,
+			Namespace: nsName,
+			Mount:     mount,
+			Path:      path,
+			SizeDelta: sizeDelta,
+		})
+	}
+
+	for key, toEntry := range to {
+		fromEntry, ok := from[key]
+		switch {
+		case !ok:
+			add(key, snapshotDiffAdded, toEntry.size)
+		case fromEntry.sum != toEntry.sum:
+			add(key, snapshotDiffChanged, toEntry.size-fromEntry.size)
+		}
+	}
+	for key, fromEntry := range from {
+		if _, ok := to[key]; !ok {
+			add(key, snapshotDiffRemoved, -fromEntry.size)
+		}
+	}
+
+	sort.Slice(data.Mounts, func(i, j int) bool {
+		if data.Mounts[i].Namespace != data.Mounts[j].Namespace {
+			return data.Mounts[i].Namespace < data.Mounts[j].Namespace
+		}
+		return data.Mounts[i].Mount < data.Mounts[j].Mount
+	})
+	sort.Slice(data.Keys, func(i, j int) bool {
+		return data.Keys[i].Key < data.Keys[j].Key
+	})
+	return data
+}
+
+// snapshotKeyLocation returns the ID of the namespace a storage key belongs
+// to, and the storage prefix of the mount or other storage area it belongs
+// to, such as logical/<uuid>/ or sys/policy/
+func snapshotKeyLocation(key string) (string, string) {
+	ns := snapshotDiffRootNamespace
+	if strings.HasPrefix(key, "namespaces/") {
+		parts := strings.SplitN(key, "/", 3)
+		if len(parts) == 3 {
+			ns, key = parts[1], parts[2]
+		}
+	}
+
+	parts := strings.SplitN(key, "/", 3)
+	switch {
+	case len(parts) == 1:
+		return ns, key
+	case len(parts) == 2:
+		return ns, parts[0] + "/"
+	}
+
+	switch parts[0] {
+	case "logical", "auth", "audit", "sys":
+		return ns, parts[0] + "/" + parts[1] + "/"
+	}
+	return ns, parts[0] + "/"
+}
+
+// snapshotMountNames maps the namespace IDs and the storage prefixes of mounts
+// to their paths
+type snapshotMountNames struct {
+	namespaces map[string]string
+	mounts     map[[2]string]string
+}
+
+// resolve returns the name to report for the namespace, and the path of the
+// mount if known
+func (n *snapshotMountNames) resolve(ns, mount string) (string, string) {
+	if n == nil {
+		return ns, ""
+	}
+	nsName := ns
+	if path, ok := n.namespaces[ns]; ok {
+		nsName = path
+	}
+	return nsName, n.mounts[[2]string{ns, mount}]
+}
+
+// resolveSnapshotMountNames lists the namespaces and the mounts of the server
+// to map their IDs to their paths
+func resolveSnapshotMountNames(client *api.Client) (*snapshotMountNames, error) {
+	names := &snapshotMountNames{
+		namespaces: make(map[string]string),
+		mounts:     make(map[[2]string]string),
+	}
+
+	type namespace struct {
+		id   string
+		path string
+	}
+	queue := []namespace{{id: snapshotDiffRootNamespace}}
+	for len(queue) > 0 {
+		ns := queue[0]
+		queue = queue[1:]
+
+		nsClient := client
+		if ns.path != "" {
+			nsClient = client.WithNamespace(ns.path)
+			names.namespaces[ns.id] = ns.path
+		}
+
+		secretMounts, err := nsClient.Sys().ListMounts()
+		if err != nil {
+			return nil, err
+		}
+		for path, mount := range secretMounts {
+			switch mount.Type {
+			case "system", "ns_system":
+				names.mounts[[2]string{ns.id, "sys/"}] = path
+			default:
+				names.mounts[[2]string{ns.id, "logical/" + mount.UUID + "/"}] = path
+			}
+		}
+
+		authMounts, err := nsClient.Sys().ListAuth()
+		if err != nil {
+			return nil, err
+		}
+		for path, mount := range authMounts {
+			switch mount.Type {
+			case "token", "ns_token":
+				names.mounts[[2]string{ns.id, "sys/token/"}] = "auth/" + path
+			default:
+				names.mounts[[2]string{ns.id, "auth/" + mount.UUID + "/"}] = "auth/" + path
+			}
+		}
+
+		// Namespaces only exist on Vault Enterprise, so an error listing them
+		// means there are none
+		secret, err := nsClient.Logical().List("sys/namespaces")
+		if err != nil || secret == nil {
+			continue
+		}
+		keyInfo, _ := secret.Data["key_info"].(map[string]interface{})
+		for _, info := range keyInfo {
+			info, ok := info.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id, _ := info["id"].(string)
+			path, _ := info["path"].(string)
+			if id != "" && path != "" {
+				queue = append(queue, namespace{id: id, path: path})
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// formatSizeDelta formats a change in size with its sign
+func formatSizeDelta(delta int) string {
+	switch {
+	case delta > 0:
+		return "+" + ByteSize(uint64(delta))
+	case delta < 0:
+		return "-" + ByteSize(uint64(-delta))
+	}
+	return "0"
+}
+
+func formatSnapshotDiffTable(data *SnapshotDiffOutput) (string, error) {
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 8, 8, 6, ' ', 0)
+
+	fmt.Fprintf(tw, " From\t%s\tIndex %d\tTerm %d", data.From.ID, data.From.Index, data.From.Term)
+	fmt.Fprintf(tw, "\n To\t%s\tIndex %d\tTerm %d", data.To.ID, data.To.Index, data.To.Term)
+	fmt.Fprintf(tw, "\n")
+
+	fmt.Fprintf(tw, "\n")
+	fmt.Fprintln(tw, "\n Namespace\tMount\tAdded\tRemoved\tChanged\tSize Delta")
+	fmt.Fprintf(tw, " %s\t%s\t%s\t%s\t%s\t%s", "----", "----", "----", "----", "----", "----")
+	for _, m := range data.Mounts {
+		mount := m.Mount
+		if m.Path != "" {
+			mount = m.Path
+		}
+		fmt.Fprintf(tw, "\n %s\t%s\t%d\t%d\t%d\t%s", m.Namespace, mount, m.Added, m.Removed, m.Changed, formatSizeDelta(m.SizeDelta))
+	}
+	fmt.Fprintf(tw, "\n %s\t%s\t%s\t%s\t%s\t%s", "----", "----", "----", "----", "----", "----")
+	fmt.Fprintf(tw, "\n Total\t\t%d\t%d\t%d\t%s", data.Added, data.Removed, data.Changed, formatSizeDelta(data.SizeDelta))
+
+	if len(data.Keys) > 0 {
+		fmt.Fprintf(tw, "\n")
+		fmt.Fprintln(tw, "\n Change\tNamespace\tMount\tKey\tSize Delta")
+		fmt.Fprintf(tw, " %s\t%s\t%s\t%s\t%s", "----", "----", "----", "----", "----")
+		for _, k := range data.Keys {
+			mount := k.Mount
+			if k.Path != "" {
+				mount = k.Path
+			}
+			fmt.Fprintf(tw, "\n %s\t%s\t%s\t%s\t%s", k.Change, k.Namespace, mount, k.Key, formatSizeDelta(k.SizeDelta))
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return b.String(), err
+	}
+
+	return b.String(), nil
+}
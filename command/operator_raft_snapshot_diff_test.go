@@ -0,0 +1,187 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/cli"
+	"github.com/hashicorp/vault/physical/raft"
+	"github.com/hashicorp/vault/sdk/physical"
+	"github.com/stretchr/testify/require"
+)
+
+func testOperatorRaftSnapshotDiffCommand(tb testing.TB) (*cli.MockUi, *OperatorRaftSnapshotDiffCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &OperatorRaftSnapshotDiffCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func TestSnapshotKeyLocation(t *testing.T) {
+	t.Parallel()
+
+	for key, expected := range map[string][2]string{
+		"core/mounts":                         {"root", "core/"},
+		"logical/1234/foo/bar":                {"root", "logical/1234/"},
+		"auth/5678/role/admin":                {"root", "auth/5678/"},
+		"sys/token/id/h1234":                  {"root", "sys/token/"},
+		"sys/policy/default":                  {"root", "sys/policy/"},
+		"namespaces/ns1/logical/abcd/secret":  {"ns1", "logical/abcd/"},
+		"namespaces/ns1/sys/expire/id/secret": {"ns1", "sys/expire/"},
+		"index-version":                       {"root", "index-version"},
+	} {
+		ns, mount := snapshotKeyLocation(key)
+		require.Equal(t, expected, [2]string{ns, mount}, key)
+	}
+}
+
+func TestOperatorRaftSnapshotDiffCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	r, raftDir := raft.GetRaft(t, true, false)
+	defer os.RemoveAll(raftDir)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	put := func(key, value string) {
+		t.Helper()
+		require.NoError(t, r.Put(ctx, &physical.Entry{Key: key, Value: []byte(value)}))
+	}
+	save := func(name string) string {
+		t.Helper()
+		f, err := os.Create(filepath.Join(dir, name))
+		require.NoError(t, err)
+		defer f.Close()
+		require.NoError(t, r.Snapshot(f, nil))
+		return f.Name()
+	}
+
+	put("logical/1234/a", "a")
+	put("logical/1234/b", "b")
+	put("sys/policy/ops", "ops")
+	from := save("from.snap")
+
+	require.NoError(t, r.Delete(ctx, "logical/1234/a"))
+	put("logical/1234/b", "a longer value")
+	put("logical/1234/c", "c")
+	put("sys/policy/ops", "ops")
+	to := save("to.snap")
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"not_enough_args",
+			[]string{from},
+			"Not enough arguments",
+			1,
+		},
+		{
+			"too_many_args",
+			[]string{from, to, to},
+			"Too many arguments",
+			1,
+		},
+		{
+			"missing_file",
+			[]string{from, filepath.Join(dir, "missing.snap")},
+			"Error reading snapshot",
+			1,
+		},
+		{
+			"default",
+			[]string{from, to},
+			"removed      root",
+			0,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ui, cmd := testOperatorRaftSnapshotDiffCommand(t)
+			code := cmd.Run(tc.args)
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+			require.Equal(t, tc.code, code, combined)
+			require.Contains(t, combined, tc.out)
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		ui, cmd := testOperatorRaftSnapshotDiffCommand(t)
+		cmd.UI = &VaultUI{Ui: ui, format: "json"}
+		code := cmd.Run([]string{"-filter=logical/", from, to})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var data SnapshotDiffOutput
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &data))
+		require.Equal(t, 1, data.Added)
+		require.Equal(t, 1, data.Removed)
+		require.Equal(t, 1, data.Changed)
+		require.Len(t, data.Mounts, 1)
+		require.Equal(t, "logical/1234/", data.Mounts[0].Mount)
+		require.Equal(t, "root", data.Mounts[0].Namespace)
+
+		changes := map[string]string{}
+		for _, k := range data.Keys {
+			changes[k.Key] = k.Change
+			if k.Key == "logical/1234/b" {
+				require.Positive(t, k.SizeDelta)
+			}
+		}
+		require.Equal(t, map[string]string{
+			"logical/1234/a": "removed",
+			"logical/1234/b": "changed",
+			"logical/1234/c": "added",
+		}, changes)
+	})
+
+	t.Run("totals_only", func(t *testing.T) {
+		t.Parallel()
+
+		ui, cmd := testOperatorRaftSnapshotDiffCommand(t)
+		code := cmd.Run([]string{"-details=false", from, to})
+		require.Equal(t, 0, code)
+		require.NotContains(t, ui.OutputWriter.String(), "logical/1234/a")
+	})
+}
+
+func TestResolveSnapshotMountNames(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	mounts, err := client.Sys().ListMounts()
+	require.NoError(t, err)
+	secretUUID := mounts["secret/"].UUID
+	require.NotEmpty(t, secretUUID)
+
+	names, err := resolveSnapshotMountNames(client)
+	require.NoError(t, err)
+
+	ns, path := names.resolve("root", "logical/"+secretUUID+"/")
+	require.Equal(t, "root", ns)
+	require.Equal(t, "secret/", path)
+	_, path = names.resolve("root", "sys/token/")
+	require.Equal(t, "auth/token/", path)
+	_, path = names.resolve("root", "core/")
+	require.Empty(t, path)
+}
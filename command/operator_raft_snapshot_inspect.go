@@ -243,12 +243,9 @@ func (c *OperatorRaftSnapshotInspectCommand) kvEnhance(val *pb.StorageEntry, inf
 	info.StatsKV[prefix] = kvs
 }
 
-// Read from snapshot's state.bin and update the SnapshotInfo struct
-func (c *OperatorRaftSnapshotInspectCommand) parseState(r io.Reader) (SnapshotInfo, error) {
-	info := SnapshotInfo{
-		StatsKV: make(map[string]typeStats),
-	}
-
+// parseSnapshotState reads the storage entries from a snapshot's state.bin,
+// calling fn with each entry and its encoded size
+func parseSnapshotState(r io.Reader, fn func(entry *pb.StorageEntry, size int)) error {
 	protoReader := protoio.NewDelimitedReader(r, math.MaxInt32)
 
 	for {
@@ -257,22 +254,38 @@ func (c *OperatorRaftSnapshotInspectCommand) parseState(r io.Reader) (SnapshotIn
 			if err == io.EOF {
 				break
 			}
-			return info, err
+			return err
 		}
-		size := protoReader.GetLastReadSize()
-		c.kvEnhance(s, &info, size)
+		fn(s, protoReader.GetLastReadSize())
 	}
 
-	return info, nil
+	return nil
 }
 
 // Read contents of snapshot. Parse metadata and snapshot info
 // Also, verify validity of snapshot
 func (c *OperatorRaftSnapshotInspectCommand) Read(logger hclog.Logger, in io.Reader) (*SnapshotInfo, *raft.SnapshotMeta, error) {
+	info := SnapshotInfo{
+		StatsKV: make(map[string]typeStats),
+	}
+	metadata, err := readSnapshot(logger, in, func(entry *pb.StorageEntry, size int) {
+		c.kvEnhance(entry, &info, size)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &info, metadata, nil
+}
+
+// readSnapshot reads a snapshot file, calling fn with each storage entry of
+// its state and the entry's encoded size, and verifies the integrity of the
+// snapshot. The storage entries are read as stored, so their values are
+// encrypted.
+func readSnapshot(logger hclog.Logger, in io.Reader, fn func(entry *pb.StorageEntry, size int)) (*raft.SnapshotMeta, error) {
 	// Wrap the reader in a gzip decompressor.
 	decomp, err := gzip.NewReader(in)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to decompress snapshot: %v", err)
+		return nil, fmt.Errorf("failed to decompress snapshot: %v", err)
 	}
 
 	defer func() {
@@ -286,20 +299,20 @@ func (c *OperatorRaftSnapshotInspectCommand) Read(logger hclog.Logger, in io.Rea
 	}()
 
 	// Read the archive.
-	snapshotInfo, metadata, err := c.read(decomp)
+	metadata, err := readSnapshotArchive(decomp, fn)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read snapshot file: %v", err)
+		return nil, fmt.Errorf("failed to read snapshot file: %v", err)
 	}
 
 	if err := concludeGzipRead(decomp); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err := decomp.Close(); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	decomp = nil
-	return snapshotInfo, metadata, nil
+	return metadata, nil
 }
 
 func formatTable(info *OutputFormat) (string, error) {
@@ -474,9 +487,10 @@ func (hl *hashList) DecodeAndVerify(r io.Reader) error {
 	return nil
 }
 
-// read takes a reader and extracts the snapshot metadata and snapshot
-// info. It also checks the integrity of the snapshot data.
-func (c *OperatorRaftSnapshotInspectCommand) read(in io.Reader) (*SnapshotInfo, *raft.SnapshotMeta, error) {
+// readSnapshotArchive takes a reader and extracts the snapshot metadata,
+// calling fn with each storage entry of the state. It also checks the
+// integrity of the snapshot data.
+func readSnapshotArchive(in io.Reader, fn func(entry *pb.StorageEntry, size int)) (*raft.SnapshotMeta, error) {
 	// Start a new tar reader.
 	archive := tar.NewReader(in)
 
@@ -492,7 +506,6 @@ func (c *OperatorRaftSnapshotInspectCommand) read(in io.Reader) (*SnapshotInfo,
 
 	// Look through the archive for the pieces we care about.
 	var shaBuffer bytes.Buffer
-	var snapshotInfo SnapshotInfo
 	var metadata raft.SnapshotMeta
 	for {
 		hdr, err := archive.Next()
@@ -500,7 +513,7 @@ func (c *OperatorRaftSnapshotInspectCommand) read(in io.Reader) (*SnapshotInfo,
 			break
 		}
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed reading snapshot: %v", err)
+			return nil, fmt.Errorf("failed reading snapshot: %v", err)
 		}
 
 		switch hdr.Name {
@@ -515,23 +528,21 @@ func (c *OperatorRaftSnapshotInspectCommand) read(in io.Reader) (*SnapshotInfo,
 			// independent of how json.Decode works internally.
 			buf, err := io.ReadAll(io.TeeReader(archive, metaHash))
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to read snapshot metadata: %v", err)
+				return nil, fmt.Errorf("failed to read snapshot metadata: %v", err)
 			}
 			if err := json.Unmarshal(buf, &metadata); err != nil {
-				return nil, nil, fmt.Errorf("failed to decode snapshot metadata: %v", err)
+				return nil, fmt.Errorf("failed to decode snapshot metadata: %v", err)
 			}
 		case "state.bin":
 			// create reader that writes to snapHash what it reads from archive
 			wrappedReader := io.TeeReader(archive, snapHash)
-			var err error
-			snapshotInfo, err = c.parseState(wrappedReader)
-			if err != nil {
-				return nil, nil, fmt.Errorf("error parsing snapshot state: %v", err)
+			if err := parseSnapshotState(wrappedReader, fn); err != nil {
+				return nil, fmt.Errorf("error parsing snapshot state: %v", err)
 			}
 
 		case "SHA256SUMS":
 			if _, err := io.CopyN(&shaBuffer, archive, 10000); err != nil && err != io.EOF {
-				return nil, nil, fmt.Errorf("failed to read snapshot hashes: %v", err)
+				return nil, fmt.Errorf("failed to read snapshot hashes: %v", err)
 			}
 
 		case "SHA256SUMS.sealed":
@@ -539,16 +550,16 @@ func (c *OperatorRaftSnapshotInspectCommand) read(in io.Reader) (*SnapshotInfo,
 			continue
 
 		default:
-			return nil, nil, fmt.Errorf("unexpected file %q in snapshot", hdr.Name)
+			return nil, fmt.Errorf("unexpected file %q in snapshot", hdr.Name)
 		}
 	}
 
 	// Verify all the hashes.
 	if err := hl.DecodeAndVerify(&shaBuffer); err != nil {
-		return nil, nil, fmt.Errorf("failed checking integrity of snapshot: %v", err)
+		return nil, fmt.Errorf("failed checking integrity of snapshot: %v", err)
 	}
 
-	return &snapshotInfo, &metadata, nil
+	return &metadata, nil
 }
 
 // concludeGzipRead should be invoked after you think you've consumed all of
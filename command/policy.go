@@ -39,6 +39,14 @@ Usage: vault policy <subcommand> [options] [args]
 
       $ vault policy delete my-policy
 
+  Print the version history of the policy named my-policy:
+
+      $ vault policy history my-policy
+
+  Check the local policy file "my-policy.hcl" for likely mistakes:
+
+      $ vault policy lint my-policy.hcl
+
   Please see the individual subcommand help for detailed usage information.
 `
 
@@ -0,0 +1,180 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*PolicyHistoryCommand)(nil)
+	_ cli.CommandAutocomplete = (*PolicyHistoryCommand)(nil)
+)
+
+type PolicyHistoryCommand struct {
+	*BaseCommand
+
+	flagDiff     bool
+	flagRollback int
+}
+
+func (c *PolicyHistoryCommand) Synopsis() string {
+	return "Prints the version history of a policy"
+}
+
+func (c *PolicyHistoryCommand) Help() string {
+	helpText := `
+Usage: vault policy history [options] NAME
+
+  Prints the versions of the Vault policy named NAME that are kept in its
+  history, with their author and creation time. A version is recorded each
+  time the policy is changed or deleted.
+
+  Print the history of the policy named "my-policy":
+
+      $ vault policy history my-policy
+
+  Print the history with the diff of each version from the previous one:
+
+      $ vault policy history -diff my-policy
+
+  Roll the policy back to version 3:
+
+      $ vault policy history -rollback=3 my-policy
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PolicyHistoryCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+	f := set.NewFlagSet("Command Options")
+
+	f.BoolVar(&BoolVar{
+		Name:    "diff",
+		Target:  &c.flagDiff,
+		Default: false,
+		Usage:   "Print the diff of each version from the previous version.",
+	})
+
+	f.IntVar(&IntVar{
+		Name:    "rollback",
+		Target:  &c.flagRollback,
+		Default: 0,
+		Usage: "Roll the policy back to the given version from its history. " +
+			"The rollback is recorded as a new version.",
+	})
+
+	return set
+}
+
+func (c *PolicyHistoryCommand) AutocompleteArgs() complete.Predictor {
+	return c.PredictVaultPolicies()
+}
+
+func (c *PolicyHistoryCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *PolicyHistoryCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	switch {
+	case len(args) < 1:
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected 1, got %d)", len(args)))
+		return 1
+	case len(args) > 1:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+		return 1
+	case c.flagRollback < 0:
+		c.UI.Error("Version to roll back to must be a positive integer")
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	name := strings.ToLower(strings.TrimSpace(args[0]))
+
+	if c.flagRollback > 0 {
+		version, err := client.Sys().RollbackPolicy(name, c.flagRollback)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error rolling back policy named %s: %s", name, err))
+			return 2
+		}
+		if Format(c.UI) != "table" {
+			return OutputData(c.UI, version)
+		}
+		c.UI.Output(fmt.Sprintf("Success! Rolled back policy %s to version %d as version %d", name, c.flagRollback, version.Version))
+		return 0
+	}
+
+	history, err := client.Sys().PolicyVersions(name)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading history of policy named %s: %s", name, err))
+		return 2
+	}
+	if history == nil {
+		c.UI.Error(fmt.Sprintf("No history for policy named: %s", name))
+		return 2
+	}
+
+	if Format(c.UI) != "table" {
+		return OutputData(c.UI, history)
+	}
+
+	out := []string{"Version | Created | Change | Entity ID | Accessor"}
+	for _, version := range history.Versions {
+		created := "n/a"
+		if !version.CreatedTime.IsZero() {
+			created = version.CreatedTime.Format(time.RFC3339)
+		}
+		change := "write"
+		switch {
+		case version.Deleted:
+			change = "delete"
+		case version.RestoredVersion > 0:
+			change = "rollback to " + strconv.Itoa(version.RestoredVersion)
+		case version.CreatedTime.IsZero():
+			change = "existing"
+		}
+		out = append(out, fmt.Sprintf("%d | %s | %s | %s | %s",
+			version.Version, created, change, orNA(version.EntityID), orNA(version.Accessor)))
+	}
+	c.UI.Output(tableOutput(out, nil))
+
+	if c.flagDiff {
+		for _, version := range history.Versions {
+			if version.Diff == "" {
+				continue
+			}
+			c.UI.Output("")
+			c.UI.Output(strings.TrimRight(version.Diff, "\n"))
+		}
+	}
+	return 0
+}
+
+func orNA(s string) string {
+	if s == "" {
+		return "n/a"
+	}
+	return s
+}
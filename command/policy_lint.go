@@ -0,0 +1,271 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/cli"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/vault"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*PolicyLintCommand)(nil)
+	_ cli.CommandAutocomplete = (*PolicyLintCommand)(nil)
+)
+
+type PolicyLintCommand struct {
+	*BaseCommand
+
+	flagCheckMounts bool
+	flagServer      bool
+
+	testStdin io.Reader // for tests
+}
+
+// policyLintResult is a finding of the linter in a policy file.
+type policyLintResult struct {
+	File     string `json:"file"`
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+func (c *PolicyLintCommand) Synopsis() string {
+	return "Statically analyzes policies on disk"
+}
+
+func (c *PolicyLintCommand) Help() string {
+	helpText := `
+Usage: vault policy lint [options] PATH...
+
+  Statically analyzes the ACL policy files at the given PATHs and reports
+  rules that likely do not do what is intended: rules shadowed by a more
+  specific rule, deny rules that never match or are overridden, globs
+  broader than intended, and parameter constraints on rules that grant no
+  operation checking parameters. If PATH is "-", the policy is read from
+  stdin.
+
+  The command exits with 0 if no issues are found, 2 if issues are found or
+  an error occurs, and 1 on usage errors, so it can be used in CI.
+
+  Lint the local file "my-policy.hcl":
+
+      $ vault policy lint my-policy.hcl
+
+  Also report rules outside of the mounts of the target server, as JSON:
+
+      $ vault policy lint -check-mounts -format=json policies/*.hcl
+
+  Lint on the server through the sys/policies/lint endpoint:
+
+      $ vault policy lint -server my-policy.hcl
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PolicyLintCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+	f := set.NewFlagSet("Command Options")
+
+	f.BoolVar(&BoolVar{
+		Name:    "check-mounts",
+		Target:  &c.flagCheckMounts,
+		Default: false,
+		Usage: "Report rules on paths outside of the secret and auth mounts " +
+			"of the target server.",
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:    "server",
+		Target:  &c.flagServer,
+		Default: false,
+		Usage: "Lint the policies on the target server instead of locally. " +
+			"The policies are not written.",
+	})
+
+	return set
+}
+
+func (c *PolicyLintCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*.hcl")
+}
+
+func (c *PolicyLintCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *PolicyLintCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	if len(args) < 1 {
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected at least 1, got %d)", len(args)))
+		return 1
+	}
+
+	var client *api.Client
+	var mounts []string
+	if c.flagServer || c.flagCheckMounts {
+		var err error
+		client, err = c.Client()
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 2
+		}
+	}
+	if c.flagCheckMounts && !c.flagServer {
+		var err error
+		mounts, err = c.mountPaths(client)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error listing mounts: %s", err))
+			return 2
+		}
+	}
+
+	results := []*policyLintResult{}
+	for _, path := range args {
+		path = strings.TrimSpace(path)
+		rules, err := c.readPolicy(path)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading policy %s: %s", path, err))
+			return 2
+		}
+
+		var findings []*api.PolicyLintFinding
+		if c.flagServer {
+			findings, err = client.Sys().LintPolicy(&api.LintPolicyInput{
+				Policy:      rules,
+				CheckMounts: c.flagCheckMounts,
+			})
+		} else {
+			findings, err = lintPolicy(rules, mounts)
+		}
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error linting policy %s: %s", path, err))
+			return 2
+		}
+
+		for _, finding := range findings {
+			results = append(results, &policyLintResult{
+				File:     path,
+				Check:    finding.Check,
+				Severity: finding.Severity,
+				Rule:     finding.Rule,
+				Message:  finding.Message,
+			})
+		}
+	}
+
+	code := 0
+	if len(results) > 0 {
+		code = 2
+	}
+
+	if Format(c.UI) != "table" {
+		if ret := OutputData(c.UI, results); ret != 0 {
+			return ret
+		}
+		return code
+	}
+
+	if len(results) == 0 {
+		c.UI.Output("Success! No issues found")
+		return 0
+	}
+	out := []string{"File | Rule | Severity | Check | Message"}
+	for _, result := range results {
+		out = append(out, fmt.Sprintf("%s | %s | %s | %s | %s",
+			result.File, result.Rule, result.Severity, result.Check, result.Message))
+	}
+	c.UI.Output(tableOutput(out, nil))
+	return code
+}
+
+// readPolicy reads the policy at the given path, or from stdin if the path is
+// "-".
+func (c *PolicyLintCommand) readPolicy(path string) (string, error) {
+	var reader io.Reader
+	if path == "-" {
+		reader = os.Stdin
+		if c.testStdin != nil {
+			reader = c.testStdin
+		}
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, reader); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// mountPaths returns the paths of the secret and auth mounts of the namespace
+// of the client, with auth mount paths prefixed by "auth/".
+func (c *PolicyLintCommand) mountPaths(client *api.Client) ([]string, error) {
+	mounts, err := client.Sys().ListMounts()
+	if err != nil {
+		return nil, err
+	}
+	auths, err := client.Sys().ListAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(mounts)+len(auths))
+	for path := range mounts {
+		paths = append(paths, path)
+	}
+	for path := range auths {
+		paths = append(paths, "auth/"+path)
+	}
+	return paths, nil
+}
+
+// lintPolicy lints a policy locally. Policies are parsed in the root namespace,
+// so rule paths are relative to the namespace of the mounts.
+func lintPolicy(rules string, mounts []string) ([]*api.PolicyLintFinding, error) {
+	policy, err := vault.ParseACLPolicy(namespace.RootNamespace, rules)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := vault.LintACLPolicy(namespace.RootContext(context.Background()), policy, mounts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*api.PolicyLintFinding, 0, len(findings))
+	for _, finding := range findings {
+		ret = append(ret, &api.PolicyLintFinding{
+			Check:    finding.Check,
+			Severity: finding.Severity,
+			Rule:     finding.Rule,
+			Message:  finding.Message,
+		})
+	}
+	return ret, nil
+}
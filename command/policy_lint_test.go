@@ -0,0 +1,200 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/cli"
+)
+
+func testPolicyLintCommand(tb testing.TB) (*cli.MockUi, *PolicyLintCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &PolicyLintCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func testPolicyLintFile(tb testing.TB, rules string) string {
+	tb.Helper()
+
+	f, err := os.CreateTemp(tb.TempDir(), "vault-policy-lint")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(rules); err != nil {
+		tb.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestPolicyLintCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	policy := `
+path "secret/*" {
+  capabilities = ["read", "sudo"]
+}
+
+path "missing/config" {
+  capabilities = ["read"]
+}
+`
+
+	cases := []struct {
+		name  string
+		args  []string
+		rules string
+		out   string
+		code  int
+	}{
+		{
+			"not_enough_args",
+			[]string{},
+			"",
+			"Not enough arguments",
+			1,
+		},
+		{
+			"bad_policy",
+			nil,
+			`path "secret/" { capabilities = ["bogus"] }`,
+			"Error linting policy",
+			2,
+		},
+		{
+			"clean_policy",
+			nil,
+			`path "secret/data/app" { capabilities = ["read"] }`,
+			"Success! No issues found",
+			0,
+		},
+		{
+			"findings",
+			nil,
+			policy,
+			"grants sudo on every path",
+			2,
+		},
+	}
+
+	t.Run("validations", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range cases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				args := tc.args
+				if tc.rules != "" {
+					args = []string{testPolicyLintFile(t, tc.rules)}
+				}
+
+				ui, cmd := testPolicyLintCommand(t)
+
+				code := cmd.Run(args)
+				if code != tc.code {
+					t.Errorf("expected %d to be %d", code, tc.code)
+				}
+
+				combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+				if !strings.Contains(combined, tc.out) {
+					t.Errorf("expected %q to contain %q", combined, tc.out)
+				}
+			})
+		}
+	})
+
+	t.Run("check_mounts", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		ui, cmd := testPolicyLintCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-check-mounts",
+			testPolicyLintFile(t, policy),
+		})
+		if exp := 2; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		for _, expected := range []string{"broad_glob", "missing_mount", "no mount exists"} {
+			if !strings.Contains(combined, expected) {
+				t.Errorf("expected %q to contain %q", combined, expected)
+			}
+		}
+	})
+
+	t.Run("server", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		ui, cmd := testPolicyLintCommand(t)
+		cmd.client = client
+		cmd.testStdin = strings.NewReader(policy)
+
+		code := cmd.Run([]string{
+			"-server",
+			"-check-mounts",
+			"-",
+		})
+		if exp := 2; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		for _, expected := range []string{"broad_glob", "missing_mount"} {
+			if !strings.Contains(combined, expected) {
+				t.Errorf("expected %q to contain %q", combined, expected)
+			}
+		}
+	})
+
+	t.Run("communication_failure", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServerBad(t)
+		defer closer()
+
+		ui, cmd := testPolicyLintCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-server",
+			testPolicyLintFile(t, policy),
+		})
+		if exp := 2; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		expected := "Error linting policy "
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+	})
+
+	t.Run("no_tabs", func(t *testing.T) {
+		t.Parallel()
+
+		_, cmd := testPolicyLintCommand(t)
+		assertNoTabs(t, cmd)
+	})
+}
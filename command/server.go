@@ -1362,6 +1362,15 @@ func (c *ServerCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Record the changes made during online storage migrations. Raft storage
+	// can't be migrated while in use, as its files are locked.
+	var migrationJournal *StorageMigrationJournal
+	if backend != nil && config.EnableOnlineStorageMigration && config.Storage.Type != storageTypeRaft {
+		journalLogger := c.logger.Named("storage-migration")
+		c.allLoggers = append(c.allLoggers, journalLogger)
+		coreConfig.Physical, migrationJournal = NewStorageMigrationJournal(coreConfig.Physical, journalLogger)
+	}
+
 	// Override the UI enabling config by the environment variable
 	if enableUI := os.Getenv("VAULT_UI"); enableUI != "" {
 		var err error
@@ -1414,6 +1423,16 @@ func (c *ServerCommand) Run(args []string) int {
 
 	}
 
+	if migrationJournal != nil {
+		migrationJournal.SetActiveCheck(func() bool {
+			standby, _ := core.Standby()
+			return !standby
+		})
+		journalStopCh := make(chan struct{})
+		defer close(journalStopCh)
+		go migrationJournal.Run(journalStopCh)
+	}
+
 	// Copy the reload funcs pointers back
 	c.reloadFuncs = coreConfig.ReloadFuncs
 	c.reloadFuncsLock = coreConfig.ReloadFuncsLock
@@ -2420,6 +2439,12 @@ func (c *ServerCommand) storageMigrationActive(backend physical.Backend) bool {
 	for {
 		migrationStatus, err := CheckStorageMigration(backend)
 		if err == nil {
+			if migrationStatus != nil && migrationStatus.Online && !migrationStatus.Cutover {
+				c.UI.Warn(wrapAtLength(fmt.Sprintf("WARNING! Online storage migration in progress (started: %s). "+
+					"Changes to the storage will be recorded for the migration.",
+					migrationStatus.Start.Format(time.RFC3339))))
+				return false
+			}
 			if migrationStatus != nil {
 				startTime := migrationStatus.Start.Format(time.RFC3339)
 				c.UI.Error(wrapAtLength(fmt.Sprintf("ERROR! Storage migration in progress (started: %s). "+
@@ -2450,6 +2475,12 @@ func (c *ServerCommand) storageMigrationActive(backend physical.Backend) bool {
 
 type StorageMigrationStatus struct {
 	Start time.Time `json:"start"`
+
+	// Online is set for migrations that copy the storage while Vault is
+	// running. Vault records the changes made in the meantime, until the
+	// cutover disables the writes.
+	Online  bool `json:"online,omitempty"`
+	Cutover bool `json:"cutover,omitempty"`
 }
 
 func CheckStorageMigration(b physical.Backend) (*StorageMigrationStatus, error) {
@@ -3436,10 +3467,12 @@ func SetStorageMigration(b physical.Backend, active bool) error {
 		return b.Delete(context.Background(), storageMigrationLock)
 	}
 
-	status := StorageMigrationStatus{
+	return setStorageMigrationStatus(b, &StorageMigrationStatus{
 		Start: time.Now(),
-	}
+	})
+}
 
+func setStorageMigrationStatus(b physical.Backend, status *StorageMigrationStatus) error {
 	enc, err := jsonutil.EncodeJSON(status)
 	if err != nil {
 		return err
@@ -126,6 +126,9 @@ type Config struct {
 	EnableResponseHeaderRaftNodeID    bool        `hcl:"-"`
 	EnableResponseHeaderRaftNodeIDRaw interface{} `hcl:"enable_response_header_raft_node_id"`
 
+	EnableOnlineStorageMigration    bool        `hcl:"-"`
+	EnableOnlineStorageMigrationRaw interface{} `hcl:"enable_online_storage_migration"`
+
 	License          string `hcl:"-"`
 	LicensePath      string `hcl:"license_path"`
 	DisableSSCTokens bool   `hcl:"-"`
@@ -470,6 +473,11 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.EnableResponseHeaderRaftNodeID = c2.EnableResponseHeaderRaftNodeID
 	}
 
+	result.EnableOnlineStorageMigration = c.EnableOnlineStorageMigration
+	if c2.EnableOnlineStorageMigration {
+		result.EnableOnlineStorageMigration = c2.EnableOnlineStorageMigration
+	}
+
 	result.LicensePath = c.LicensePath
 	if c2.LicensePath != "" {
 		result.LicensePath = c2.LicensePath
@@ -824,6 +832,12 @@ func ParseConfigCheckDuplicate(d, source string) (cfg *Config, duplicate bool, e
 		}
 	}
 
+	if result.EnableOnlineStorageMigrationRaw != nil {
+		if result.EnableOnlineStorageMigration, err = parseutil.ParseBool(result.EnableOnlineStorageMigrationRaw); err != nil {
+			return nil, duplicate, err
+		}
+	}
+
 	list, ok := obj.Node.(*ast.ObjectList)
 	if !ok {
 		return nil, duplicate, fmt.Errorf("error parsing: file doesn't contain a root object")
@@ -1426,6 +1440,8 @@ func (c *Config) Sanitized() map[string]interface{} {
 
 		"enable_response_header_raft_node_id": c.EnableResponseHeaderRaftNodeID,
 
+		"enable_online_storage_migration": c.EnableOnlineStorageMigration,
+
 		"log_requests_level": c.LogRequestsLevel,
 		"experiments":        c.Experiments,
 
@@ -0,0 +1,304 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/physical"
+)
+
+const (
+	// storageMigrationJournalPrefix holds the state of online migrations,
+	// which isn't migrated itself.
+	storageMigrationJournalPrefix = "core/migration-journal/"
+	storageMigrationChangesPrefix = storageMigrationJournalPrefix + "changes/"
+	storageMigrationAckPath       = storageMigrationJournalPrefix + "ack"
+)
+
+// storageMigrationPollInterval is how often the server checks for changes of
+// the migration status.
+var storageMigrationPollInterval = time.Second
+
+var errStorageMigrationCutover = errors.New("storage migration cutover in progress, writes are disabled")
+
+// storageMigrationChange is a change recorded for an online migration. Only
+// the keys are recorded, the migration copies their current values.
+type storageMigrationChange struct {
+	Keys []string `json:"keys"`
+}
+
+// storageMigrationAck is written by the active node once it applied the
+// migration status, so that the migration knows the changes are being
+// recorded or that the writes are disabled. Standbys don't write to the
+// storage, so only the active node acknowledges.
+type storageMigrationAck struct {
+	Start   time.Time `json:"start"`
+	Cutover bool      `json:"cutover"`
+}
+
+// StorageMigrationJournal records the keys written to the storage while an
+// online migration copies it, and disables the writes during the cutover.
+type StorageMigrationJournal struct {
+	logger  log.Logger
+	backend physical.Backend
+
+	// l is held for reading by writes, so that a cutover waits for the
+	// writes in flight.
+	l      sync.RWMutex
+	status *StorageMigrationStatus
+
+	// isActive reports whether the node is the active node. It is nil until
+	// the core is set up, and the node isn't active until then.
+	isActive func() bool
+	// polledActive is whether the status was last read while the node was
+	// active, and acked whether it was acknowledged.
+	polledActive atomic.Bool
+	acked        atomic.Bool
+}
+
+// TransactionalStorageMigrationJournal is the transactional version of the
+// storage migration journal.
+type TransactionalStorageMigrationJournal struct {
+	*StorageMigrationJournal
+	Transactional physical.Transactional
+}
+
+var (
+	_ physical.Backend       = (*StorageMigrationJournal)(nil)
+	_ physical.Transactional = (*TransactionalStorageMigrationJournal)(nil)
+)
+
+// NewStorageMigrationJournal wraps the backend in a storage migration journal,
+// keeping the backend transactional if it is. The migration status is read
+// before returning, so that no write is missed when the server starts during
+// an online migration.
+func NewStorageMigrationJournal(b physical.Backend, logger log.Logger) (physical.Backend, *StorageMigrationJournal) {
+	j := &StorageMigrationJournal{
+		logger:  logger,
+		backend: b,
+	}
+	j.poll()
+
+	if txn, ok := b.(physical.Transactional); ok {
+		return &TransactionalStorageMigrationJournal{
+			StorageMigrationJournal: j,
+			Transactional:           txn,
+		}, j
+	}
+	return j, j
+}
+
+// SetActiveCheck sets the function reporting whether the node is the active
+// node, which is the only one to acknowledge the migration status. It is
+// never called while handling a storage operation.
+func (j *StorageMigrationJournal) SetActiveCheck(isActive func() bool) {
+	j.l.Lock()
+	defer j.l.Unlock()
+	j.isActive = isActive
+}
+
+func (j *StorageMigrationJournal) active() bool {
+	j.l.RLock()
+	isActive := j.isActive
+	j.l.RUnlock()
+	return isActive != nil && isActive()
+}
+
+// Run checks the migration status until stopCh is closed.
+func (j *StorageMigrationJournal) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(storageMigrationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			j.poll()
+		}
+	}
+}
+
+// poll applies the migration status, and acknowledges it if the node is the
+// active node. It is only called outside of requests, as checking whether the
+// node is active takes the state lock of the core.
+func (j *StorageMigrationJournal) poll() {
+	// Checked before reading the status, so that a status read while active
+	// is never older than the activation
+	active := j.active()
+
+	status, changed, err := j.refresh()
+	if err != nil {
+		j.logger.Warn("failed to check storage migration status", "error", err)
+		return
+	}
+	j.polledActive.Store(active)
+
+	// A node that became active acknowledges the status it applied, even if
+	// it didn't change, as the migration waits for the active node
+	if status == nil || !active {
+		j.acked.Store(false)
+		return
+	}
+	if !changed && j.acked.Load() {
+		return
+	}
+
+	ack, err := jsonutil.EncodeJSON(&storageMigrationAck{
+		Start:   status.Start,
+		Cutover: status.Cutover,
+	})
+	if err == nil {
+		err = j.backend.Put(context.Background(), &physical.Entry{
+			Key:   storageMigrationAckPath,
+			Value: ack,
+		})
+	}
+	if err != nil {
+		j.logger.Error("failed to acknowledge storage migration status", "error", err)
+		return
+	}
+	j.acked.Store(true)
+}
+
+// refresh reads and applies the migration status, and returns it along with
+// whether it changed.
+func (j *StorageMigrationJournal) refresh() (*StorageMigrationStatus, bool, error) {
+	status, err := CheckStorageMigration(j.backend)
+	if err != nil {
+		return nil, false, err
+	}
+	if status != nil && !status.Online {
+		status = nil
+	}
+
+	j.l.RLock()
+	current := j.status
+	j.l.RUnlock()
+	if current == nil && status == nil ||
+		current != nil && status != nil && current.Start.Equal(status.Start) && current.Cutover == status.Cutover {
+		return status, false, nil
+	}
+
+	j.l.Lock()
+	j.status = status
+	j.l.Unlock()
+
+	switch {
+	case status == nil:
+		j.logger.Info("online storage migration ended")
+	case status.Cutover:
+		j.logger.Warn("online storage migration cutover started, writes are disabled")
+	default:
+		j.logger.Info("online storage migration started, recording changes", "start", status.Start.Format(time.RFC3339))
+	}
+	return status, true, nil
+}
+
+// write runs fn, recording the keys for the migration when it succeeds.
+func (j *StorageMigrationJournal) write(ctx context.Context, keys []string, fn func() error) error {
+	// Standbys don't write, so a write on a node that wasn't active when it
+	// last read the status is from a node becoming active, which may have
+	// read the status up to a poll interval ago. The status is read again
+	// until the node is seen active.
+	if !j.polledActive.Load() {
+		if _, _, err := j.refresh(); err != nil {
+			return fmt.Errorf("failed to check storage migration status: %w", err)
+		}
+	}
+
+	j.l.RLock()
+	defer j.l.RUnlock()
+
+	if j.status != nil && j.status.Cutover {
+		return errStorageMigrationCutover
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	if j.status == nil {
+		return nil
+	}
+
+	// The changes are recorded once written, so that the migration can't
+	// copy a value older than the recorded change.
+	change := storageMigrationChange{}
+	for _, key := range keys {
+		if key != storageMigrationLock && !strings.HasPrefix(key, storageMigrationJournalPrefix) {
+			change.Keys = append(change.Keys, key)
+		}
+	}
+	if len(change.Keys) == 0 {
+		return nil
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return fmt.Errorf("failed to record change for storage migration: %w", err)
+	}
+	value, err := jsonutil.EncodeJSON(&change)
+	if err != nil {
+		return fmt.Errorf("failed to record change for storage migration: %w", err)
+	}
+	if err := j.backend.Put(ctx, &physical.Entry{
+		Key:   fmt.Sprintf("%s%020d-%s", storageMigrationChangesPrefix, time.Now().UnixNano(), id),
+		Value: value,
+	}); err != nil {
+		return fmt.Errorf("failed to record change for storage migration: %w", err)
+	}
+	return nil
+}
+
+func (j *StorageMigrationJournal) Put(ctx context.Context, entry *physical.Entry) error {
+	return j.write(ctx, []string{entry.Key}, func() error {
+		return j.backend.Put(ctx, entry)
+	})
+}
+
+func (j *StorageMigrationJournal) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	return j.backend.Get(ctx, key)
+}
+
+func (j *StorageMigrationJournal) Delete(ctx context.Context, key string) error {
+	return j.write(ctx, []string{key}, func() error {
+		return j.backend.Delete(ctx, key)
+	})
+}
+
+func (j *StorageMigrationJournal) List(ctx context.Context, prefix string) ([]string, error) {
+	return j.backend.List(ctx, prefix)
+}
+
+func (j *TransactionalStorageMigrationJournal) Transaction(ctx context.Context, txns []*physical.TxnEntry) error {
+	keys := make([]string, 0, len(txns))
+	for _, txn := range txns {
+		if txn.Operation != physical.GetOperation {
+			keys = append(keys, txn.Entry.Key)
+		}
+	}
+	return j.write(ctx, keys, func() error {
+		return j.Transactional.Transaction(ctx, txns)
+	})
+}
+
+// TransactionLimits implements physical.TransactionalLimits
+func (j *TransactionalStorageMigrationJournal) TransactionLimits() (int, int) {
+	if tl, ok := j.Transactional.(physical.TransactionalLimits); ok {
+		return tl.TransactionLimits()
+	}
+	// We don't have any specific limits of our own so return zeros to signal that
+	// the caller should use whatever reasonable defaults it would if it used a
+	// non-TransactionalLimits backend.
+	return 0, 0
+}
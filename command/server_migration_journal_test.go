@@ -0,0 +1,172 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/physical"
+	"github.com/hashicorp/vault/sdk/physical/inmem"
+)
+
+// recordedKeys returns the keys of the changes recorded in the storage.
+func recordedKeys(t *testing.T, b physical.Backend) []string {
+	t.Helper()
+
+	ctx := context.Background()
+	names, err := b.List(ctx, storageMigrationChangesPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, name := range names {
+		entry, err := b.Get(ctx, storageMigrationChangesPrefix+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var change storageMigrationChange
+		if err := jsonutil.DecodeJSON(entry.Value, &change); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, change.Keys...)
+	}
+	return keys
+}
+
+func TestStorageMigrationJournal(t *testing.T) {
+	ctx := context.Background()
+	b, err := inmem.NewTransactionalInmem(nil, log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrapped, journal := NewStorageMigrationJournal(b, log.NewNullLogger())
+	journal.SetActiveCheck(func() bool { return true })
+	txn, ok := wrapped.(physical.Transactional)
+	if !ok {
+		t.Fatal("expected the journal of a transactional backend to be transactional")
+	}
+
+	// Nothing is recorded without an online migration
+	if err := wrapped.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	if keys := recordedKeys(t, b); len(keys) != 0 {
+		t.Fatalf("expected no changes, got %v", keys)
+	}
+	if err := SetStorageMigration(b, true); err != nil {
+		t.Fatal(err)
+	}
+	journal.poll()
+	if err := wrapped.Delete(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if keys := recordedKeys(t, b); len(keys) != 0 {
+		t.Fatalf("expected no changes for an offline migration, got %v", keys)
+	}
+
+	status := &StorageMigrationStatus{
+		Start:  time.Now(),
+		Online: true,
+	}
+	if err := setStorageMigrationStatus(b, status); err != nil {
+		t.Fatal(err)
+	}
+	journal.poll()
+	if err := waitForStorageMigrationAck(ctx, b, status); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wrapped.Put(ctx, &physical.Entry{Key: "a", Value: []byte("a")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wrapped.Delete(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Transaction(ctx, []*physical.TxnEntry{
+		{Operation: physical.PutOperation, Entry: &physical.Entry{Key: "c", Value: []byte("c")}},
+		{Operation: physical.GetOperation, Entry: &physical.Entry{Key: "a"}},
+		{Operation: physical.DeleteOperation, Entry: &physical.Entry{Key: "d"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if keys := recordedKeys(t, b); !reflect.DeepEqual(keys, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("unexpected changes: %v", keys)
+	}
+
+	status.Cutover = true
+	if err := setStorageMigrationStatus(b, status); err != nil {
+		t.Fatal(err)
+	}
+	journal.poll()
+	if err := waitForStorageMigrationAck(ctx, b, status); err != nil {
+		t.Fatal(err)
+	}
+	if err := wrapped.Put(ctx, &physical.Entry{Key: "a", Value: []byte("b")}); !errors.Is(err, errStorageMigrationCutover) {
+		t.Fatalf("expected writes to be rejected, got %v", err)
+	}
+	if _, err := wrapped.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes are enabled again once the migration lock is reset
+	if err := SetStorageMigration(b, false); err != nil {
+		t.Fatal(err)
+	}
+	journal.poll()
+	if err := wrapped.Put(ctx, &physical.Entry{Key: "a", Value: []byte("b")}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStorageMigrationJournal_Standby(t *testing.T) {
+	ctx := context.Background()
+	b, err := inmem.NewInmem(nil, log.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var active atomic.Bool
+	wrapped, journal := NewStorageMigrationJournal(b, log.NewNullLogger())
+	journal.SetActiveCheck(active.Load)
+
+	status := &StorageMigrationStatus{
+		Start:  time.Now(),
+		Online: true,
+	}
+	if err := setStorageMigrationStatus(b, status); err != nil {
+		t.Fatal(err)
+	}
+
+	// Standbys don't acknowledge the migration
+	journal.poll()
+	if entry, err := b.Get(ctx, storageMigrationAckPath); err != nil {
+		t.Fatal(err)
+	} else if entry != nil {
+		t.Fatal("expected no acknowledgement from a standby")
+	}
+
+	// A node becoming active reads the status before writing, even before
+	// it is polled again
+	status.Cutover = true
+	if err := setStorageMigrationStatus(b, status); err != nil {
+		t.Fatal(err)
+	}
+	active.Store(true)
+	if err := wrapped.Put(ctx, &physical.Entry{Key: "a", Value: []byte("a")}); !errors.Is(err, errStorageMigrationCutover) {
+		t.Fatalf("expected writes to be rejected, got %v", err)
+	}
+
+	journal.poll()
+	if err := waitForStorageMigrationAck(ctx, b, status); err != nil {
+		t.Fatal(err)
+	}
+}
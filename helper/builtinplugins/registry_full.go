@@ -12,7 +12,6 @@ import (
 	credAzure "github.com/hashicorp/vault-plugin-auth-azure"
 	credCF "github.com/hashicorp/vault-plugin-auth-cf"
 	credGcp "github.com/hashicorp/vault-plugin-auth-gcp/plugin"
-	credKerb "github.com/hashicorp/vault-plugin-auth-kerberos"
 	credKube "github.com/hashicorp/vault-plugin-auth-kubernetes"
 	credOCI "github.com/hashicorp/vault-plugin-auth-oci"
 	dbCouchbase "github.com/hashicorp/vault-plugin-database-couchbase"
@@ -32,7 +31,9 @@ import (
 	logicalTerraform "github.com/hashicorp/vault-plugin-secrets-terraform"
 	credAws "github.com/hashicorp/vault/builtin/credential/aws"
 	credGitHub "github.com/hashicorp/vault/builtin/credential/github"
+	credKerb "github.com/hashicorp/vault/builtin/credential/kerberos"
 	credLdap "github.com/hashicorp/vault/builtin/credential/ldap"
+	credOAuth2 "github.com/hashicorp/vault/builtin/credential/oauth2"
 	credOkta "github.com/hashicorp/vault/builtin/credential/okta"
 	credRadius "github.com/hashicorp/vault/builtin/credential/radius"
 	logicalAws "github.com/hashicorp/vault/builtin/logical/aws"
@@ -42,6 +43,7 @@ import (
 	logicalTotp "github.com/hashicorp/vault/builtin/logical/totp"
 	"github.com/hashicorp/vault/helper/pluginconsts"
 	dbCass "github.com/hashicorp/vault/plugins/database/cassandra"
+	dbClickHouse "github.com/hashicorp/vault/plugins/database/clickhouse"
 	dbHana "github.com/hashicorp/vault/plugins/database/hana"
 	dbInflux "github.com/hashicorp/vault/plugins/database/influxdb"
 	dbMongo "github.com/hashicorp/vault/plugins/database/mongodb"
@@ -68,6 +70,7 @@ func newFullAddonRegistry() *registry {
 			pluginconsts.AuthTypeKerberos:   {Factory: credKerb.Factory},
 			pluginconsts.AuthTypeKubernetes: {Factory: credKube.Factory},
 			pluginconsts.AuthTypeLDAP:       {Factory: credLdap.Factory},
+			pluginconsts.AuthTypeOAuth2:     {Factory: credOAuth2.Factory},
 			pluginconsts.AuthTypeOCI:        {Factory: credOCI.Factory},
 			pluginconsts.AuthTypeOkta:       {Factory: credOkta.Factory},
 			pluginconsts.AuthTypePCF: {
@@ -85,6 +88,7 @@ func newFullAddonRegistry() *registry {
 			"mysql-legacy-database-plugin": {Factory: dbMysql.New(dbMysql.DefaultLegacyUserNameTemplate)},
 
 			"cassandra-database-plugin":         {Factory: dbCass.New},
+			"clickhouse-database-plugin":        {Factory: dbClickHouse.New},
 			"couchbase-database-plugin":         {Factory: dbCouchbase.New},
 			"elasticsearch-database-plugin":     {Factory: dbElastic.New},
 			"hana-database-plugin":              {Factory: dbHana.New},
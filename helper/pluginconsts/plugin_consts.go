@@ -16,6 +16,7 @@ const (
 	AuthTypeKubernetes        = "kubernetes"
 	AuthTypeLDAP              = "ldap"
 	AuthTypeOCI               = "oci"
+	AuthTypeOAuth2            = "oauth2"
 	AuthTypeOkta              = "okta"
 	AuthTypePCF               = "pcf"
 	AuthTypeRadius            = "radius"
@@ -71,6 +72,7 @@ const (
 // These DB consts match the type returned from database plugin's Type() method.
 const (
 	DbCassandraPluginType        = "cassandra"
+	DbClickHousePluginType       = "clickhouse"
 	DbCouchbasePluginType        = "couchbase"
 	DbElasticSearchPluginType    = "elasticsearch"
 	DbHanaPluginType             = "hdb"
@@ -84,4 +86,5 @@ const (
 	DbRedisPluginType            = "redis"
 	DbRedisElasticachePluginType = "redisElastiCache"
 	DbSnowflakePluginType        = "snowflake"
+	DbSQLitePluginType           = "sqlite"
 )
@@ -0,0 +1,146 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+	"github.com/stretchr/testify/require"
+)
+
+// dpopRecordingTransport records the last request sent with a DPoP proof.
+type dpopRecordingTransport struct {
+	base http.RoundTripper
+
+	l    sync.Mutex
+	last *http.Request
+}
+
+func (t *dpopRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(consts.DPoPHeaderName) != "" {
+		t.l.Lock()
+		t.last = req.Clone(context.Background())
+		t.l.Unlock()
+	}
+	return t.base.RoundTrip(req)
+}
+
+// TestHandler_DPoP logs in with a DPoP proof and verifies that the bound token
+// is rejected without a proof, with a replayed proof, and with a proof that
+// doesn't carry the nonce of the node.
+func TestHandler_DPoP(t *testing.T) {
+	cluster := vault.NewTestCluster(t, &vault.CoreConfig{
+		CredentialBackends: map[string]logical.Factory{
+			"userpass": credUserpass.Factory,
+		},
+	}, &vault.TestClusterOptions{
+		HandlerFunc: Handler,
+	})
+	defer cluster.Cleanup()
+
+	root := cluster.Cores[0].Client
+	require.NoError(t, root.Sys().EnableAuthWithOptions("userpass", &api.EnableAuthOptions{Type: "userpass"}))
+	_, err := root.Logical().Write("auth/userpass/users/alice", map[string]interface{}{
+		"password": "secret",
+		"policies": "default",
+	})
+	require.NoError(t, err)
+
+	config := root.CloneConfig()
+	transport := &dpopRecordingTransport{base: config.HttpClient.Transport}
+	config.HttpClient = &http.Client{Transport: transport}
+	client, err := api.NewClient(config)
+	require.NoError(t, err)
+	client.ClearToken()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, client.SetDPoPSigner(key))
+
+	// The first proof carries no nonce, so the client retries with the one
+	// returned by Vault
+	secret, err := client.Logical().Write("auth/userpass/login/alice", map[string]interface{}{
+		"password": "secret",
+	})
+	require.NoError(t, err)
+	client.SetToken(secret.Auth.ClientToken)
+
+	self, err := client.Auth().Token().LookupSelf()
+	require.NoError(t, err)
+	require.NotEmpty(t, self.Data["bound_dpop_key_thumbprint"])
+
+	// The bound token is unusable without a proof
+	noProof, err := api.NewClient(root.CloneConfig())
+	require.NoError(t, err)
+	noProof.SetToken(secret.Auth.ClientToken)
+	_, err = noProof.Auth().Token().LookupSelf()
+	require.Error(t, err)
+
+	// A proof is accepted only once
+	transport.l.Lock()
+	replay := transport.last
+	transport.l.Unlock()
+	require.NotNil(t, replay)
+	resp, err := transport.base.RoundTrip(replay)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Contains(t, string(body), "already been used")
+
+	// A proof without the nonce of the node is rejected with a new nonce
+	req := replay.Clone(replay.Context())
+	proof, err := testDPoPProofWithoutNonce(key, req, secret.Auth.ClientToken)
+	require.NoError(t, err)
+	req.Header.Set(consts.DPoPHeaderName, proof)
+	resp, err = transport.base.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get(consts.DPoPNonceHeaderName))
+	require.True(t, strings.Contains(resp.Header.Get("WWW-Authenticate"), "use_dpop_nonce"))
+}
+
+// testDPoPProofWithoutNonce signs a proof for the request through a client
+// that never received a nonce.
+func testDPoPProofWithoutNonce(key *ecdsa.PrivateKey, req *http.Request, token string) (string, error) {
+	var proof string
+	client, err := api.NewClient(&api.Config{
+		Address: req.URL.Scheme + "://" + req.URL.Host,
+		HttpClient: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			proof = r.Header.Get(consts.DPoPHeaderName)
+			return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: r}, nil
+		})},
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := client.SetDPoPSigner(key); err != nil {
+		return "", err
+	}
+	client.SetToken(token)
+	if _, err := client.RawRequest(client.NewRequest(req.Method, req.URL.Path)); err != nil {
+		return "", err
+	}
+	return proof, nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -1492,10 +1492,13 @@ func getTokenFromReq(r *http.Request) (string, bool) {
 		// If string does not start by 'Bearer ', it is not one we would use,
 		// but might be used by plugins
 		for _, v := range headers {
-			if !strings.HasPrefix(v, "Bearer ") {
-				continue
+			switch {
+			case strings.HasPrefix(v, "Bearer "):
+				return strings.TrimSpace(v[7:]), true
+			case strings.HasPrefix(v, "DPoP "):
+				// Sender-constrained tokens as defined by RFC 9449
+				return strings.TrimSpace(v[5:]), true
 			}
-			return strings.TrimSpace(v[7:]), true
 		}
 	}
 	return "", false
@@ -1515,6 +1518,48 @@ func requestAuth(r *http.Request, req *logical.Request) {
 	}
 }
 
+// requestSPNEGOToken attaches the SPNEGO token of an "Authorization: Negotiate"
+// header, if any, to the logical.Request.
+func requestSPNEGOToken(r *http.Request, req *logical.Request) {
+	for _, v := range r.Header.Values("Authorization") {
+		if strings.HasPrefix(v, "Negotiate ") {
+			req.SPNEGOToken = strings.TrimSpace(v[10:])
+			return
+		}
+	}
+}
+
+// requestDPoPProof verifies the DPoP proof of the request, if any, and
+// attaches the thumbprint of the key that signed it to the logical.Request.
+// The nonce the next proofs must carry is returned in the DPoP-Nonce header,
+// and requested with a use_dpop_nonce error when the proof lacks it.
+func requestDPoPProof(core *vault.Core, w http.ResponseWriter, r *http.Request, req *logical.Request) error {
+	proofs := r.Header.Values(consts.DPoPHeaderName)
+	switch len(proofs) {
+	case 0:
+		return nil
+	case 1:
+	default:
+		return errors.New("only one DPoP proof may be provided")
+	}
+
+	nonce, err := core.DPoPNonce()
+	if err != nil {
+		return err
+	}
+	w.Header().Set(consts.DPoPNonceHeaderName, nonce)
+
+	thumbprint, err := core.ValidateDPoPProof(proofs[0], r.Method, r.URL.Path, req.ClientToken)
+	if errors.Is(err, vault.ErrDPoPNonceRequired) {
+		w.Header().Set("WWW-Authenticate", `DPoP error="use_dpop_nonce", error_description="`+err.Error()+`"`)
+	}
+	if err != nil {
+		return err
+	}
+	req.DPoPKeyThumbprint = thumbprint
+	return nil
+}
+
 func requestPolicyOverride(r *http.Request, req *logical.Request) error {
 	raw := r.Header.Get(PolicyOverrideHeaderName)
 	if raw == "" {
@@ -342,6 +342,13 @@ func buildLogicalRequest(core *vault.Core, w http.ResponseWriter, r *http.Reques
 		return nil, nil, http.StatusBadRequest, fmt.Errorf("failed to parse %s header: %w", PolicyOverrideHeaderName, err)
 	}
 
+	requestSPNEGOToken(r, req)
+
+	err = requestDPoPProof(core, w, r, req)
+	if err != nil {
+		return nil, nil, http.StatusUnauthorized, fmt.Errorf("invalid %s proof: %w", consts.DPoPHeaderName, err)
+	}
+
 	return req, origBody, 0, nil
 }
 
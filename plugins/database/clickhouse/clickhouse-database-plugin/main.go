@@ -0,0 +1,26 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/hashicorp/vault/plugins/database/clickhouse"
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+)
+
+func main() {
+	if err := Run(); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
+
+// Run instantiates a ClickHouse object, and runs the RPC server for the plugin
+func Run() error {
+	dbplugin.ServeMultiplex(clickhouse.New)
+
+	return nil
+}
@@ -0,0 +1,392 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package clickhouse
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/go-uuid"
+	dbplugin "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/database/helper/connutil"
+	"github.com/hashicorp/vault/sdk/database/helper/dbutil"
+	"github.com/hashicorp/vault/sdk/helper/dbtxn"
+	"github.com/hashicorp/vault/sdk/helper/template"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// This is how this plugin will be reflected in middleware
+	// such as metrics.
+	middlewareTypeName = "clickhouse"
+
+	defaultRenewSQL = `
+ALTER USER "{{name}}" VALID UNTIL '{{expiration}}';
+`
+	defaultRotateCredentialsSQL = `
+ALTER USER "{{name}}" IDENTIFIED WITH sha256_password BY '{{password}}';
+`
+	defaultDeleteSQL = `
+DROP USER IF EXISTS "{{name}}";
+`
+	defaultUserNameTemplate = `{{ printf "v-%s-%s-%s-%s" (.DisplayName | truncate 8) (.RoleName | truncate 8) (random 20) (unix_time) | truncate 63 | lowercase }}`
+
+	// ClickHouse parses VALID UNTIL in the server's time zone unless one is
+	// given, so expirations are always sent in UTC with an explicit zone.
+	expirationFormat = "2006-01-02T15:04:05Z"
+)
+
+var _ dbplugin.Database = (*ClickHouse)(nil)
+
+// New implements builtinplugins.BuiltinFactory
+func New() (interface{}, error) {
+	db := newClickHouse()
+	// Wrap the plugin with middleware to sanitize errors
+	dbType := dbplugin.NewDatabaseErrorSanitizerMiddleware(db, db.secretValues)
+	return dbType, nil
+}
+
+func newClickHouse() *ClickHouse {
+	connProducer := &connutil.SQLConnectionProducer{}
+	connProducer.Type = driverName
+
+	return &ClickHouse{
+		SQLConnectionProducer: connProducer,
+	}
+}
+
+// ClickHouse manages users through the native or HTTP interface of
+// ClickHouse, depending on the scheme of the connection URL. ClickHouse has no
+// transactions for access management statements, so the statements of
+// a request are applied one at a time, and a failure part way through leaves
+// the earlier ones in effect.
+type ClickHouse struct {
+	*connutil.SQLConnectionProducer
+
+	usernameProducer template.StringTemplate
+
+	// tlsConfigName references the TLS config of this instance in the
+	// registry of the driver
+	tlsConfigName string
+}
+
+// connectionOptions holds the settings of the connections to ClickHouse,
+// which are passed to the driver through the connection URL.
+type connectionOptions struct {
+	TLSCertificateKeyData []byte      `mapstructure:"tls_certificate_key"`
+	TLSCAData             []byte      `mapstructure:"tls_ca"`
+	TLSServerName         string      `mapstructure:"tls_server_name"`
+	TLSSkipVerify         bool        `mapstructure:"tls_skip_verify"`
+	RequestTimeoutRaw     interface{} `mapstructure:"request_timeout"`
+}
+
+func (o *connectionOptions) tlsConfig() (*tls.Config, error) {
+	if len(o.TLSCAData) == 0 &&
+		len(o.TLSCertificateKeyData) == 0 &&
+		o.TLSServerName == "" &&
+		!o.TLSSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         o.TLSServerName,
+		InsecureSkipVerify: o.TLSSkipVerify,
+	}
+
+	if len(o.TLSCAData) > 0 {
+		rootCertPool := x509.NewCertPool()
+		if ok := rootCertPool.AppendCertsFromPEM(o.TLSCAData); !ok {
+			return nil, errors.New("failed to parse tls_ca")
+		}
+		tlsConfig.RootCAs = rootCertPool
+	}
+
+	if len(o.TLSCertificateKeyData) > 0 {
+		certificate, err := tls.X509KeyPair(o.TLSCertificateKeyData, o.TLSCertificateKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load tls_certificate_key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+func (c *ClickHouse) secretValues() map[string]string {
+	return map[string]string{
+		c.Password: "[password]",
+	}
+}
+
+func (c *ClickHouse) Type() (string, error) {
+	return middlewareTypeName, nil
+}
+
+// Initialize must be called on each new ClickHouse struct before use.
+// It uses the connutil.SQLConnectionProducer's Init function to do all the lifting.
+func (c *ClickHouse) Initialize(ctx context.Context, req dbplugin.InitializeRequest) (dbplugin.InitializeResponse, error) {
+	conf, err := c.withConnectionOptions(req.Config)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("error initializing db: %w", err)
+	}
+
+	if _, err := c.Init(ctx, conf, req.VerifyConnection); err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("error initializing db: %w", err)
+	}
+
+	usernameTemplate, err := strutil.GetString(req.Config, "username_template")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve username_template: %w", err)
+	}
+	if usernameTemplate == "" {
+		usernameTemplate = defaultUserNameTemplate
+	}
+
+	up, err := template.NewTemplate(template.Template(usernameTemplate))
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("unable to initialize username template: %w", err)
+	}
+	c.usernameProducer = up
+
+	_, err = c.usernameProducer.Generate(dbplugin.UsernameMetadata{})
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("invalid username template: %w", err)
+	}
+
+	// Return the config as given, without the driver parameters added to the
+	// connection URL
+	return dbplugin.InitializeResponse{
+		Config: req.Config,
+	}, nil
+}
+
+// withConnectionOptions registers the TLS config of the plugin config with the
+// driver, replacing any previous one of this instance, and returns a copy of
+// the config whose connection URL references it along with the dial and read
+// timeouts of the driver.
+func (c *ClickHouse) withConnectionOptions(config map[string]interface{}) (map[string]interface{}, error) {
+	var opts connectionOptions
+	if err := mapstructure.WeakDecode(config, &opts); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	if opts.RequestTimeoutRaw != nil {
+		timeout, err := parseutil.ParseDurationSecond(opts.RequestTimeoutRaw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request_timeout: %w", err)
+		}
+		if timeout <= 0 {
+			return nil, errors.New("request_timeout must be positive")
+		}
+		params.Set("dial_timeout", timeout.String())
+		params.Set("read_timeout", timeout.String())
+	}
+
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	previousTLSConfigName := c.tlsConfigName
+	c.tlsConfigName = ""
+	if tlsConfig != nil {
+		name, err := uuid.GenerateUUID()
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate UUID for TLS configuration: %w", err)
+		}
+		registerTLSConfig(name, tlsConfig)
+		c.tlsConfigName = name
+		params.Set(tlsConfigParam, name)
+	}
+	if previousTLSConfigName != "" {
+		deregisterTLSConfig(previousTLSConfigName)
+	}
+
+	conf := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		conf[k] = v
+	}
+
+	connURL, ok := conf["connection_url"].(string)
+	if ok && connURL != "" && len(params) > 0 {
+		sep := "?"
+		if strings.Contains(connURL, "?") {
+			sep = "&"
+		}
+		conf["connection_url"] = connURL + sep + params.Encode()
+	}
+
+	return conf, nil
+}
+
+// Close closes the connection and releases the TLS config of the instance.
+func (c *ClickHouse) Close() error {
+	err := c.SQLConnectionProducer.Close()
+
+	c.Lock()
+	defer c.Unlock()
+	if c.tlsConfigName != "" {
+		deregisterTLSConfig(c.tlsConfigName)
+		c.tlsConfigName = ""
+	}
+
+	return err
+}
+
+func (c *ClickHouse) getConnection(ctx context.Context) (*sql.DB, error) {
+	db, err := c.Connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return db.(*sql.DB), nil
+}
+
+// NewUser creates a new user in the database. There is no default statement for
+// creating users, since they are usually created along with the GRANTs for
+// the role, so one must be specified in the role.
+func (c *ClickHouse) NewUser(ctx context.Context, req dbplugin.NewUserRequest) (dbplugin.NewUserResponse, error) {
+	if len(req.Statements.Commands) == 0 {
+		return dbplugin.NewUserResponse{}, dbutil.ErrEmptyCreationStatement
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	username, err := c.usernameProducer.Generate(req.UsernameConfig)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
+
+	db, err := c.getConnection(ctx)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
+
+	m := map[string]string{
+		"name":       username,
+		"username":   username,
+		"password":   req.Password,
+		"expiration": req.Expiration.UTC().Format(expirationFormat),
+	}
+	if err := executeStatements(ctx, db, req.Statements.Commands, m); err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
+
+	return dbplugin.NewUserResponse{
+		Username: username,
+	}, nil
+}
+
+// UpdateUser can update the expiration or the password of a user, or both.
+// Both updates support both default and custom statements.
+func (c *ClickHouse) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+	if req.Password == nil && req.Expiration == nil {
+		return dbplugin.UpdateUserResponse{}, errors.New("no changes requested")
+	}
+	if req.Username == "" {
+		return dbplugin.UpdateUserResponse{}, errors.New("must provide a username to update")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	db, err := c.getConnection(ctx)
+	if err != nil {
+		return dbplugin.UpdateUserResponse{}, err
+	}
+
+	if req.Expiration != nil {
+		statements := req.Expiration.Statements.Commands
+		if len(statements) == 0 {
+			statements = []string{defaultRenewSQL}
+		}
+
+		m := map[string]string{
+			"name":       req.Username,
+			"username":   req.Username,
+			"expiration": req.Expiration.NewExpiration.UTC().Format(expirationFormat),
+		}
+		if err := executeStatements(ctx, db, statements, m); err != nil {
+			return dbplugin.UpdateUserResponse{}, err
+		}
+	}
+
+	if req.Password != nil {
+		if req.Password.NewPassword == "" {
+			return dbplugin.UpdateUserResponse{}, errors.New("must provide a new password to update user password")
+		}
+
+		statements := req.Password.Statements.Commands
+		if len(statements) == 0 {
+			statements = []string{defaultRotateCredentialsSQL}
+		}
+
+		m := map[string]string{
+			"name":     req.Username,
+			"username": req.Username,
+			"password": req.Password.NewPassword,
+		}
+		if err := executeStatements(ctx, db, statements, m); err != nil {
+			return dbplugin.UpdateUserResponse{}, err
+		}
+	}
+
+	return dbplugin.UpdateUserResponse{}, nil
+}
+
+// DeleteUser supports both default and custom statements to delete a user.
+func (c *ClickHouse) DeleteUser(ctx context.Context, req dbplugin.DeleteUserRequest) (dbplugin.DeleteUserResponse, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	db, err := c.getConnection(ctx)
+	if err != nil {
+		return dbplugin.DeleteUserResponse{}, err
+	}
+
+	statements := req.Statements.Commands
+	if len(statements) == 0 {
+		statements = []string{defaultDeleteSQL}
+	}
+
+	m := map[string]string{
+		"name":     req.Username,
+		"username": req.Username,
+	}
+	if err := executeStatements(ctx, db, statements, m); err != nil {
+		return dbplugin.DeleteUserResponse{}, err
+	}
+
+	return dbplugin.DeleteUserResponse{}, nil
+}
+
+// executeStatements runs each semicolon separated query of the statements in
+// order, since ClickHouse only accepts a single query per request.
+func executeStatements(ctx context.Context, db *sql.DB, statements []string, m map[string]string) error {
+	for _, stmt := range statements {
+		for _, query := range strutil.ParseArbitraryStringSlice(stmt, ";") {
+			query = strings.TrimSpace(query)
+			if len(query) == 0 {
+				continue
+			}
+
+			if err := dbtxn.ExecuteDBQueryDirect(ctx, db, m, query); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
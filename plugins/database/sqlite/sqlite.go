@@ -0,0 +1,264 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	dbplugin "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/database/helper/connutil"
+	"github.com/hashicorp/vault/sdk/helper/dbtxn"
+	"github.com/hashicorp/vault/sdk/helper/template"
+)
+
+const (
+	// This is how this plugin will be reflected in middleware
+	// such as metrics.
+	middlewareTypeName = "sqlite"
+
+	// SQLDriverName is the database/sql driver the plugin connects with. No
+	// driver is linked into this package; the program serving the plugin must
+	// import one that registers itself under this name, such as the pure Go
+	// modernc.org/sqlite.
+	SQLDriverName = "sqlite"
+
+	// SQLite has no users of its own, so accounts are kept in a table that
+	// applications authenticate against.
+	defaultSchemaSQL = `
+CREATE TABLE IF NOT EXISTS vault_users (
+	username TEXT PRIMARY KEY,
+	password TEXT NOT NULL,
+	expiration TEXT
+);
+`
+	defaultCreationSQL = `
+INSERT INTO vault_users (username, password, expiration) VALUES ('{{name}}', '{{password}}', '{{expiration}}');
+`
+	defaultRenewSQL = `
+UPDATE vault_users SET expiration = '{{expiration}}' WHERE username = '{{name}}';
+`
+	defaultRotateCredentialsSQL = `
+UPDATE vault_users SET password = '{{password}}' WHERE username = '{{name}}';
+`
+	defaultDeleteSQL = `
+DELETE FROM vault_users WHERE username = '{{name}}';
+`
+	defaultUserNameTemplate = `{{ printf "v-%s-%s-%s-%s" (.DisplayName | truncate 8) (.RoleName | truncate 8) (random 20) (unix_time) | truncate 63 | lowercase }}`
+
+	expirationFormat = "2006-01-02T15:04:05Z"
+)
+
+var _ dbplugin.Database = (*SQLite)(nil)
+
+// New implements builtinplugins.BuiltinFactory
+func New() (interface{}, error) {
+	db := newSQLite()
+	// Wrap the plugin with middleware to sanitize errors
+	dbType := dbplugin.NewDatabaseErrorSanitizerMiddleware(db, db.secretValues)
+	return dbType, nil
+}
+
+func newSQLite() *SQLite {
+	connProducer := &connutil.SQLConnectionProducer{}
+	connProducer.Type = SQLDriverName
+
+	return &SQLite{
+		SQLConnectionProducer: connProducer,
+	}
+}
+
+// SQLite is a reference dbplugin.Database implementation backed by an
+// embedded SQLite database, which needs no server to run against. The
+// connection_url is the path of the database file, or ":memory:".
+type SQLite struct {
+	*connutil.SQLConnectionProducer
+
+	usernameProducer template.StringTemplate
+}
+
+func (s *SQLite) secretValues() map[string]string {
+	return map[string]string{
+		s.Password: "[password]",
+	}
+}
+
+func (s *SQLite) Type() (string, error) {
+	return middlewareTypeName, nil
+}
+
+// Initialize must be called on each new SQLite struct before use.
+// It uses the connutil.SQLConnectionProducer's Init function to do all the lifting.
+func (s *SQLite) Initialize(ctx context.Context, req dbplugin.InitializeRequest) (dbplugin.InitializeResponse, error) {
+	conf, err := s.Init(ctx, req.Config, req.VerifyConnection)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("error initializing db: %w", err)
+	}
+
+	usernameTemplate, err := strutil.GetString(req.Config, "username_template")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve username_template: %w", err)
+	}
+	if usernameTemplate == "" {
+		usernameTemplate = defaultUserNameTemplate
+	}
+
+	up, err := template.NewTemplate(template.Template(usernameTemplate))
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("unable to initialize username template: %w", err)
+	}
+	s.usernameProducer = up
+
+	_, err = s.usernameProducer.Generate(dbplugin.UsernameMetadata{})
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("invalid username template: %w", err)
+	}
+
+	return dbplugin.InitializeResponse{
+		Config: conf,
+	}, nil
+}
+
+func (s *SQLite) getConnection(ctx context.Context) (*sql.DB, error) {
+	db, err := s.Connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return db.(*sql.DB), nil
+}
+
+// NewUser adds a user to the vault_users table, or runs the role's creation
+// statements if it has any.
+func (s *SQLite) NewUser(ctx context.Context, req dbplugin.NewUserRequest) (dbplugin.NewUserResponse, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	username, err := s.usernameProducer.Generate(req.UsernameConfig)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
+
+	statements := req.Statements.Commands
+	if len(statements) == 0 {
+		statements = []string{defaultSchemaSQL, defaultCreationSQL}
+	}
+
+	m := map[string]string{
+		"name":       username,
+		"username":   username,
+		"password":   req.Password,
+		"expiration": req.Expiration.UTC().Format(expirationFormat),
+	}
+	if err := s.executeStatements(ctx, statements, m); err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
+
+	return dbplugin.NewUserResponse{
+		Username: username,
+	}, nil
+}
+
+// UpdateUser can update the expiration or the password of a user, or both.
+// The updates all happen in a single transaction, so they will either all
+// succeed or all fail.
+func (s *SQLite) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+	if req.Password == nil && req.Expiration == nil {
+		return dbplugin.UpdateUserResponse{}, errors.New("no changes requested")
+	}
+	if req.Username == "" {
+		return dbplugin.UpdateUserResponse{}, errors.New("must provide a username to update")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	var statements []string
+	m := map[string]string{
+		"name":     req.Username,
+		"username": req.Username,
+	}
+
+	if req.Expiration != nil {
+		renewStmts := req.Expiration.Statements.Commands
+		if len(renewStmts) == 0 {
+			renewStmts = []string{defaultRenewSQL}
+		}
+		statements = append(statements, renewStmts...)
+		m["expiration"] = req.Expiration.NewExpiration.UTC().Format(expirationFormat)
+	}
+
+	if req.Password != nil {
+		if req.Password.NewPassword == "" {
+			return dbplugin.UpdateUserResponse{}, errors.New("must provide a new password to update user password")
+		}
+
+		rotateStmts := req.Password.Statements.Commands
+		if len(rotateStmts) == 0 {
+			rotateStmts = []string{defaultRotateCredentialsSQL}
+		}
+		statements = append(statements, rotateStmts...)
+		m["password"] = req.Password.NewPassword
+	}
+
+	if err := s.executeStatements(ctx, statements, m); err != nil {
+		return dbplugin.UpdateUserResponse{}, err
+	}
+	return dbplugin.UpdateUserResponse{}, nil
+}
+
+// DeleteUser supports both default and custom statements to delete a user.
+func (s *SQLite) DeleteUser(ctx context.Context, req dbplugin.DeleteUserRequest) (dbplugin.DeleteUserResponse, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	statements := req.Statements.Commands
+	if len(statements) == 0 {
+		statements = []string{defaultSchemaSQL, defaultDeleteSQL}
+	}
+
+	m := map[string]string{
+		"name":     req.Username,
+		"username": req.Username,
+	}
+	if err := s.executeStatements(ctx, statements, m); err != nil {
+		return dbplugin.DeleteUserResponse{}, err
+	}
+	return dbplugin.DeleteUserResponse{}, nil
+}
+
+// executeStatements runs each semicolon separated query of the statements in
+// a single transaction.
+func (s *SQLite) executeStatements(ctx context.Context, statements []string, m map[string]string) error {
+	db, err := s.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tx.Rollback()
+	}()
+
+	for _, stmt := range statements {
+		for _, query := range strutil.ParseArbitraryStringSlice(stmt, ";") {
+			query = strings.TrimSpace(query)
+			if len(query) == 0 {
+				continue
+			}
+
+			if err := dbtxn.ExecuteTxQueryDirect(ctx, tx, m, query); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,99 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	dbplugin "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	dbtesting "github.com/hashicorp/vault/sdk/database/dbplugin/v5/testing"
+	"github.com/stretchr/testify/require"
+)
+
+func getSQLite(t *testing.T) (*SQLite, *sql.DB) {
+	t.Helper()
+
+	if !slices.Contains(sql.Drivers(), SQLDriverName) {
+		t.Skipf("no %q database/sql driver is linked into the test binary", SQLDriverName)
+	}
+
+	path := filepath.Join(t.TempDir(), "vault.db")
+	db := newSQLite()
+	dbtesting.AssertInitialize(t, db, dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url": path,
+		},
+		VerifyConnection: true,
+	})
+	t.Cleanup(func() { dbtesting.AssertClose(t, db) })
+
+	conn, err := sql.Open(SQLDriverName, path)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return db, conn
+}
+
+func userPassword(t *testing.T, conn *sql.DB, username string) string {
+	t.Helper()
+
+	var password string
+	err := conn.QueryRowContext(context.Background(), "SELECT password FROM vault_users WHERE username = ?", username).Scan(&password)
+	if err == sql.ErrNoRows {
+		return ""
+	}
+	require.NoError(t, err)
+	return password
+}
+
+func TestSQLite_UserLifecycle(t *testing.T) {
+	db, conn := getSQLite(t)
+
+	resp := dbtesting.AssertNewUser(t, db, dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "token",
+			RoleName:    "reader",
+		},
+		Password:   "secret",
+		Expiration: time.Now().Add(time.Hour),
+	})
+	require.Equal(t, "secret", userPassword(t, conn, resp.Username))
+
+	dbtesting.AssertUpdateUser(t, db, dbplugin.UpdateUserRequest{
+		Username: resp.Username,
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "rotated",
+		},
+		Expiration: &dbplugin.ChangeExpiration{
+			NewExpiration: time.Now().Add(2 * time.Hour),
+		},
+	})
+	require.Equal(t, "rotated", userPassword(t, conn, resp.Username))
+
+	// A failing statement rolls back the whole update
+	_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+		Username: resp.Username,
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "again",
+			Statements: dbplugin.Statements{
+				Commands: []string{
+					"UPDATE vault_users SET password = '{{password}}' WHERE username = '{{name}}'",
+					"UPDATE missing_table SET x = 1",
+				},
+			},
+		},
+	})
+	require.Error(t, err)
+	require.Equal(t, "rotated", userPassword(t, conn, resp.Username))
+
+	dbtesting.AssertDeleteUser(t, db, dbplugin.DeleteUserRequest{
+		Username: resp.Username,
+	})
+	require.Empty(t, userPassword(t, conn, resp.Username))
+}
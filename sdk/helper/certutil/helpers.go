@@ -13,10 +13,12 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/pem"
 	"errors"
@@ -122,6 +124,14 @@ func GetHexFormatted(buf []byte, sep string) string {
 	return ret.String()
 }
 
+// GetCertificateThumbprint returns the base64url-encoded (unpadded) SHA-256
+// hash of the DER encoding of the certificate, matching the "x5t#S256"
+// confirmation method of RFC 8705.
+func GetCertificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
 // ParseHexFormatted returns the raw bytes from a formatted hex string
 func ParseHexFormatted(in, sep string) []byte {
 	var ret bytes.Buffer
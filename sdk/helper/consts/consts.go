@@ -23,6 +23,14 @@ const (
 	// wrap the response
 	WrapTTLHeaderName = "X-Vault-Wrap-TTL"
 
+	// DPoPHeaderName is the name of the header containing a DPoP proof JWT
+	// as defined by RFC 9449.
+	DPoPHeaderName = "DPoP"
+
+	// DPoPNonceHeaderName is the name of the header containing the nonce
+	// that DPoP proofs must carry, as defined by RFC 9449.
+	DPoPNonceHeaderName = "DPoP-Nonce"
+
 	// PerformanceReplicationALPN is the negotiated protocol used for
 	// performance replication.
 	PerformanceReplicationALPN = "replication_v1"
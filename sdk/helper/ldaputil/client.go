@@ -24,6 +24,10 @@ import (
 	"github.com/hashicorp/go-secure-stdlib/tlsutil"
 )
 
+// DefaultNestedGroupFilter is the default filter used to find the groups
+// containing a given group when resolving nested group memberships.
+const DefaultNestedGroupFilter = "(|(member={{.GroupDN}})(uniqueMember={{.GroupDN}}))"
+
 type Client struct {
 	Logger hclog.Logger
 	LDAP   LDAP
@@ -575,21 +579,120 @@ func (c *Client) performLdapTokenGroupsSearch(cfg *ConfigEntry, conn Connection,
  *
  */
 func (c *Client) GetLdapGroups(cfg *ConfigEntry, conn Connection, userDN string, username string) ([]string, error) {
-	var entries []*ldap.Entry
-	var err error
-	if cfg.UseTokenGroups {
-		entries, err = c.performLdapTokenGroupsSearch(cfg, conn, userDN)
-	} else {
-		if paging, ok := conn.(PagingConnection); ok && cfg.MaximumPageSize > 0 {
-			entries, err = c.performLdapFilterGroupsSearchPaging(cfg, paging, userDN, username)
-		} else {
-			entries, err = c.performLdapFilterGroupsSearch(cfg, conn, userDN, username)
-		}
+	entries, err := c.searchLdapGroups(cfg, conn, userDN, username)
+	if err != nil {
+		return nil, err
 	}
+
+	return ldapGroupNames(cfg, entries), nil
+}
+
+// GetNestedLdapGroups returns the groups of the user like GetLdapGroups, and
+// additionally follows group-in-group memberships for up to maxDepth levels.
+// nestedFilter is a Go template exposing the GroupDN of the group being
+// expanded, and is evaluated under the configured group DN to find the
+// groups that contain it. When Active Directory tokenGroups are used, the
+// result is already transitive and no additional queries are made.
+func (c *Client) GetNestedLdapGroups(cfg *ConfigEntry, conn Connection, userDN, username, nestedFilter string, maxDepth int) ([]string, error) {
+	entries, err := c.searchLdapGroups(cfg, conn, userDN, username)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.UseTokenGroups || maxDepth <= 0 || cfg.GroupDN == "" {
+		return ldapGroupNames(cfg, entries), nil
+	}
+
+	t, err := template.New("nestedGroupFilter").Parse(nestedFilter)
+	if err != nil {
+		return nil, fmt.Errorf("LDAP search failed due to nested group filter compilation error: %w", err)
+	}
+
+	// Track visited group DNs so that membership cycles terminate
+	seen := make(map[string]bool)
+	var frontier []string
+	for _, e := range entries {
+		for _, dn := range ldapGroupDNs(cfg, e) {
+			if key := strings.ToLower(dn); !seen[key] {
+				seen[key] = true
+				frontier = append(frontier, dn)
+			}
+		}
+	}
+
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, groupDN := range frontier {
+			context := struct {
+				GroupDN string
+			}{
+				ldap.EscapeFilter(groupDN),
+			}
+
+			var renderedQuery bytes.Buffer
+			if err := t.Execute(&renderedQuery, context); err != nil {
+				return nil, fmt.Errorf("LDAP search failed due to nested group filter parsing error: %w", err)
+			}
+
+			if c.Logger.IsDebug() {
+				c.Logger.Debug("searching parent groups", "groupdn", cfg.GroupDN, "depth", depth+1, "rendered_query", renderedQuery.String())
+			}
+
+			result, err := conn.Search(&ldap.SearchRequest{
+				BaseDN:       cfg.GroupDN,
+				Scope:        ldap.ScopeWholeSubtree,
+				DerefAliases: ldapDerefAliasMap[cfg.DerefAliases],
+				Filter:       renderedQuery.String(),
+				// Only the DN of parent groups is needed
+				Attributes: []string{"1.1"},
+				SizeLimit:  math.MaxInt32,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("LDAP search for parent groups failed: %w", err)
+			}
+
+			for _, e := range result.Entries {
+				key := strings.ToLower(e.DN)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				next = append(next, e.DN)
+				entries = append(entries, e)
+			}
+		}
+		frontier = next
+	}
+
+	return ldapGroupNames(cfg, entries), nil
+}
+
+func (c *Client) searchLdapGroups(cfg *ConfigEntry, conn Connection, userDN string, username string) ([]*ldap.Entry, error) {
+	if cfg.UseTokenGroups {
+		return c.performLdapTokenGroupsSearch(cfg, conn, userDN)
+	}
+	if paging, ok := conn.(PagingConnection); ok && cfg.MaximumPageSize > 0 {
+		return c.performLdapFilterGroupsSearchPaging(cfg, paging, userDN, username)
+	}
+	return c.performLdapFilterGroupsSearch(cfg, conn, userDN, username)
+}
+
+// ldapGroupDNs returns the DNs of the groups described by a group search
+// result entry: either the DN values of the configured group attribute, such
+// as memberOf on user entries, or the DN of the entry itself.
+func ldapGroupDNs(cfg *ConfigEntry, e *ldap.Entry) []string {
+	var dns []string
+	for _, val := range e.GetAttributeValues(cfg.GroupAttr) {
+		if dn, err := ldap.ParseDN(val); err == nil && len(dn.RDNs) > 0 {
+			dns = append(dns, val)
+		}
+	}
+	if len(dns) == 0 {
+		dns = append(dns, e.DN)
+	}
+	return dns
+}
 
+func ldapGroupNames(cfg *ConfigEntry, entries []*ldap.Entry) []string {
 	// retrieve the groups in a string/bool map as a structure to avoid duplicates inside
 	ldapMap := make(map[string]bool)
 
@@ -618,7 +721,7 @@ func (c *Client) GetLdapGroups(cfg *ConfigEntry, conn Connection, userDN string,
 		ldapGroups = append(ldapGroups, key)
 	}
 
-	return ldapGroups, nil
+	return ldapGroups
 }
 
 // EscapeLDAPValue is exported because a plugin uses it outside this package.
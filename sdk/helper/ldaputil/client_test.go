@@ -4,8 +4,10 @@
 package ldaputil
 
 import (
+	"sort"
 	"testing"
 
+	"github.com/go-ldap/ldap/v3"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -150,3 +152,62 @@ func TestClient_renderUserSearchFilter(t *testing.T) {
 		})
 	}
 }
+
+// nestedGroupsConn is a Connection answering group searches from a static
+// map of filter to matching group DNs.
+type nestedGroupsConn struct {
+	Connection
+	results  map[string][]string
+	searches int
+}
+
+func (c *nestedGroupsConn) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	c.searches++
+	result := &ldap.SearchResult{}
+	for _, dn := range c.results[req.Filter] {
+		result.Entries = append(result.Entries, ldap.NewEntry(dn, nil))
+	}
+	return result, nil
+}
+
+func TestClient_GetNestedLdapGroups(t *testing.T) {
+	t.Parallel()
+
+	pre111 := false
+	cfg := &ConfigEntry{
+		GroupDN:                  "ou=groups,dc=example,dc=com",
+		GroupAttr:                "cn",
+		GroupFilter:              "(member={{.UserDN}})",
+		UsePre111GroupCNBehavior: &pre111,
+	}
+	nestedFilter := "(member={{.GroupDN}})"
+
+	conn := &nestedGroupsConn{
+		results: map[string][]string{
+			"(member=uid=alice,ou=users,dc=example,dc=com)": {"cn=dev,ou=groups,dc=example,dc=com"},
+			"(member=cn=dev,ou=groups,dc=example,dc=com)":   {"cn=engineering,ou=groups,dc=example,dc=com"},
+			// A membership cycle must not cause an endless search
+			"(member=cn=engineering,ou=groups,dc=example,dc=com)": {"cn=staff,ou=groups,dc=example,dc=com", "cn=dev,ou=groups,dc=example,dc=com"},
+		},
+	}
+	c := Client{
+		Logger: hclog.NewNullLogger(),
+		LDAP:   NewLDAP(),
+	}
+
+	groups, err := c.GetLdapGroups(cfg, conn, "uid=alice,ou=users,dc=example,dc=com", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, groups)
+
+	groups, err = c.GetNestedLdapGroups(cfg, conn, "uid=alice,ou=users,dc=example,dc=com", "alice", nestedFilter, 1)
+	require.NoError(t, err)
+	sort.Strings(groups)
+	assert.Equal(t, []string{"dev", "engineering"}, groups)
+
+	conn.searches = 0
+	groups, err = c.GetNestedLdapGroups(cfg, conn, "uid=alice,ou=users,dc=example,dc=com", "alice", nestedFilter, 10)
+	require.NoError(t, err)
+	sort.Strings(groups)
+	assert.Equal(t, []string{"dev", "engineering", "staff"}, groups)
+	assert.Equal(t, 4, conn.searches)
+}
@@ -99,6 +99,20 @@ type Auth struct {
 	// The set of CIDRs that this token can be used with
 	BoundCIDRs []*sockaddr.SockAddrMarshaler `json:"bound_cidrs"`
 
+	// BoundCertificateThumbprint is the base64url-encoded SHA-256 thumbprint
+	// of the TLS client certificate that the issued token is bound to, as
+	// described by RFC 8705. When set, requests using the token must be made
+	// over a TLS connection presenting the same certificate. It is not
+	// included in plugin RPC serialization, so only builtin auth methods can
+	// issue certificate-bound tokens.
+	BoundCertificateThumbprint string `json:"bound_certificate_thumbprint,omitempty"`
+
+	// BoundDPoPKeyThumbprint is the RFC 7638 thumbprint of the public key the
+	// issued token is bound to, as described by RFC 9449. It is set by core
+	// when the login request carries a valid DPoP proof, regardless of the
+	// auth method used.
+	BoundDPoPKeyThumbprint string `json:"bound_dpop_key_thumbprint,omitempty"`
+
 	// CreationPath is a path that the backend can return to use in the lease.
 	// This is currently only supported for the token store where roles may
 	// change the perceived path of the lease, even though they don't change
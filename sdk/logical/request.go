@@ -294,6 +294,18 @@ type Request struct {
 	// caller-visible token representation when needed.
 	InboundSSCToken string
 
+	// DPoPKeyThumbprint is the RFC 7638 thumbprint of the public key that
+	// signed a valid DPoP proof (RFC 9449) presented with the request. It is
+	// only set by the HTTP layer once the proof has been verified.
+	DPoPKeyThumbprint string `json:"dpop_key_thumbprint,omitempty" structs:"dpop_key_thumbprint" mapstructure:"dpop_key_thumbprint" sentinel:""`
+
+	// SPNEGOToken is the base64 encoded SPNEGO token presented in an
+	// "Authorization: Negotiate" header, as described in RFC 4559. It is set
+	// by the HTTP layer so that credential backends can authenticate Kerberos
+	// clients without the header having to be allowed through with
+	// passthrough_request_headers.
+	SPNEGOToken string `json:"-" sentinel:""`
+
 	// When a request has been forwarded, contains information of the host the request was forwarded 'from'
 	ForwardedFrom string `json:"forwarded_from,omitempty"`
 
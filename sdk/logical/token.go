@@ -102,6 +102,11 @@ type TokenEntry struct {
 	// InlinePolicy specifies ACL rules to be applied to this token entry.
 	InlinePolicy string `json:"inline_policy" mapstructure:"inline_policy" structs:"inline_policy"`
 
+	// ScopePolicies are ACL rules that limit this token to the requests they
+	// also allow, regardless of its other policies. A down-scoped token
+	// carries the scopes of its parent followed by its own.
+	ScopePolicies []string `json:"scope_policies" mapstructure:"scope_policies" structs:"scope_policies"`
+
 	// Used for audit trails, this is something like "auth/user/login"
 	Path string `json:"path" mapstructure:"path" structs:"path"`
 
@@ -156,6 +161,12 @@ type TokenEntry struct {
 	// The set of CIDRs that this token can be used with
 	BoundCIDRs []*sockaddr.SockAddrMarshaler `json:"bound_cidrs" sentinel:""`
 
+	// The thumbprint of the TLS client certificate this token is bound to
+	BoundCertificateThumbprint string `json:"bound_certificate_thumbprint" mapstructure:"bound_certificate_thumbprint" structs:"bound_certificate_thumbprint" sentinel:""`
+
+	// The thumbprint of the DPoP public key this token is bound to
+	BoundDPoPKeyThumbprint string `json:"bound_dpop_key_thumbprint" mapstructure:"bound_dpop_key_thumbprint" structs:"bound_dpop_key_thumbprint" sentinel:""`
+
 	// NamespaceID is the identifier of the namespace to which this token is
 	// confined to. Do not return this value over the API when the token is
 	// being looked up.
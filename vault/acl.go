@@ -11,6 +11,7 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/armon/go-radix"
 	"github.com/hashicorp/go-multierror"
@@ -41,6 +42,10 @@ type ACL struct {
 
 	// Stores policies that are actually RGPs for later fetching
 	rgpPolicies []*Policy
+
+	// scopes are the ACLs of the scope policies of a down-scoped token. A
+	// request is only allowed if every scope also allows it.
+	scopes []*ACL
 }
 
 type PolicyCheckOpts struct {
@@ -146,6 +151,13 @@ func NewACL(ctx context.Context, policies []*Policy) (*ACL, error) {
 					return nil, fmt.Errorf("error cloning ACL permissions: %w", err)
 				}
 
+				// The capabilities of a rule with a condition are only granted
+				// to requests meeting it
+				if pc.ParsedCondition != nil {
+					clonedPerms.ConditionalGrants = []*ACLConditionalGrant{newConditionalGrant(policy, pc)}
+					clonedPerms.CapabilitiesBitmap = 0
+				}
+
 				// Store this policy name as the policy that permits these
 				// capabilities
 				clonedPerms.GrantingPoliciesMap = addGrantingPoliciesToMap(nil, policy, clonedPerms.CapabilitiesBitmap)
@@ -172,8 +184,12 @@ func NewACL(ctx context.Context, policies []*Policy) (*ACL, error) {
 				existingPerms.CapabilitiesBitmap = DenyCapabilityInt
 				existingPerms.AllowedParameters = nil
 				existingPerms.DeniedParameters = nil
+				existingPerms.ConditionalGrants = nil
 				goto INSERT
 
+			case pc.ParsedCondition != nil:
+				existingPerms.ConditionalGrants = append(existingPerms.ConditionalGrants, newConditionalGrant(policy, pc))
+
 			default:
 				// Insert the capabilities in this new policy into the existing
 				// value
@@ -319,41 +335,51 @@ func (a *ACL) CapabilitiesAndSubscribeEventTypes(ctx context.Context, path strin
 	subscribeEventTypes = res.SubscribeEventTypes
 	capabilities := res.CapabilitiesBitmap
 
+	pathCapabilities = capabilityNames(capabilities &^ DenyCapabilityInt)
+
+	// If "deny" is explicitly set or if the path has no capabilities at all,
+	// set the path capabilities to "deny"
+	if capabilities&DenyCapabilityInt > 0 || len(pathCapabilities) == 0 {
+		pathCapabilities = []string{DenyCapability}
+	}
+
+	return
+}
+
+// capabilityNames returns the names of the capabilities in the bitmap.
+func capabilityNames(capabilities uint32) []string {
+	var names []string
+	if capabilities&DenyCapabilityInt > 0 {
+		names = append(names, DenyCapability)
+	}
 	if capabilities&SudoCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, SudoCapability)
+		names = append(names, SudoCapability)
 	}
 	if capabilities&ReadCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, ReadCapability)
+		names = append(names, ReadCapability)
 	}
 	if capabilities&ListCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, ListCapability)
+		names = append(names, ListCapability)
 	}
 	if capabilities&UpdateCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, UpdateCapability)
+		names = append(names, UpdateCapability)
 	}
 	if capabilities&DeleteCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, DeleteCapability)
+		names = append(names, DeleteCapability)
 	}
 	if capabilities&CreateCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, CreateCapability)
+		names = append(names, CreateCapability)
 	}
 	if capabilities&PatchCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, PatchCapability)
+		names = append(names, PatchCapability)
 	}
 	if capabilities&SubscribeCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, SubscribeCapability)
+		names = append(names, SubscribeCapability)
 	}
 	if capabilities&RecoverCapabilityInt > 0 {
-		pathCapabilities = append(pathCapabilities, RecoverCapability)
+		names = append(names, RecoverCapability)
 	}
-
-	// If "deny" is explicitly set or if the path has no capabilities at all,
-	// set the path capabilities to "deny"
-	if capabilities&DenyCapabilityInt > 0 || len(pathCapabilities) == 0 {
-		pathCapabilities = []string{DenyCapability}
-	}
-
-	return
+	return names
 }
 
 func (a *ACL) Capabilities(ctx context.Context, path string) []string {
@@ -363,6 +389,10 @@ func (a *ACL) Capabilities(ctx context.Context, path string) []string {
 
 // AllowOperation is used to check if the given operation is permitted.
 func (a *ACL) AllowOperation(ctx context.Context, req *logical.Request, capCheckOnly bool) (ret *ACLResults) {
+	if len(a.scopes) > 0 {
+		return a.allowScopedOperation(ctx, req, capCheckOnly)
+	}
+
 	ret = a.performEnterpriseAclChecks(ctx, req, capCheckOnly)
 	if ret != nil {
 		return ret
@@ -392,8 +422,6 @@ func (a *ACL) AllowOperation(ctx context.Context, req *logical.Request, capCheck
 		return
 	}
 
-	var permissions *ACLPermissions
-
 	ns, err := namespace.FromContext(ctx)
 	if err != nil {
 		return
@@ -410,20 +438,92 @@ func (a *ACL) AllowOperation(ctx context.Context, req *logical.Request, capCheck
 		}
 	}
 
+	permissions := a.permissionsForPath(path, op)
+	if permissions == nil {
+		// No exact, prefix, or segment wildcard paths found, return without
+		// setting allowed
+		return
+	}
+	capabilities := permissions.CapabilitiesBitmap
+	var conditionalPolicies map[uint32][]logical.PolicyInfo
+	if capabilities&DenyCapabilityInt == 0 && len(permissions.ConditionalGrants) > 0 {
+		var conditionalCapabilities uint32
+		conditionalCapabilities, conditionalPolicies = permissions.conditionalCapabilities(req, time.Now())
+		capabilities |= conditionalCapabilities
+	}
+
+	// Check if the minimum permissions are met
+	// If "deny" has been explicitly set, only deny will be in the map, so we
+	// only need to check for the existence of other values
+	ret.RootPrivs = capabilities&SudoCapabilityInt > 0
+
+	// This is after the RootPrivs check so we can gate on it being from sudo
+	// rather than policy root
+	if capCheckOnly {
+		ret.CapabilitiesBitmap = capabilities
+		ret.SubscribeEventTypes = slices.Clone(permissions.SubscribeEventTypes)
+		return ret
+	}
+
+	ret.MFAMethods = permissions.MFAMethods
+	ret.ControlGroup = permissions.ControlGroup
+
+	capability, ok := operationCapability(op)
+	if !ok {
+		return
+	}
+	operationAllowed := capabilities&capability > 0
+	grantingPolicies := permissions.GrantingPoliciesMap[capability]
+	if len(conditionalPolicies[capability]) > 0 {
+		grantingPolicies = append(slices.Clone(grantingPolicies), conditionalPolicies[capability]...)
+	}
+
+	if !operationAllowed {
+		return
+	}
+
+	ret.GrantingPolicies = grantingPolicies
+
+	if permissions.MaxWrappingTTL > 0 {
+		if req.WrapInfo == nil || req.WrapInfo.TTL > permissions.MaxWrappingTTL {
+			return
+		}
+	}
+	if permissions.MinWrappingTTL > 0 {
+		if req.WrapInfo == nil || req.WrapInfo.TTL < permissions.MinWrappingTTL {
+			return
+		}
+	}
+	// This situation can happen because of merging, even though in a single
+	// path statement we check on ingress
+	if permissions.MinWrappingTTL != 0 &&
+		permissions.MaxWrappingTTL != 0 &&
+		permissions.MaxWrappingTTL < permissions.MinWrappingTTL {
+		return
+	}
+
+	if operationChecksParameters(op) {
+		if reason := checkParameterPermissions(permissions, req.Data); reason != "" {
+			return
+		}
+	}
+
+	ret.Allowed = true
+	return
+}
+
+// permissionsForPath returns the permissions of the rule that applies to the
+// operation on the given full request path, or nil if no rule matches it.
+func (a *ACL) permissionsForPath(path string, op logical.Operation) *ACLPermissions {
 	// Find an exact matching rule, look for prefix if no match
-	var capabilities uint32
 	raw, ok := a.exactRules.Get(path)
 	if ok {
-		permissions = raw.(*ACLPermissions)
-		capabilities = permissions.CapabilitiesBitmap
-		goto CHECK
+		return raw.(*ACLPermissions)
 	}
 	if op == logical.ListOperation {
 		raw, ok = a.exactRules.Get(strings.TrimSuffix(path, "/"))
 		if ok {
-			permissions = raw.(*ACLPermissions)
-			capabilities = permissions.CapabilitiesBitmap
-			goto CHECK
+			return raw.(*ACLPermissions)
 		}
 	}
 
@@ -460,163 +560,107 @@ func (a *ACL) AllowOperation(ctx context.Context, req *logical.Request, capCheck
 		fullDescrs := a.prefixAndWCCandidatesForPath(path, nil)
 
 		if len(trimmedDescrs) > 0 || len(fullDescrs) > 0 {
-			permissions = a.resolveACLPermsForListOp(trimmedDescrs, fullDescrs)
-			capabilities = permissions.CapabilitiesBitmap
-			goto CHECK
+			return a.resolveACLPermsForListOp(trimmedDescrs, fullDescrs)
 		}
-		// No prefix or segment-wildcard rule matched either form; fall through
-		// to the "no match" return below.
-		return
-	}
-	permissions = a.CheckAllowedFromNonExactPaths(path, false)
-	if permissions != nil {
-		capabilities = permissions.CapabilitiesBitmap
-		goto CHECK
+		// No prefix or segment-wildcard rule matched either form
+		return nil
 	}
+	return a.CheckAllowedFromNonExactPaths(path, false)
+}
 
-	// No exact, prefix, or segment wildcard paths found, return without
-	// setting allowed
-	return
-
-CHECK:
-	// Check if the minimum permissions are met
-	// If "deny" has been explicitly set, only deny will be in the map, so we
-	// only need to check for the existence of other values
-	ret.RootPrivs = capabilities&SudoCapabilityInt > 0
-
-	// This is after the RootPrivs check so we can gate on it being from sudo
-	// rather than policy root
-	if capCheckOnly {
-		ret.CapabilitiesBitmap = capabilities
-		ret.SubscribeEventTypes = slices.Clone(permissions.SubscribeEventTypes)
-		return ret
-	}
-
-	ret.MFAMethods = permissions.MFAMethods
-	ret.ControlGroup = permissions.ControlGroup
-
-	var grantingPolicies []logical.PolicyInfo
-	operationAllowed := false
+// operationCapability returns the capability that grants the operation.
+func operationCapability(op logical.Operation) (uint32, bool) {
 	switch op {
 	case logical.ReadOperation:
-		operationAllowed = capabilities&ReadCapabilityInt > 0
-		grantingPolicies = permissions.GrantingPoliciesMap[ReadCapabilityInt]
+		return ReadCapabilityInt, true
 	case logical.ListOperation:
-		operationAllowed = capabilities&ListCapabilityInt > 0
-		grantingPolicies = permissions.GrantingPoliciesMap[ListCapabilityInt]
+		return ListCapabilityInt, true
 	case logical.UpdateOperation:
-		operationAllowed = capabilities&UpdateCapabilityInt > 0
-		grantingPolicies = permissions.GrantingPoliciesMap[UpdateCapabilityInt]
+		return UpdateCapabilityInt, true
 	case logical.DeleteOperation:
-		operationAllowed = capabilities&DeleteCapabilityInt > 0
-		grantingPolicies = permissions.GrantingPoliciesMap[DeleteCapabilityInt]
+		return DeleteCapabilityInt, true
 	case logical.CreateOperation:
-		operationAllowed = capabilities&CreateCapabilityInt > 0
-		grantingPolicies = permissions.GrantingPoliciesMap[CreateCapabilityInt]
+		return CreateCapabilityInt, true
 	case logical.PatchOperation:
-		operationAllowed = capabilities&PatchCapabilityInt > 0
-		grantingPolicies = permissions.GrantingPoliciesMap[PatchCapabilityInt]
+		return PatchCapabilityInt, true
 	case logical.RecoverOperation:
-		operationAllowed = capabilities&RecoverCapabilityInt > 0
-		grantingPolicies = permissions.GrantingPoliciesMap[RecoverCapabilityInt]
+		return RecoverCapabilityInt, true
 
 	// These three re-use UpdateCapabilityInt since that's the most appropriate
 	// capability/operation mapping
 	case logical.RevokeOperation, logical.RenewOperation, logical.RollbackOperation:
-		operationAllowed = capabilities&UpdateCapabilityInt > 0
-		grantingPolicies = permissions.GrantingPoliciesMap[UpdateCapabilityInt]
+		return UpdateCapabilityInt, true
 
 	default:
-		return
-	}
-
-	if !operationAllowed {
-		return
+		return 0, false
 	}
+}
 
-	ret.GrantingPolicies = grantingPolicies
+// operationChecksParameters reports whether parameter permissions apply to
+// the operation. Only operations that can modify parameters are checked.
+func operationChecksParameters(op logical.Operation) bool {
+	return op == logical.ReadOperation || op == logical.UpdateOperation || op == logical.CreateOperation || op == logical.PatchOperation || op == logical.RecoverOperation
+}
 
-	if permissions.MaxWrappingTTL > 0 {
-		if req.WrapInfo == nil || req.WrapInfo.TTL > permissions.MaxWrappingTTL {
-			return
-		}
-	}
-	if permissions.MinWrappingTTL > 0 {
-		if req.WrapInfo == nil || req.WrapInfo.TTL < permissions.MinWrappingTTL {
-			return
+// checkParameterPermissions checks the request data against the required,
+// denied and allowed parameters of the permissions. It returns the reason the
+// data is rejected, or an empty string if it is permitted.
+func checkParameterPermissions(permissions *ACLPermissions, data map[string]interface{}) string {
+	for _, parameter := range permissions.RequiredParameters {
+		if _, ok := data[strings.ToLower(parameter)]; !ok {
+			return fmt.Sprintf("required parameter %q is missing", parameter)
 		}
 	}
-	// This situation can happen because of merging, even though in a single
-	// path statement we check on ingress
-	if permissions.MinWrappingTTL != 0 &&
-		permissions.MaxWrappingTTL != 0 &&
-		permissions.MaxWrappingTTL < permissions.MinWrappingTTL {
-		return
+
+	// If there are no data fields, allow
+	if len(data) == 0 {
+		return ""
 	}
 
-	// Only check parameter permissions for operations that can modify
-	// parameters.
-	if op == logical.ReadOperation || op == logical.UpdateOperation || op == logical.CreateOperation || op == logical.PatchOperation || op == logical.RecoverOperation {
-		for _, parameter := range permissions.RequiredParameters {
-			if _, ok := req.Data[strings.ToLower(parameter)]; !ok {
-				return
-			}
-		}
+	useLegacyMatching := os.Getenv("VAULT_LEGACY_EXACT_MATCHING_ON_LIST") != ""
 
-		// If there are no data fields, allow
-		if len(req.Data) == 0 {
-			ret.Allowed = true
-			return
+	if len(permissions.DeniedParameters) > 0 {
+		// Check if all parameters have been denied
+		if _, ok := permissions.DeniedParameters["*"]; ok {
+			return "all parameters are denied"
 		}
 
-		useLegacyMatching := os.Getenv("VAULT_LEGACY_EXACT_MATCHING_ON_LIST") != ""
-
-		if len(permissions.DeniedParameters) > 0 {
-			// Check if all parameters have been denied
-			if _, ok := permissions.DeniedParameters["*"]; ok {
-				return
-			}
-
-			for parameter, value := range req.Data {
-				// Check if parameter has been explicitly denied
-				if valueSlice, ok := permissions.DeniedParameters[strings.ToLower(parameter)]; ok {
-					// If the value exists in denied values slice, deny
-					if valueInDeniedParameterList(value, valueSlice, useLegacyMatching) {
-						return
-					}
+		for parameter, value := range data {
+			// Check if parameter has been explicitly denied
+			if valueSlice, ok := permissions.DeniedParameters[strings.ToLower(parameter)]; ok {
+				// If the value exists in denied values slice, deny
+				if valueInDeniedParameterList(value, valueSlice, useLegacyMatching) {
+					return fmt.Sprintf("parameter %q is denied", parameter)
 				}
 			}
 		}
+	}
 
-		// If we don't have any allowed parameters set, allow
-		if len(permissions.AllowedParameters) == 0 {
-			ret.Allowed = true
-			return
-		}
+	// If we don't have any allowed parameters set, allow
+	if len(permissions.AllowedParameters) == 0 {
+		return ""
+	}
 
-		_, allowedAll := permissions.AllowedParameters["*"]
-		if len(permissions.AllowedParameters) == 1 && allowedAll {
-			ret.Allowed = true
-			return
-		}
+	_, allowedAll := permissions.AllowedParameters["*"]
+	if len(permissions.AllowedParameters) == 1 && allowedAll {
+		return ""
+	}
 
-		for parameter, value := range req.Data {
-			valueSlice, ok := permissions.AllowedParameters[strings.ToLower(parameter)]
-			// Requested parameter is not in allowed list
-			if !ok && !allowedAll {
-				return
-			}
+	for parameter, value := range data {
+		valueSlice, ok := permissions.AllowedParameters[strings.ToLower(parameter)]
+		// Requested parameter is not in allowed list
+		if !ok && !allowedAll {
+			return fmt.Sprintf("parameter %q is not allowed", parameter)
+		}
 
-			// If the value doesn't exist in the allowed values slice,
-			// deny
-			if ok && !valueInAllowedParameterList(value, valueSlice, useLegacyMatching) {
-				return
-			}
+		// If the value doesn't exist in the allowed values slice,
+		// deny
+		if ok && !valueInAllowedParameterList(value, valueSlice, useLegacyMatching) {
+			return fmt.Sprintf("value of parameter %q is not allowed", parameter)
 		}
 	}
 
-	ret.Allowed = true
-	return
+	return ""
 }
 
 // wcPathDescr is a single candidate ACL rule matched via a prefix-glob or
@@ -0,0 +1,277 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// The ways in which a path rule of a policy can match a request path.
+const (
+	ACLRuleMatchExact           = "exact"
+	ACLRuleMatchGlob            = "glob"
+	ACLRuleMatchSegmentWildcard = "segment_wildcard"
+)
+
+// ACLExplanation describes how an ACL decides a request: the rule that was
+// selected for the request path, the rules of each policy that match the
+// path, and why the request is allowed or denied.
+type ACLExplanation struct {
+	Allowed bool
+	Reason  string
+
+	// Path is the full request path the rules were matched against.
+	Path string
+
+	// SelectedRule and SelectedMatch identify the rule that applies to the
+	// request, and Capabilities are its capabilities merged across all of
+	// the policies containing it.
+	SelectedRule     string
+	SelectedMatch    string
+	Capabilities     []string
+	GrantingPolicies []logical.PolicyInfo
+
+	Policies []*ACLPolicyExplanation
+}
+
+// ACLPolicyExplanation lists the path rules of a policy that match a request.
+type ACLPolicyExplanation struct {
+	Name          string                `json:"name"`
+	NamespacePath string                `json:"namespace_path"`
+	Rules         []*ACLRuleExplanation `json:"rules"`
+}
+
+// ACLRuleExplanation describes a path rule of a policy that matches a request
+// and whether it takes precedence over the other matching rules.
+type ACLRuleExplanation struct {
+	Path               string                   `json:"path"`
+	Match              string                   `json:"match"`
+	Capabilities       []string                 `json:"capabilities"`
+	Selected           bool                     `json:"selected"`
+	Precedence         string                   `json:"precedence"`
+	AllowedParameters  map[string][]interface{} `json:"allowed_parameters,omitempty"`
+	DeniedParameters   map[string][]interface{} `json:"denied_parameters,omitempty"`
+	RequiredParameters []string                 `json:"required_parameters,omitempty"`
+
+	// ParameterVerdict is "allowed", or the reason the parameters of the
+	// request are rejected by this rule on its own. It is only set for
+	// operations whose parameters are checked.
+	ParameterVerdict string `json:"parameter_verdict,omitempty"`
+
+	// ConditionVerdict is "met", or the reason the request does not meet
+	// the condition of the rule. It is only set for rules with a condition.
+	ConditionVerdict string `json:"condition_verdict,omitempty"`
+}
+
+// Explain describes how the ACL decides the request. The policies must be the
+// ones the ACL was built from. As with AllowOperation, the request path is
+// relative to the namespace in the context.
+func (a *ACL) Explain(ctx context.Context, policies []*Policy, req *logical.Request) (*ACLExplanation, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	op := req.Operation
+	capability, ok := operationCapability(op)
+	if !ok {
+		return nil, fmt.Errorf("unsupported operation %q", op)
+	}
+
+	results := a.AllowOperation(ctx, req, false)
+	ret := &ACLExplanation{
+		Allowed:          results.Allowed,
+		Path:             strings.TrimLeft(ns.Path+req.Path, "/"),
+		GrantingPolicies: results.GrantingPolicies,
+		Policies:         make([]*ACLPolicyExplanation, 0, len(policies)),
+	}
+	if results.IsRoot {
+		ret.Reason = "the root policy grants every operation"
+		ret.Capabilities = []string{RootCapability}
+		return ret, nil
+	}
+
+	now := time.Now()
+	permissions := a.permissionsForPath(ret.Path, op)
+	var capabilities uint32
+	if permissions != nil {
+		ret.SelectedRule, ret.SelectedMatch = a.ruleForPermissions(permissions)
+		capabilities = permissions.CapabilitiesBitmap
+		if capabilities&DenyCapabilityInt == 0 {
+			conditionalCapabilities, _ := permissions.conditionalCapabilities(req, now)
+			capabilities |= conditionalCapabilities
+		}
+		ret.Capabilities = capabilityNames(capabilities)
+	}
+
+	for _, policy := range policies {
+		if policy == nil || policy.Type != PolicyTypeACL {
+			continue
+		}
+
+		pe := &ACLPolicyExplanation{
+			Name:  policy.Name,
+			Rules: make([]*ACLRuleExplanation, 0),
+		}
+		if policy.namespace != nil {
+			pe.NamespacePath = policy.namespace.Path
+		}
+		for _, pc := range policy.Paths {
+			match, ok := ruleMatchesPath(pc, ret.Path, op)
+			if !ok {
+				continue
+			}
+
+			rule := &ACLRuleExplanation{
+				Path:               displayRulePath(pc.Path, match),
+				Match:              match,
+				Capabilities:       capabilityNames(pc.Permissions.CapabilitiesBitmap),
+				Selected:           pc.Path == ret.SelectedRule && match == ret.SelectedMatch,
+				AllowedParameters:  pc.Permissions.AllowedParameters,
+				DeniedParameters:   pc.Permissions.DeniedParameters,
+				RequiredParameters: pc.Permissions.RequiredParameters,
+			}
+			isDeny := pc.Permissions.CapabilitiesBitmap&DenyCapabilityInt > 0
+			switch {
+			case rule.Selected && permissions.CapabilitiesBitmap&DenyCapabilityInt > 0 && !isDeny:
+				rule.Precedence = "selected, but overridden by a deny on the same path in another policy"
+			case rule.Selected:
+				rule.Precedence = "selected"
+			case ret.SelectedRule != "":
+				rule.Precedence = fmt.Sprintf("overridden by %q", displayRulePath(ret.SelectedRule, ret.SelectedMatch))
+			default:
+				rule.Precedence = "not selected"
+			}
+			if operationChecksParameters(op) && !isDeny {
+				rule.ParameterVerdict = checkParameterPermissions(pc.Permissions, req.Data)
+				if rule.ParameterVerdict == "" {
+					rule.ParameterVerdict = "allowed"
+				}
+			}
+			if pc.ParsedCondition != nil {
+				rule.ConditionVerdict = pc.ParsedCondition.Check(req, now)
+				if rule.ConditionVerdict == "" {
+					rule.ConditionVerdict = "met"
+				}
+			}
+
+			pe.Rules = append(pe.Rules, rule)
+		}
+		ret.Policies = append(ret.Policies, pe)
+	}
+
+	capabilityName := capabilityNames(capability)[0]
+	var conditionReason string
+	if permissions != nil && capabilities&capability == 0 {
+		conditionReason = unmetConditionReason(permissions, capability, req, now)
+	}
+	switch {
+	case permissions == nil:
+		ret.Reason = "no policy has a rule matching the path"
+	case permissions.CapabilitiesBitmap&DenyCapabilityInt > 0:
+		ret.Reason = fmt.Sprintf("the path is explicitly denied by %q", displayRulePath(ret.SelectedRule, ret.SelectedMatch))
+	case conditionReason != "":
+		ret.Reason = fmt.Sprintf("%q grants the %q capability only on a condition: %s", displayRulePath(ret.SelectedRule, ret.SelectedMatch), capabilityName, conditionReason)
+	case capabilities&capability == 0:
+		ret.Reason = fmt.Sprintf("%q does not grant the %q capability", displayRulePath(ret.SelectedRule, ret.SelectedMatch), capabilityName)
+	case ret.Allowed:
+		ret.Reason = fmt.Sprintf("%q grants the %q capability", displayRulePath(ret.SelectedRule, ret.SelectedMatch), capabilityName)
+	case !a.scopesAllow(ctx, req):
+		ret.Reason = fmt.Sprintf("%q grants the %q capability, but the request is outside of the scope the token was limited to when it was created", displayRulePath(ret.SelectedRule, ret.SelectedMatch), capabilityName)
+	case permissions.MinWrappingTTL > 0 || permissions.MaxWrappingTTL > 0:
+		ret.Reason = "the response must be wrapped within the wrapping TTL bounds of the rule"
+	case operationChecksParameters(op) && checkParameterPermissions(permissions, req.Data) != "":
+		ret.Reason = checkParameterPermissions(permissions, req.Data)
+	default:
+		ret.Reason = "the request is denied"
+	}
+
+	return ret, nil
+}
+
+// unmetConditionReason returns why the request does not meet the condition of
+// the first conditional grant of the capability, prefixed with the name of the
+// policy of the grant.
+func unmetConditionReason(permissions *ACLPermissions, capability uint32, req *logical.Request, now time.Time) string {
+	for _, grant := range permissions.ConditionalGrants {
+		if grant.CapabilitiesBitmap&capability == 0 {
+			continue
+		}
+		if reason := grant.Condition.Check(req, now); reason != "" {
+			return fmt.Sprintf("in policy %q, %s", grant.Policy.Name, reason)
+		}
+	}
+	return ""
+}
+
+// ruleForPermissions returns the path and the kind of match of the rule whose
+// merged permissions are given.
+func (a *ACL) ruleForPermissions(permissions *ACLPermissions) (string, string) {
+	var rulePath, match string
+	find := func(kind string) func(string, interface{}) bool {
+		return func(path string, raw interface{}) bool {
+			if raw.(*ACLPermissions) != permissions {
+				return false
+			}
+			rulePath, match = path, kind
+			return true
+		}
+	}
+
+	a.exactRules.Walk(find(ACLRuleMatchExact))
+	if match != "" {
+		return rulePath, match
+	}
+	a.prefixRules.Walk(find(ACLRuleMatchGlob))
+	if match != "" {
+		return rulePath, match
+	}
+	for path, raw := range a.segmentWildcardPaths {
+		if find(ACLRuleMatchSegmentWildcard)(path, raw) {
+			break
+		}
+	}
+	return rulePath, match
+}
+
+// ruleMatchesPath reports whether a path rule matches the full request path,
+// and how. The path forms tried are the ones AllowOperation considers.
+func ruleMatchesPath(pc *PathRules, path string, op logical.Operation) (string, bool) {
+	paths := []string{path}
+	if op == logical.ListOperation && strings.HasSuffix(path, "/") {
+		paths = append(paths, strings.TrimSuffix(path, "/"))
+	}
+
+	for _, path := range paths {
+		switch {
+		case pc.HasSegmentWildcards:
+			wc := &ACL{segmentWildcardPaths: map[string]interface{}{pc.Path: pc.Permissions}}
+			if _, ok := wc.tryMatchWildcardPath(pc.Path, strings.Split(path, "/"), false); ok {
+				return ACLRuleMatchSegmentWildcard, true
+			}
+		case pc.IsPrefix:
+			if strings.HasPrefix(path, pc.Path) {
+				return ACLRuleMatchGlob, true
+			}
+		case pc.Path == path:
+			return ACLRuleMatchExact, true
+		}
+	}
+	return "", false
+}
+
+// displayRulePath returns a rule path as it is written in a policy. Glob rules
+// are stored without their trailing glob.
+func displayRulePath(path, match string) string {
+	if match == ACLRuleMatchGlob {
+		return path + "*"
+	}
+	return path
+}
@@ -0,0 +1,131 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACL_Explain(t *testing.T) {
+	ctx := namespace.RootContext(context.Background())
+	parse := func(name, rules string) *Policy {
+		t.Helper()
+		policy, err := ParseACLPolicy(namespace.RootNamespace, rules)
+		require.NoError(t, err)
+		policy.Name = name
+		return policy
+	}
+	policies := []*Policy{
+		parse("dev", `
+path "secret/*" {
+	capabilities = ["read", "list"]
+}
+path "secret/+/config" {
+	capabilities = ["read", "update"]
+	allowed_parameters = {
+		"ttl" = ["1h", "2h"]
+	}
+}
+`),
+		parse("ops", `
+path "secret/app/config" {
+	capabilities = ["read"]
+	required_parameters = ["reason"]
+}
+path "secret/app/keys" {
+	capabilities = ["deny"]
+}
+`),
+		parse("audit", `
+path "secret/app/keys" {
+	capabilities = ["read"]
+}
+`),
+	}
+	acl, err := NewACL(ctx, policies)
+	require.NoError(t, err)
+
+	explain := func(op logical.Operation, path string, data map[string]interface{}) *ACLExplanation {
+		t.Helper()
+		explanation, err := acl.Explain(ctx, policies, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		return explanation
+	}
+	rules := func(explanation *ACLExplanation) map[string]*ACLRuleExplanation {
+		ret := make(map[string]*ACLRuleExplanation)
+		for _, policy := range explanation.Policies {
+			for _, rule := range policy.Rules {
+				ret[policy.Name+":"+rule.Path] = rule
+			}
+		}
+		return ret
+	}
+
+	// The exact rule takes precedence over the segment wildcard and the glob,
+	// and its required parameter is missing
+	explanation := explain(logical.UpdateOperation, "secret/app/config", map[string]interface{}{
+		"ttl": "1h",
+	})
+	require.False(t, explanation.Allowed)
+	require.Equal(t, "secret/app/config", explanation.SelectedRule)
+	require.Equal(t, ACLRuleMatchExact, explanation.SelectedMatch)
+	require.Equal(t, `"secret/app/config" does not grant the "update" capability`, explanation.Reason)
+	matched := rules(explanation)
+	require.Len(t, matched, 3)
+	require.True(t, matched["ops:secret/app/config"].Selected)
+	require.Equal(t, `required parameter "reason" is missing`, matched["ops:secret/app/config"].ParameterVerdict)
+	require.Equal(t, ACLRuleMatchSegmentWildcard, matched["dev:secret/+/config"].Match)
+	require.Equal(t, `overridden by "secret/app/config"`, matched["dev:secret/+/config"].Precedence)
+	require.Equal(t, "allowed", matched["dev:secret/+/config"].ParameterVerdict)
+	require.Equal(t, ACLRuleMatchGlob, matched["dev:secret/*"].Match)
+	require.Len(t, explanation.Policies, 3)
+	require.Empty(t, explanation.Policies[2].Rules)
+
+	// The segment wildcard takes precedence over the glob, and rejects the
+	// parameter value
+	explanation = explain(logical.UpdateOperation, "secret/db/config", map[string]interface{}{
+		"ttl": "24h",
+	})
+	require.False(t, explanation.Allowed)
+	require.Equal(t, "secret/+/config", explanation.SelectedRule)
+	require.Equal(t, `value of parameter "ttl" is not allowed`, explanation.Reason)
+	explanation = explain(logical.UpdateOperation, "secret/db/config", map[string]interface{}{
+		"ttl": "2h",
+	})
+	require.True(t, explanation.Allowed)
+	require.Equal(t, `"secret/+/config" grants the "update" capability`, explanation.Reason)
+	require.Equal(t, "dev", explanation.GrantingPolicies[0].Name)
+
+	// A deny on the same path wins over the capabilities of other policies
+	explanation = explain(logical.ReadOperation, "secret/app/keys", nil)
+	require.False(t, explanation.Allowed)
+	require.Equal(t, `the path is explicitly denied by "secret/app/keys"`, explanation.Reason)
+	require.Equal(t, []string{DenyCapability}, explanation.Capabilities)
+	matched = rules(explanation)
+	require.Equal(t, "selected", matched["ops:secret/app/keys"].Precedence)
+	require.Equal(t, "selected, but overridden by a deny on the same path in another policy", matched["audit:secret/app/keys"].Precedence)
+	require.Equal(t, `overridden by "secret/app/keys"`, matched["dev:secret/*"].Precedence)
+
+	explanation = explain(logical.ListOperation, "secret/", nil)
+	require.True(t, explanation.Allowed)
+	require.Equal(t, "secret/", explanation.SelectedRule)
+	require.Equal(t, ACLRuleMatchGlob, explanation.SelectedMatch)
+
+	explanation = explain(logical.ReadOperation, "sys/mounts", nil)
+	require.False(t, explanation.Allowed)
+	require.Equal(t, "no policy has a rule matching the path", explanation.Reason)
+	require.Empty(t, rules(explanation))
+
+	_, err = acl.Explain(ctx, policies, &logical.Request{Operation: logical.HelpOperation, Path: "secret/app"})
+	require.Error(t, err)
+}
@@ -0,0 +1,126 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// scopePolicyName is the name given to the scope policies of a token in the
+// ACL, as they have none of their own.
+const scopePolicyName = "token-scope"
+
+// parseScopePolicy parses a scope policy of a token. Scope policies are
+// static, as they are checked against the ACL of the token when it is created.
+func parseScopePolicy(ns *namespace.Namespace, raw string, opts ...ParseACLPolicyOption) (*Policy, error) {
+	policy, err := ParseACLPolicy(ns, raw, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Templated {
+		return nil, errors.New("inline policy cannot use identity templating")
+	}
+	policy.Name = scopePolicyName
+	return policy, nil
+}
+
+// scopeTokenACL limits the ACL of a token to what its scope policies allow.
+// The context must be in the namespace of the token.
+func (c *Core) scopeTokenACL(ctx context.Context, tokenNS *namespace.Namespace, te *logical.TokenEntry, acl *ACL) error {
+	for _, raw := range te.ScopePolicies {
+		policy, err := parseScopePolicy(tokenNS, raw, WithDenySlashInTemplatedPaths(c.denySlashInTemplatedPolicyPaths))
+		if err != nil {
+			return fmt.Errorf("failed to parse scope policy of token: %w", err)
+		}
+		scope, err := NewACL(ctx, []*Policy{policy})
+		if err != nil {
+			return fmt.Errorf("failed to construct scope ACL of token: %w", err)
+		}
+		acl.scopes = append(acl.scopes, scope)
+	}
+	return nil
+}
+
+// allowScopedOperation checks an operation against the ACL and each of its
+// scopes, and returns the intersection of their results. The granting
+// policies are the ones of the ACL, as scopes only restrict it.
+func (a *ACL) allowScopedOperation(ctx context.Context, req *logical.Request, capCheckOnly bool) *ACLResults {
+	unscoped := *a
+	unscoped.scopes = nil
+	ret := unscoped.AllowOperation(ctx, req, capCheckOnly)
+
+	for _, scope := range a.scopes {
+		scoped := scope.AllowOperation(ctx, req, capCheckOnly)
+
+		// The scopes of a root token are all it is allowed to do
+		if ret.IsRoot {
+			ret = &ACLResults{
+				Allowed:             scoped.Allowed,
+				RootPrivs:           scoped.RootPrivs,
+				MFAMethods:          scoped.MFAMethods,
+				ControlGroup:        scoped.ControlGroup,
+				CapabilitiesBitmap:  scoped.CapabilitiesBitmap,
+				GrantingPolicies:    ret.GrantingPolicies,
+				SubscribeEventTypes: scoped.SubscribeEventTypes,
+			}
+			continue
+		}
+
+		ret.Allowed = ret.Allowed && scoped.Allowed
+		ret.RootPrivs = ret.RootPrivs && scoped.RootPrivs
+		ret.CapabilitiesBitmap &= scoped.CapabilitiesBitmap
+		ret.MFAMethods = strutil.RemoveDuplicates(append(ret.MFAMethods, scoped.MFAMethods...), false)
+		if ret.ControlGroup == nil {
+			ret.ControlGroup = scoped.ControlGroup
+		}
+		ret.SubscribeEventTypes = intersectEventTypes(ret.SubscribeEventTypes, scoped.SubscribeEventTypes)
+	}
+	if !ret.Allowed {
+		ret.GrantingPolicies = nil
+	}
+	return ret
+}
+
+// intersectEventTypes returns the event types in both lists, where "*" stands
+// for every event type.
+func intersectEventTypes(a, b []string) []string {
+	switch {
+	case strutil.StrListContains(a, "*"):
+		return b
+	case strutil.StrListContains(b, "*"):
+		return a
+	}
+
+	var ret []string
+	for _, eventType := range a {
+		if strutil.StrListContains(b, eventType) ||
+			strutil.StrListContainsGlob(b, eventType) {
+			ret = append(ret, eventType)
+			continue
+		}
+		for _, other := range b {
+			if strings.HasSuffix(eventType, "*") && strutil.GlobbedStringsMatch(eventType, other) {
+				ret = append(ret, other)
+			}
+		}
+	}
+	return strutil.RemoveDuplicates(ret, false)
+}
+
+// scopesAllow reports whether every scope of the ACL allows the request.
+func (a *ACL) scopesAllow(ctx context.Context, req *logical.Request) bool {
+	for _, scope := range a.scopes {
+		if !scope.AllowOperation(ctx, req, false).Allowed {
+			return false
+		}
+	}
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"sort"
 
+	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -37,36 +38,66 @@ func (c *Core) CapabilitiesAndSubscribeEventTypes(ctx context.Context, token, pa
 		return nil, nil, &logical.StatusBadRequest{Err: "invalid token"}
 	}
 
-	var tokenNS *namespace.Namespace
-	tokenNS, err = NamespaceByID(ctx, te.NamespaceID, c)
+	tokenNS, entity, policyNames, policies, err := c.tokenPolicies(ctx, te)
 	if err != nil {
 		return nil, nil, err
 	}
+
+	policyCount := len(policies)
+	for _, nsPolicies := range policyNames {
+		policyCount += len(nsPolicies)
+	}
+
+	if policyCount == 0 {
+		return []string{DenyCapability}, nil, nil
+	}
+
+	// Construct the corresponding ACL object. ACL construction should be
+	// performed on the token's namespace.
+	tokenCtx := namespace.ContextWithNamespace(ctx, tokenNS)
+	acl, err := c.policyStore.ACL(tokenCtx, entity, policyNames, policies...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.scopeTokenACL(tokenCtx, tokenNS, te, acl); err != nil {
+		return nil, nil, err
+	}
+
+	capabilities, eventTypes := acl.CapabilitiesAndSubscribeEventTypes(ctx, path)
+	sort.Strings(capabilities)
+	return capabilities, eventTypes, nil
+}
+
+// tokenPolicies returns the namespace and the entity of the token, along with
+// the names of its token and identity policies and its parsed inline policy,
+// which together make up the ACL of the token.
+func (c *Core) tokenPolicies(ctx context.Context, te *logical.TokenEntry) (*namespace.Namespace, *identity.Entity, map[string][]string, []*Policy, error) {
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, c)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
 	if tokenNS == nil {
-		return nil, nil, namespace.ErrNoNamespace
+		return nil, nil, nil, nil, namespace.ErrNoNamespace
 	}
 
-	var policyCount int
 	policyNames := make(map[string][]string)
 	policyNames[tokenNS.ID] = te.Policies
-	policyCount += len(te.Policies)
 
 	entity, identityPolicies, err := c.fetchEntityAndDerivedPolicies(ctx, tokenNS, te.EntityID, te.NoIdentityPolicies)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 	if entity != nil && entity.Disabled {
 		c.logger.Warn("permission denied as the entity on the token is disabled")
-		return nil, nil, logical.ErrPermissionDenied
+		return nil, nil, nil, nil, logical.ErrPermissionDenied
 	}
 	if te.EntityID != "" && entity == nil {
 		c.logger.Warn("permission denied as the entity on the token is invalid")
-		return nil, nil, logical.ErrPermissionDenied
+		return nil, nil, nil, nil, logical.ErrPermissionDenied
 	}
 
 	for nsID, nsPolicies := range identityPolicies {
 		policyNames[nsID] = append(policyNames[nsID], nsPolicies...)
-		policyCount += len(nsPolicies)
 	}
 
 	// Add capabilities of the inline policy if it's set
@@ -75,28 +106,13 @@ func (c *Core) CapabilitiesAndSubscribeEventTypes(ctx context.Context, token, pa
 		// TODO (HCL_DUP_KEYS_DEPRECATION): return to ParseACLPolicy once the deprecation is done
 		inlinePolicy, duplicate, err := ParseACLPolicyCheckDuplicates(tokenNS, te.InlinePolicy, WithDenySlashInTemplatedPaths(c.denySlashInTemplatedPolicyPaths))
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, nil, err
 		}
 		if duplicate {
 			c.logger.Warn("HCL inline policy contains duplicate attributes, which will no longer be supported in a future version", "namespace", tokenNS.Path)
 		}
 		policies = append(policies, inlinePolicy)
-		policyCount++
 	}
 
-	if policyCount == 0 {
-		return []string{DenyCapability}, nil, nil
-	}
-
-	// Construct the corresponding ACL object. ACL construction should be
-	// performed on the token's namespace.
-	tokenCtx := namespace.ContextWithNamespace(ctx, tokenNS)
-	acl, err := c.policyStore.ACL(tokenCtx, entity, policyNames, policies...)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	capabilities, eventTypes := acl.CapabilitiesAndSubscribeEventTypes(ctx, path)
-	sort.Strings(capabilities)
-	return capabilities, eventTypes, nil
+	return tokenNS, entity, policyNames, policies, nil
 }
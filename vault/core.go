@@ -487,6 +487,12 @@ type Core struct {
 	mfaResponseAuthQueue     *LoginMFAPriorityQueue
 	mfaResponseAuthQueueLock sync.Mutex
 
+	// dpopSeenProofs records recently accepted DPoP proofs to reject replays
+	dpopSeenProofs *cache.Cache
+
+	// dpopNonces are the nonces DPoP proofs sent to this node must carry
+	dpopNonces *dpopNonces
+
 	// metricSink is the destination for all metrics that have
 	// a cluster label.
 	metricSink *metricsutil.ClusterMetricSink
@@ -674,6 +680,11 @@ type Core struct {
 	raftFollowerStates *raft.FollowerStates
 	// Stop channel for raft TLS rotations
 	raftTLSRotationStopCh chan struct{}
+	// Takes the automated raft snapshots on the active node
+	raftSnapshotAuto *raftSnapshotAutoManager
+	// Holds the raft snapshots loaded on the active node for reads and
+	// selective restores
+	raftSnapshotLoad *raftSnapshotLoadManager
 	// Stores the pending peers we are waiting to give answers
 	pendingRaftPeers *lru.Cache[string, *raftBootstrapChallenge]
 	// holds the lock for modifying pendingRaftPeers
@@ -1146,6 +1157,8 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		clusterName:                     conf.ClusterName,
 		clusterNetworkLayer:             conf.ClusterNetworkLayer,
 		clusterPeerClusterAddrsCache:    cache.New(3*clusterHeartbeatInterval, time.Second),
+		dpopSeenProofs:                  newDPoPSeenProofsCache(),
+		dpopNonces:                      &dpopNonces{},
 		enableMlock:                     !conf.DisableMlock,
 		rawEnabled:                      conf.EnableRaw,
 		introspectionEnabled:            conf.EnableIntrospection,
@@ -1404,6 +1417,8 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 		c.ha = conf.HAPhysical
 	}
 
+	c.raftSnapshotLoad = newRaftSnapshotLoadManager(c)
+
 	// MFA method
 	c.loginMFABackend = NewLoginMFABackend(c, conf.Logger)
 	if c.loginMFABackend.mfaLogger != nil {
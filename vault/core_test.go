@@ -1672,6 +1672,46 @@ func TestCore_HandleLogin_Token(t *testing.T) {
 	}
 }
 
+func TestCore_HandleLogin_BoundBatchToken(t *testing.T) {
+	noop := &NoopBackend{
+		Login: []string{"login"},
+		Response: &logical.Response{
+			Auth: &logical.Auth{
+				Policies:                   []string{"foo"},
+				BoundCertificateThumbprint: "thumbprint",
+			},
+		},
+		BackendType: logical.TypeCredential,
+	}
+	c, _, root := TestCoreUnsealed(t)
+	c.credentialBackends["noop"] = func(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+		return noop, nil
+	}
+
+	// Enable the credential backend issuing batch tokens by default
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/auth/foo")
+	req.Data["type"] = "noop"
+	req.Data["config"] = map[string]interface{}{
+		"token_type": "default-batch",
+	}
+	req.ClientToken = root
+	_, err := c.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Batch tokens can't be bound, which is a client error
+	lresp, err := c.HandleRequest(namespace.RootContext(nil), &logical.Request{
+		Path: "auth/foo/login",
+	})
+	if !errors.Is(err, logical.ErrInvalidRequest) {
+		t.Fatalf("expected an invalid request error, got %v", err)
+	}
+	if lresp == nil || !lresp.IsError() {
+		t.Fatalf("expected an error response, got %#v", lresp)
+	}
+}
+
 func TestCore_HandleRequest_AuditTrail(t *testing.T) {
 	// Create a noop audit backend
 	var noop *audit.NoopAudit
@@ -0,0 +1,216 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	// dpopProofType is the required "typ" header of a DPoP proof JWT, see
+	// RFC 9449 section 4.2.
+	dpopProofType = "dpop+jwt"
+
+	// dpopProofMaxSkew bounds how far the "iat" claim of a proof may be from
+	// the current time, in either direction.
+	dpopProofMaxSkew = 60 * time.Second
+
+	// dpopNonceRotation is how often the DPoP nonce of the node changes.
+	// Proofs carrying the current or the previous nonce are accepted.
+	dpopNonceRotation = dpopProofMaxSkew
+)
+
+// ErrDPoPNonceRequired is returned for proofs that don't carry a current
+// nonce of this node. The client should retry with the nonce returned by
+// DPoPNonce, see RFC 9449 section 8.
+var ErrDPoPNonceRequired = errors.New("proof must carry a current nonce")
+
+// dpopAllowedAlgs are the asymmetric signature algorithms accepted for DPoP
+// proofs. Symmetric algorithms are never allowed since the proof must
+// demonstrate possession of a private key.
+var dpopAllowedAlgs = []jose.SignatureAlgorithm{
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.EdDSA,
+}
+
+type dpopProofClaims struct {
+	jwt.Claims
+
+	// HTTPMethod is the "htm" claim, the HTTP method of the request
+	HTTPMethod string `json:"htm"`
+
+	// HTTPURI is the "htu" claim, the HTTP URI of the request without query
+	// and fragment parts
+	HTTPURI string `json:"htu"`
+
+	// AccessTokenHash is the "ath" claim, the base64url-encoded SHA-256 hash
+	// of the access token presented with the proof
+	AccessTokenHash string `json:"ath,omitempty"`
+
+	// Nonce is the "nonce" claim, a nonce issued by Vault
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// dpopNonces are the DPoP nonces issued by the node. They are random and
+// never shared with other nodes, so that a proof accepted by one node can't
+// be replayed against another one that hasn't seen its jti.
+type dpopNonces struct {
+	l        sync.Mutex
+	current  string
+	previous string
+	rotated  time.Time
+}
+
+// get returns the current and previous nonces, rotating them when due.
+func (n *dpopNonces) get() (string, string, error) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	if n.current == "" || time.Since(n.rotated) >= dpopNonceRotation {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", "", err
+		}
+		// A nonce older than twice the rotation is not accepted anymore
+		if time.Since(n.rotated) >= 2*dpopNonceRotation {
+			n.current = ""
+		}
+		n.previous = n.current
+		n.current = base64.RawURLEncoding.EncodeToString(b)
+		n.rotated = time.Now()
+	}
+	return n.current, n.previous, nil
+}
+
+// DPoPNonce returns the nonce that DPoP proofs sent to this node must carry.
+func (c *Core) DPoPNonce() (string, error) {
+	nonce, _, err := c.dpopNonces.get()
+	return nonce, err
+}
+
+// ValidateDPoPProof verifies a DPoP proof JWT (RFC 9449) presented with an
+// HTTP request and returns the RFC 7638 thumbprint of the public key that
+// signed it. The proof must be bound to the given method and request path,
+// must be fresh, and must not have been seen before. If accessToken is set,
+// the proof must also carry its hash in the "ath" claim.
+//
+// Each "jti" is accepted only once within the allowed clock skew on this
+// node. Since that cache is local, proofs must also carry a nonce issued by
+// this node, otherwise ErrDPoPNonceRequired is returned.
+func (c *Core) ValidateDPoPProof(proof, method, requestPath, accessToken string) (string, error) {
+	jws, err := jose.ParseSigned(proof, dpopAllowedAlgs)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse proof: %w", err)
+	}
+	if len(jws.Signatures) != 1 {
+		return "", errors.New("proof must have exactly one signature")
+	}
+
+	header := jws.Signatures[0].Protected
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != dpopProofType {
+		return "", fmt.Errorf("proof must have a %q type header", dpopProofType)
+	}
+	if !dpopAlgAllowed(header.Algorithm) {
+		return "", fmt.Errorf("unsupported proof signing algorithm %q", header.Algorithm)
+	}
+	jwk := header.JSONWebKey
+	if jwk == nil || !jwk.Valid() || !jwk.IsPublic() {
+		return "", errors.New("proof must embed a valid public key in its jwk header")
+	}
+
+	payload, err := jws.Verify(jwk)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify proof signature: %w", err)
+	}
+
+	var claims dpopProofClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("failed to decode proof claims: %w", err)
+	}
+
+	if claims.ID == "" {
+		return "", errors.New("proof is missing the jti claim")
+	}
+	if !strings.EqualFold(claims.HTTPMethod, method) {
+		return "", errors.New("proof htm claim does not match the request method")
+	}
+	htu, err := url.Parse(claims.HTTPURI)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse proof htu claim: %w", err)
+	}
+	// Only the path is compared since the scheme and host seen by Vault can
+	// legitimately differ from the client's view when behind a load balancer.
+	if htu.Path != requestPath {
+		return "", errors.New("proof htu claim does not match the request path")
+	}
+
+	if claims.IssuedAt == nil {
+		return "", errors.New("proof is missing the iat claim")
+	}
+	issuedAt := claims.IssuedAt.Time()
+	now := time.Now()
+	if issuedAt.Before(now.Add(-dpopProofMaxSkew)) || issuedAt.After(now.Add(dpopProofMaxSkew)) {
+		return "", errors.New("proof iat claim is outside of the allowed window")
+	}
+
+	current, previous, err := c.dpopNonces.get()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	if claims.Nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(current)) != 1 &&
+		(previous == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(previous)) != 1) {
+		return "", ErrDPoPNonceRequired
+	}
+
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		if claims.AccessTokenHash != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return "", errors.New("proof ath claim does not match the presented token")
+		}
+	}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute proof key thumbprint: %w", err)
+	}
+	encodedThumbprint := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	// Scope replay detection to the key so that one client cannot exhaust
+	// the jti space of another.
+	if err := c.dpopSeenProofs.Add(encodedThumbprint+":"+claims.ID, struct{}{}, 2*dpopProofMaxSkew); err != nil {
+		return "", errors.New("proof has already been used")
+	}
+
+	return encodedThumbprint, nil
+}
+
+func dpopAlgAllowed(alg string) bool {
+	for _, allowed := range dpopAllowedAlgs {
+		if alg == string(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func newDPoPSeenProofsCache() *cache.Cache {
+	return cache.New(2*dpopProofMaxSkew, dpopProofMaxSkew)
+}
@@ -0,0 +1,116 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func testDPoPProof(t *testing.T, key *ecdsa.PrivateKey, claims dpopProofClaims) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, &jose.SignerOptions{
+		EmbedJWK: true,
+		ExtraHeaders: map[jose.HeaderKey]interface{}{
+			jose.HeaderType: dpopProofType,
+		},
+	})
+	require.NoError(t, err)
+
+	proof, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return proof
+}
+
+// TestCore_ValidateDPoPProof verifies that DPoP proofs are bound to the
+// request method, path, token and node nonce, and cannot be replayed.
+func TestCore_ValidateDPoPProof(t *testing.T) {
+	c := &Core{dpopSeenProofs: newDPoPSeenProofsCache(), dpopNonces: &dpopNonces{}}
+	nonce, err := c.DPoPNonce()
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	token := "hvs.test"
+	sum := sha256.Sum256([]byte(token))
+	claims := func(jti string) dpopProofClaims {
+		return dpopProofClaims{
+			Claims: jwt.Claims{
+				ID:       jti,
+				IssuedAt: jwt.NewNumericDate(time.Now()),
+			},
+			HTTPMethod:      "GET",
+			HTTPURI:         "https://vault.example.com:8200/v1/secret/foo",
+			AccessTokenHash: base64.RawURLEncoding.EncodeToString(sum[:]),
+			Nonce:           nonce,
+		}
+	}
+
+	proof := testDPoPProof(t, key, claims("1"))
+	thumbprint, err := c.ValidateDPoPProof(proof, "GET", "/v1/secret/foo", token)
+	require.NoError(t, err)
+	require.NotEmpty(t, thumbprint)
+
+	// Replaying the same proof is rejected
+	_, err = c.ValidateDPoPProof(proof, "GET", "/v1/secret/foo", token)
+	require.ErrorContains(t, err, "already been used")
+
+	// A fresh proof from the same key yields the same thumbprint
+	second, err := c.ValidateDPoPProof(testDPoPProof(t, key, claims("2")), "GET", "/v1/secret/foo", token)
+	require.NoError(t, err)
+	require.Equal(t, thumbprint, second)
+
+	_, err = c.ValidateDPoPProof(testDPoPProof(t, key, claims("3")), "POST", "/v1/secret/foo", token)
+	require.ErrorContains(t, err, "htm")
+
+	_, err = c.ValidateDPoPProof(testDPoPProof(t, key, claims("4")), "GET", "/v1/secret/bar", token)
+	require.ErrorContains(t, err, "htu")
+
+	_, err = c.ValidateDPoPProof(testDPoPProof(t, key, claims("5")), "GET", "/v1/secret/foo", "hvs.other")
+	require.ErrorContains(t, err, "ath")
+
+	stale := claims("6")
+	stale.IssuedAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Minute))
+	_, err = c.ValidateDPoPProof(testDPoPProof(t, key, stale), "GET", "/v1/secret/foo", token)
+	require.ErrorContains(t, err, "iat")
+
+	// Login requests carry no token, so no ath claim is required
+	login := claims("7")
+	login.AccessTokenHash = ""
+	_, err = c.ValidateDPoPProof(testDPoPProof(t, key, login), "GET", "/v1/secret/foo", "")
+	require.NoError(t, err)
+
+	// Proofs must carry a nonce of this node
+	missing := claims("8")
+	missing.Nonce = ""
+	_, err = c.ValidateDPoPProof(testDPoPProof(t, key, missing), "GET", "/v1/secret/foo", token)
+	require.ErrorIs(t, err, ErrDPoPNonceRequired)
+
+	other := &Core{dpopSeenProofs: newDPoPSeenProofsCache(), dpopNonces: &dpopNonces{}}
+	_, err = other.ValidateDPoPProof(testDPoPProof(t, key, claims("9")), "GET", "/v1/secret/foo", token)
+	require.ErrorIs(t, err, ErrDPoPNonceRequired)
+
+	// The previous nonce is still accepted after a rotation, but not older ones
+	c.dpopNonces.rotated = time.Now().Add(-dpopNonceRotation)
+	rotated, err := c.DPoPNonce()
+	require.NoError(t, err)
+	require.NotEqual(t, nonce, rotated)
+	_, err = c.ValidateDPoPProof(testDPoPProof(t, key, claims("10")), "GET", "/v1/secret/foo", token)
+	require.NoError(t, err)
+
+	c.dpopNonces.rotated = time.Now().Add(-2 * dpopNonceRotation)
+	_, err = c.ValidateDPoPProof(testDPoPProof(t, key, claims("11")), "GET", "/v1/secret/foo", token)
+	require.ErrorIs(t, err, ErrDPoPNonceRequired)
+}
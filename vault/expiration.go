@@ -179,6 +179,10 @@ type ExpirationManager struct {
 
 	jobManager      *fairshare.JobManager
 	revokeRetryBase time.Duration
+
+	// leaseIndex indexes the leases in the pending, nonexpiring and
+	// irrevocable maps for lease queries. It is updated with pendingLock held.
+	leaseIndex *leaseIndex
 }
 
 type ExpireLeaseStrategy func(context.Context, *ExpirationManager, string, *namespace.Namespace)
@@ -397,6 +401,7 @@ func NewExpirationManager(c *Core, view *BarrierView, e ExpireLeaseStrategy, log
 
 		jobManager:      jobManager,
 		revokeRetryBase: c.expirationRevokeRetryBase,
+		leaseIndex:      newLeaseIndex(),
 	}
 	exp.expireFunc.Store(&e)
 	if exp.revokeRetryBase == 0 {
@@ -534,6 +539,7 @@ func (m *ExpirationManager) invalidate(key string) {
 				pending := info.(pendingInfo)
 				pending.timer.Stop()
 				m.pending.Delete(leaseID)
+				m.leaseIndex.remove(leaseID)
 				m.leaseCount--
 
 				// Avoid nil pointer dereference. Without cachedLeaseInfo we do not have enough information to
@@ -556,6 +562,7 @@ func (m *ExpirationManager) invalidate(key string) {
 				// resulted in a nil entry. Therefore we should clean up the
 				// other maps, and update metrics/quotas if appropriate.
 				m.nonexpiring.Delete(leaseID)
+				m.leaseIndex.remove(leaseID)
 
 				if info, ok := m.irrevocable.Load(leaseID); ok {
 					ile := info.(*leaseEntry)
@@ -918,6 +925,7 @@ func (m *ExpirationManager) Stop() error {
 	m.expireFunc.Store(&newStrategy)
 	oldPending := &m.pending
 	m.pending, m.nonexpiring, m.irrevocable = sync.Map{}, sync.Map{}, sync.Map{}
+	m.leaseIndex.reset()
 	m.leaseCount = 0
 	m.uniquePolicies = make(map[string][]string)
 	m.irrevocableLeaseCount = 0
@@ -1125,6 +1133,7 @@ func (m *ExpirationManager) revokeCommon(ctx context.Context, leaseID string, fo
 	m.pendingLock.Lock()
 	m.removeFromPending(ctx, leaseID, true)
 	m.nonexpiring.Delete(leaseID)
+	m.leaseIndex.remove(leaseID)
 
 	if _, ok := m.irrevocable.Load(le.LeaseID); ok {
 		m.irrevocable.Delete(leaseID)
@@ -1604,6 +1613,8 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 		Data:            resp.Data,
 		Secret:          resp.Secret,
 		LoginRole:       loginRole,
+		EntityID:        te.EntityID,
+		AuthAccessor:    m.tokenAuthAccessor(ctx, te),
 		IssueTime:       time.Now(),
 		ExpireTime:      resp.Secret.ExpirationTime(),
 		namespace:       ns,
@@ -1783,15 +1794,17 @@ func (m *ExpirationManager) RegisterAuth(ctx context.Context, te *logical.TokenE
 	// Create a lease entry
 	issueTime := time.Now()
 	le := leaseEntry{
-		LeaseID:     leaseID,
-		ClientToken: auth.ClientToken,
-		Auth:        auth,
-		Path:        te.Path,
-		LoginRole:   loginRole,
-		IssueTime:   issueTime,
-		ExpireTime:  authExpirationTime,
-		namespace:   tokenNS,
-		Version:     1,
+		LeaseID:      leaseID,
+		ClientToken:  auth.ClientToken,
+		Auth:         auth,
+		Path:         te.Path,
+		LoginRole:    loginRole,
+		EntityID:     te.EntityID,
+		AuthAccessor: m.tokenAuthAccessor(ctx, te),
+		IssueTime:    issueTime,
+		ExpireTime:   authExpirationTime,
+		namespace:    tokenNS,
+		Version:      1,
 	}
 
 	leaseLock := m.lockForLeaseID(leaseID)
@@ -1826,6 +1839,20 @@ func (m *ExpirationManager) RegisterAuth(ctx context.Context, te *logical.TokenE
 	return nil
 }
 
+// tokenAuthAccessor returns the accessor of the auth method that issued the
+// token, or an empty string if it is not mounted anymore.
+func (m *ExpirationManager) tokenAuthAccessor(ctx context.Context, te *logical.TokenEntry) string {
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, m.core)
+	if err != nil || tokenNS == nil {
+		return ""
+	}
+	mount := m.router.MatchingMountEntry(namespace.ContextWithNamespace(ctx, tokenNS), te.Path)
+	if mount == nil {
+		return ""
+	}
+	return mount.Accessor
+}
+
 // FetchLeaseTimesByToken is a helper function to use token values to compute
 // the leaseID, rather than pushing that logic back into the token store.
 // As a special case, for a batch token it simply returns the information
@@ -2044,6 +2071,8 @@ func (m *ExpirationManager) sendLeaseEvent(ctx context.Context, le *leaseEntry,
 // updatePendingInternal is the locked version of updatePending; do not call
 // this without a write lock on m.pending
 func (m *ExpirationManager) updatePendingInternal(le *leaseEntry) {
+	m.leaseIndex.add(le)
+
 	// Check for an existing timer
 	info, leaseInPending := m.pending.Load(le.LeaseID)
 
@@ -2305,6 +2334,12 @@ func (m *ExpirationManager) loadEntryInternal(ctx context.Context, leaseID strin
 			}
 		}
 
+		// Leases created before leases recorded the entity and the auth
+		// method of their token are backfilled from the token once
+		if le.AuthAccessor == "" && le.ClientToken != "" {
+			m.backfillTokenInfo(ctx, le)
+		}
+
 		// Update the cache of restored leases, either synchronously or through
 		// the lazy loaded restore process
 		m.restoreLoaded.Store(le.LeaseID, struct{}{})
@@ -2315,6 +2350,41 @@ func (m *ExpirationManager) loadEntryInternal(ctx context.Context, leaseID strin
 	return le, nil
 }
 
+// backfillTokenInfo sets the entity and the auth method accessor of a lease
+// from its token, and persists the lease if either was found so that it isn't
+// looked up again on the next restore.
+func (m *ExpirationManager) backfillTokenInfo(ctx context.Context, le *leaseEntry) {
+	if m.tokenStore == nil {
+		return
+	}
+
+	te, err := m.tokenStore.lookupForRestore(ctx, le.ClientToken)
+	if err != nil {
+		m.logger.Debug("failed to look up the token of a restored lease", "lease_id", le.LeaseID, "error", err)
+		return
+	}
+	if te == nil {
+		return
+	}
+
+	updated := false
+	if le.EntityID == "" && te.EntityID != "" {
+		le.EntityID = te.EntityID
+		updated = true
+	}
+	if accessor := m.tokenAuthAccessor(ctx, te); accessor != "" {
+		le.AuthAccessor = accessor
+		updated = true
+	}
+	if !updated {
+		return
+	}
+
+	if err := m.persistEntry(ctx, le); err != nil {
+		m.logger.Warn("failed to persist the token info of a restored lease", "lease_id", le.LeaseID, "error", err)
+	}
+}
+
 // persistEntry is used to persist a lease entry
 func (m *ExpirationManager) persistEntry(ctx context.Context, le *leaseEntry) error {
 	// Encode the entry
@@ -2842,6 +2912,7 @@ func (m *ExpirationManager) markLeaseIrrevocable(ctx context.Context, le *leaseE
 
 	m.irrevocable.Store(le.LeaseID, m.inMemoryLeaseInfo(le))
 	m.irrevocableLeaseCount++
+	m.leaseIndex.add(le)
 	m.removeFromPending(ctx, le.LeaseID, false)
 	m.nonexpiring.Delete(le.LeaseID)
 }
@@ -3027,6 +3098,13 @@ type leaseEntry struct {
 	// based on login roles upon lease expiry.
 	LoginRole string `json:"login_role"`
 
+	// EntityID is the entity of the token that created the lease, if any.
+	EntityID string `json:"entity_id,omitempty"`
+
+	// AuthAccessor is the accessor of the auth method that issued the token
+	// that created the lease.
+	AuthAccessor string `json:"auth_accessor,omitempty"`
+
 	// Version is used to track new different versions of leases. V0 (or
 	// zero-value) had non-root namespaced secondary indexes live in the root
 	// namespace, and V1 has secondary indexes live in the matching namespace.
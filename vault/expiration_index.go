@@ -0,0 +1,283 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+)
+
+// leaseIndexEntry is the subset of a lease entry kept by the lease index.
+type leaseIndexEntry struct {
+	LeaseID      string
+	EntityID     string
+	AuthAccessor string
+	LoginRole    string
+	IssueTime    time.Time
+	ExpireTime   time.Time
+	Irrevocable  bool
+
+	namespace *namespace.Namespace
+}
+
+// leaseIndex indexes the leases known to the expiration manager by the entity
+// and the auth method of the token that created them, and by the hour they
+// were issued in, so they can be queried without scanning storage. Like the
+// pending map, it is kept in memory and rebuilt when leases are restored.
+type leaseIndex struct {
+	l              sync.RWMutex
+	leases         map[string]*leaseIndexEntry
+	byEntity       map[string]map[string]struct{}
+	byAuthAccessor map[string]map[string]struct{}
+	byIssueHour    map[int64]map[string]struct{}
+}
+
+func newLeaseIndex() *leaseIndex {
+	return &leaseIndex{
+		leases:         make(map[string]*leaseIndexEntry),
+		byEntity:       make(map[string]map[string]struct{}),
+		byAuthAccessor: make(map[string]map[string]struct{}),
+		byIssueHour:    make(map[int64]map[string]struct{}),
+	}
+}
+
+// reset removes every lease from the index.
+func (i *leaseIndex) reset() {
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	i.leases = make(map[string]*leaseIndexEntry)
+	i.byEntity = make(map[string]map[string]struct{})
+	i.byAuthAccessor = make(map[string]map[string]struct{})
+	i.byIssueHour = make(map[int64]map[string]struct{})
+}
+
+// leaseIndexHour returns the key of the hour a lease was issued in.
+func leaseIndexHour(t time.Time) int64 {
+	return t.Unix() / int64(time.Hour/time.Second)
+}
+
+func leaseIndexAdd[K comparable](index map[K]map[string]struct{}, key K, leaseID string) {
+	set, ok := index[key]
+	if !ok {
+		set = make(map[string]struct{})
+		index[key] = set
+	}
+	set[leaseID] = struct{}{}
+}
+
+func leaseIndexRemove[K comparable](index map[K]map[string]struct{}, key K, leaseID string) {
+	set, ok := index[key]
+	if !ok {
+		return
+	}
+	delete(set, leaseID)
+	if len(set) == 0 {
+		delete(index, key)
+	}
+}
+
+// add indexes the lease, replacing any previous entry for it.
+func (i *leaseIndex) add(le *leaseEntry) {
+	entry := &leaseIndexEntry{
+		LeaseID:      le.LeaseID,
+		EntityID:     le.EntityID,
+		AuthAccessor: le.AuthAccessor,
+		LoginRole:    le.LoginRole,
+		IssueTime:    le.IssueTime,
+		ExpireTime:   le.ExpireTime,
+		Irrevocable:  le.isIrrevocable(),
+		namespace:    le.namespace,
+	}
+	// Auth leases created before leases recorded their entity still carry it
+	// in the auth response
+	if entry.EntityID == "" && le.Auth != nil {
+		entry.EntityID = le.Auth.EntityID
+	}
+
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	i.removeLocked(le.LeaseID)
+	i.leases[entry.LeaseID] = entry
+	if entry.EntityID != "" {
+		leaseIndexAdd(i.byEntity, entry.EntityID, entry.LeaseID)
+	}
+	if entry.AuthAccessor != "" {
+		leaseIndexAdd(i.byAuthAccessor, entry.AuthAccessor, entry.LeaseID)
+	}
+	leaseIndexAdd(i.byIssueHour, leaseIndexHour(entry.IssueTime), entry.LeaseID)
+}
+
+// remove removes the lease from the index.
+func (i *leaseIndex) remove(leaseID string) {
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	i.removeLocked(leaseID)
+}
+
+func (i *leaseIndex) removeLocked(leaseID string) {
+	entry, ok := i.leases[leaseID]
+	if !ok {
+		return
+	}
+	delete(i.leases, leaseID)
+	leaseIndexRemove(i.byEntity, entry.EntityID, leaseID)
+	leaseIndexRemove(i.byAuthAccessor, entry.AuthAccessor, leaseID)
+	leaseIndexRemove(i.byIssueHour, leaseIndexHour(entry.IssueTime), leaseID)
+}
+
+// leaseQuery holds the filters of a lease query. Empty filters match every
+// lease.
+type leaseQuery struct {
+	// EntityID matches the leases created by tokens of the entity.
+	EntityID string
+
+	// AuthAccessor matches the leases created by tokens of the auth method.
+	AuthAccessor string
+
+	// LoginRole matches the leases of tokens logged in with the role.
+	LoginRole string
+
+	// Mount matches the leases whose ID, relative to the namespace of the
+	// query, is under the prefix.
+	Mount string
+
+	// IssuedBefore matches the leases issued before the time.
+	IssuedBefore time.Time
+
+	// ExpiringBefore matches the leases that expire before the time.
+	// Non-expiring leases never match.
+	ExpiringBefore time.Time
+
+	IncludeChildNamespaces bool
+}
+
+// empty reports whether the query has no filters beyond the namespace.
+func (q *leaseQuery) empty() bool {
+	return q.EntityID == "" && q.AuthAccessor == "" && q.LoginRole == "" && q.Mount == "" &&
+		q.IssuedBefore.IsZero() && q.ExpiringBefore.IsZero()
+}
+
+// query returns the leases of the namespace, or of it and its children, that
+// match the query, sorted by lease ID.
+func (i *leaseIndex) query(ns *namespace.Namespace, q *leaseQuery) []*leaseIndexEntry {
+	i.l.RLock()
+	defer i.l.RUnlock()
+
+	var ret []*leaseIndexEntry
+	match := func(leaseID string) {
+		entry, ok := i.leases[leaseID]
+		if !ok || !entry.matches(ns, q) {
+			return
+		}
+		ret = append(ret, entry)
+	}
+
+	// Walk the smallest set of candidates the indexes give
+	switch {
+	case q.EntityID != "":
+		for leaseID := range i.byEntity[q.EntityID] {
+			match(leaseID)
+		}
+	case q.AuthAccessor != "":
+		for leaseID := range i.byAuthAccessor[q.AuthAccessor] {
+			match(leaseID)
+		}
+	case !q.IssuedBefore.IsZero():
+		last := leaseIndexHour(q.IssuedBefore)
+		for hour, set := range i.byIssueHour {
+			if hour > last {
+				continue
+			}
+			for leaseID := range set {
+				match(leaseID)
+			}
+		}
+	default:
+		for leaseID := range i.leases {
+			match(leaseID)
+		}
+	}
+
+	sort.Slice(ret, func(a, b int) bool {
+		return ret[a].LeaseID < ret[b].LeaseID
+	})
+	return ret
+}
+
+// matches reports whether the lease is in the namespace, or in one of its
+// children if the query includes them, and matches every filter of the query.
+func (e *leaseIndexEntry) matches(ns *namespace.Namespace, q *leaseQuery) bool {
+	if e.namespace == nil {
+		return false
+	}
+	if e.namespace.ID != ns.ID && !(q.IncludeChildNamespaces && e.namespace.HasParent(ns)) {
+		return false
+	}
+
+	switch {
+	case q.EntityID != "" && e.EntityID != q.EntityID,
+		q.AuthAccessor != "" && e.AuthAccessor != q.AuthAccessor,
+		q.LoginRole != "" && e.LoginRole != q.LoginRole,
+		q.Mount != "" && !strings.HasPrefix(e.relativeID(ns), q.Mount),
+		!q.IssuedBefore.IsZero() && !e.IssueTime.Before(q.IssuedBefore),
+		!q.ExpiringBefore.IsZero() && (e.ExpireTime.IsZero() || !e.ExpireTime.Before(q.ExpiringBefore)):
+		return false
+	}
+	return true
+}
+
+// relativeID returns the ID of the lease relative to the namespace, which is
+// the namespace of the lease or one of its parents.
+func (e *leaseIndexEntry) relativeID(ns *namespace.Namespace) string {
+	return strings.TrimPrefix(e.namespace.Path, ns.Path) + e.LeaseID
+}
+
+// QueryLeases returns the leases of the namespace in the context that match
+// the query, sorted by lease ID.
+func (m *ExpirationManager) QueryLeases(ctx context.Context, q *leaseQuery) ([]*leaseIndexEntry, error) {
+	if m.inRestoreMode() {
+		return nil, ErrInRestoreMode
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.leaseIndex.query(ns, q), nil
+}
+
+// RevokeByQuery queues the revocation of the leases of the namespace in the
+// context that match the query on the job manager, as if they had expired,
+// and returns the number of leases queued. Irrevocable leases are skipped, as
+// they can only be force-revoked.
+func (m *ExpirationManager) RevokeByQuery(ctx context.Context, q *leaseQuery) (int, error) {
+	leases, err := m.QueryLeases(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, lease := range leases {
+		if lease.Irrevocable {
+			continue
+		}
+
+		nsCtx := namespace.ContextWithNamespace(m.quitContext, lease.namespace)
+		job, err := newRevocationJob(nsCtx, lease.LeaseID, lease.namespace, m)
+		if err != nil {
+			return queued, err
+		}
+		m.jobManager.AddJob(job, m.getLeaseMountAccessor(nsCtx, lease.LeaseID))
+		queued++
+	}
+	return queued, nil
+}
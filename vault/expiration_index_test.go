@@ -0,0 +1,247 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiration_QueryLeases(t *testing.T) {
+	exp := mockExpiration(t)
+	ctx := namespace.RootContext(context.Background())
+
+	noop := &NoopBackend{}
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+	meUUID, err := uuid.GenerateUUID()
+	require.NoError(t, err)
+	err = exp.router.Mount(noop, "prod/", &MountEntry{Path: "prod/", Type: "noop", UUID: meUUID, Accessor: "noop-accessor", namespace: namespace.RootNamespace}, view)
+	require.NoError(t, err)
+
+	tokenAccessor := exp.router.MatchingMountEntry(ctx, "auth/token/create").Accessor
+
+	register := func(path, entityID string, ttl time.Duration) string {
+		t.Helper()
+		req := &logical.Request{
+			Operation:   logical.ReadOperation,
+			Path:        path,
+			ClientToken: entityID + "-token",
+		}
+		req.SetTokenEntry(&logical.TokenEntry{
+			ID:          entityID + "-token",
+			NamespaceID: namespace.RootNamespaceID,
+			Path:        "auth/token/create",
+			EntityID:    entityID,
+		})
+		resp := &logical.Response{
+			Secret: &logical.Secret{
+				LeaseOptions: logical.LeaseOptions{
+					TTL: ttl,
+				},
+			},
+		}
+		leaseID, err := exp.Register(ctx, req, resp, "")
+		require.NoError(t, err)
+		return leaseID
+	}
+
+	aws := register("prod/aws/creds", "alice", time.Hour)
+	db := register("prod/db/creds", "alice", 10*time.Minute)
+	bob := register("prod/aws/creds", "bob", 2*time.Hour)
+
+	te := &logical.TokenEntry{
+		ID:          "login-token",
+		Path:        "auth/token/create",
+		NamespaceID: namespace.RootNamespaceID,
+		EntityID:    "alice",
+		Policies:    []string{"default"},
+		TTL:         time.Hour,
+	}
+	auth := &logical.Auth{
+		ClientToken: te.ID,
+		EntityID:    te.EntityID,
+		LeaseOptions: logical.LeaseOptions{
+			TTL: time.Hour,
+		},
+	}
+	require.NoError(t, exp.RegisterAuth(ctx, te, auth, "deploy"))
+
+	leaseIDs := func(q *leaseQuery) []string {
+		t.Helper()
+		leases, err := exp.QueryLeases(ctx, q)
+		require.NoError(t, err)
+		ret := []string{}
+		for _, lease := range leases {
+			ret = append(ret, lease.LeaseID)
+		}
+		return ret
+	}
+
+	alice := leaseIDs(&leaseQuery{EntityID: "alice"})
+	require.Len(t, alice, 3)
+	require.Subset(t, alice, []string{aws, db})
+	require.Equal(t, []string{aws}, leaseIDs(&leaseQuery{EntityID: "alice", Mount: "prod/aws/"}))
+	require.ElementsMatch(t, []string{aws, bob}, leaseIDs(&leaseQuery{Mount: "prod/aws/"}))
+	require.Len(t, leaseIDs(&leaseQuery{LoginRole: "deploy"}), 1)
+	require.Len(t, leaseIDs(&leaseQuery{AuthAccessor: tokenAccessor}), 4)
+	require.Empty(t, leaseIDs(&leaseQuery{AuthAccessor: "unknown"}))
+	require.Equal(t, []string{db}, leaseIDs(&leaseQuery{ExpiringBefore: time.Now().Add(30 * time.Minute)}))
+	require.Len(t, leaseIDs(&leaseQuery{IssuedBefore: time.Now().Add(time.Second)}), 4)
+	require.Empty(t, leaseIDs(&leaseQuery{IssuedBefore: time.Now().Add(-time.Hour)}))
+
+	// Revoked leases are removed from the index
+	require.NoError(t, exp.Revoke(ctx, aws))
+	require.Equal(t, []string{bob}, leaseIDs(&leaseQuery{Mount: "prod/aws/"}))
+
+	// Restored leases from before leases recorded their entity and auth
+	// method are backfilled from their token
+	legacyToken := &logical.TokenEntry{
+		Path:     "auth/token/create",
+		EntityID: "carol",
+		Policies: []string{"default"},
+		TTL:      time.Hour,
+	}
+	testMakeTokenDirectly(t, exp.tokenStore, legacyToken)
+	legacy := &leaseEntry{
+		LeaseID:         "prod/aws/creds/legacy",
+		ClientToken:     legacyToken.ID,
+		ClientTokenType: logical.TokenTypeService,
+		Path:            "prod/aws/creds",
+		Secret: &logical.Secret{
+			LeaseOptions: logical.LeaseOptions{
+				TTL: time.Hour,
+			},
+		},
+		IssueTime:  time.Now(),
+		ExpireTime: time.Now().Add(time.Hour),
+		namespace:  namespace.RootNamespace,
+	}
+	require.NoError(t, exp.persistEntry(ctx, legacy))
+	_, err = exp.loadEntryInternal(ctx, legacy.LeaseID, true, false)
+	require.NoError(t, err)
+	require.Contains(t, leaseIDs(&leaseQuery{EntityID: "carol"}), legacy.LeaseID)
+	require.Contains(t, leaseIDs(&leaseQuery{AuthAccessor: tokenAccessor}), legacy.LeaseID)
+
+	// The backfilled lease is persisted, so it isn't looked up again
+	stored, err := exp.loadEntryInternal(ctx, legacy.LeaseID, false, false)
+	require.NoError(t, err)
+	require.Equal(t, "carol", stored.EntityID)
+	require.Equal(t, tokenAccessor, stored.AuthAccessor)
+
+	// Leases of other namespaces are not returned
+	nsCtx := namespace.ContextWithNamespace(ctx, &namespace.Namespace{ID: "child", Path: "child/"})
+	leases, err := exp.QueryLeases(nsCtx, &leaseQuery{})
+	require.NoError(t, err)
+	require.Empty(t, leases)
+}
+
+func TestSystemBackend_LeaseQuery(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+	exp := c.expiration
+	ctx := namespace.RootContext(context.Background())
+
+	noop := &NoopBackend{}
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+	meUUID, err := uuid.GenerateUUID()
+	require.NoError(t, err)
+	err = exp.router.Mount(noop, "prod/", &MountEntry{Path: "prod/", Type: "noop", UUID: meUUID, Accessor: "noop-accessor", namespace: namespace.RootNamespace}, view)
+	require.NoError(t, err)
+
+	var bobLeases []string
+	for i, entityID := range []string{"alice", "alice", "bob", "bob", "bob"} {
+		req := &logical.Request{
+			Operation:   logical.ReadOperation,
+			Path:        "prod/creds",
+			ClientToken: entityID,
+		}
+		req.SetTokenEntry(&logical.TokenEntry{ID: entityID, NamespaceID: namespace.RootNamespaceID, EntityID: entityID})
+		resp := &logical.Response{
+			Secret: &logical.Secret{
+				LeaseOptions: logical.LeaseOptions{
+					TTL: time.Duration(i+1) * time.Hour,
+				},
+			},
+		}
+		leaseID, err := exp.Register(ctx, req, resp, "")
+		require.NoError(t, err)
+		if entityID == "bob" {
+			bobLeases = append(bobLeases, leaseID)
+		}
+	}
+
+	query := func(data map[string]interface{}) *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, logical.ReadOperation, "leases/query")
+		req.Data = data
+		resp, err := b.HandleRequest(ctx, req)
+		if resp == nil || !resp.IsError() {
+			require.NoError(t, err)
+		}
+		return resp
+	}
+
+	// Paginate through the leases of bob
+	resp := query(map[string]interface{}{"entity_id": "bob", "limit": 2})
+	require.Equal(t, 3, resp.Data["lease_count"])
+	page := resp.Data["leases"].([]map[string]interface{})
+	require.Len(t, page, 2)
+	require.Equal(t, "bob", page[0]["entity_id"])
+	require.Equal(t, "", page[0]["namespace"])
+	require.NotNil(t, page[0]["expire_time"])
+	require.Equal(t, page[1]["lease_id"], resp.Data["next_after"])
+
+	resp = query(map[string]interface{}{"entity_id": "bob", "limit": 2, "after": resp.Data["next_after"]})
+	require.Len(t, resp.Data["leases"], 1)
+	require.NotContains(t, resp.Data, "next_after")
+
+	resp = query(map[string]interface{}{"expiring_before": "150m"})
+	require.Equal(t, 2, resp.Data["lease_count"])
+	resp = query(map[string]interface{}{"expiring_before": time.Now().Add(150 * time.Minute).Format(time.RFC3339)})
+	require.Equal(t, 2, resp.Data["lease_count"])
+
+	for name, data := range map[string]map[string]interface{}{
+		"bad_limit":           {"limit": 0},
+		"bad_expiring_before": {"expiring_before": "tomorrow"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := query(data)
+			require.True(t, resp.IsError())
+		})
+	}
+
+	// Bulk revocation needs a filter
+	req := logical.TestRequest(t, logical.UpdateOperation, "leases/revoke-query")
+	resp, err = b.HandleRequest(ctx, req)
+	require.ErrorIs(t, err, logical.ErrInvalidRequest)
+	require.True(t, resp.IsError())
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "leases/revoke-query")
+	req.Data["entity_id"] = "bob"
+	resp, err = b.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.Data[logical.HTTPStatusCode])
+
+	// The leases are revoked by the job manager
+	require.Eventually(t, func() bool {
+		for _, leaseID := range bobLeases {
+			le, err := exp.loadEntry(ctx, leaseID)
+			if err != nil || le != nil {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 50*time.Millisecond)
+
+	resp = query(map[string]interface{}{})
+	require.Equal(t, 2, resp.Data["lease_count"])
+}
@@ -116,6 +116,10 @@ func (e extendedSystemViewImpl) SudoPrivilege(ctx context.Context, path string,
 		e.core.logger.Error("failed to retrieve ACL for token's policies", "token_policies", te.Policies, "error", err)
 		return false
 	}
+	if err := e.core.scopeTokenACL(tokenCtx, tokenNS, te, acl); err != nil {
+		e.core.logger.Error("failed to retrieve ACL for token's scope policies", "error", err)
+		return false
+	}
 
 	// The operation type isn't important here as this is run from a path the
 	// user has already been given access to; we only care about whether they
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"testing"
@@ -1668,3 +1669,243 @@ func TestRaft_BootstrapWhenSealed(t *testing.T) {
 	_, err := client.Logical().Write("sys/storage/raft/bootstrap", nil)
 	require.ErrorContains(t, err, "node must be unsealed to bootstrap")
 }
+
+// TestRaft_SnapshotAuto verifies that the active node takes snapshots on the
+// schedule of an automated snapshot configuration, and retains the configured
+// number of them.
+func TestRaft_SnapshotAuto(t *testing.T) {
+	t.Parallel()
+	cluster, _ := raftCluster(t, &RaftClusterOpts{
+		InmemCluster: true,
+		NumCores:     1,
+	})
+	client := cluster.Cores[0].Client
+	dir := t.TempDir()
+
+	_, err := client.Logical().Write("sys/storage/raft/snapshot-auto/config/frequent", map[string]interface{}{
+		"interval":     "1s",
+		"storage_type": "ftp",
+		"path_prefix":  dir,
+	})
+	require.Error(t, err)
+
+	_, err = client.Logical().Write("sys/storage/raft/snapshot-auto/config/frequent", map[string]interface{}{
+		"interval":           "1s",
+		"retain":             2,
+		"storage_type":       "local",
+		"path_prefix":        dir,
+		"file_name_template": "{{.Name}}-{{.Unix}}-{{.Time.Nanosecond}}.snap",
+	})
+	require.NoError(t, err)
+
+	secret, err := client.Logical().List("sys/storage/raft/snapshot-auto/config")
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"frequent"}, secret.Data["keys"])
+
+	secret, err = client.Logical().Read("sys/storage/raft/snapshot-auto/config/frequent")
+	require.NoError(t, err)
+	require.Equal(t, "local", secret.Data["storage_type"])
+	require.Equal(t, "1", fmt.Sprint(secret.Data["interval"]))
+
+	readStatus := func() map[string]interface{} {
+		secret, err := client.Logical().Read("sys/storage/raft/snapshot-auto/status/frequent")
+		if err != nil || secret == nil {
+			return nil
+		}
+		return secret.Data
+	}
+
+	var first string
+	require.Eventually(t, func() bool {
+		status := readStatus()
+		if status == nil || status["last_snapshot_url"] == "" {
+			return false
+		}
+		first = status["last_snapshot_url"].(string)
+		return true
+	}, 30*time.Second, 200*time.Millisecond)
+	require.FileExists(t, first)
+
+	// Wait for the first snapshot to be rotated out
+	var status map[string]interface{}
+	require.Eventually(t, func() bool {
+		status = readStatus()
+		_, err := os.Stat(first)
+		return status != nil && errors.Is(err, os.ErrNotExist)
+	}, 30*time.Second, 200*time.Millisecond)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Len(t, status["snapshots"], 2)
+	require.Equal(t, "0", fmt.Sprint(status["consecutive_errors"]))
+	require.Empty(t, status["last_snapshot_error"])
+	require.NotEmpty(t, status["next_snapshot_start"])
+
+	lastURL := status["last_snapshot_url"].(string)
+	require.FileExists(t, lastURL)
+	snap, err := os.ReadFile(lastURL)
+	require.NoError(t, err)
+	require.NoError(t, client.Sys().RaftSnapshotRestore(bytes.NewReader(snap), false))
+	testhelpers.WaitForActiveNode(t, cluster)
+
+	// Failures are reported in the status
+	require.NoError(t, os.RemoveAll(dir))
+	require.Eventually(t, func() bool {
+		status := readStatus()
+		return status != nil && status["last_snapshot_error"] != ""
+	}, 30*time.Second, 200*time.Millisecond)
+
+	_, err = client.Logical().Delete("sys/storage/raft/snapshot-auto/config/frequent")
+	require.NoError(t, err)
+	secret, err = client.Logical().Read("sys/storage/raft/snapshot-auto/status/frequent")
+	require.NoError(t, err)
+	require.Nil(t, secret)
+}
+
+// TestRaft_SnapshotLoad loads a snapshot next to the live storage, reads and
+// recovers paths from it, and selectively restores a mount, a policy and an
+// entity after previewing the changes.
+func TestRaft_SnapshotLoad(t *testing.T) {
+	t.Parallel()
+	cluster, _ := raftCluster(t, &RaftClusterOpts{
+		InmemCluster: true,
+		NumCores:     1,
+	})
+	client := cluster.Cores[0].Client
+	ctx := context.Background()
+
+	require.NoError(t, client.Sys().Mount("kv1", &api.MountInput{
+		Type:    "kv",
+		Options: map[string]string{"version": "1"},
+	}))
+	for key, value := range map[string]string{"a": "1", "b": "2", "app/d": "4"} {
+		_, err := client.Logical().Write("kv1/"+key, map[string]interface{}{"value": value})
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.Sys().PutPolicy("ops", `path "kv1/*" { capabilities = ["read"] }`))
+	secret, err := client.Logical().Write("identity/entity", map[string]interface{}{
+		"name":     "alice",
+		"policies": []string{"ops"},
+	})
+	require.NoError(t, err)
+	entityID := secret.Data["id"].(string)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, client.Sys().RaftSnapshot(buf))
+
+	// Change everything after the snapshot
+	_, err = client.Logical().Delete("kv1/a")
+	require.NoError(t, err)
+	_, err = client.Logical().Write("kv1/b", map[string]interface{}{"value": "changed"})
+	require.NoError(t, err)
+	_, err = client.Logical().Write("kv1/c", map[string]interface{}{"value": "3"})
+	require.NoError(t, err)
+	require.NoError(t, client.Sys().DeletePolicy("ops"))
+	_, err = client.Logical().Delete("identity/entity/id/" + entityID)
+	require.NoError(t, err)
+
+	secret, err = client.Sys().RaftLoadLocalSnapshot(buf)
+	require.NoError(t, err)
+	snapID := secret.Data["snapshot_id"].(string)
+	require.Equal(t, "ready", secret.Data["status"])
+
+	secret, err = client.Logical().List("sys/storage/raft/snapshot-load")
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{snapID}, secret.Data["keys"])
+
+	// Reads go to the snapshot, except for the mounts of core subsystems
+	secret, err = client.Logical().ReadWithData("kv1/a", map[string][]string{"read_snapshot_id": {snapID}})
+	require.NoError(t, err)
+	require.Equal(t, "1", secret.Data["value"])
+	_, err = client.Logical().ReadWithData("sys/policy/ops", map[string][]string{"read_snapshot_id": {snapID}})
+	require.Error(t, err)
+
+	selection := map[string]interface{}{
+		"mounts":     "kv1",
+		"policies":   "ops",
+		"entity_ids": entityID,
+	}
+	secret, err = client.Logical().Write("sys/storage/raft/snapshot-load/"+snapID+"/diff", selection)
+	require.NoError(t, err)
+	mount := secret.Data["mounts"].(map[string]interface{})["kv1/"].(map[string]interface{})
+	require.Equal(t, []interface{}{"a"}, mount["added"])
+	require.Equal(t, []interface{}{"b"}, mount["changed"])
+	require.Equal(t, []interface{}{"c"}, mount["removed"])
+	require.Equal(t, map[string]interface{}{"ops": "added"}, secret.Data["policies"])
+	require.Equal(t, map[string]interface{}{entityID: "added"}, secret.Data["entities"])
+
+	// Nothing is restored if part of the selection is missing from the snapshot
+	_, err = client.Logical().Write("sys/storage/raft/snapshot-load/"+snapID+"/restore", map[string]interface{}{
+		"mounts":   "kv1",
+		"policies": "missing",
+	})
+	require.Error(t, err)
+	secret, err = client.Logical().Read("kv1/a")
+	require.NoError(t, err)
+	require.Nil(t, secret)
+
+	_, err = client.Logical().Write("sys/storage/raft/snapshot-load/"+snapID+"/restore", selection)
+	require.NoError(t, err)
+
+	for key, value := range map[string]string{"a": "1", "b": "2"} {
+		secret, err = client.Logical().Read("kv1/" + key)
+		require.NoError(t, err)
+		require.Equal(t, value, secret.Data["value"])
+	}
+	secret, err = client.Logical().Read("kv1/c")
+	require.NoError(t, err)
+	require.Nil(t, secret)
+	policy, err := client.Sys().GetPolicy("ops")
+	require.NoError(t, err)
+	require.Contains(t, policy, "kv1/*")
+	secret, err = client.Logical().Read("identity/entity/id/" + entityID)
+	require.NoError(t, err)
+	require.Equal(t, "alice", secret.Data["name"])
+
+	secret, err = client.Logical().Write("sys/storage/raft/snapshot-load/"+snapID+"/diff", selection)
+	require.NoError(t, err)
+	mount = secret.Data["mounts"].(map[string]interface{})["kv1/"].(map[string]interface{})
+	require.Empty(t, mount["added"])
+	require.Empty(t, mount["changed"])
+	require.Empty(t, mount["removed"])
+	require.Equal(t, map[string]interface{}{"ops": "unchanged"}, secret.Data["policies"])
+
+	// Paths within a mount restore only the storage keys under them
+	for key, value := range map[string]string{"a": "changed", "app/d": "changed", "app/e": "5"} {
+		_, err := client.Logical().Write("kv1/"+key, map[string]interface{}{"value": value})
+		require.NoError(t, err)
+	}
+	secret, err = client.Logical().Write("sys/storage/raft/snapshot-load/"+snapID+"/restore", map[string]interface{}{
+		"mounts": "kv1/app",
+	})
+	require.NoError(t, err)
+	mount = secret.Data["mounts"].(map[string]interface{})["kv1/app/"].(map[string]interface{})
+	require.Equal(t, []interface{}{"d"}, mount["changed"])
+	require.Equal(t, []interface{}{"e"}, mount["removed"])
+	secret, err = client.Logical().Read("kv1/app/d")
+	require.NoError(t, err)
+	require.Equal(t, "4", secret.Data["value"])
+	secret, err = client.Logical().Read("kv1/app/e")
+	require.NoError(t, err)
+	require.Nil(t, secret)
+	secret, err = client.Logical().Read("kv1/a")
+	require.NoError(t, err)
+	require.Equal(t, "changed", secret.Data["value"])
+
+	// Single paths are recovered through the mount
+	_, err = client.Logical().Delete("kv1/a")
+	require.NoError(t, err)
+	_, err = client.Logical().Recover(ctx, "kv1/a", snapID)
+	require.NoError(t, err)
+	secret, err = client.Logical().Read("kv1/a")
+	require.NoError(t, err)
+	require.Equal(t, "1", secret.Data["value"])
+
+	_, err = client.Sys().RaftUnloadSnapshot(snapID)
+	require.NoError(t, err)
+	secret, err = client.Logical().Read("sys/storage/raft/snapshot-load/" + snapID)
+	require.NoError(t, err)
+	require.Nil(t, secret)
+	_, err = client.Logical().ReadWithData("kv1/a", map[string][]string{"read_snapshot_id": {snapID}})
+	require.Error(t, err)
+}
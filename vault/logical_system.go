@@ -192,7 +192,12 @@ func NewSystemBackend(core *Core, logger log.Logger, config *logical.BackendConf
 				"leases/revoke-prefix/*",
 				"leases/revoke-force/*",
 				"leases/lookup/*",
+				"leases/query",
+				"leases/revoke-query",
+				"storage/raft/snapshot-auto/config",
 				"storage/raft/snapshot-auto/config/*",
+				"storage/raft/snapshot-load",
+				"storage/raft/snapshot-load/*",
 				"leases",
 				"reporting/scan",
 				"internal/inspect/*",
@@ -244,6 +249,11 @@ func NewSystemBackend(core *Core, logger log.Logger, config *logical.BackendConf
 	b.Backend.Paths = append(b.Backend.Paths, entWrappedAuthPath(b)...)
 	b.Backend.Paths = append(b.Backend.Paths, b.lockedUserPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.leasePaths()...)
+	// sys/policies/acl/<name>/versions must be matched before
+	// sys/policies/acl/<name>
+	b.Backend.Paths = append(b.Backend.Paths, b.policyExplainPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.policyLintPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.policyHistoryPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.policyPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.wrappingPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.toolsPaths()...)
@@ -270,6 +280,7 @@ func NewSystemBackend(core *Core, logger log.Logger, config *logical.BackendConf
 	if backend := core.getRaftBackend(); backend != nil {
 		b.Backend.Paths = append(b.Backend.Paths, b.raftStoragePaths()...)
 	}
+	b.Backend.Paths = append(b.Backend.Paths, b.raftSnapshotPaths()...)
 
 	// If the node is in a DR secondary cluster, gate some raft operations by
 	// the DR operation token.
@@ -472,6 +483,110 @@ func (b *SystemBackend) handleLeaseList(ctx context.Context, req *logical.Reques
 	return resp, nil
 }
 
+func (b *SystemBackend) handleLeaseQuery(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	q, err := leaseQueryFromFieldData(d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	limit := d.Get("limit").(int)
+	if limit < 1 {
+		return logical.ErrorResponse("limit must be a positive integer"), logical.ErrInvalidRequest
+	}
+
+	leases, err := b.Core.expiration.QueryLeases(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	after := d.Get("after").(string)
+	page := leases[sort.Search(len(leases), func(i int) bool {
+		return leases[i].LeaseID > after
+	}):]
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"lease_count": len(leases),
+		},
+	}
+	if len(page) > limit {
+		page = page[:limit]
+		resp.Data["next_after"] = page[limit-1].LeaseID
+	}
+
+	ret := make([]map[string]interface{}, 0, len(page))
+	for _, lease := range page {
+		info := map[string]interface{}{
+			"lease_id":      lease.LeaseID,
+			"namespace":     lease.namespace.Path,
+			"entity_id":     lease.EntityID,
+			"auth_accessor": lease.AuthAccessor,
+			"role":          lease.LoginRole,
+			"issue_time":    lease.IssueTime,
+			"expire_time":   nil,
+			"irrevocable":   lease.Irrevocable,
+		}
+		if !lease.ExpireTime.IsZero() {
+			info["expire_time"] = lease.ExpireTime
+		}
+		ret = append(ret, info)
+	}
+	resp.Data["leases"] = ret
+
+	return resp, nil
+}
+
+func (b *SystemBackend) handleLeaseRevokeQuery(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	q, err := leaseQueryFromFieldData(d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if q.empty() {
+		return logical.ErrorResponse("at least one filter is required to revoke leases by query"), logical.ErrInvalidRequest
+	}
+
+	queued, err := b.Core.expiration.RevokeByQuery(ctx, q)
+	if err != nil {
+		b.Backend.Logger().Error("revoke leases by query failed", "error", err, "queued", queued)
+		return handleErrorNoReadOnlyForward(err)
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"lease_count": queued,
+		},
+	}
+	return logical.RespondWithStatusCode(resp, req, http.StatusAccepted)
+}
+
+// leaseQueryFromFieldData returns the lease query given by the filters of the
+// request.
+func leaseQueryFromFieldData(d *framework.FieldData) (*leaseQuery, error) {
+	q := &leaseQuery{
+		EntityID:               d.Get("entity_id").(string),
+		AuthAccessor:           d.Get("auth_accessor").(string),
+		LoginRole:              d.Get("role").(string),
+		Mount:                  d.Get("mount").(string),
+		IncludeChildNamespaces: d.Get("include_child_namespaces").(bool),
+	}
+
+	now := time.Now()
+	if olderThan := d.Get("older_than").(int); olderThan > 0 {
+		q.IssuedBefore = now.Add(-time.Duration(olderThan) * time.Second)
+	}
+	if raw := d.Get("expiring_before").(string); raw != "" {
+		if expiringBefore, err := time.Parse(time.RFC3339, raw); err == nil {
+			q.ExpiringBefore = expiringBefore
+		} else {
+			within, err := parseutil.ParseDurationSecond(raw)
+			if err != nil {
+				return nil, fmt.Errorf("expiring_before must be an RFC 3339 timestamp or a duration")
+			}
+			q.ExpiringBefore = now.Add(within)
+		}
+	}
+	return q, nil
+}
+
 func (b *SystemBackend) handlePluginCatalogTypedList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	pluginType, err := consts.ParsePluginType(d.Get("type").(string))
 	if err != nil {
@@ -7188,6 +7303,53 @@ or delete a policy.
 		`,
 	},
 
+	"policy-versions": {
+		`Read the version history of an ACL policy.`,
+		`
+Returns the last versions of an ACL policy, oldest first. A version is
+recorded each time the policy is written with a change or deleted, with the
+entity and token accessor of its author, its creation time and a unified diff
+from the previous version.
+		`,
+	},
+
+	"policy-rollback": {
+		`Roll an ACL policy back to a previous version.`,
+		`
+Writes the given version from the history of an ACL policy as its current
+version. The rollback is recorded in the history as a new version.
+		`,
+	},
+
+	"policy-explain": {
+		`Explain which policy rules allow or deny a request.`,
+		`
+Given a path, an operation and optionally the parameters of a request, returns
+the ACL decision on the request for a token, the token of an accessor, or an
+entity through its identity policies. Each policy lists its path rules that
+match the path, whether they match exactly, by glob or by segment wildcard,
+which rule takes precedence, and the verdict of its allowed, denied and
+required parameters on the request parameters. Defaults to the token of the
+request.
+
+Rule conditions are evaluated at the current time against the given remote
+address and headers.
+		`,
+	},
+
+	"policy-lint": {
+		`Statically analyze the rules of an ACL policy.`,
+		`
+Lints the given rules of an ACL policy, or the rules of the named existing
+policy, and returns its findings. Each finding names the check that raised it,
+its severity, the rule it applies to and a message. The checks report rules
+shadowed by a more specific rule, deny rules that never match or are
+overridden, globs broader than likely intended, parameter constraints on
+rules that grant no operation checking parameters and, unless check_mounts is
+false, rules on paths outside of the mounts of the namespace.
+		`,
+	},
+
 	"policy-name": {
 		`The name of the policy. Example: "ops"`,
 		"",
@@ -7698,6 +7860,30 @@ This path responds to the following HTTP methods.
 		"List leases associated with this Vault cluster",
 		"Requires sudo capability. List leases associated with this Vault cluster",
 	},
+	"query-leases": {
+		"Query leases by the entity and role that created them, mount and age.",
+		`
+Requires sudo capability. Returns the leases of the namespace that match all of
+the given filters, sorted by lease ID and paginated with after and limit:
+entity_id and auth_accessor match the entity and the auth method of the token
+that created the lease, role matches the role tokens logged in with, mount
+matches a lease ID prefix, older_than matches leases issued longer than the
+duration ago, and expiring_before matches leases expiring before the time.
+
+Leases created by a token are revoked along with it, so revoking the leases of
+the tokens of a role also revokes the secrets they created.
+		`,
+	},
+	"revoke-query-leases": {
+		"Revoke the leases matching a lease query.",
+		`
+Requires sudo capability. Queues the revocation of the leases that match the
+given filters, which are the same as the ones of sys/leases/query, as if they
+had expired, and returns the number of leases queued. At least one filter must
+be given. Irrevocable leases are not revoked; use sys/leases/revoke-force for
+them.
+		`,
+	},
 	"version-history": {
 		"List historical version changes sorted by installation time in ascending order.",
 		`
@@ -3745,7 +3745,132 @@ func (b *SystemBackend) leasePaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["list-leases"][0]),
 			HelpDescription: strings.TrimSpace(sysHelp["list-leases"][1]),
 		},
+
+		{
+			Pattern: "leases/query$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "leases",
+				OperationVerb:   "query",
+			},
+
+			Fields: leaseQueryFields(map[string]*framework.FieldSchema{
+				"after": {
+					Type:        framework.TypeString,
+					Description: "Lease ID to return the matching leases after, as given in next_after by the previous page.",
+				},
+				"limit": {
+					Type:        framework.TypeInt,
+					Default:     1000,
+					Description: "Maximum number of leases to return.",
+				},
+			}),
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleLeaseQuery,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"lease_count": {
+									Type:        framework.TypeInt,
+									Description: "Number of matching leases",
+									Required:    true,
+								},
+								"leases": {
+									Type:        framework.TypeSlice,
+									Description: "The page of matching leases, sorted by lease ID",
+									Required:    true,
+								},
+								"next_after": {
+									Type:        framework.TypeString,
+									Description: "Value of after to get the next page of matching leases, if there is one",
+									Required:    false,
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["query-leases"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["query-leases"][1]),
+		},
+
+		{
+			Pattern: "leases/revoke-query$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "leases",
+				OperationVerb:   "revoke",
+				OperationSuffix: "leases-by-query",
+			},
+
+			Fields: leaseQueryFields(nil),
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleLeaseRevokeQuery,
+					Responses: map[int][]framework.Response{
+						http.StatusAccepted: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"lease_count": {
+									Type:        framework.TypeInt,
+									Description: "Number of leases queued for revocation",
+									Required:    true,
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["revoke-query-leases"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["revoke-query-leases"][1]),
+		},
+	}
+}
+
+// leaseQueryFields returns the filters of lease queries, along with the given
+// fields.
+func leaseQueryFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
+	ret := map[string]*framework.FieldSchema{
+		"entity_id": {
+			Type:        framework.TypeString,
+			Description: "Return leases created by tokens of this entity.",
+		},
+		"auth_accessor": {
+			Type:        framework.TypeString,
+			Description: "Return leases created by tokens issued by the auth method with this accessor.",
+		},
+		"role": {
+			Type:        framework.TypeString,
+			Description: "Return leases of tokens logged in with this role.",
+		},
+		"mount": {
+			Type:        framework.TypeString,
+			Description: "Return leases with IDs under this prefix, such as the path of a mount.",
+		},
+		"older_than": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Return leases issued longer than this duration ago.",
+		},
+		"expiring_before": {
+			Type:        framework.TypeString,
+			Description: "Return leases expiring before this RFC 3339 timestamp, or before this duration from now.",
+		},
+		"include_child_namespaces": {
+			Type:        framework.TypeBool,
+			Default:     false,
+			Description: "Set true to also return leases of the child namespaces.",
+		},
+	}
+	for name, field := range fields {
+		ret[name] = field
 	}
+	return ret
 }
 
 func (b *SystemBackend) remountPaths() []*framework.Path {
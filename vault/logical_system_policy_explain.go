@@ -0,0 +1,270 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// policyExplainOperations are the operations whose policy decision can be
+// explained.
+var policyExplainOperations = []logical.Operation{
+	logical.CreateOperation,
+	logical.ReadOperation,
+	logical.UpdateOperation,
+	logical.PatchOperation,
+	logical.DeleteOperation,
+	logical.ListOperation,
+	logical.RecoverOperation,
+}
+
+func (b *SystemBackend) policyExplainPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "policies/explain$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "explain",
+				OperationSuffix: "acl-decision",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"path": {
+					Type:        framework.TypeString,
+					Required:    true,
+					Description: "Path of the request to explain, relative to the namespace of the request.",
+				},
+				"operation": {
+					Type:          framework.TypeString,
+					Default:       string(logical.ReadOperation),
+					AllowedValues: []interface{}{"create", "read", "update", "patch", "delete", "list", "recover"},
+					Description:   "Operation of the request to explain.",
+				},
+				"parameters": {
+					Type:        framework.TypeMap,
+					Description: "Parameters of the request to check against the allowed, denied and required parameters of the policies.",
+				},
+				"remote_address": {
+					Type:        framework.TypeString,
+					Description: "Source address of the request, checked against the source_cidrs of rule conditions.",
+				},
+				"headers": {
+					Type:        framework.TypeMap,
+					Description: "Headers of the request, checked against the required_headers of rule conditions. Each value can be a string or a list of strings.",
+				},
+				"token": {
+					Type:        framework.TypeString,
+					Description: "Token whose policies are explained. Defaults to the token of the request.",
+				},
+				"accessor": {
+					Type:        framework.TypeString,
+					Description: "Accessor of the token whose policies are explained.",
+				},
+				"entity_id": {
+					Type:        framework.TypeString,
+					Description: "ID of the entity whose identity policies are explained.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePolicyExplain,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"allowed": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
+								"reason": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"path": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"operation": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"selected_rule": {
+									Type: framework.TypeString,
+								},
+								"selected_match": {
+									Type: framework.TypeString,
+								},
+								"capabilities": {
+									Type: framework.TypeStringSlice,
+								},
+								"granting_policies": {
+									Type: framework.TypeSlice,
+								},
+								"policies": {
+									Type: framework.TypeSlice,
+								},
+							},
+						}},
+					},
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-explain"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-explain"][1]),
+		},
+	}
+}
+
+// handlePolicyExplain explains the ACL policy decision on a request made by a
+// token, or by an entity through its identity policies.
+func (b *SystemBackend) handlePolicyExplain(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	path := strings.TrimPrefix(d.Get("path").(string), "/")
+	if path == "" {
+		return logical.ErrorResponse("missing path"), nil
+	}
+	op := logical.Operation(d.Get("operation").(string))
+	supported := false
+	for _, explainable := range policyExplainOperations {
+		supported = supported || op == explainable
+	}
+	if !supported {
+		return logical.ErrorResponse("unsupported operation %q", op), nil
+	}
+
+	token := d.Get("token").(string)
+	accessor := d.Get("accessor").(string)
+	entityID := d.Get("entity_id").(string)
+	principals := 0
+	for _, principal := range []string{token, accessor, entityID} {
+		if principal != "" {
+			principals++
+		}
+	}
+	if principals > 1 {
+		return logical.ErrorResponse("only one of token, accessor and entity_id can be given"), nil
+	}
+
+	var policyNS *namespace.Namespace
+	var entity *identity.Entity
+	var policyNames map[string][]string
+	var inlinePolicies []*Policy
+	var te *logical.TokenEntry
+	var err error
+	switch {
+	case entityID != "":
+		policyNS, err = namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		entity, policyNames, err = b.Core.fetchEntityAndDerivedPolicies(ctx, policyNS, entityID, false)
+		if err != nil {
+			return nil, err
+		}
+		if entity == nil {
+			return logical.ErrorResponse("entity not found"), nil
+		}
+		if entity.Disabled {
+			return logical.ErrorResponse("entity is disabled, so all of its requests are denied"), nil
+		}
+
+	default:
+		if accessor != "" {
+			aEntry, err := b.Core.tokenStore.lookupByAccessor(ctx, accessor, false, false)
+			if err != nil {
+				return nil, err
+			}
+			if aEntry == nil {
+				return logical.ErrorResponse("invalid accessor"), nil
+			}
+			token = aEntry.TokenID
+		}
+		if token == "" {
+			token = req.ClientToken
+		}
+
+		te, err = b.Core.tokenStore.Lookup(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if te == nil {
+			return logical.ErrorResponse("invalid token"), nil
+		}
+		policyNS, entity, policyNames, inlinePolicies, err = b.Core.tokenPolicies(ctx, te)
+		if errors.Is(err, logical.ErrPermissionDenied) {
+			return logical.ErrorResponse("entity of the token is disabled or invalid, so all of its requests are denied"), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// As with capabilities, the ACL is constructed in the namespace of the
+	// policies and evaluated in the namespace of the request
+	policyCtx := namespace.ContextWithNamespace(ctx, policyNS)
+	policies, err := b.Core.policyStore.aclPolicies(policyCtx, entity, policyNames, inlinePolicies...)
+	if err != nil {
+		return nil, err
+	}
+	acl, err := NewACL(policyCtx, policies)
+	if err != nil {
+		return nil, err
+	}
+	if te != nil {
+		if err := b.Core.scopeTokenACL(policyCtx, policyNS, te, acl); err != nil {
+			return nil, err
+		}
+	}
+
+	headers := make(http.Header)
+	for name, raw := range d.Get("headers").(map[string]interface{}) {
+		values, err := parseutil.ParseCommaStringSlice(raw)
+		if err != nil {
+			return logical.ErrorResponse("invalid value of header %q: %s", name, err), nil
+		}
+		for _, value := range values {
+			headers.Add(name, value)
+		}
+	}
+
+	// Client certificates can't be given, so rules conditioned on their
+	// subject alternative names are reported as unmet
+	parameters := d.Get("parameters").(map[string]interface{})
+	explanation, err := acl.Explain(ctx, policies, &logical.Request{
+		Operation: op,
+		Path:      path,
+		Data:      parameters,
+		Headers:   headers,
+		Connection: &logical.Connection{
+			RemoteAddr: d.Get("remote_address").(string),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"allowed":           explanation.Allowed,
+			"reason":            explanation.Reason,
+			"path":              explanation.Path,
+			"operation":         string(op),
+			"selected_rule":     displayRulePath(explanation.SelectedRule, explanation.SelectedMatch),
+			"selected_match":    explanation.SelectedMatch,
+			"capabilities":      explanation.Capabilities,
+			"granting_policies": explanation.GrantingPolicies,
+			"policies":          explanation.Policies,
+		},
+	}, nil
+}
@@ -0,0 +1,151 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemBackend_PolicyExplain(t *testing.T) {
+	core, b, rootToken := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	for name, rules := range map[string]string{
+		"apps": `
+path "secret/apps/*" {
+	capabilities = ["read", "update"]
+	denied_parameters = {
+		"admin" = []
+	}
+}`,
+		"locked": `
+path "secret/apps/locked" {
+	capabilities = ["deny"]
+}`,
+		"ops": `
+path "secret/ops/*" {
+	capabilities = ["update"]
+	condition {
+		source_cidrs     = ["10.0.1.0/24"]
+		required_headers = {
+			"X-Change-Ticket" = ["CHG-*"]
+		}
+	}
+}`,
+	} {
+		policy, err := ParseACLPolicy(namespace.RootNamespace, rules)
+		require.NoError(t, err)
+		policy.Name = name
+		require.NoError(t, core.policyStore.SetPolicy(ctx, policy))
+	}
+
+	resp, err := core.identityStore.HandleRequest(ctx, &logical.Request{
+		Path:      "entity",
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"policies": "locked",
+		},
+	})
+	require.NoError(t, err)
+	entityID := resp.Data["id"].(string)
+	te := &logical.TokenEntry{
+		ID:       "explaintoken",
+		Path:     "auth/token/create",
+		Policies: []string{"apps", "ops"},
+		EntityID: entityID,
+		TTL:      time.Hour,
+	}
+	testMakeTokenDirectly(t, core.tokenStore, te)
+
+	explain := func(data map[string]interface{}) *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "policies/explain")
+		req.ClientToken = rootToken
+		req.Data = data
+		resp, err := b.HandleRequest(ctx, req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// The token is granted by its own policy
+	resp = explain(map[string]interface{}{
+		"path":      "secret/apps/web",
+		"operation": "update",
+		"token":     "explaintoken",
+	})
+	require.False(t, resp.IsError(), resp)
+	require.Equal(t, true, resp.Data["allowed"])
+	require.Equal(t, "secret/apps/*", resp.Data["selected_rule"])
+	require.Equal(t, ACLRuleMatchGlob, resp.Data["selected_match"])
+
+	// and denied by the identity policy of its entity, which is shown by
+	// accessor as well
+	resp = explain(map[string]interface{}{
+		"path":     "secret/apps/locked",
+		"accessor": te.Accessor,
+	})
+	require.Equal(t, false, resp.Data["allowed"])
+	require.Equal(t, `the path is explicitly denied by "secret/apps/locked"`, resp.Data["reason"])
+	policies := resp.Data["policies"].([]*ACLPolicyExplanation)
+	require.Len(t, policies, 3)
+
+	resp = explain(map[string]interface{}{
+		"path":       "secret/apps/web",
+		"operation":  "update",
+		"token":      "explaintoken",
+		"parameters": map[string]interface{}{"admin": true},
+	})
+	require.Equal(t, false, resp.Data["allowed"])
+	require.Equal(t, `parameter "admin" is denied`, resp.Data["reason"])
+
+	// Conditions are evaluated against the given request context
+	resp = explain(map[string]interface{}{
+		"path":           "secret/ops/deploy",
+		"operation":      "update",
+		"token":          "explaintoken",
+		"remote_address": "10.0.1.5",
+		"headers":        map[string]interface{}{"x-change-ticket": "CHG-42"},
+	})
+	require.False(t, resp.IsError(), resp)
+	require.Equal(t, true, resp.Data["allowed"])
+
+	resp = explain(map[string]interface{}{
+		"path":           "secret/ops/deploy",
+		"operation":      "update",
+		"token":          "explaintoken",
+		"remote_address": "10.0.1.5",
+	})
+	require.Equal(t, false, resp.Data["allowed"])
+	require.Equal(t, `"secret/ops/*" grants the "update" capability only on a condition: in policy "ops", required header "X-Change-Ticket" is missing`, resp.Data["reason"])
+
+	// Entities are explained through their identity policies only
+	resp = explain(map[string]interface{}{
+		"path":      "secret/apps/web",
+		"entity_id": entityID,
+	})
+	require.Equal(t, false, resp.Data["allowed"])
+	require.Equal(t, "no policy has a rule matching the path", resp.Data["reason"])
+
+	// The token of the request is used by default
+	resp = explain(map[string]interface{}{
+		"path": "sys/mounts",
+	})
+	require.Equal(t, true, resp.Data["allowed"])
+	require.Equal(t, []string{RootCapability}, resp.Data["capabilities"])
+
+	for _, data := range []map[string]interface{}{
+		{"path": "secret/apps/web", "token": "explaintoken", "entity_id": entityID},
+		{"path": "secret/apps/web", "operation": "help"},
+		{"path": "secret/apps/web", "accessor": "missing"},
+		{"path": "secret/apps/web", "entity_id": "missing"},
+	} {
+		resp = explain(data)
+		require.True(t, resp.IsError(), data)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *SystemBackend) policyHistoryPaths() []*framework.Path {
+	versionFields := map[string]*framework.FieldSchema{
+		"version": {
+			Type:     framework.TypeInt,
+			Required: true,
+		},
+		"policy": {
+			Type: framework.TypeString,
+		},
+		"deleted": {
+			Type: framework.TypeBool,
+		},
+		"created_time": {
+			Type: framework.TypeTime,
+		},
+		"entity_id": {
+			Type: framework.TypeString,
+		},
+		"accessor": {
+			Type: framework.TypeString,
+		},
+		"restored_version": {
+			Type: framework.TypeInt,
+		},
+		"diff": {
+			Type: framework.TypeString,
+		},
+	}
+
+	return []*framework.Path{
+		{
+			Pattern: "policies/acl/(?P<name>.+)/versions$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "read",
+				OperationSuffix: "acl-policy-versions",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["policy-name"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handlePolicyVersionsRead,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"name": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"latest_version": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"versions": {
+									Type:     framework.TypeSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Retrieve the version history of the named ACL policy.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-versions"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-versions"][1]),
+		},
+
+		{
+			Pattern: "policies/acl/(?P<name>.+)/rollback$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "rollback",
+				OperationSuffix: "acl-policy",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["policy-name"][0]),
+				},
+				"version": {
+					Type:        framework.TypeInt,
+					Required:    true,
+					Description: "Version of the policy to roll back to.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePolicyRollback,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields:      versionFields,
+						}},
+					},
+					Summary: "Roll the named ACL policy back to a version from its history.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-rollback"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-rollback"][1]),
+		},
+	}
+}
+
+// handlePolicyVersionsRead returns the version history of an ACL policy,
+// oldest version first.
+func (b *SystemBackend) handlePolicyVersionsRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := b.Core.policyStore.sanitizeName(d.Get("name").(string))
+	history, err := b.Core.policyStore.GetPolicyHistory(ctx, name)
+	if err != nil {
+		return handleError(err)
+	}
+	if history == nil {
+		return nil, nil
+	}
+
+	versions := make([]map[string]interface{}, 0, len(history.Versions))
+	for _, version := range history.Versions {
+		versions = append(versions, policyVersionResponseData(version))
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":           name,
+			"latest_version": history.LatestVersion,
+			"versions":       versions,
+		},
+	}, nil
+}
+
+// handlePolicyRollback writes a version from the history of an ACL policy as
+// its current version, which is recorded as a new version.
+func (b *SystemBackend) handlePolicyRollback(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	version := d.Get("version").(int)
+	if version <= 0 {
+		return logical.ErrorResponse("version must be a positive integer"), nil
+	}
+
+	current, err := b.Core.policyStore.RollbackPolicyWithRequest(ctx, d.Get("name").(string), version, req)
+	if err != nil {
+		return handleError(err)
+	}
+	return &logical.Response{
+		Data: policyVersionResponseData(current),
+	}, nil
+}
+
+func policyVersionResponseData(version *PolicyVersion) map[string]interface{} {
+	data := map[string]interface{}{
+		"version":   version.Version,
+		"policy":    version.Raw,
+		"deleted":   version.Deleted,
+		"entity_id": version.EntityID,
+		"accessor":  version.Accessor,
+		"diff":      version.Diff,
+	}
+	if !version.CreatedTime.IsZero() {
+		data["created_time"] = version.CreatedTime.Format(time.RFC3339Nano)
+	}
+	if version.RestoredVersion > 0 {
+		data["restored_version"] = version.RestoredVersion
+	}
+	return data
+}
@@ -0,0 +1,156 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *SystemBackend) policyLintPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "policies/lint$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "lint",
+				OperationSuffix: "acl-policy",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"policy": {
+					Type:        framework.TypeString,
+					Description: "The rules of the ACL policy to lint, optionally base64 encoded.",
+				},
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of an existing ACL policy to lint, instead of the given rules.",
+				},
+				"check_mounts": {
+					Type:        framework.TypeBool,
+					Default:     true,
+					Description: "Report rules on paths outside of the mounts of the namespace.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePolicyLint,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"findings": {
+									Type:     framework.TypeSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Statically analyze the rules of an ACL policy.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-lint"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-lint"][1]),
+		},
+	}
+}
+
+// handlePolicyLint lints the given ACL policy, or an existing one, against the
+// mounts of the namespace of the request.
+func (b *SystemBackend) handlePolicyLint(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := d.Get("policy").(string)
+	name := d.Get("name").(string)
+	var policy *Policy
+	switch {
+	case raw != "" && name != "":
+		return logical.ErrorResponse("only one of policy and name can be given"), nil
+
+	case raw != "":
+		if polBytes, err := base64.StdEncoding.DecodeString(raw); err == nil {
+			raw = string(polBytes)
+		}
+		policy, err = ParseACLPolicy(ns, raw, WithDenySlashInTemplatedPaths(b.Core.denySlashInTemplatedPolicyPaths))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+
+	case name != "":
+		policy, err = b.Core.policyStore.GetPolicy(ctx, b.Core.policyStore.sanitizeName(name), PolicyTypeACL)
+		if err != nil {
+			return handleError(err)
+		}
+		if policy == nil {
+			return logical.ErrorResponse("policy %q not found", name), nil
+		}
+		if policy.Raw == "" {
+			return logical.ErrorResponse("policy %q has no rules to lint", name), nil
+		}
+
+	default:
+		return logical.ErrorResponse("one of policy and name must be given"), nil
+	}
+
+	var mounts []string
+	if d.Get("check_mounts").(bool) {
+		mounts = b.Core.namespaceMountPaths(ns)
+	}
+
+	findings, err := LintACLPolicy(ctx, policy, mounts)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	data := make([]map[string]interface{}, 0, len(findings))
+	for _, finding := range findings {
+		data = append(data, map[string]interface{}{
+			"check":    finding.Check,
+			"severity": finding.Severity,
+			"rule":     finding.Rule,
+			"message":  finding.Message,
+		})
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"findings": data,
+		},
+	}, nil
+}
+
+// namespaceMountPaths returns the paths of the secret and auth mounts of a
+// namespace, relative to it. Auth mount paths are prefixed by "auth/".
+func (c *Core) namespaceMountPaths(ns *namespace.Namespace) []string {
+	paths := []string{}
+
+	c.mountsLock.RLock()
+	for _, entry := range c.mounts.Entries {
+		if entry.Namespace().Path == ns.Path {
+			paths = append(paths, entry.Path)
+		}
+	}
+	c.mountsLock.RUnlock()
+
+	c.authLock.RLock()
+	for _, entry := range c.auth.Entries {
+		if entry.Namespace().Path == ns.Path {
+			paths = append(paths, credentialRoutePrefix+entry.Path)
+		}
+	}
+	c.authLock.RUnlock()
+
+	return paths
+}
@@ -231,6 +231,232 @@ func (b *SystemBackend) raftStoragePaths() []*framework.Path {
 	}
 }
 
+// raftSnapshotPaths returns the paths of the raft snapshot features that are
+// registered whether or not raft storage is in use, like on Enterprise. Their
+// handlers report when raft storage is not in use.
+func (b *SystemBackend) raftSnapshotPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "storage/raft/snapshot-auto/config/?$",
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigList(),
+					Summary:  "Lists the automated snapshot configurations.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-config-list"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-config-list"][1]),
+		},
+		{
+			Pattern: "storage/raft/snapshot-auto/config/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the automated snapshot configuration.",
+				},
+				"interval": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Time between snapshots.",
+				},
+				"retain": {
+					Type:        framework.TypeInt,
+					Description: "Number of snapshots to keep; older snapshots taken for the configuration are deleted. Defaults to 1.",
+				},
+				"file_name_template": {
+					Type:        framework.TypeString,
+					Description: fmt.Sprintf("Go template of the snapshot file names, with the fields Name, Timestamp, Unix and Time. Snapshots taken an interval apart must get different names. Defaults to %q.", raftSnapshotAutoDefaultFileNameTemplate),
+				},
+				"storage_type": {
+					Type:        framework.TypeString,
+					Description: fmt.Sprintf("Where to store the snapshots, %q or %q.", raftSnapshotAutoStorageLocal, raftSnapshotAutoStorageS3),
+				},
+				"path_prefix": {
+					Type:        framework.TypeString,
+					Description: "Directory of the snapshots for local storage, or object key prefix for aws-s3 storage.",
+				},
+				"aws_s3_bucket": {
+					Type:        framework.TypeString,
+					Description: "Bucket of the snapshots for aws-s3 storage.",
+				},
+				"aws_s3_region": {
+					Type:        framework.TypeString,
+					Description: "Region of the bucket for aws-s3 storage.",
+				},
+				"aws_s3_endpoint": {
+					Type:        framework.TypeString,
+					Description: "Endpoint of an S3-compatible service for aws-s3 storage.",
+				},
+				"aws_s3_disable_tls": {
+					Type:        framework.TypeBool,
+					Description: "Whether to connect to the endpoint without TLS for aws-s3 storage.",
+				},
+				"aws_s3_force_path_style": {
+					Type:        framework.TypeBool,
+					Description: "Whether to use path-style bucket addressing for aws-s3 storage, as some S3-compatible services require.",
+				},
+				"aws_access_key_id": {
+					Type:        framework.TypeString,
+					Description: "Access key ID for aws-s3 storage. Defaults to the AWS credential chain of the server.",
+				},
+				"aws_secret_access_key": {
+					Type:        framework.TypeString,
+					Description: "Secret access key for aws-s3 storage.",
+				},
+				"aws_session_token": {
+					Type:        framework.TypeString,
+					Description: "Session token for aws-s3 storage.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigRead(),
+					Summary:  "Reads an automated snapshot configuration.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigUpdate(),
+					Summary:  "Creates or updates an automated snapshot configuration.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigDelete(),
+					Summary:  "Deletes an automated snapshot configuration.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-config"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-config"][1]),
+		},
+		{
+			Pattern: "storage/raft/snapshot-auto/status/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the automated snapshot configuration.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoStatusRead(),
+					Summary:  "Reads the status of an automated snapshot configuration.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-status"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-status"][1]),
+		},
+		{
+			Pattern: "storage/raft/snapshot-load/?$",
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotLoad(),
+					Summary:  "Loads the provided snapshot into a read-only view, separate from the live storage.",
+				},
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotLoadList(),
+					Summary:  "Lists the loaded snapshots.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-load"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-load"][1]),
+		},
+		{
+			Pattern: "storage/raft/snapshot-load/" + framework.GenericNameRegex("snapshot_id") + "$",
+
+			Fields: map[string]*framework.FieldSchema{
+				"snapshot_id": {
+					Type:        framework.TypeString,
+					Description: "ID of the loaded snapshot.",
+				},
+				"force": {
+					Type:        framework.TypeBool,
+					Query:       true,
+					Description: "Unload the snapshot even if requests are reading from it, once they are done.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotLoadRead(),
+					Summary:  "Reads the status of a loaded snapshot.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotUnload(),
+					Summary:  "Unloads a loaded snapshot.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-load-id"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-load-id"][1]),
+		},
+		{
+			Pattern: "storage/raft/snapshot-load/" + framework.GenericNameRegex("snapshot_id") + "/diff$",
+
+			Fields: snapshotRestoreSelectionFields(),
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotLoadDiff(),
+					Summary:  "Previews the changes restoring the selection from a loaded snapshot would make.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotLoadDiff(),
+					Summary:  "Previews the changes restoring the selection from a loaded snapshot would make.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-load-diff"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-load-diff"][1]),
+		},
+		{
+			Pattern: "storage/raft/snapshot-load/" + framework.GenericNameRegex("snapshot_id") + "/restore$",
+
+			Fields: snapshotRestoreSelectionFields(),
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotLoadRestore(),
+					Summary:  "Restores the selection from a loaded snapshot into the live cluster.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-load-restore"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-load-restore"][1]),
+		},
+	}
+}
+
+func snapshotRestoreSelectionFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"snapshot_id": {
+			Type:        framework.TypeString,
+			Description: "ID of the loaded snapshot.",
+		},
+		"mounts": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "Paths of the secrets engines and auth methods whose storage to restore, such as \"secret/\" or \"auth/userpass/\", or paths within them, such as \"secret/app/\", to only restore the storage keys under that prefix. They need to be mounted at the same path in the cluster.",
+		},
+		"policies": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "Names of the ACL policies to restore.",
+		},
+		"entity_ids": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "IDs of the identity entities to restore.",
+		},
+		"group_ids": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "IDs of the identity groups to restore.",
+		},
+	}
+}
+
 func (b *SystemBackend) handleRaftConfigurationGet() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 		raftBackend := b.Core.getRaftBackend()
@@ -723,6 +949,345 @@ func (b *SystemBackend) handleStorageRaftSnapshotWrite(force bool, makeSealer fu
 	}
 }
 
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigList() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if _, ok := b.Core.underlyingPhysical.(*raft.RaftBackend); !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		names, err := b.Core.barrier.List(ctx, raftSnapshotAutoConfigPrefix)
+		if err != nil {
+			return nil, err
+		}
+		return logical.ListResponse(names), nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if _, ok := b.Core.underlyingPhysical.(*raft.RaftBackend); !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		config, err := b.Core.loadRaftSnapshotAutoConfig(ctx, d.Get("name").(string))
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			return nil, nil
+		}
+
+		// The secret access key and session token are never returned
+		data := map[string]interface{}{
+			"interval":           int64(config.Interval.Seconds()),
+			"retain":             config.Retain,
+			"file_name_template": config.FileNameTemplate,
+			"storage_type":       config.StorageType,
+			"path_prefix":        config.PathPrefix,
+		}
+		if config.StorageType == raftSnapshotAutoStorageS3 {
+			data["aws_s3_bucket"] = config.AWSS3Bucket
+			data["aws_s3_region"] = config.AWSS3Region
+			data["aws_s3_endpoint"] = config.AWSS3Endpoint
+			data["aws_s3_disable_tls"] = config.AWSS3DisableTLS
+			data["aws_s3_force_path_style"] = config.AWSS3ForcePathStyle
+			data["aws_access_key_id"] = config.AWSAccessKeyID
+		}
+		return &logical.Response{
+			Data: data,
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigUpdate() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if _, ok := b.Core.underlyingPhysical.(*raft.RaftBackend); !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		name := d.Get("name").(string)
+		existing, err := b.Core.loadRaftSnapshotAutoConfig(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fields that aren't given keep their current value
+		config := &raftSnapshotAutoConfig{
+			Name:             name,
+			Retain:           1,
+			FileNameTemplate: raftSnapshotAutoDefaultFileNameTemplate,
+		}
+		if existing != nil {
+			*config = *existing
+		}
+		if interval, ok := d.GetOk("interval"); ok {
+			config.Interval = time.Duration(interval.(int)) * time.Second
+		}
+		if retain, ok := d.GetOk("retain"); ok {
+			config.Retain = retain.(int)
+		}
+		for field, value := range map[string]*string{
+			"file_name_template":    &config.FileNameTemplate,
+			"storage_type":          &config.StorageType,
+			"path_prefix":           &config.PathPrefix,
+			"aws_s3_bucket":         &config.AWSS3Bucket,
+			"aws_s3_region":         &config.AWSS3Region,
+			"aws_s3_endpoint":       &config.AWSS3Endpoint,
+			"aws_access_key_id":     &config.AWSAccessKeyID,
+			"aws_secret_access_key": &config.AWSSecretAccessKey,
+			"aws_session_token":     &config.AWSSessionToken,
+		} {
+			if raw, ok := d.GetOk(field); ok {
+				*value = raw.(string)
+			}
+		}
+		for field, value := range map[string]*bool{
+			"aws_s3_disable_tls":      &config.AWSS3DisableTLS,
+			"aws_s3_force_path_style": &config.AWSS3ForcePathStyle,
+		} {
+			if raw, ok := d.GetOk(field); ok {
+				*value = raw.(bool)
+			}
+		}
+
+		if err := config.validate(); err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+
+		entry, err := logical.StorageEntryJSON(raftSnapshotAutoConfigPrefix+name, config)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := b.Core.barrier.Put(ctx, entry); err != nil {
+			return nil, err
+		}
+
+		// Reschedule the snapshots with the new configuration
+		if manager := b.Core.raftSnapshotAuto; manager != nil {
+			manager.stop(name)
+			defer manager.start(config)
+		}
+
+		// When the snapshots move elsewhere, the ones already taken are no
+		// longer rotated, as deleting them from the new location could delete
+		// files the configuration doesn't own
+		if existing != nil && !existing.sameTarget(config) {
+			status, err := b.Core.loadRaftSnapshotAutoStatus(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if status != nil && len(status.Snapshots) > 0 {
+				status.Snapshots = nil
+				entry, err := logical.StorageEntryJSON(raftSnapshotAutoStatusPrefix+name, status)
+				if err != nil {
+					return nil, err
+				}
+				if err := b.Core.barrier.Put(ctx, entry); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigDelete() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if _, ok := b.Core.underlyingPhysical.(*raft.RaftBackend); !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		name := d.Get("name").(string)
+		if manager := b.Core.raftSnapshotAuto; manager != nil {
+			manager.stop(name)
+		}
+
+		// Snapshots already taken are kept
+		if err := b.Core.barrier.Delete(ctx, raftSnapshotAutoConfigPrefix+name); err != nil {
+			return nil, err
+		}
+		if err := b.Core.barrier.Delete(ctx, raftSnapshotAutoStatusPrefix+name); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoStatusRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if _, ok := b.Core.underlyingPhysical.(*raft.RaftBackend); !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		name := d.Get("name").(string)
+		config, err := b.Core.loadRaftSnapshotAutoConfig(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			return nil, nil
+		}
+		status, err := b.Core.loadRaftSnapshotAutoStatus(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if status == nil {
+			status = &raftSnapshotAutoStatus{}
+		}
+
+		snapshots := make([]map[string]interface{}, 0, len(status.Snapshots))
+		for _, snap := range status.Snapshots {
+			snapshots = append(snapshots, map[string]interface{}{
+				"name": snap.Name,
+				"url":  snap.URL,
+				"time": snap.Time.Format(time.RFC3339),
+				"size": snap.Size,
+			})
+		}
+
+		data := map[string]interface{}{
+			"consecutive_errors":  status.ConsecutiveErrors,
+			"last_snapshot_error": status.LastSnapshotError,
+			"last_snapshot_url":   status.LastSnapshotURL,
+			"last_snapshot_size":  status.LastSnapshotSize,
+			"snapshots":           snapshots,
+			"next_snapshot_start": config.nextSnapshot(status).Format(time.RFC3339),
+		}
+		for field, value := range map[string]time.Time{
+			"last_snapshot_start": status.LastSnapshotStart,
+			"last_snapshot_end":   status.LastSnapshotEnd,
+			"last_success_time":   status.LastSuccessTime,
+		} {
+			if !value.IsZero() {
+				data[field] = value.Format(time.RFC3339)
+			}
+		}
+		return &logical.Response{
+			Data: data,
+		}, nil
+	}
+}
+
+func loadedSnapshotResponseData(snap *loadedSnapshot) map[string]interface{} {
+	return map[string]interface{}{
+		"snapshot_id": snap.ID,
+		"status":      "ready",
+		"loaded_at":   snap.LoadedAt.Format(time.RFC3339),
+		"index":       snap.Index,
+		"term":        snap.Term,
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotLoad() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if _, ok := b.Core.underlyingPhysical.(*raft.RaftBackend); !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		source, err := b.makeSnapshotSource(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		r, err := source.ReadCloser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		snap, err := b.Core.raftSnapshotLoad.load(ctx, r)
+		if err != nil {
+			return logical.ErrorResponse("failed to load snapshot: %s", err), logical.ErrInvalidRequest
+		}
+		return &logical.Response{
+			Data: loadedSnapshotResponseData(snap),
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotLoadList() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		return logical.ListResponse(b.Core.raftSnapshotLoad.list()), nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotLoadRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		snap := b.Core.raftSnapshotLoad.get(d.Get("snapshot_id").(string))
+		if snap == nil {
+			return nil, nil
+		}
+		return &logical.Response{
+			Data: loadedSnapshotResponseData(snap),
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotUnload() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		err := b.Core.raftSnapshotLoad.unload(d.Get("snapshot_id").(string), d.Get("force").(bool))
+		switch {
+		case errors.Is(err, ErrSnapshotNotFound):
+			return logical.RespondWithStatusCode(logical.ErrorResponse(err.Error()), req, http.StatusNotFound)
+		case err != nil:
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		return nil, nil
+	}
+}
+
+func snapshotRestoreSelectionFromFieldData(d *framework.FieldData) *snapshotRestoreSelection {
+	return &snapshotRestoreSelection{
+		Mounts:    d.Get("mounts").([]string),
+		Policies:  d.Get("policies").([]string),
+		EntityIDs: d.Get("entity_ids").([]string),
+		GroupIDs:  d.Get("group_ids").([]string),
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotLoadDiff() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		id := d.Get("snapshot_id").(string)
+		if b.Core.raftSnapshotLoad.get(id) == nil {
+			return nil, nil
+		}
+		sel := snapshotRestoreSelectionFromFieldData(d)
+		if sel.empty() {
+			return logical.ErrorResponse("at least one of mounts, policies, entity_ids or group_ids is required"), logical.ErrInvalidRequest
+		}
+
+		diff, err := b.Core.diffSnapshot(ctx, id, sel)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		return &logical.Response{
+			Data: diff.toMap(),
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotLoadRestore() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		id := d.Get("snapshot_id").(string)
+		if b.Core.raftSnapshotLoad.get(id) == nil {
+			return logical.RespondWithStatusCode(logical.ErrorResponse(ErrSnapshotNotFound.Error()), req, http.StatusNotFound)
+		}
+		sel := snapshotRestoreSelectionFromFieldData(d)
+		if sel.empty() {
+			return logical.ErrorResponse("at least one of mounts, policies, entity_ids or group_ids is required"), logical.ErrInvalidRequest
+		}
+
+		diff, err := b.Core.restoreSnapshot(ctx, id, sel)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		return &logical.Response{
+			Data: diff.toMap(),
+		}, nil
+	}
+}
+
 var sysRaftHelp = map[string][2]string{
 	"raft-bootstrap-challenge": {
 		"Creates a challenge for the new peer to be joined to the raft cluster.",
@@ -757,6 +1322,47 @@ var sysRaftHelp = map[string][2]string{
 		"Returns autopilot configuration.",
 		"",
 	},
+	"raft-snapshot-auto-config-list": {
+		"Lists the automated snapshot configurations.",
+		"",
+	},
+	"raft-snapshot-auto-config": {
+		"Manages automated snapshot configurations.",
+		`The active node takes a snapshot for every configuration each interval,
+		and stores it in a local directory or in an S3-compatible object storage.
+		The oldest snapshots taken for the configuration beyond the number it
+		retains are deleted.`,
+	},
+	"raft-snapshot-auto-status": {
+		"Returns the status of an automated snapshot configuration.",
+		"",
+	},
+	"raft-snapshot-load": {
+		"Loads a snapshot into a read-only view, or lists the loaded snapshots.",
+		`Loaded snapshots are kept on the active node, separate from the live storage,
+until they are unloaded or the node steps down. Requests to secrets engines and
+auth methods read from a loaded snapshot with the read_snapshot_id parameter,
+and restore single paths with the recover_snapshot_id parameter.`,
+	},
+	"raft-snapshot-load-id": {
+		"Returns the status of a loaded snapshot, or unloads it.",
+		"",
+	},
+	"raft-snapshot-load-diff": {
+		"Previews the changes restoring the selection from a loaded snapshot would make.",
+		`For mounts, the storage keys that would be added, changed and removed are
+returned. For policies, entities and groups, whether they would be added or
+changed, or are unchanged.`,
+	},
+	"raft-snapshot-load-restore": {
+		"Restores the selection from a loaded snapshot into the live cluster.",
+		`The storage of the selected mounts, or of the selected prefixes within
+them, is replaced with their storage in the snapshot, and the mounts are
+reloaded. The selected policies, entities and groups are written as they are in
+the snapshot. Everything selected is checked before anything is restored, but
+the restore isn't transactional: if a write fails, the writes made before it
+are not rolled back. Restoring the same selection again completes it.`,
+	},
 }
 
 func NewSealAccessSealer(access seal.Access, logger hclog.Logger, use string) snapshot.Sealer {
@@ -78,7 +78,7 @@ func (c *Core) entBuiltinPluginMetrics(ctx context.Context, entry *MountEntry, v
 }
 
 func newSnapshotStorageRouter(c *Core, storage logical.Storage) logical.Storage {
-	return storage
+	return logical.NewSnapshotStorageRouter(storage, c.raftSnapshotLoad)
 }
 
 func (c *Core) addRequiredNamespaceMounts(mountEntries []*MountEntry) ([]*MountEntry, bool, error) {
@@ -6,6 +6,7 @@ package vault
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -162,6 +163,12 @@ type PathRules struct {
 	MFAMethodsHCL          []string                 `hcl:"mfa_methods"`
 	ControlGroupHCL        *ControlGroupHCL         `hcl:"control_group"`
 	SubscribeEventTypesHCL []string                 `hcl:"subscribe_event_types"`
+	ConditionHCL           *ConditionHCL            `hcl:"condition"`
+
+	// ParsedCondition restricts the capabilities of the rule to requests meeting
+	// it. It is kept out of ACLPermissions, which are merged across policies
+	// while conditions belong to the rule of a single policy.
+	ParsedCondition *ACLCondition
 }
 
 type ControlGroupHCL struct {
@@ -208,6 +215,11 @@ type ACLPermissions struct {
 	ControlGroup        *ControlGroup
 	GrantingPoliciesMap map[uint32][]logical.PolicyInfo
 	SubscribeEventTypes []string
+
+	// ConditionalGrants are the capabilities granted on the path by rules
+	// with a condition. They are not part of CapabilitiesBitmap, as they only
+	// apply to requests meeting the condition.
+	ConditionalGrants []*ACLConditionalGrant
 }
 
 func (p *ACLPermissions) Clone() (*ACLPermissions, error) {
@@ -217,6 +229,7 @@ func (p *ACLPermissions) Clone() (*ACLPermissions, error) {
 		MaxWrappingTTL:      p.MaxWrappingTTL,
 		RequiredParameters:  p.RequiredParameters[:],
 		SubscribeEventTypes: p.SubscribeEventTypes[:],
+		ConditionalGrants:   slices.Clone(p.ConditionalGrants),
 	}
 
 	switch {
@@ -422,10 +435,14 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 			"mfa_methods",
 			"control_group",
 			"subscribe_event_types",
+			"condition",
 		}
 		if err := hclutil.CheckHCLKeys(item.Val, valid); err != nil {
 			return multierror.Prefix(err, fmt.Sprintf("path %q:", key))
 		}
+		if err := checkConditionHCLKeys(item.Val); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("path %q:", key))
+		}
 
 		var pc PathRules
 
@@ -486,6 +503,9 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 			switch cap {
 			// If it's deny, don't include any other capability
 			case DenyCapability:
+				if pc.ConditionHCL != nil {
+					return fmt.Errorf("path %q: condition cannot be used with the deny capability", key)
+				}
 				pc.Capabilities = []string{DenyCapability}
 				pc.Permissions.CapabilitiesBitmap = DenyCapabilityInt
 				goto PathFinished
@@ -590,6 +610,13 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 		if len(pc.SubscribeEventTypesHCL) > 0 {
 			pc.Permissions.SubscribeEventTypes = pc.SubscribeEventTypesHCL[:]
 		}
+		if pc.ConditionHCL != nil {
+			condition, err := parseACLCondition(pc.ConditionHCL)
+			if err != nil {
+				return fmt.Errorf("path %q: %w", key, err)
+			}
+			pc.ParsedCondition = condition
+		}
 
 	PathFinished:
 		paths = append(paths, &pc)
@@ -0,0 +1,348 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/go-sockaddr"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/vault/sdk/helper/cidrutil"
+	"github.com/hashicorp/vault/sdk/helper/hclutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// ConditionHCL is the condition block of a path rule. The capabilities of a
+// rule with a condition only apply to requests made in the described context.
+type ConditionHCL struct {
+	SourceCIDRs     []string            `hcl:"source_cidrs"`
+	Timezone        string              `hcl:"timezone"`
+	TimeWindows     []*TimeWindowHCL    `hcl:"time_windows"`
+	ClientCertSANs  []string            `hcl:"client_cert_sans"`
+	RequiredHeaders map[string][]string `hcl:"required_headers"`
+}
+
+// TimeWindowHCL is an element of the time_windows list of a condition.
+type TimeWindowHCL struct {
+	Days  []string `hcl:"days"`
+	Start string   `hcl:"start"`
+	End   string   `hcl:"end"`
+}
+
+// ACLCondition is the parsed condition of a path rule. A request meets the
+// condition when it meets every constraint that is set.
+type ACLCondition struct {
+	// SourceCIDRs are the CIDRs the remote address of the request must be
+	// in.
+	SourceCIDRs []*sockaddr.SockAddrMarshaler
+
+	// TimeWindows are the windows the request must be made in, in the
+	// timezone of Location.
+	Location    *time.Location
+	TimeWindows []*ACLTimeWindow
+
+	// ClientCertSANs are globs, one of which must match a subject
+	// alternative name of the TLS client certificate of the request.
+	ClientCertSANs []string
+
+	// RequiredHeaders maps the canonical names of the headers the request
+	// must have to globs, one of which must match a value of the header. A
+	// header without globs only needs to be present.
+	RequiredHeaders map[string][]string
+}
+
+// ACLTimeWindow is a daily window of time, as offsets from midnight. A window
+// whose end is not after its start runs past midnight into the next day.
+type ACLTimeWindow struct {
+	Days  []time.Weekday
+	Start time.Duration
+	End   time.Duration
+}
+
+// ACLConditionalGrant holds the capabilities a policy grants on a path only to
+// requests meeting the condition of its rule.
+type ACLConditionalGrant struct {
+	CapabilitiesBitmap uint32
+	Condition          *ACLCondition
+	Policy             logical.PolicyInfo
+}
+
+var conditionWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// checkConditionHCLKeys checks the keys of the condition block of a path
+// rule, as the HCL decoder ignores unknown keys.
+func checkConditionHCLKeys(node ast.Node) error {
+	obj, ok := node.(*ast.ObjectType)
+	if !ok {
+		return nil
+	}
+
+	conditions := obj.List.Filter("condition")
+	if len(conditions.Items) > 1 {
+		return errors.New("only one condition block is allowed")
+	}
+	for _, item := range conditions.Items {
+		valid := []string{
+			"source_cidrs",
+			"timezone",
+			"time_windows",
+			"client_cert_sans",
+			"required_headers",
+		}
+		if err := hclutil.CheckHCLKeys(item.Val, valid); err != nil {
+			return multierror.Prefix(err, "condition:")
+		}
+
+		condition, ok := item.Val.(*ast.ObjectType)
+		if !ok {
+			continue
+		}
+		for _, windows := range condition.List.Filter("time_windows").Items {
+			list, ok := windows.Val.(*ast.ListType)
+			if !ok {
+				continue
+			}
+			for _, window := range list.List {
+				if err := hclutil.CheckHCLKeys(window, []string{"days", "start", "end"}); err != nil {
+					return multierror.Prefix(err, "condition: time_windows:")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseACLCondition validates a condition block and parses it.
+func parseACLCondition(c *ConditionHCL) (*ACLCondition, error) {
+	ret := &ACLCondition{
+		Location:       time.UTC,
+		ClientCertSANs: c.ClientCertSANs,
+	}
+
+	if len(c.SourceCIDRs) > 0 {
+		cidrs, err := parseutil.ParseAddrs(c.SourceCIDRs)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing source_cidrs: %w", err)
+		}
+		ret.SourceCIDRs = cidrs
+	}
+
+	if c.Timezone != "" {
+		if len(c.TimeWindows) == 0 {
+			return nil, errors.New("timezone requires time_windows")
+		}
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", c.Timezone, err)
+		}
+		ret.Location = loc
+	}
+
+	for _, w := range c.TimeWindows {
+		window := &ACLTimeWindow{}
+		for _, day := range w.Days {
+			weekday, ok := conditionWeekdays[strings.ToLower(day)]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %q in time_windows, must be one of sun, mon, tue, wed, thu, fri or sat", day)
+			}
+			window.Days = append(window.Days, weekday)
+		}
+
+		var err error
+		if window.Start, err = parseConditionTimeOfDay(w.Start); err != nil {
+			return nil, fmt.Errorf("invalid start of time window: %w", err)
+		}
+		if window.End, err = parseConditionTimeOfDay(w.End); err != nil {
+			return nil, fmt.Errorf("invalid end of time window: %w", err)
+		}
+		if window.Start == window.End {
+			return nil, errors.New("start and end of a time window cannot be equal")
+		}
+		ret.TimeWindows = append(ret.TimeWindows, window)
+	}
+
+	if len(c.RequiredHeaders) > 0 {
+		ret.RequiredHeaders = make(map[string][]string, len(c.RequiredHeaders))
+		for name, values := range c.RequiredHeaders {
+			if name == "" {
+				return nil, errors.New("required_headers cannot contain an empty header name")
+			}
+			ret.RequiredHeaders[textproto.CanonicalMIMEHeaderKey(name)] = values
+		}
+	}
+
+	if len(ret.SourceCIDRs) == 0 && len(ret.TimeWindows) == 0 && len(ret.ClientCertSANs) == 0 && len(ret.RequiredHeaders) == 0 {
+		return nil, errors.New("condition must set at least one of source_cidrs, time_windows, client_cert_sans or required_headers")
+	}
+
+	return ret, nil
+}
+
+// parseConditionTimeOfDay parses a time of day in the 24-hour "HH:MM" format
+// into an offset from midnight. "24:00" is accepted as the end of the day.
+func parseConditionTimeOfDay(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day in the HH:MM format", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Check returns the reason the request does not meet the condition at the
+// given time, or an empty string if it does.
+func (c *ACLCondition) Check(req *logical.Request, now time.Time) string {
+	if len(c.SourceCIDRs) > 0 {
+		if req.Connection == nil || req.Connection.RemoteAddr == "" {
+			return "the source address of the request is unknown"
+		}
+		if !cidrutil.RemoteAddrIsOk(req.Connection.RemoteAddr, c.SourceCIDRs) {
+			return fmt.Sprintf("source address %q is not in an allowed CIDR", req.Connection.RemoteAddr)
+		}
+	}
+
+	if len(c.TimeWindows) > 0 {
+		local := now.In(c.Location)
+		inWindow := false
+		for _, window := range c.TimeWindows {
+			if window.contains(local) {
+				inWindow = true
+				break
+			}
+		}
+		if !inWindow {
+			return fmt.Sprintf("the request is outside of the allowed time windows (%s in %s)", local.Format("Mon 15:04"), c.Location)
+		}
+	}
+
+	if len(c.ClientCertSANs) > 0 {
+		if req.Connection == nil || req.Connection.ConnState == nil || len(req.Connection.ConnState.PeerCertificates) == 0 {
+			return "the request has no client certificate"
+		}
+		cert := req.Connection.ConnState.PeerCertificates[0]
+		sans := append([]string{}, cert.DNSNames...)
+		sans = append(sans, cert.EmailAddresses...)
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+		for _, uri := range cert.URIs {
+			sans = append(sans, uri.String())
+		}
+		matched := false
+		for _, san := range sans {
+			if strutil.StrListContainsGlob(c.ClientCertSANs, san) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "the client certificate has no allowed subject alternative name"
+		}
+	}
+
+	for name, allowed := range c.RequiredHeaders {
+		values := req.Headers[name]
+		if len(values) == 0 {
+			return fmt.Sprintf("required header %q is missing", name)
+		}
+		if len(allowed) == 0 {
+			continue
+		}
+		matched := false
+		for _, value := range values {
+			if strutil.StrListContainsGlob(allowed, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("value of header %q is not allowed", name)
+		}
+	}
+
+	return ""
+}
+
+// contains reports whether the local time is within the window.
+func (w *ACLTimeWindow) contains(local time.Time) bool {
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	day := local.Weekday()
+
+	if w.Start < w.End {
+		return w.onDay(day) && offset >= w.Start && offset < w.End
+	}
+
+	// The window runs past midnight, so it started either today or on the
+	// previous day
+	return (w.onDay(day) && offset >= w.Start) ||
+		(w.onDay((day+6)%7) && offset < w.End)
+}
+
+// onDay reports whether the window applies to the day. A window without days
+// applies to every day.
+func (w *ACLTimeWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionalCapabilities returns the capabilities of the conditional grants
+// whose condition the request meets, and the policies granting each of them.
+func (p *ACLPermissions) conditionalCapabilities(req *logical.Request, now time.Time) (uint32, map[uint32][]logical.PolicyInfo) {
+	var capabilities uint32
+	var grantingPolicies map[uint32][]logical.PolicyInfo
+	for _, grant := range p.ConditionalGrants {
+		if grant.Condition.Check(req, now) != "" {
+			continue
+		}
+		capabilities |= grant.CapabilitiesBitmap
+		if grantingPolicies == nil {
+			grantingPolicies = make(map[uint32][]logical.PolicyInfo)
+		}
+		for _, capability := range cap2Int {
+			if grant.CapabilitiesBitmap&capability > 0 {
+				grantingPolicies[capability] = append(grantingPolicies[capability], grant.Policy)
+			}
+		}
+	}
+	return capabilities, grantingPolicies
+}
+
+// newConditionalGrant returns the conditional grant of a path rule of the
+// policy.
+func newConditionalGrant(policy *Policy, pc *PathRules) *ACLConditionalGrant {
+	return &ACLConditionalGrant{
+		CapabilitiesBitmap: pc.Permissions.CapabilitiesBitmap,
+		Condition:          pc.ParsedCondition,
+		Policy: logical.PolicyInfo{
+			Name:          policy.Name,
+			NamespaceId:   policy.namespace.ID,
+			NamespacePath: policy.namespace.Path,
+			Type:          "acl",
+		},
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_ParseCondition(t *testing.T) {
+	policy, err := ParseACLPolicy(namespace.RootNamespace, `
+path "secret/prod/*" {
+	capabilities = ["create", "update"]
+	condition {
+		source_cidrs     = ["10.0.1.0/24"]
+		timezone         = "Europe/Berlin"
+		client_cert_sans = ["*.bastion.example.com"]
+		required_headers = {
+			"x-change-ticket" = []
+		}
+		time_windows = [
+			{
+				days = ["mon", "tue", "wed", "thu", "fri"],
+				start = "09:00",
+				end = "17:30"
+			},
+			{
+				days = ["sat"],
+				start = "22:00",
+				end = "02:00"
+			}
+		]
+	}
+}
+`)
+	require.NoError(t, err)
+	require.Len(t, policy.Paths, 1)
+
+	condition := policy.Paths[0].ParsedCondition
+	require.NotNil(t, condition)
+	require.Len(t, condition.SourceCIDRs, 1)
+	require.Equal(t, "Europe/Berlin", condition.Location.String())
+	require.Equal(t, []string{"*.bastion.example.com"}, condition.ClientCertSANs)
+	require.Equal(t, map[string][]string{"X-Change-Ticket": {}}, condition.RequiredHeaders)
+	require.Equal(t, []*ACLTimeWindow{
+		{
+			Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Start: 9 * time.Hour,
+			End:   17*time.Hour + 30*time.Minute,
+		},
+		{
+			Days:  []time.Weekday{time.Saturday},
+			Start: 22 * time.Hour,
+			End:   2 * time.Hour,
+		},
+	}, condition.TimeWindows)
+
+	for name, rules := range map[string]string{
+		"unknown key": `
+path "secret/*" {
+	capabilities = ["read"]
+	condition {
+		source_cidr = ["10.0.0.0/8"]
+	}
+}`,
+		"unknown time window key": `
+path "secret/*" {
+	capabilities = ["read"]
+	condition {
+		time_windows = [
+			{
+				start = "09:00",
+				end = "17:00",
+				zone = "UTC"
+			}
+		]
+	}
+}`,
+		"empty condition": `
+path "secret/*" {
+	capabilities = ["read"]
+	condition {}
+}`,
+		"invalid cidr": `
+path "secret/*" {
+	capabilities = ["read"]
+	condition {
+		source_cidrs = ["not-a-cidr"]
+	}
+}`,
+		"invalid timezone": `
+path "secret/*" {
+	capabilities = ["read"]
+	condition {
+		timezone = "Mars/Olympus_Mons"
+		time_windows = [
+			{
+				start = "09:00",
+				end = "17:00"
+			}
+		]
+	}
+}`,
+		"invalid day": `
+path "secret/*" {
+	capabilities = ["read"]
+	condition {
+		time_windows = [
+			{
+				days = ["someday"],
+				start = "09:00",
+				end = "17:00"
+			}
+		]
+	}
+}`,
+		"invalid time": `
+path "secret/*" {
+	capabilities = ["read"]
+	condition {
+		time_windows = [
+			{
+				start = "9am",
+				end = "17:00"
+			}
+		]
+	}
+}`,
+		"empty time window": `
+path "secret/*" {
+	capabilities = ["read"]
+	condition {
+		time_windows = [
+			{
+				start = "09:00",
+				end = "09:00"
+			}
+		]
+	}
+}`,
+		"deny": `
+path "secret/*" {
+	capabilities = ["deny"]
+	condition {
+		source_cidrs = ["10.0.0.0/8"]
+	}
+}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseACLPolicy(namespace.RootNamespace, rules)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestACLCondition_Check(t *testing.T) {
+	policy, err := ParseACLPolicy(namespace.RootNamespace, `
+path "secret/*" {
+	capabilities = ["update"]
+	condition {
+		source_cidrs     = ["10.0.1.0/24"]
+		timezone         = "America/New_York"
+		client_cert_sans = ["*.bastion.example.com", "spiffe://example.com/*"]
+		required_headers = {
+			"X-Change-Ticket" = ["CHG-*"]
+		}
+		time_windows = [
+			{
+				days = ["fri"],
+				start = "22:00",
+				end = "02:00"
+			}
+		]
+	}
+}
+`)
+	require.NoError(t, err)
+	condition := policy.Paths[0].ParsedCondition
+
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	// A Friday
+	friday := time.Date(2026, time.October, 16, 23, 0, 0, 0, ny)
+
+	request := func() *logical.Request {
+		return &logical.Request{
+			Connection: &logical.Connection{
+				RemoteAddr: "10.0.1.7",
+				ConnState: &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{{
+						DNSNames: []string{"host1.bastion.example.com"},
+					}},
+				},
+			},
+			Headers: http.Header{
+				"X-Change-Ticket": []string{"CHG-1234"},
+			},
+		}
+	}
+
+	require.Empty(t, condition.Check(request(), friday))
+	require.Empty(t, condition.Check(request(), friday.UTC()))
+
+	// The window runs past midnight into Saturday, but not into Friday
+	// morning
+	require.Empty(t, condition.Check(request(), friday.Add(2*time.Hour+59*time.Minute)))
+	require.Contains(t, condition.Check(request(), friday.Add(3*time.Hour)), "outside of the allowed time windows")
+	require.Contains(t, condition.Check(request(), friday.Add(-22*time.Hour)), "outside of the allowed time windows")
+
+	req := request()
+	req.Connection.RemoteAddr = "10.0.2.7"
+	require.Contains(t, condition.Check(req, friday), "is not in an allowed CIDR")
+
+	req = request()
+	req.Connection = nil
+	require.Equal(t, "the source address of the request is unknown", condition.Check(req, friday))
+
+	req = request()
+	req.Connection.ConnState = nil
+	require.Equal(t, "the request has no client certificate", condition.Check(req, friday))
+
+	req = request()
+	req.Connection.ConnState.PeerCertificates[0].DNSNames = []string{"bastion.example.org"}
+	require.Equal(t, "the client certificate has no allowed subject alternative name", condition.Check(req, friday))
+
+	req = request()
+	req.Connection.ConnState.PeerCertificates[0].DNSNames = nil
+	uri, err := url.Parse("spiffe://example.com/bastion")
+	require.NoError(t, err)
+	req.Connection.ConnState.PeerCertificates[0].URIs = []*url.URL{uri}
+	require.Empty(t, condition.Check(req, friday))
+
+	req = request()
+	req.Headers = nil
+	require.Equal(t, `required header "X-Change-Ticket" is missing`, condition.Check(req, friday))
+
+	req = request()
+	req.Headers["X-Change-Ticket"] = []string{"INC-1"}
+	require.Equal(t, `value of header "X-Change-Ticket" is not allowed`, condition.Check(req, friday))
+}
+
+func TestACL_Conditions(t *testing.T) {
+	ctx := namespace.RootContext(context.Background())
+	parse := func(name, rules string) *Policy {
+		t.Helper()
+		policy, err := ParseACLPolicy(namespace.RootNamespace, rules)
+		require.NoError(t, err)
+		policy.Name = name
+		return policy
+	}
+	bastion := parse("bastion", `
+path "secret/prod/*" {
+	capabilities = ["create", "update"]
+	condition {
+		source_cidrs = ["10.0.1.0/24"]
+	}
+}
+path "secret/shared/*" {
+	capabilities = ["update"]
+	condition {
+		source_cidrs = ["10.0.1.0/24"]
+	}
+}
+`)
+	reader := parse("reader", `
+path "secret/prod/*" {
+	capabilities = ["read"]
+}
+path "secret/shared/*" {
+	capabilities = ["update"]
+}
+`)
+	denier := parse("denier", `
+path "secret/prod/*" {
+	capabilities = ["deny"]
+}
+`)
+
+	request := func(op logical.Operation, path, remoteAddr string) *logical.Request {
+		return &logical.Request{
+			Operation:  op,
+			Path:       path,
+			Connection: &logical.Connection{RemoteAddr: remoteAddr},
+		}
+	}
+
+	acl, err := NewACL(ctx, []*Policy{bastion, reader})
+	require.NoError(t, err)
+
+	// The conditional capabilities are only granted from the bastion subnet,
+	// while the unconditional ones are always granted
+	result := acl.AllowOperation(ctx, request(logical.UpdateOperation, "secret/prod/db", "10.0.1.5"), false)
+	require.True(t, result.Allowed)
+	require.Len(t, result.GrantingPolicies, 1)
+	require.Equal(t, "bastion", result.GrantingPolicies[0].Name)
+	require.False(t, acl.AllowOperation(ctx, request(logical.UpdateOperation, "secret/prod/db", "192.168.0.5"), false).Allowed)
+	require.True(t, acl.AllowOperation(ctx, request(logical.ReadOperation, "secret/prod/db", "192.168.0.5"), false).Allowed)
+
+	// An unconditional grant of the same capability is not restricted by the
+	// condition of another policy
+	result = acl.AllowOperation(ctx, request(logical.UpdateOperation, "secret/shared/db", "192.168.0.5"), false)
+	require.True(t, result.Allowed)
+	require.Len(t, result.GrantingPolicies, 1)
+	require.Equal(t, "reader", result.GrantingPolicies[0].Name)
+	result = acl.AllowOperation(ctx, request(logical.UpdateOperation, "secret/shared/db", "10.0.1.5"), false)
+	require.True(t, result.Allowed)
+	require.Len(t, result.GrantingPolicies, 2)
+
+	// Capabilities reflect the conditions being met by the request
+	require.Equal(t, []string{ReadCapability}, acl.Capabilities(ctx, "secret/prod/db"))
+
+	// Deny overrides conditional grants, whatever the order of the policies
+	for _, policies := range [][]*Policy{{bastion, denier}, {denier, bastion}} {
+		acl, err := NewACL(ctx, policies)
+		require.NoError(t, err)
+		require.False(t, acl.AllowOperation(ctx, request(logical.UpdateOperation, "secret/prod/db", "10.0.1.5"), false).Allowed)
+	}
+
+	// Explain reports why the condition is not met
+	policies := []*Policy{bastion, reader}
+	explanation, err := acl.Explain(ctx, policies, request(logical.UpdateOperation, "secret/prod/db", "192.168.0.5"))
+	require.NoError(t, err)
+	require.False(t, explanation.Allowed)
+	require.Equal(t, `"secret/prod/*" grants the "update" capability only on a condition: in policy "bastion", source address "192.168.0.5" is not in an allowed CIDR`, explanation.Reason)
+	require.Equal(t, []string{ReadCapability}, explanation.Capabilities)
+	require.Equal(t, `source address "192.168.0.5" is not in an allowed CIDR`, explanation.Policies[0].Rules[0].ConditionVerdict)
+	require.Empty(t, explanation.Policies[1].Rules[0].ConditionVerdict)
+
+	explanation, err = acl.Explain(ctx, policies, request(logical.UpdateOperation, "secret/prod/db", "10.0.1.5"))
+	require.NoError(t, err)
+	require.True(t, explanation.Allowed)
+	require.Equal(t, "met", explanation.Policies[0].Rules[0].ConditionVerdict)
+}
@@ -0,0 +1,283 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// The checks run by LintACLPolicy.
+const (
+	PolicyLintDuplicateRule       = "duplicate_rule"
+	PolicyLintShadowedRule        = "shadowed_rule"
+	PolicyLintDenyOverridden      = "deny_overridden"
+	PolicyLintUnmatchableRule     = "unmatchable_rule"
+	PolicyLintDenyWithOtherFields = "deny_with_other_fields"
+	PolicyLintBroadGlob           = "broad_glob"
+	PolicyLintIgnoredParameters   = "ignored_parameters"
+	PolicyLintMissingMount        = "missing_mount"
+)
+
+// The severities of lint findings. Errors are rules that don't do what they
+// say, warnings are rules that likely do more or less than intended.
+const (
+	PolicyLintSeverityError   = "error"
+	PolicyLintSeverityWarning = "warning"
+)
+
+// PolicyLintFinding is an issue found in a path rule of an ACL policy.
+type PolicyLintFinding struct {
+	Check    string `json:"check" mapstructure:"check"`
+	Severity string `json:"severity" mapstructure:"severity"`
+	Rule     string `json:"rule" mapstructure:"rule"`
+	Message  string `json:"message" mapstructure:"message"`
+}
+
+// writeCapabilities are the capabilities that modify data.
+const writeCapabilities = CreateCapabilityInt | UpdateCapabilityInt | DeleteCapabilityInt | PatchCapabilityInt | SudoCapabilityInt
+
+// LintACLPolicy statically analyzes the path rules of an ACL policy. If mounts
+// is not nil, it lists the paths of the mounts of the namespace of the policy,
+// with auth mounts prefixed by "auth/", and rules outside of all of them are
+// reported.
+func LintACLPolicy(ctx context.Context, p *Policy, mounts []string) ([]*PolicyLintFinding, error) {
+	if p.Type != PolicyTypeACL {
+		return nil, fmt.Errorf("only ACL policies can be linted")
+	}
+
+	ns := p.namespace
+	if ns == nil {
+		ns = namespace.RootNamespace
+	}
+	acl, err := NewACL(namespace.ContextWithNamespace(ctx, ns), []*Policy{p})
+	if err != nil {
+		return nil, err
+	}
+	writtenCapabilities, err := policyWrittenCapabilities(p.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*PolicyLintFinding
+	add := func(pc *PathRules, check, severity, format string, args ...interface{}) {
+		findings = append(findings, &PolicyLintFinding{
+			Check:    check,
+			Severity: severity,
+			Rule:     lintRulePath(ns, pc),
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	seen := make(map[string]bool, len(p.Paths))
+	for _, pc := range p.Paths {
+		rule := lintRulePath(ns, pc)
+		isDeny := pc.Permissions.CapabilitiesBitmap&DenyCapabilityInt > 0
+		relative := strings.TrimPrefix(pc.Path, ns.Path)
+
+		if seen[rule] {
+			add(pc, PolicyLintDuplicateRule, PolicyLintSeverityWarning,
+				"the rule appears more than once in the policy, and its occurrences are merged")
+		}
+		seen[rule] = true
+
+		// A '*' is only a glob at the end of a path, and a '+' only a
+		// wildcard as a whole segment. Anywhere else they are literals that
+		// requests never contain.
+		unmatchable := strings.Contains(strings.TrimSuffix(relative, "*"), "*")
+		for _, segment := range strings.Split(relative, "/") {
+			unmatchable = unmatchable || (strings.Contains(segment, "+") && segment != "+")
+		}
+		if unmatchable {
+			severity := PolicyLintSeverityWarning
+			if isDeny {
+				severity = PolicyLintSeverityError
+			}
+			add(pc, PolicyLintUnmatchableRule, severity,
+				"'*' is only a glob at the end of a path and '+' is only a wildcard as a whole segment, so the rule matches them literally and never applies")
+		}
+
+		if isDeny {
+			var ignored []string
+			for _, capability := range writtenCapabilities[rule] {
+				if capability != DenyCapability {
+					ignored = append(ignored, capability)
+				}
+			}
+			if len(ignored) > 0 {
+				add(pc, PolicyLintDenyWithOtherFields, PolicyLintSeverityWarning,
+					"deny overrides every other capability, so %s are ignored", strings.Join(ignored, ", "))
+			}
+			if len(pc.AllowedParametersHCL) > 0 || len(pc.DeniedParametersHCL) > 0 || len(pc.RequiredParametersHCL) > 0 ||
+				pc.MinWrappingTTLHCL != nil || pc.MaxWrappingTTLHCL != nil || len(pc.MFAMethodsHCL) > 0 || pc.ControlGroupHCL != nil {
+				add(pc, PolicyLintDenyWithOtherFields, PolicyLintSeverityWarning,
+					"the parameter, wrapping, MFA and control group settings of a deny rule are ignored")
+			}
+		}
+
+		if !isDeny && operationsIgnoreParameters(pc) {
+			add(pc, PolicyLintIgnoredParameters, PolicyLintSeverityWarning,
+				"parameter constraints only apply to create, read, update, patch and recover requests, which the rule does not grant")
+		}
+
+		if !isDeny && (pc.IsPrefix || pc.HasSegmentWildcards) {
+			prefix := lintLiteralPrefix(relative)
+			granted := pc.Permissions.CapabilitiesBitmap
+			mountWide := pc.IsPrefix && (!strings.Contains(prefix, "/") || strings.Count(strings.TrimPrefix(prefix, "auth/"), "/") == 1 && strings.HasSuffix(prefix, "/"))
+			switch {
+			case prefix == "":
+				add(pc, PolicyLintBroadGlob, PolicyLintSeverityWarning,
+					"the rule matches every path of the namespace")
+			case mountWide && strings.HasPrefix(prefix, "sys") && granted&writeCapabilities > 0:
+				add(pc, PolicyLintBroadGlob, PolicyLintSeverityWarning,
+					"the rule grants %s on every system endpoint", strings.Join(capabilityNames(granted&writeCapabilities), ", "))
+			case mountWide && granted&SudoCapabilityInt > 0:
+				add(pc, PolicyLintBroadGlob, PolicyLintSeverityWarning,
+					"the rule grants sudo on every path of the %q mount", strings.TrimSuffix(prefix, "/")+"/")
+			}
+			if pc.IsPrefix && prefix != "" && !strings.Contains(prefix, "/") {
+				add(pc, PolicyLintBroadGlob, PolicyLintSeverityWarning,
+					"the rule also matches every mount whose path starts with %q, such as %q", prefix, prefix+"-prod/")
+			}
+		}
+
+		if mounts != nil && !lintMountExists(relative, pc.IsPrefix || pc.HasSegmentWildcards, mounts) {
+			add(pc, PolicyLintMissingMount, PolicyLintSeverityWarning,
+				"no mount exists at the path of the rule")
+		}
+
+		// Rules matching the paths of this rule, over which this rule takes
+		// precedence
+		representative := strings.TrimSuffix(pc.Path, "*")
+		permissions := acl.permissionsForPath(representative, logical.ReadOperation)
+		if permissions == nil {
+			continue
+		}
+		selectedPath, selectedMatch := acl.ruleForPermissions(permissions)
+		if selectedPath != pc.Path || (selectedMatch == ACLRuleMatchGlob) != pc.IsPrefix {
+			continue
+		}
+		for _, broader := range p.Paths {
+			if lintRulePath(ns, broader) == rule || !(broader.IsPrefix || broader.HasSegmentWildcards) {
+				continue
+			}
+			if _, ok := ruleMatchesPath(broader, representative, logical.ReadOperation); !ok {
+				continue
+			}
+
+			broaderRule := lintRulePath(ns, broader)
+			switch {
+			case broader.Permissions.CapabilitiesBitmap&DenyCapabilityInt > 0:
+				if !isDeny {
+					add(pc, PolicyLintDenyOverridden, PolicyLintSeverityWarning,
+						"the rule is more specific than the deny of %q, so the paths it matches are not denied", broaderRule)
+				}
+			case isDeny:
+			default:
+				missing := broader.Permissions.CapabilitiesBitmap &^ pc.Permissions.CapabilitiesBitmap
+				if missing != 0 {
+					add(pc, PolicyLintShadowedRule, PolicyLintSeverityWarning,
+						"the rule is more specific than %q and takes precedence over it, so %s granted by %q do not apply to the paths it matches",
+						broaderRule, strings.Join(capabilityNames(missing), ", "), broaderRule)
+				}
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// operationsIgnoreParameters reports whether a rule constrains parameters
+// without granting any of the operations whose parameters are checked.
+func operationsIgnoreParameters(pc *PathRules) bool {
+	if len(pc.AllowedParametersHCL) == 0 && len(pc.DeniedParametersHCL) == 0 && len(pc.RequiredParametersHCL) == 0 {
+		return false
+	}
+	for _, op := range policyExplainOperations {
+		capability, ok := operationCapability(op)
+		if ok && operationChecksParameters(op) && pc.Permissions.CapabilitiesBitmap&capability != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lintRulePath returns the path of a rule as it is written in the policy.
+func lintRulePath(ns *namespace.Namespace, pc *PathRules) string {
+	path := strings.TrimPrefix(pc.Path, ns.Path)
+	if pc.IsPrefix {
+		return path + "*"
+	}
+	return path
+}
+
+// lintLiteralPrefix returns the part of a rule path before its first glob or
+// wildcard.
+func lintLiteralPrefix(path string) string {
+	if i := strings.IndexAny(path, "*+"); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
+
+// lintMountExists reports whether a rule path, relative to the namespace of
+// the mounts, is within one of them. Paths whose mount is a wildcard or a
+// template are assumed to be.
+func lintMountExists(path string, wildcard bool, mounts []string) bool {
+	prefix := lintLiteralPrefix(path)
+	if i := strings.Index(prefix, "{{"); i >= 0 {
+		prefix = prefix[:i]
+		wildcard = true
+	}
+	if prefix == "" || prefix == "auth/" {
+		return true
+	}
+
+	for _, mount := range mounts {
+		switch {
+		case strings.HasPrefix(prefix, mount):
+			return true
+		case wildcard && strings.HasPrefix(mount, prefix):
+			return true
+		case strings.TrimSuffix(mount, "/") == prefix:
+			return true
+		}
+	}
+	return false
+}
+
+// policyWrittenCapabilities returns the capabilities of each rule as written
+// in the policy, before a deny replaces all others.
+func policyWrittenCapabilities(raw string) (map[string][]string, error) {
+	root, err := hcl.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	list, ok := root.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse policy: does not contain a root object")
+	}
+
+	ret := make(map[string][]string)
+	for _, item := range list.Filter("path").Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+		var rule struct {
+			Capabilities []string `hcl:"capabilities"`
+		}
+		if err := hcl.DecodeObject(&rule, item.Val); err != nil {
+			return nil, fmt.Errorf("failed to parse policy: %w", err)
+		}
+		key := strings.TrimPrefix(item.Keys[0].Token.Value().(string), "/")
+		ret[key] = append(ret[key], rule.Capabilities...)
+	}
+	return ret, nil
+}
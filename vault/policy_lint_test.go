@@ -0,0 +1,179 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintACLPolicy(t *testing.T) {
+	ctx := namespace.RootContext(context.Background())
+
+	lint := func(rules string, mounts []string) map[string][]*PolicyLintFinding {
+		t.Helper()
+		policy, err := ParseACLPolicy(namespace.RootNamespace, rules)
+		require.NoError(t, err)
+		findings, err := LintACLPolicy(ctx, policy, mounts)
+		require.NoError(t, err)
+		byRule := make(map[string][]*PolicyLintFinding)
+		for _, finding := range findings {
+			byRule[finding.Rule] = append(byRule[finding.Rule], finding)
+		}
+		return byRule
+	}
+	checks := func(findings []*PolicyLintFinding) []string {
+		var ret []string
+		for _, finding := range findings {
+			ret = append(ret, finding.Check)
+		}
+		return ret
+	}
+
+	// A clean policy has no findings
+	require.Empty(t, lint(`
+path "secret/data/app/*" {
+	capabilities = ["read", "list"]
+}
+path "secret/data/app/config" {
+	capabilities = ["create", "read", "update", "list"]
+	allowed_parameters = {
+		"*" = []
+	}
+}
+`, []string{"secret/", "sys/"}))
+
+	findings := lint(`
+path "secret/*" {
+	capabilities = ["read", "update", "sudo"]
+}
+path "secret/data/app" {
+	capabilities = ["read"]
+}
+path "secret/private/*" {
+	capabilities = ["deny", "read"]
+}
+path "secret/private/app" {
+	capabilities = ["read"]
+}
+path "secret/*/keys" {
+	capabilities = ["deny"]
+}
+path "secret/data/fo+" {
+	capabilities = ["read"]
+}
+path "kv/config" {
+	capabilities = ["list"]
+	denied_parameters = {
+		"ttl" = []
+	}
+}
+path "kv*" {
+	capabilities = ["list"]
+}
+path "sys/*" {
+	capabilities = ["update"]
+}
+path "missing/+/config" {
+	capabilities = ["read"]
+}
+path "auth/missing/login" {
+	capabilities = ["update"]
+}
+path "kv/config" {
+	capabilities = ["read", "list"]
+}
+`, []string{"secret/", "kv/", "sys/", "auth/token/"})
+
+	require.ElementsMatch(t, []string{PolicyLintBroadGlob}, checks(findings["secret/*"]))
+	require.Contains(t, findings["secret/*"][0].Message, "sudo")
+
+	require.ElementsMatch(t, []string{PolicyLintShadowedRule}, checks(findings["secret/data/app"]))
+	require.Contains(t, findings["secret/data/app"][0].Message, `sudo, update granted by "secret/*"`)
+
+	require.ElementsMatch(t, []string{PolicyLintDenyWithOtherFields}, checks(findings["secret/private/*"]))
+	require.Contains(t, findings["secret/private/*"][0].Message, "read are ignored")
+	require.ElementsMatch(t, []string{PolicyLintDenyOverridden, PolicyLintShadowedRule}, checks(findings["secret/private/app"]))
+
+	require.ElementsMatch(t, []string{PolicyLintUnmatchableRule}, checks(findings["secret/*/keys"]))
+	require.Equal(t, PolicyLintSeverityError, findings["secret/*/keys"][0].Severity)
+	require.ElementsMatch(t, []string{PolicyLintUnmatchableRule, PolicyLintShadowedRule}, checks(findings["secret/data/fo+"]))
+
+	require.ElementsMatch(t, []string{PolicyLintIgnoredParameters, PolicyLintDuplicateRule}, checks(findings["kv/config"]))
+
+	// Parameters are checked on reads and recovers too
+	require.Empty(t, lint(`path "kv/config" {
+	capabilities = ["read"]
+	required_parameters = ["version"]
+}`, []string{"kv/"}))
+	require.ElementsMatch(t, []string{PolicyLintBroadGlob}, checks(findings["kv*"]))
+	require.ElementsMatch(t, []string{PolicyLintBroadGlob}, checks(findings["sys/*"]))
+
+	require.ElementsMatch(t, []string{PolicyLintMissingMount}, checks(findings["missing/+/config"]))
+	require.ElementsMatch(t, []string{PolicyLintMissingMount}, checks(findings["auth/missing/login"]))
+
+	findings = lint(`path "*" { capabilities = ["list"] }`, nil)
+	require.ElementsMatch(t, []string{PolicyLintBroadGlob}, checks(findings["*"]))
+
+	// Without mounts, mounts are not checked
+	require.Empty(t, lint(`path "missing/config" { capabilities = ["read"] }`, nil))
+
+	// Templated mounts are not checked
+	require.Empty(t, lint(`path "{{identity.entity.name}}/config" { capabilities = ["read"] }`, []string{"secret/"}))
+}
+
+func TestSystemBackend_PolicyLint(t *testing.T) {
+	_, b, rootToken := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	request := func(data map[string]interface{}) (*logical.Response, error) {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "policies/lint")
+		req.ClientToken = rootToken
+		req.Data = data
+		return b.HandleRequest(ctx, req)
+	}
+
+	rules := `
+path "secret/*" {
+	capabilities = ["read", "sudo"]
+}
+path "missing/config" {
+	capabilities = ["read"]
+}
+`
+	resp, err := request(map[string]interface{}{"policy": rules})
+	require.NoError(t, err)
+	findings := resp.Data["findings"].([]map[string]interface{})
+	require.Len(t, findings, 2)
+	require.Equal(t, PolicyLintBroadGlob, findings[0]["check"])
+	require.Equal(t, PolicyLintMissingMount, findings[1]["check"])
+
+	resp, err = request(map[string]interface{}{"policy": rules, "check_mounts": false})
+	require.NoError(t, err)
+	require.Len(t, resp.Data["findings"], 1)
+
+	// Existing policies are linted by name, including one named lint
+	req := logical.TestRequest(t, logical.UpdateOperation, "policy/lint")
+	req.ClientToken = rootToken
+	req.Data = map[string]interface{}{"policy": rules}
+	_, err = b.HandleRequest(ctx, req)
+	require.NoError(t, err)
+
+	resp, err = request(map[string]interface{}{"name": "lint"})
+	require.NoError(t, err)
+	require.Len(t, resp.Data["findings"], 2)
+
+	resp, err = request(map[string]interface{}{"name": "missing"})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = request(map[string]interface{}{"policy": `path "secret/" { capabilities = ["bogus"] }`})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+}
@@ -198,10 +198,11 @@ var (
 type PolicyStore struct {
 	entPolicyStore
 
-	core    *Core
-	aclView *BarrierView
-	rgpView *BarrierView
-	egpView *BarrierView
+	core           *Core
+	aclView        *BarrierView
+	aclHistoryView *BarrierView
+	rgpView        *BarrierView
+	egpView        *BarrierView
 
 	tokenPoliciesLRU *lru.TwoQueueCache
 	egpLRU           *lru.TwoQueueCache
@@ -235,12 +236,13 @@ type PolicyEntry struct {
 // using a given view. It used used to durable store and manage named policy.
 func NewPolicyStore(ctx context.Context, core *Core, baseView *BarrierView, system logical.SystemView, logger log.Logger) (*PolicyStore, error) {
 	ps := &PolicyStore{
-		aclView:    baseView.SubView(policyACLSubPath),
-		rgpView:    baseView.SubView(policyRGPSubPath),
-		egpView:    baseView.SubView(policyEGPSubPath),
-		modifyLock: new(sync.RWMutex),
-		logger:     logger,
-		core:       core,
+		aclView:        baseView.SubView(policyACLSubPath),
+		aclHistoryView: baseView.SubView(policyACLHistorySubPath),
+		rgpView:        baseView.SubView(policyRGPSubPath),
+		egpView:        baseView.SubView(policyEGPSubPath),
+		modifyLock:     new(sync.RWMutex),
+		logger:         logger,
+		core:           core,
 	}
 
 	ps.extraInit()
@@ -373,6 +375,12 @@ func (ps *PolicyStore) invalidate(ctx context.Context, name string, policyType P
 
 // SetPolicyWithRequest is used to create or update a given policy from a request
 func (ps *PolicyStore) SetPolicyWithRequest(ctx context.Context, p *Policy, req *logical.Request) error {
+	return ps.setPolicyWithRequest(ctx, p, req, 0)
+}
+
+// setPolicyWithRequest creates or updates a policy. restoredVersion is the
+// version of the policy being rolled back to, if any.
+func (ps *PolicyStore) setPolicyWithRequest(ctx context.Context, p *Policy, req *logical.Request, restoredVersion int) error {
 	defer metrics.MeasureSince([]string{"policy", "set_policy"}, time.Now())
 	if p == nil {
 		return fmt.Errorf("nil policy passed in for storage")
@@ -386,7 +394,7 @@ func (ps *PolicyStore) SetPolicyWithRequest(ctx context.Context, p *Policy, req
 		return fmt.Errorf("cannot update %q policy", p.Name)
 	}
 
-	return ps.setPolicyInternal(ctx, p, req)
+	return ps.setPolicyInternal(ctx, p, req, restoredVersion)
 }
 
 // SetPolicy is used to create or update the given policy
@@ -394,7 +402,7 @@ func (ps *PolicyStore) SetPolicy(ctx context.Context, p *Policy) error {
 	return ps.SetPolicyWithRequest(ctx, p, nil)
 }
 
-func (ps *PolicyStore) setPolicyInternal(ctx context.Context, p *Policy, req *logical.Request) error {
+func (ps *PolicyStore) setPolicyInternal(ctx context.Context, p *Policy, req *logical.Request, restoredVersion int) error {
 	ps.modifyLock.Lock()
 	defer ps.modifyLock.Unlock()
 
@@ -434,10 +442,32 @@ func (ps *PolicyStore) setPolicyInternal(ctx context.Context, p *Policy, req *lo
 			return fmt.Errorf("cannot reuse policy names between ACLs and RGPs")
 		}
 
+		previous, err := view.Get(ctx, entry.Key)
+		if err != nil {
+			return fmt.Errorf("failed looking up previous policy: %w", err)
+		}
+		var previousRaw string
+		if previous != nil {
+			var previousEntry PolicyEntry
+			if err := previous.DecodeJSON(&previousEntry); err != nil {
+				return fmt.Errorf("failed to decode previous policy: %w", err)
+			}
+			previousRaw = previousEntry.Raw
+		}
+
 		if err := view.Put(ctx, entry); err != nil {
 			return fmt.Errorf("failed to persist policy: %w", err)
 		}
 
+		// The policy is in effect at this point, so a failure to record its
+		// version doesn't fail the write
+		if err := ps.recordPolicyVersion(ctx, p.namespace, p.Name, previousRaw, &PolicyVersion{
+			Raw:             p.Raw,
+			RestoredVersion: restoredVersion,
+		}, req); err != nil {
+			ps.logger.Error("failed to record policy version", "name", p.Name, "error", err)
+		}
+
 		ps.policyTypeMap.Store(index, PolicyTypeACL)
 
 		if ps.tokenPoliciesLRU != nil {
@@ -864,10 +894,25 @@ func (ps *PolicyStore) switchedDeletePolicy(ctx context.Context, name string, po
 		}
 
 		if physicalDeletion {
-			err := view.Delete(ctx, name)
+			previous, err := view.Get(ctx, name)
+			if err != nil {
+				return fmt.Errorf("failed looking up policy: %w", err)
+			}
+
+			err = view.Delete(ctx, name)
 			if err != nil {
 				return fmt.Errorf("failed to delete policy: %w", err)
 			}
+
+			if previous != nil {
+				var previousEntry PolicyEntry
+				if err := previous.DecodeJSON(&previousEntry); err != nil {
+					return fmt.Errorf("failed to decode deleted policy: %w", err)
+				}
+				if err := ps.recordPolicyVersion(ctx, ns, name, previousEntry.Raw, &PolicyVersion{Deleted: true}, req); err != nil {
+					ps.logger.Error("failed to record policy deletion", "name", name, "error", err)
+				}
+			}
 		}
 
 		if ps.tokenPoliciesLRU != nil {
@@ -938,6 +983,23 @@ func (ps *PolicyStore) switchedDeletePolicy(ctx context.Context, name string, po
 // ACL is used to return an ACL which is built using the
 // named policies and pre-fetched policies if given.
 func (ps *PolicyStore) ACL(ctx context.Context, entity *identity.Entity, policyNames map[string][]string, additionalPolicies ...*Policy) (*ACL, error) {
+	allPolicies, err := ps.aclPolicies(ctx, entity, policyNames, additionalPolicies...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct the ACL
+	acl, err := NewACL(ctx, allPolicies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct ACL: %w", err)
+	}
+
+	return acl, nil
+}
+
+// aclPolicies fetches the named policies and renders the templated ones for
+// the entity, returning them along with the pre-fetched policies if given.
+func (ps *PolicyStore) aclPolicies(ctx context.Context, entity *identity.Entity, policyNames map[string][]string, additionalPolicies ...*Policy) ([]*Policy, error) {
 	var allPolicies []*Policy
 
 	// Fetch the named policies
@@ -990,13 +1052,7 @@ func (ps *PolicyStore) ACL(ctx context.Context, entity *identity.Entity, policyN
 		}
 	}
 
-	// Construct the ACL
-	acl, err := NewACL(ctx, allPolicies)
-	if err != nil {
-		return nil, fmt.Errorf("failed to construct ACL: %w", err)
-	}
-
-	return acl, nil
+	return allPolicies, nil
 }
 
 // loadACLPolicy is used to load default ACL policies. The default policies will
@@ -1035,7 +1091,7 @@ func (ps *PolicyStore) loadACLPolicyInternal(ctx context.Context, policyName, po
 
 	policy.Name = policyName
 	policy.Type = PolicyTypeACL
-	return ps.setPolicyInternal(ctx, policy, nil)
+	return ps.setPolicyInternal(ctx, policy, nil, 0)
 }
 
 func (ps *PolicyStore) sanitizeName(name string) string {
@@ -0,0 +1,206 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// policyACLHistorySubPath is the sub-path of the system view under which
+	// the version history of each ACL policy is stored.
+	policyACLHistorySubPath = "policy-history/"
+
+	// policyHistoryMaxVersions is the number of versions of an ACL policy
+	// kept in its history.
+	policyHistoryMaxVersions = 10
+)
+
+// PolicyHistory is the stored version history of an ACL policy.
+type PolicyHistory struct {
+	// LatestVersion is the number of the latest version ever recorded, which
+	// may no longer be in Versions.
+	LatestVersion int              `json:"latest_version"`
+	Versions      []*PolicyVersion `json:"versions"`
+}
+
+// PolicyVersion is a version of an ACL policy, recorded when the policy is
+// written or deleted.
+type PolicyVersion struct {
+	Version     int       `json:"version"`
+	Raw         string    `json:"raw"`
+	Deleted     bool      `json:"deleted"`
+	CreatedTime time.Time `json:"created_time"`
+
+	// EntityID and Accessor identify the author of the version. They are
+	// empty for versions written by Vault itself, and for the version seeded
+	// from a policy that existed before its history was kept.
+	EntityID string `json:"entity_id"`
+	Accessor string `json:"accessor"`
+
+	// RestoredVersion is set when the version is a rollback to a previous
+	// version.
+	RestoredVersion int `json:"restored_version,omitempty"`
+
+	// Diff is the unified diff from the previous version.
+	Diff string `json:"diff"`
+}
+
+// GetPolicyHistory returns the version history of the named ACL policy, or
+// nil if it has none.
+func (ps *PolicyStore) GetPolicyHistory(ctx context.Context, name string) (*PolicyHistory, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.modifyLock.RLock()
+	defer ps.modifyLock.RUnlock()
+
+	return ps.getPolicyHistory(ctx, ns, ps.sanitizeName(name))
+}
+
+func (ps *PolicyStore) getPolicyHistory(ctx context.Context, ns *namespace.Namespace, name string) (*PolicyHistory, error) {
+	entry, err := ps.getACLHistoryView(ns).Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy history: %w", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var history PolicyHistory
+	if err := entry.DecodeJSON(&history); err != nil {
+		return nil, fmt.Errorf("failed to decode policy history: %w", err)
+	}
+	return &history, nil
+}
+
+// recordPolicyVersion appends a version of the named ACL policy to its
+// history. previousRaw is the stored policy being replaced, if any, which
+// seeds the history of policies written before history was kept. The caller
+// must hold the modify lock.
+func (ps *PolicyStore) recordPolicyVersion(ctx context.Context, ns *namespace.Namespace, name, previousRaw string, version *PolicyVersion, req *logical.Request) error {
+	history, err := ps.getPolicyHistory(ctx, ns, name)
+	if err != nil {
+		return err
+	}
+	if history == nil {
+		history = &PolicyHistory{}
+	}
+
+	var previous *PolicyVersion
+	if len(history.Versions) > 0 {
+		previous = history.Versions[len(history.Versions)-1]
+	}
+	if previousRaw != "" && (previous == nil || previous.Deleted || previous.Raw != previousRaw) {
+		history.LatestVersion++
+		previous = &PolicyVersion{
+			Version: history.LatestVersion,
+			Raw:     previousRaw,
+		}
+		history.Versions = append(history.Versions, previous)
+	}
+
+	// Writing the current policy again is not a new version
+	if previous != nil && previous.Deleted == version.Deleted && previous.Raw == version.Raw {
+		return nil
+	}
+
+	history.LatestVersion++
+	version.Version = history.LatestVersion
+	version.CreatedTime = time.Now().UTC()
+	if req != nil {
+		version.EntityID = req.EntityID
+		version.Accessor = req.ClientTokenAccessor
+	}
+
+	fromFile, fromRaw := "/dev/null", ""
+	if previous != nil && !previous.Deleted {
+		fromFile, fromRaw = fmt.Sprintf("%s (version %d)", name, previous.Version), previous.Raw
+	}
+	toFile, toRaw := "/dev/null", ""
+	if !version.Deleted {
+		toFile, toRaw = fmt.Sprintf("%s (version %d)", name, version.Version), version.Raw
+	}
+	version.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(fromRaw),
+		B:        difflib.SplitLines(toRaw),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to diff policy versions: %w", err)
+	}
+
+	history.Versions = append(history.Versions, version)
+	if len(history.Versions) > policyHistoryMaxVersions {
+		history.Versions = history.Versions[len(history.Versions)-policyHistoryMaxVersions:]
+	}
+
+	entry, err := logical.StorageEntryJSON(name, history)
+	if err != nil {
+		return fmt.Errorf("failed to create policy history entry: %w", err)
+	}
+	if err := ps.getACLHistoryView(ns).Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to persist policy history: %w", err)
+	}
+	return nil
+}
+
+// RollbackPolicyWithRequest writes the given version from the history of the
+// named ACL policy as its current version, and returns the new version.
+func (ps *PolicyStore) RollbackPolicyWithRequest(ctx context.Context, name string, version int, req *logical.Request) (*PolicyVersion, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name = ps.sanitizeName(name)
+
+	history, err := ps.GetPolicyHistory(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	var target *PolicyVersion
+	if history != nil {
+		for _, v := range history.Versions {
+			if v.Version == version {
+				target = v
+			}
+		}
+	}
+	if target == nil {
+		return nil, logical.CodedError(http.StatusNotFound, fmt.Sprintf("version %d of policy %q is not in its history", version, name))
+	}
+	if target.Deleted {
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("version %d of policy %q is a deletion, which cannot be rolled back to", version, name))
+	}
+
+	p, err := ParseACLPolicy(ns, target.Raw, WithDenySlashInTemplatedPaths(ps.core.denySlashInTemplatedPolicyPaths))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %d of policy %q: %w", version, name, err)
+	}
+	p.Name = name
+	p.Type = PolicyTypeACL
+	if err := ps.setPolicyWithRequest(ctx, p, req, version); err != nil {
+		return nil, err
+	}
+
+	history, err = ps.GetPolicyHistory(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if history == nil || len(history.Versions) == 0 {
+		return nil, fmt.Errorf("policy history missing after rollback")
+	}
+	return history.Versions[len(history.Versions)-1], nil
+}
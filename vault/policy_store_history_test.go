@@ -0,0 +1,146 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyStore_History(t *testing.T) {
+	_, ps := mockPolicyWithCore(t, false)
+	ctx := namespace.RootContext(context.Background())
+
+	set := func(rules string, req *logical.Request) {
+		t.Helper()
+		policy, err := ParseACLPolicy(namespace.RootNamespace, rules)
+		require.NoError(t, err)
+		policy.Name = "dev"
+		require.NoError(t, ps.SetPolicyWithRequest(ctx, policy, req))
+	}
+	history := func() *PolicyHistory {
+		t.Helper()
+		history, err := ps.GetPolicyHistory(ctx, "dev")
+		require.NoError(t, err)
+		require.NotNil(t, history)
+		return history
+	}
+
+	// A policy written before its history was kept is seeded into the
+	// history on its next write
+	entry, err := logical.StorageEntryJSON("dev", &PolicyEntry{
+		Version: 2,
+		Raw:     `path "secret/old" { capabilities = ["read"] }`,
+		Type:    PolicyTypeACL,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ps.aclView.Put(ctx, entry))
+
+	req := &logical.Request{
+		EntityID:            "entity-1",
+		ClientTokenAccessor: "accessor-1",
+	}
+	set(`path "secret/new" { capabilities = ["read"] }`, req)
+	h := history()
+	require.Equal(t, 2, h.LatestVersion)
+	require.Len(t, h.Versions, 2)
+	require.True(t, h.Versions[0].CreatedTime.IsZero())
+	require.Empty(t, h.Versions[0].EntityID)
+	require.Equal(t, "entity-1", h.Versions[1].EntityID)
+	require.Equal(t, "accessor-1", h.Versions[1].Accessor)
+	require.False(t, h.Versions[1].CreatedTime.IsZero())
+	require.Contains(t, h.Versions[1].Diff, `-path "secret/old" { capabilities = ["read"] }`)
+	require.Contains(t, h.Versions[1].Diff, `+path "secret/new" { capabilities = ["read"] }`)
+
+	// Writing the same policy again is not a new version
+	set(`path "secret/new" { capabilities = ["read"] }`, req)
+	require.Equal(t, 2, history().LatestVersion)
+
+	// Deletions are recorded, and can be undone by rolling back
+	require.NoError(t, ps.DeletePolicyWithRequest(ctx, "dev", PolicyTypeACL, req))
+	h = history()
+	require.Equal(t, 3, h.LatestVersion)
+	require.True(t, h.Versions[2].Deleted)
+	require.Contains(t, h.Versions[2].Diff, "+++ /dev/null")
+
+	_, err = ps.RollbackPolicyWithRequest(ctx, "dev", 3, req)
+	require.Error(t, err)
+	_, err = ps.RollbackPolicyWithRequest(ctx, "dev", 9, req)
+	require.Error(t, err)
+
+	version, err := ps.RollbackPolicyWithRequest(ctx, "DEV", 1, req)
+	require.NoError(t, err)
+	require.Equal(t, 4, version.Version)
+	require.Equal(t, 1, version.RestoredVersion)
+	require.Contains(t, version.Diff, "--- /dev/null")
+	policy, err := ps.GetPolicy(ctx, "dev", PolicyTypeACL)
+	require.NoError(t, err)
+	require.Equal(t, `path "secret/old" { capabilities = ["read"] }`, policy.Raw)
+
+	// Only the latest versions are kept
+	for i := 0; i < policyHistoryMaxVersions+2; i++ {
+		set(fmt.Sprintf(`path "secret/%d" { capabilities = ["read"] }`, i), req)
+	}
+	h = history()
+	require.Equal(t, policyHistoryMaxVersions+6, h.LatestVersion)
+	require.Len(t, h.Versions, policyHistoryMaxVersions)
+	require.Equal(t, 7, h.Versions[0].Version)
+
+	// Immutable policies can't be rolled back
+	_, err = ps.RollbackPolicyWithRequest(ctx, responseWrappingPolicyName, 1, req)
+	require.Error(t, err)
+}
+
+func TestSystemBackend_PolicyHistory(t *testing.T) {
+	_, b, rootToken := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		t.Helper()
+		req := logical.TestRequest(t, op, path)
+		req.ClientToken = rootToken
+		req.Data = data
+		return b.HandleRequest(ctx, req)
+	}
+
+	for _, rules := range []string{
+		`path "secret/a" { capabilities = ["read"] }`,
+		`path "secret/b" { capabilities = ["read"] }`,
+	} {
+		resp, err := request(logical.UpdateOperation, "policies/acl/dev", map[string]interface{}{"policy": rules})
+		require.NoError(t, err)
+		require.Nil(t, resp)
+	}
+
+	resp, err := request(logical.ReadOperation, "policies/acl/dev/versions", nil)
+	require.NoError(t, err)
+	require.Equal(t, "dev", resp.Data["name"])
+	require.Equal(t, 2, resp.Data["latest_version"])
+	versions := resp.Data["versions"].([]map[string]interface{})
+	require.Len(t, versions, 2)
+	require.Equal(t, `path "secret/b" { capabilities = ["read"] }`, versions[1]["policy"])
+	require.Contains(t, versions[1]["diff"], `+path "secret/b" { capabilities = ["read"] }`)
+
+	resp, err = request(logical.UpdateOperation, "policies/acl/dev/rollback", map[string]interface{}{"version": 1})
+	require.NoError(t, err)
+	require.Equal(t, 3, resp.Data["version"])
+	require.Equal(t, 1, resp.Data["restored_version"])
+
+	resp, err = request(logical.ReadOperation, "policies/acl/dev", nil)
+	require.NoError(t, err)
+	require.Equal(t, `path "secret/a" { capabilities = ["read"] }`, resp.Data["policy"])
+
+	resp, err = request(logical.ReadOperation, "policies/acl/missing/versions", nil)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = request(logical.UpdateOperation, "policies/acl/dev/rollback", map[string]interface{}{"version": 0})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+}
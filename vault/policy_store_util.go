@@ -23,6 +23,10 @@ func (ps *PolicyStore) getACLView(*namespace.Namespace) *BarrierView {
 	return ps.aclView
 }
 
+func (ps *PolicyStore) getACLHistoryView(*namespace.Namespace) *BarrierView {
+	return ps.aclHistoryView
+}
+
 func (ps *PolicyStore) getRGPView(ns *namespace.Namespace) *BarrierView {
 	return ps.rgpView
 }
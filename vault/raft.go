@@ -371,6 +371,10 @@ func (c *Core) setupRaftActiveNode(ctx context.Context) error {
 		PersistedStates:     persistedState,
 		SavePersistedStates: c.saveAutopilotPersistedState,
 	})
+
+	if err := c.startRaftSnapshotAuto(c.activeContext); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -408,6 +412,8 @@ func (c *Core) stopRaftActiveNode() {
 		raftBackend.StopAutopilot()
 	}
 
+	c.stopRaftSnapshotAuto()
+	c.raftSnapshotLoad.unloadAll()
 	c.pendingRaftPeers = nil
 	c.stopPeriodicRaftTLSRotate()
 }
@@ -0,0 +1,497 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/helper/metricsutil"
+	"github.com/hashicorp/vault/physical/raft"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/snapshots"
+)
+
+const (
+	// raftSnapshotAutoConfigPrefix is the storage prefix of the automated
+	// snapshot configurations
+	raftSnapshotAutoConfigPrefix = "core/raft/snapshot-auto/config/"
+
+	// raftSnapshotAutoStatusPrefix is the storage prefix of the status of the
+	// automated snapshot configurations
+	raftSnapshotAutoStatusPrefix = "core/raft/snapshot-auto/status/"
+
+	raftSnapshotAutoStorageLocal = "local"
+	raftSnapshotAutoStorageS3    = "aws-s3"
+
+	raftSnapshotAutoDefaultFileNameTemplate = "vault-snapshot-{{.Name}}-{{.Timestamp}}.snap"
+
+	// raftSnapshotAutoRetryDelay is the longest time to wait before retrying a
+	// failed snapshot
+	raftSnapshotAutoRetryDelay = time.Minute
+)
+
+// raftSnapshotAutoConfig is a configuration of snapshots taken on a schedule
+// by the active node.
+type raftSnapshotAutoConfig struct {
+	Name             string        `json:"name"`
+	Interval         time.Duration `json:"interval"`
+	Retain           int           `json:"retain"`
+	FileNameTemplate string        `json:"file_name_template"`
+	StorageType      string        `json:"storage_type"`
+	PathPrefix       string        `json:"path_prefix"`
+
+	AWSS3Bucket         string `json:"aws_s3_bucket,omitempty"`
+	AWSS3Region         string `json:"aws_s3_region,omitempty"`
+	AWSS3Endpoint       string `json:"aws_s3_endpoint,omitempty"`
+	AWSS3DisableTLS     bool   `json:"aws_s3_disable_tls,omitempty"`
+	AWSS3ForcePathStyle bool   `json:"aws_s3_force_path_style,omitempty"`
+	AWSAccessKeyID      string `json:"aws_access_key_id,omitempty"`
+	AWSSecretAccessKey  string `json:"aws_secret_access_key,omitempty"`
+	AWSSessionToken     string `json:"aws_session_token,omitempty"`
+}
+
+// raftSnapshotAutoFileNameData is the data the file name template of a
+// configuration is rendered with.
+type raftSnapshotAutoFileNameData struct {
+	Name      string
+	Timestamp string
+	Unix      int64
+	Time      time.Time
+}
+
+// fileName renders the file name of a snapshot started at the given time.
+func (config *raftSnapshotAutoConfig) fileName(start time.Time) (string, error) {
+	tmpl, err := template.New("file_name").Option("missingkey=error").Parse(config.FileNameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid file name template: %w", err)
+	}
+
+	start = start.UTC()
+	var name bytes.Buffer
+	if err := tmpl.Execute(&name, &raftSnapshotAutoFileNameData{
+		Name:      config.Name,
+		Timestamp: start.Format("20060102T150405Z"),
+		Unix:      start.Unix(),
+		Time:      start,
+	}); err != nil {
+		return "", fmt.Errorf("invalid file name template: %w", err)
+	}
+
+	fileName := strings.TrimSpace(name.String())
+	switch {
+	case fileName == "", fileName == ".", fileName == "..":
+		return "", fmt.Errorf("invalid file name %q", fileName)
+	case strings.ContainsAny(fileName, `/\`):
+		return "", fmt.Errorf("file name %q must not contain path separators", fileName)
+	}
+	return fileName, nil
+}
+
+// validate checks that the configuration is complete and that its file name
+// template renders to a valid name.
+func (config *raftSnapshotAutoConfig) validate() error {
+	switch {
+	case config.Interval < time.Second:
+		return errors.New("interval must be at least 1s")
+	case config.Retain < 1:
+		return errors.New("retain must be at least 1")
+	}
+
+	switch config.StorageType {
+	case raftSnapshotAutoStorageLocal:
+		if config.PathPrefix == "" {
+			return errors.New("path_prefix is required for local storage")
+		}
+	case raftSnapshotAutoStorageS3:
+		if config.AWSS3Bucket == "" {
+			return errors.New("aws_s3_bucket is required for aws-s3 storage")
+		}
+	default:
+		return fmt.Errorf("storage_type must be %q or %q", raftSnapshotAutoStorageLocal, raftSnapshotAutoStorageS3)
+	}
+
+	// Snapshots stored under the same name replace each other, so the names
+	// of consecutive snapshots must differ
+	start := time.Unix(0, 0)
+	first, err := config.fileName(start)
+	if err != nil {
+		return err
+	}
+	next, err := config.fileName(start.Add(config.Interval))
+	if err != nil {
+		return err
+	}
+	if first == next {
+		return fmt.Errorf("file_name_template must give snapshots taken %s apart different names, such as with {{.Timestamp}}", config.Interval)
+	}
+	return nil
+}
+
+// target returns the target the snapshots of the configuration are stored in.
+func (config *raftSnapshotAutoConfig) target(logger hclog.Logger) (snapshots.Target, error) {
+	switch config.StorageType {
+	case raftSnapshotAutoStorageLocal:
+		return snapshots.NewLocalTarget(config.PathPrefix)
+	case raftSnapshotAutoStorageS3:
+		return snapshots.NewS3Target(&snapshots.S3TargetConfig{
+			Bucket:          config.AWSS3Bucket,
+			PathPrefix:      config.PathPrefix,
+			Region:          config.AWSS3Region,
+			Endpoint:        config.AWSS3Endpoint,
+			AccessKeyID:     config.AWSAccessKeyID,
+			SecretAccessKey: config.AWSSecretAccessKey,
+			SessionToken:    config.AWSSessionToken,
+			DisableTLS:      config.AWSS3DisableTLS,
+			ForcePathStyle:  config.AWSS3ForcePathStyle,
+			Logger:          logger,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", config.StorageType)
+	}
+}
+
+// sameTarget reports whether the configurations store snapshots in the same
+// location.
+func (config *raftSnapshotAutoConfig) sameTarget(other *raftSnapshotAutoConfig) bool {
+	return config.StorageType == other.StorageType &&
+		config.PathPrefix == other.PathPrefix &&
+		config.AWSS3Bucket == other.AWSS3Bucket &&
+		config.AWSS3Endpoint == other.AWSS3Endpoint
+}
+
+// raftSnapshotAutoSnapshot is a snapshot taken for a configuration that is
+// still retained.
+type raftSnapshotAutoSnapshot struct {
+	Name string    `json:"name"`
+	URL  string    `json:"url"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// raftSnapshotAutoStatus is the status of the snapshots of a configuration.
+type raftSnapshotAutoStatus struct {
+	ConsecutiveErrors int                         `json:"consecutive_errors"`
+	LastSnapshotStart time.Time                   `json:"last_snapshot_start"`
+	LastSnapshotEnd   time.Time                   `json:"last_snapshot_end"`
+	LastSnapshotError string                      `json:"last_snapshot_error"`
+	LastSnapshotURL   string                      `json:"last_snapshot_url"`
+	LastSnapshotSize  int64                       `json:"last_snapshot_size"`
+	LastSuccessTime   time.Time                   `json:"last_success_time"`
+	Snapshots         []*raftSnapshotAutoSnapshot `json:"snapshots"`
+}
+
+// nextSnapshot returns when the next snapshot of the configuration is due.
+// Failed snapshots are retried sooner than the interval.
+func (config *raftSnapshotAutoConfig) nextSnapshot(status *raftSnapshotAutoStatus) time.Time {
+	switch {
+	case status.LastSnapshotStart.IsZero():
+		return time.Now()
+	case status.ConsecutiveErrors > 0 && config.Interval > raftSnapshotAutoRetryDelay:
+		return status.LastSnapshotEnd.Add(raftSnapshotAutoRetryDelay)
+	default:
+		return status.LastSnapshotStart.Add(config.Interval)
+	}
+}
+
+func (c *Core) loadRaftSnapshotAutoConfig(ctx context.Context, name string) (*raftSnapshotAutoConfig, error) {
+	entry, err := c.barrier.Get(ctx, raftSnapshotAutoConfigPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config raftSnapshotAutoConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *Core) loadRaftSnapshotAutoStatus(ctx context.Context, name string) (*raftSnapshotAutoStatus, error) {
+	entry, err := c.barrier.Get(ctx, raftSnapshotAutoStatusPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var status raftSnapshotAutoStatus
+	if err := entry.DecodeJSON(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// raftSnapshotAutoManager takes the snapshots of the automated snapshot
+// configurations on the active node, each on its own schedule.
+type raftSnapshotAutoManager struct {
+	core   *Core
+	ctx    context.Context
+	logger hclog.Logger
+
+	l       sync.Mutex
+	running map[string]*raftSnapshotAutoRun
+	stopped bool
+}
+
+type raftSnapshotAutoRun struct {
+	cancel context.CancelFunc
+	doneCh chan struct{}
+}
+
+// startRaftSnapshotAuto starts taking the snapshots of every automated
+// snapshot configuration. It is a no-op unless raft is the storage backend.
+func (c *Core) startRaftSnapshotAuto(ctx context.Context) error {
+	if _, ok := c.underlyingPhysical.(*raft.RaftBackend); !ok {
+		return nil
+	}
+
+	logger := c.baseLogger.Named("snapshot-auto")
+	c.AddLogger(logger)
+	m := &raftSnapshotAutoManager{
+		core:    c,
+		ctx:     ctx,
+		logger:  logger,
+		running: make(map[string]*raftSnapshotAutoRun),
+	}
+
+	names, err := c.barrier.List(ctx, raftSnapshotAutoConfigPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list automated snapshot configurations: %w", err)
+	}
+	for _, name := range names {
+		config, err := c.loadRaftSnapshotAutoConfig(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to load automated snapshot configuration %q: %w", name, err)
+		}
+		if config != nil {
+			m.start(config)
+		}
+	}
+
+	c.raftSnapshotAuto = m
+	return nil
+}
+
+// stopRaftSnapshotAuto stops taking snapshots and waits for the ones in
+// progress to be aborted.
+func (c *Core) stopRaftSnapshotAuto() {
+	if c.raftSnapshotAuto == nil {
+		return
+	}
+	c.raftSnapshotAuto.stopAll()
+	c.raftSnapshotAuto = nil
+}
+
+// start starts taking the snapshots of the configuration, replacing the
+// schedule of a previous version of it.
+func (m *raftSnapshotAutoManager) start(config *raftSnapshotAutoConfig) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.stopped {
+		return
+	}
+	m.stopLocked(config.Name)
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	run := &raftSnapshotAutoRun{
+		cancel: cancel,
+		doneCh: make(chan struct{}),
+	}
+	m.running[config.Name] = run
+	go m.run(ctx, config, run.doneCh)
+}
+
+// stop stops taking the snapshots of the configuration.
+func (m *raftSnapshotAutoManager) stop(name string) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.stopLocked(name)
+}
+
+func (m *raftSnapshotAutoManager) stopLocked(name string) {
+	run, ok := m.running[name]
+	if !ok {
+		return
+	}
+	run.cancel()
+	<-run.doneCh
+	delete(m.running, name)
+}
+
+func (m *raftSnapshotAutoManager) stopAll() {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.stopped = true
+	for name := range m.running {
+		m.stopLocked(name)
+	}
+}
+
+func (m *raftSnapshotAutoManager) run(ctx context.Context, config *raftSnapshotAutoConfig, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	for {
+		next := time.Now().Add(raftSnapshotAutoRetryDelay)
+		status, err := m.core.loadRaftSnapshotAutoStatus(ctx, config.Name)
+		switch {
+		case err != nil:
+			m.logger.Error("failed to load automated snapshot status", "config", config.Name, "error", err)
+		case status == nil:
+			status = &raftSnapshotAutoStatus{}
+			fallthrough
+		default:
+			next = config.nextSnapshot(status)
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if status != nil {
+			m.snapshot(ctx, config, status)
+		}
+	}
+}
+
+// snapshot takes a snapshot for the configuration, removes the snapshots
+// beyond its retention, and persists the updated status.
+func (m *raftSnapshotAutoManager) snapshot(ctx context.Context, config *raftSnapshotAutoConfig, status *raftSnapshotAutoStatus) {
+	labels := []metricsutil.Label{{Name: "snapshot_config", Value: config.Name}}
+
+	start := time.Now()
+	status.LastSnapshotStart = start
+	snap, target, err := m.save(ctx, config, start)
+	status.LastSnapshotEnd = time.Now()
+	if ctx.Err() != nil {
+		// The configuration was updated or deleted, or the node stepped down
+		return
+	}
+
+	if err != nil {
+		m.logger.Error("failed to take automated snapshot", "config", config.Name, "error", err)
+		m.core.metricSink.IncrCounterWithLabels([]string{"autosnapshots", "save", "errors"}, 1, labels)
+		status.ConsecutiveErrors++
+		status.LastSnapshotError = err.Error()
+	} else {
+		m.logger.Info("took automated snapshot", "config", config.Name, "url", snap.URL, "size", snap.Size)
+		m.core.metricSink.AddDurationWithLabels([]string{"autosnapshots", "save", "duration"}, status.LastSnapshotEnd.Sub(start), labels)
+		m.core.metricSink.SetGaugeWithLabels([]string{"autosnapshots", "snapshot", "size"}, float32(snap.Size), labels)
+		m.core.metricSink.SetGaugeWithLabels([]string{"autosnapshots", "last", "success", "time"}, float32(status.LastSnapshotEnd.Unix()), labels)
+		status.ConsecutiveErrors = 0
+		status.LastSnapshotError = ""
+		status.LastSnapshotURL = snap.URL
+		status.LastSnapshotSize = snap.Size
+		status.LastSuccessTime = status.LastSnapshotEnd
+		// A snapshot stored under the name of an earlier one replaced it,
+		// so the earlier one must not be deleted by the rotation
+		status.Snapshots = slices.DeleteFunc(status.Snapshots, func(earlier *raftSnapshotAutoSnapshot) bool {
+			return earlier.Name == snap.Name
+		})
+		status.Snapshots = append(status.Snapshots, snap)
+
+		rotateStart := time.Now()
+		m.rotate(ctx, config, target, status)
+		m.core.metricSink.AddDurationWithLabels([]string{"autosnapshots", "rotate", "duration"}, time.Since(rotateStart), labels)
+	}
+	m.core.metricSink.SetGaugeWithLabels([]string{"autosnapshots", "consecutive", "errors"}, float32(status.ConsecutiveErrors), labels)
+
+	entry, err := logical.StorageEntryJSON(raftSnapshotAutoStatusPrefix+config.Name, status)
+	if err == nil {
+		err = m.core.barrier.Put(ctx, entry)
+	}
+	if err != nil {
+		m.logger.Error("failed to persist automated snapshot status", "config", config.Name, "error", err)
+	}
+}
+
+// save takes a snapshot into a temporary file, and stores it in the target
+// of the configuration.
+func (m *raftSnapshotAutoManager) save(ctx context.Context, config *raftSnapshotAutoConfig, start time.Time) (*raftSnapshotAutoSnapshot, snapshots.Target, error) {
+	raftStorage, ok := m.core.underlyingPhysical.(*raft.RaftBackend)
+	if !ok {
+		return nil, nil, errors.New("raft storage is not in use")
+	}
+
+	name, err := config.fileName(start)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := config.target(m.logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set up %s storage: %w", config.StorageType, err)
+	}
+
+	f, err := os.CreateTemp("", "vault-snapshot-auto-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	sealer := NewSealAccessSealer(m.core.seal.GetAccess(), m.logger, "snapshot_auto")
+	if err := raftStorage.Snapshot(f, sealer); err != nil {
+		return nil, nil, err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+
+	url, err := target.Put(ctx, name, f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to store snapshot in %s storage: %w", target.Type(), err)
+	}
+	return &raftSnapshotAutoSnapshot{
+		Name: name,
+		URL:  url,
+		Time: start,
+		Size: size,
+	}, target, nil
+}
+
+// rotate deletes the oldest snapshots of the configuration beyond the number
+// it retains. Only snapshots taken for the configuration are ever deleted,
+// and the ones that fail to be deleted are retried on the next rotation.
+func (m *raftSnapshotAutoManager) rotate(ctx context.Context, config *raftSnapshotAutoConfig, target snapshots.Target, status *raftSnapshotAutoStatus) {
+	excess := len(status.Snapshots) - config.Retain
+	if excess <= 0 {
+		return
+	}
+
+	var kept []*raftSnapshotAutoSnapshot
+	for _, snap := range status.Snapshots[:excess] {
+		if err := target.Delete(ctx, snap.Name); err != nil {
+			m.logger.Error("failed to delete automated snapshot", "config", config.Name, "url", snap.URL, "error", err)
+			kept = append(kept, snap)
+			continue
+		}
+		m.logger.Debug("deleted automated snapshot", "config", config.Name, "url", snap.URL)
+	}
+	status.Snapshots = append(kept, status.Snapshots[excess:]...)
+}
@@ -0,0 +1,69 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaftSnapshotAutoConfig_FileName(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 30, 5, 0, time.FixedZone("", 3600))
+
+	for template, expected := range map[string]string{
+		raftSnapshotAutoDefaultFileNameTemplate:        "vault-snapshot-daily-20240301T113005Z.snap",
+		"{{.Name}}-{{.Unix}}.snap":                     "daily-1709292605.snap",
+		`{{.Name}}-{{.Time.Format "2006-01-02"}}.snap`: "daily-2024-03-01.snap",
+	} {
+		config := &raftSnapshotAutoConfig{Name: "daily", FileNameTemplate: template}
+		fileName, err := config.fileName(start)
+		require.NoError(t, err)
+		require.Equal(t, expected, fileName)
+	}
+
+	for _, template := range []string{"", "{{.Name", "{{.Bogus}}", "../{{.Name}}", `a\b`, ".."} {
+		config := &raftSnapshotAutoConfig{Name: "daily", FileNameTemplate: template}
+		_, err := config.fileName(start)
+		require.Error(t, err, template)
+	}
+}
+
+func TestRaftSnapshotAutoConfig_Validate(t *testing.T) {
+	config := &raftSnapshotAutoConfig{
+		Name:             "daily",
+		Interval:         24 * time.Hour,
+		Retain:           7,
+		FileNameTemplate: `{{.Name}}-{{.Time.Format "2006-01-02"}}.snap`,
+		StorageType:      raftSnapshotAutoStorageLocal,
+		PathPrefix:       "/tmp",
+	}
+	require.NoError(t, config.validate())
+
+	// Snapshots taken more often would replace each other
+	config.Interval = time.Hour
+	require.Error(t, config.validate())
+	config.FileNameTemplate = "{{.Name}}.snap"
+	config.Interval = 24 * time.Hour
+	require.Error(t, config.validate())
+}
+
+func TestRaftSnapshotAutoConfig_NextSnapshot(t *testing.T) {
+	config := &raftSnapshotAutoConfig{Interval: time.Hour}
+	start := time.Now().Add(-10 * time.Minute)
+
+	require.WithinDuration(t, time.Now(), config.nextSnapshot(&raftSnapshotAutoStatus{}), time.Second)
+	require.Equal(t, start.Add(time.Hour), config.nextSnapshot(&raftSnapshotAutoStatus{
+		LastSnapshotStart: start,
+		LastSnapshotEnd:   start.Add(time.Second),
+	}))
+
+	// Failed snapshots are retried sooner
+	require.Equal(t, start.Add(time.Second+raftSnapshotAutoRetryDelay), config.nextSnapshot(&raftSnapshotAutoStatus{
+		LastSnapshotStart: start,
+		LastSnapshotEnd:   start.Add(time.Second),
+		ConsecutiveErrors: 1,
+	}))
+}
@@ -0,0 +1,281 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/physical/raft"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// ErrSnapshotNotFound is returned when a request refers to a snapshot that
+// isn't loaded.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// loadedSnapshot is a raft snapshot loaded into a read-only FSM, separate from
+// the live storage, so its data can be read and selectively restored.
+type loadedSnapshot struct {
+	ID       string
+	LoadedAt time.Time
+	Index    uint64
+	Term     uint64
+
+	dir string
+	fsm *raft.FSM
+
+	// inUse counts the requests reading from the snapshot, which keep it from
+	// being unloaded
+	inUse     int
+	unloading bool
+}
+
+// raftSnapshotLoadManager keeps the snapshots loaded on the active node. The
+// loaded snapshots aren't replicated to the other nodes, and are unloaded
+// when the node steps down or seals.
+type raftSnapshotLoadManager struct {
+	core   *Core
+	logger hclog.Logger
+
+	l         sync.Mutex
+	snapshots map[string]*loadedSnapshot
+}
+
+var _ logical.SnapshotStorageProvider = (*raftSnapshotLoadManager)(nil)
+
+func newRaftSnapshotLoadManager(c *Core) *raftSnapshotLoadManager {
+	logger := c.baseLogger.Named("snapshot-load")
+	c.AddLogger(logger)
+	return &raftSnapshotLoadManager{
+		core:      c,
+		logger:    logger,
+		snapshots: make(map[string]*loadedSnapshot),
+	}
+}
+
+// load verifies the snapshot read from r against the seal, and loads it into a
+// new read-only FSM.
+func (m *raftSnapshotLoadManager) load(ctx context.Context, r io.ReadCloser) (*loadedSnapshot, error) {
+	raftStorage, ok := m.core.underlyingPhysical.(*raft.RaftBackend)
+	if !ok {
+		return nil, errors.New("raft storage is not in use")
+	}
+
+	sealer := NewSealAccessSealer(m.core.seal.GetAccess(), m.logger, "snapshot_load")
+	snapFile, cleanup, metadata, err := raftStorage.WriteSnapshotToTemp(r, sealer)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "vault-snapshot-load-")
+	if err != nil {
+		return nil, err
+	}
+	fsm, err := raft.NewReadOnlyFSM(dir, id, m.logger)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := raft.LoadReadOnlySnapshot(ctx, fsm, snapFile, nil, m.logger); err != nil {
+		fsm.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	snap := &loadedSnapshot{
+		ID:       id,
+		LoadedAt: time.Now(),
+		Index:    metadata.Index,
+		Term:     metadata.Term,
+		dir:      dir,
+		fsm:      fsm,
+	}
+
+	m.l.Lock()
+	defer m.l.Unlock()
+	m.snapshots[id] = snap
+	m.logger.Info("loaded snapshot", "snapshot_id", id, "index", snap.Index, "term", snap.Term)
+	return snap, nil
+}
+
+// get returns the loaded snapshot, or nil if it isn't loaded.
+func (m *raftSnapshotLoadManager) get(id string) *loadedSnapshot {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	snap, ok := m.snapshots[id]
+	if !ok || snap.unloading {
+		return nil
+	}
+	return snap
+}
+
+// list returns the IDs of the loaded snapshots.
+func (m *raftSnapshotLoadManager) list() []string {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	ids := make([]string, 0, len(m.snapshots))
+	for id, snap := range m.snapshots {
+		if !snap.unloading {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// acquire keeps the snapshot from being unloaded until the returned function
+// is called.
+func (m *raftSnapshotLoadManager) acquire(id string) (func(), error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	snap, ok := m.snapshots[id]
+	if !ok || snap.unloading {
+		return nil, ErrSnapshotNotFound
+	}
+	snap.inUse++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.l.Lock()
+			defer m.l.Unlock()
+
+			snap.inUse--
+			if snap.unloading && snap.inUse == 0 {
+				m.closeLocked(snap)
+			}
+		})
+	}, nil
+}
+
+// unload unloads the snapshot. A snapshot that requests are reading from is
+// only unloaded if forced, once the requests are done.
+func (m *raftSnapshotLoadManager) unload(id string, force bool) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	snap, ok := m.snapshots[id]
+	if !ok || snap.unloading {
+		return ErrSnapshotNotFound
+	}
+	if snap.inUse > 0 && !force {
+		return fmt.Errorf("snapshot is in use by %d requests", snap.inUse)
+	}
+
+	snap.unloading = true
+	if snap.inUse == 0 {
+		m.closeLocked(snap)
+	}
+	return nil
+}
+
+// unloadAll unloads every snapshot once the requests reading from it are done.
+func (m *raftSnapshotLoadManager) unloadAll() {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	for _, snap := range m.snapshots {
+		if snap.unloading {
+			continue
+		}
+		snap.unloading = true
+		if snap.inUse == 0 {
+			m.closeLocked(snap)
+		}
+	}
+}
+
+func (m *raftSnapshotLoadManager) closeLocked(snap *loadedSnapshot) {
+	delete(m.snapshots, snap.ID)
+	if err := snap.fsm.Close(); err != nil {
+		m.logger.Error("failed to close snapshot", "snapshot_id", snap.ID, "error", err)
+	}
+	if err := os.RemoveAll(snap.dir); err != nil {
+		m.logger.Error("failed to remove snapshot", "snapshot_id", snap.ID, "error", err)
+	}
+	m.logger.Info("unloaded snapshot", "snapshot_id", snap.ID)
+}
+
+// SnapshotStorage returns the storage of the loaded snapshot, as the barrier
+// would read it.
+func (m *raftSnapshotLoadManager) SnapshotStorage(_ context.Context, id string) (logical.Storage, error) {
+	snap := m.get(id)
+	if snap == nil {
+		return nil, ErrSnapshotNotFound
+	}
+	return &snapshotBarrierStorage{
+		barrier: m.core.barrier,
+		fsm:     snap.fsm,
+	}, nil
+}
+
+// snapshotBarrierStorage reads the entries of a loaded snapshot, decrypting
+// them with the keyring of the barrier. The snapshot was verified to be taken
+// with the same root key, so the keyring has the key of every term it uses.
+type snapshotBarrierStorage struct {
+	barrier SecurityBarrier
+	fsm     *raft.FSM
+}
+
+var _ logical.Storage = (*snapshotBarrierStorage)(nil)
+
+func (s *snapshotBarrierStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	return s.fsm.List(ctx, prefix)
+}
+
+func (s *snapshotBarrierStorage) Get(ctx context.Context, key string) (*logical.StorageEntry, error) {
+	pe, err := s.fsm.Get(ctx, key)
+	if err != nil || pe == nil {
+		return nil, err
+	}
+
+	value, err := s.barrier.Decrypt(ctx, key, pe.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %q: %w", key, err)
+	}
+	return &logical.StorageEntry{
+		Key:      key,
+		Value:    value,
+		SealWrap: pe.SealWrap,
+	}, nil
+}
+
+func (s *snapshotBarrierStorage) Put(context.Context, *logical.StorageEntry) error {
+	return logical.ErrReadOnly
+}
+
+func (s *snapshotBarrierStorage) Delete(context.Context, string) error {
+	return logical.ErrReadOnly
+}
+
+// snapshotReadableMount reports whether requests to the mount can read from
+// loaded snapshots. The mounts of core subsystems keep their state in memory,
+// so only plugin mounts read their storage on requests.
+func snapshotReadableMount(entry *MountEntry) bool {
+	if entry == nil || (entry.Table != mountTableType && entry.Table != credentialTableType) {
+		return false
+	}
+	switch entry.Type {
+	case mountTypeSystem, mountTypeNSSystem, mountTypeIdentity, mountTypeNSIdentity, mountTypeToken, mountTypeNSToken:
+		return false
+	}
+	return true
+}
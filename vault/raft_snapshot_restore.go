@@ -0,0 +1,581 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	snapshotDiffAdded     = "added"
+	snapshotDiffChanged   = "changed"
+	snapshotDiffUnchanged = "unchanged"
+)
+
+// snapshotRestoreSelection selects what to restore from a loaded snapshot.
+// Mounts are given by their path, such as "secret/" or "auth/userpass/", or
+// by a path within them, such as "secret/app/", to only restore the storage
+// keys under that prefix.
+type snapshotRestoreSelection struct {
+	Mounts    []string
+	Policies  []string
+	EntityIDs []string
+	GroupIDs  []string
+}
+
+func (s *snapshotRestoreSelection) empty() bool {
+	return len(s.Mounts) == 0 && len(s.Policies) == 0 && len(s.EntityIDs) == 0 && len(s.GroupIDs) == 0
+}
+
+// snapshotMountDiff describes the changes restoring a mount, or the keys
+// under a prefix of its storage, from a snapshot makes to its storage, by the
+// storage keys that are added, changed and removed. The keys are relative to
+// the prefix.
+type snapshotMountDiff struct {
+	Path    string
+	Type    string
+	Added   []string
+	Changed []string
+	Removed []string
+
+	mountPath     string
+	prefix        string
+	snapshotEntry *MountEntry
+	liveEntry     *MountEntry
+}
+
+func (d *snapshotMountDiff) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"type":    d.Type,
+		"added":   d.Added,
+		"changed": d.Changed,
+		"removed": d.Removed,
+	}
+}
+
+// snapshotItemDiff describes how restoring an object from a snapshot changes
+// it, as one of snapshotDiffAdded, snapshotDiffChanged or
+// snapshotDiffUnchanged.
+type snapshotItemDiff struct {
+	Name   string
+	Status string
+}
+
+// snapshotDiff describes the changes restoring a selection from a snapshot
+// makes to the live cluster.
+type snapshotDiff struct {
+	Mounts   []*snapshotMountDiff
+	Policies []*snapshotItemDiff
+	Entities []*snapshotItemDiff
+	Groups   []*snapshotItemDiff
+
+	policies map[string]*Policy
+	entities map[string]*identity.Entity
+	groups   map[string]*identity.Group
+}
+
+func (d *snapshotDiff) toMap() map[string]interface{} {
+	mounts := make(map[string]interface{}, len(d.Mounts))
+	for _, m := range d.Mounts {
+		mounts[m.Path] = m.toMap()
+	}
+	items := func(diffs []*snapshotItemDiff) map[string]interface{} {
+		ret := make(map[string]interface{}, len(diffs))
+		for _, item := range diffs {
+			ret[item.Name] = item.Status
+		}
+		return ret
+	}
+	return map[string]interface{}{
+		"mounts":   mounts,
+		"policies": items(d.Policies),
+		"entities": items(d.Entities),
+		"groups":   items(d.Groups),
+	}
+}
+
+// snapshotMountEntries returns the entries of the mount and auth tables of a
+// snapshot.
+func (c *Core) snapshotMountEntries(ctx context.Context, storage logical.Storage) ([]*MountEntry, error) {
+	var entries []*MountEntry
+	for _, key := range []string{coreMountConfigPath, coreLocalMountConfigPath, coreAuthConfigPath, coreLocalAuthConfigPath} {
+		raw, err := storage.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q from snapshot: %w", key, err)
+		}
+		if raw == nil {
+			continue
+		}
+		table, err := c.decodeMountTable(ctx, raw.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %q from snapshot: %w", key, err)
+		}
+		entries = append(entries, table.Entries...)
+	}
+	return entries, nil
+}
+
+// liveMountEntries returns a copy of the entries of the mount and auth tables.
+func (c *Core) liveMountEntries() []*MountEntry {
+	var entries []*MountEntry
+
+	c.mountsLock.RLock()
+	if c.mounts != nil {
+		entries = append(entries, c.mounts.Entries...)
+	}
+	c.mountsLock.RUnlock()
+
+	c.authLock.RLock()
+	if c.auth != nil {
+		entries = append(entries, c.auth.Entries...)
+	}
+	c.authLock.RUnlock()
+
+	return entries
+}
+
+func findMountEntry(entries []*MountEntry, ns *namespace.Namespace, path string) *MountEntry {
+	for _, entry := range entries {
+		if entry.NamespaceID == ns.ID && entry.APIPathNoNamespace() == path {
+			return entry
+		}
+	}
+	return nil
+}
+
+// splitSnapshotMountPath splits a path into the path of the deepest of the
+// mounts it is within and the storage prefix within that mount.
+func splitSnapshotMountPath(entries []*MountEntry, ns *namespace.Namespace, path string) (string, string, bool) {
+	mountPath := ""
+	for _, entry := range entries {
+		entryPath := entry.APIPathNoNamespace()
+		if entry.NamespaceID == ns.ID && strings.HasPrefix(path, entryPath) && len(entryPath) > len(mountPath) {
+			mountPath = entryPath
+		}
+	}
+	if mountPath == "" {
+		return "", "", false
+	}
+	return mountPath, strings.TrimPrefix(path, mountPath), true
+}
+
+// diffSnapshot returns the changes restoring the selection from the loaded
+// snapshot makes to the namespace in the context. Everything selected must
+// exist in the snapshot, and mounts must also exist in the live cluster.
+func (c *Core) diffSnapshot(ctx context.Context, id string, sel *snapshotRestoreSelection) (*snapshotDiff, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := c.raftSnapshotLoad.SnapshotStorage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	snapCtx := logical.CreateContextWithSnapshotID(ctx, id)
+
+	diff := &snapshotDiff{
+		policies: make(map[string]*Policy),
+		entities: make(map[string]*identity.Entity),
+		groups:   make(map[string]*identity.Group),
+	}
+
+	if len(sel.Mounts) > 0 {
+		snapshotEntries, err := c.snapshotMountEntries(ctx, storage)
+		if err != nil {
+			return nil, err
+		}
+		liveEntries := c.liveMountEntries()
+
+		for _, path := range sel.Mounts {
+			path = sanitizePath(path)
+			mountPath, prefix, ok := splitSnapshotMountPath(snapshotEntries, ns, path)
+			if !ok {
+				return nil, fmt.Errorf("mount %q not found in snapshot", path)
+			}
+			snapshotEntry := findMountEntry(snapshotEntries, ns, mountPath)
+			if !snapshotReadableMount(snapshotEntry) {
+				return nil, fmt.Errorf("mount %q can't be restored", mountPath)
+			}
+			liveEntry := findMountEntry(liveEntries, ns, mountPath)
+			if liveEntry == nil {
+				return nil, fmt.Errorf("mount %q not found; it needs to be mounted before being restored", mountPath)
+			}
+			if liveEntry.Type != snapshotEntry.Type {
+				return nil, fmt.Errorf("mount %q is of type %q in the snapshot but %q in the cluster", mountPath, snapshotEntry.Type, liveEntry.Type)
+			}
+
+			mountDiff, err := diffSnapshotStorage(ctx,
+				NewBarrierView(storage, snapshotEntry.ViewPath()+prefix),
+				NewBarrierView(c.barrier, liveEntry.ViewPath()+prefix))
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare mount %q: %w", path, err)
+			}
+			mountDiff.Path = path
+			mountDiff.Type = liveEntry.Type
+			mountDiff.mountPath = mountPath
+			mountDiff.prefix = prefix
+			mountDiff.snapshotEntry = snapshotEntry
+			mountDiff.liveEntry = liveEntry
+			diff.Mounts = append(diff.Mounts, mountDiff)
+		}
+	}
+
+	for _, name := range sel.Policies {
+		name = c.policyStore.sanitizeName(name)
+		if name == "root" {
+			return nil, fmt.Errorf("policy %q can't be restored", name)
+		}
+		// The policy store reads through the system view, which reads from the
+		// snapshot given in the context
+		policy, err := c.policyStore.parseStoredACLPolicy(snapCtx, ns, name)
+		if err != nil {
+			return nil, err
+		}
+		if policy == nil {
+			return nil, fmt.Errorf("policy %q not found in snapshot", name)
+		}
+		live, err := c.policyStore.GetPolicy(ctx, name, PolicyTypeACL)
+		if err != nil {
+			return nil, err
+		}
+
+		status := snapshotDiffAdded
+		if live != nil {
+			status = snapshotDiffChanged
+			if live.Raw == policy.Raw {
+				status = snapshotDiffUnchanged
+			}
+		}
+		diff.Policies = append(diff.Policies, &snapshotItemDiff{Name: name, Status: status})
+		diff.policies[name] = policy
+	}
+
+	for _, entityID := range sel.EntityIDs {
+		entity, err := c.identityStore.snapshotEntity(snapCtx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		if entity == nil || entity.NamespaceID != ns.ID {
+			return nil, fmt.Errorf("entity %q not found in snapshot", entityID)
+		}
+		live, err := c.identityStore.MemDBEntityByID(entityID, false)
+		if err != nil {
+			return nil, err
+		}
+
+		status := snapshotDiffAdded
+		if live != nil {
+			status = snapshotDiffChanged
+			if proto.Equal(live, entity) {
+				status = snapshotDiffUnchanged
+			}
+		}
+		diff.Entities = append(diff.Entities, &snapshotItemDiff{Name: entityID, Status: status})
+		diff.entities[entityID] = entity
+	}
+
+	for _, groupID := range sel.GroupIDs {
+		group, err := c.identityStore.snapshotGroup(snapCtx, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if group == nil || group.NamespaceID != ns.ID {
+			return nil, fmt.Errorf("group %q not found in snapshot", groupID)
+		}
+		live, err := c.identityStore.MemDBGroupByID(groupID, false)
+		if err != nil {
+			return nil, err
+		}
+
+		status := snapshotDiffAdded
+		if live != nil {
+			status = snapshotDiffChanged
+			if proto.Equal(live, group) {
+				status = snapshotDiffUnchanged
+			}
+		}
+		diff.Groups = append(diff.Groups, &snapshotItemDiff{Name: groupID, Status: status})
+		diff.groups[groupID] = group
+	}
+
+	return diff, nil
+}
+
+// diffSnapshotStorage compares the keys and values of the storage of a mount in
+// a snapshot with the storage of the live mount.
+func diffSnapshotStorage(ctx context.Context, snapshot, live *BarrierView) (*snapshotMountDiff, error) {
+	snapshotKeys, err := logical.CollectKeys(ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	liveKeys, err := logical.CollectKeys(ctx, live)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &snapshotMountDiff{
+		Added:   []string{},
+		Changed: []string{},
+		Removed: []string{},
+	}
+	inSnapshot := make(map[string]struct{}, len(snapshotKeys))
+	for _, key := range snapshotKeys {
+		inSnapshot[key] = struct{}{}
+
+		liveEntry, err := live.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if liveEntry == nil {
+			diff.Added = append(diff.Added, key)
+			continue
+		}
+		snapshotEntry, err := snapshot.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if snapshotEntry != nil && !bytes.Equal(snapshotEntry.Value, liveEntry.Value) {
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for _, key := range liveKeys {
+		if _, ok := inSnapshot[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff, nil
+}
+
+// restoreSnapshot restores the selection from the loaded snapshot into the
+// namespace in the context, and returns the changes it made. The whole
+// selection is checked against the snapshot before anything is restored, but
+// the writes aren't transactional: if one fails, what was restored before it
+// is kept and not rolled back. Restoring the same selection again completes
+// the restore.
+func (c *Core) restoreSnapshot(ctx context.Context, id string, sel *snapshotRestoreSelection) (*snapshotDiff, error) {
+	unlock, err := c.raftSnapshotLoad.acquire(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := c.diffSnapshot(ctx, id, sel)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := c.raftSnapshotLoad.SnapshotStorage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mountDiff := range diff.Mounts {
+		snapshotView := NewBarrierView(storage, mountDiff.snapshotEntry.ViewPath()+mountDiff.prefix)
+		liveView := NewBarrierView(c.barrier, mountDiff.liveEntry.ViewPath()+mountDiff.prefix)
+
+		for _, key := range append(mountDiff.Added, mountDiff.Changed...) {
+			entry, err := snapshotView.Get(ctx, key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore mount %q: %w", mountDiff.Path, err)
+			}
+			if entry == nil {
+				continue
+			}
+			if err := liveView.Put(ctx, entry); err != nil {
+				return nil, fmt.Errorf("failed to restore mount %q: %w", mountDiff.Path, err)
+			}
+		}
+		for _, key := range mountDiff.Removed {
+			if err := liveView.Delete(ctx, key); err != nil {
+				return nil, fmt.Errorf("failed to restore mount %q: %w", mountDiff.Path, err)
+			}
+		}
+
+		// The backend may cache what it read from storage, so reload it
+		if err := c.reloadMatchingPluginMounts(ctx, ns, []string{mountDiff.mountPath}); err != nil {
+			return nil, fmt.Errorf("failed to reload mount %q after restoring it: %w", mountDiff.mountPath, err)
+		}
+		c.logger.Info("restored mount from snapshot", "snapshot_id", id, "path", mountDiff.Path,
+			"added", len(mountDiff.Added), "changed", len(mountDiff.Changed), "removed", len(mountDiff.Removed))
+	}
+
+	for _, item := range diff.Policies {
+		if item.Status == snapshotDiffUnchanged {
+			continue
+		}
+		if err := c.policyStore.SetPolicy(ctx, diff.policies[item.Name]); err != nil {
+			return nil, fmt.Errorf("failed to restore policy %q: %w", item.Name, err)
+		}
+	}
+
+	if len(diff.Entities) > 0 {
+		if err := c.identityStore.restoreSnapshotEntities(ctx, diff); err != nil {
+			return nil, err
+		}
+	}
+	if len(diff.Groups) > 0 {
+		if err := c.identityStore.restoreSnapshotGroups(ctx, diff); err != nil {
+			return nil, err
+		}
+	}
+
+	return diff, nil
+}
+
+// parseStoredACLPolicy reads and parses the ACL policy from storage, without
+// going through the cache, so that it can be read from a loaded snapshot.
+func (ps *PolicyStore) parseStoredACLPolicy(ctx context.Context, ns *namespace.Namespace, name string) (*Policy, error) {
+	out, err := ps.getACLView(ns).Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	if out == nil {
+		return nil, nil
+	}
+
+	policyEntry := new(PolicyEntry)
+	if err := out.DecodeJSON(policyEntry); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	policy, err := ParseACLPolicy(ns, policyEntry.Raw, WithDenySlashInTemplatedPaths(ps.core.denySlashInTemplatedPolicyPaths))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	policy.Name = name
+	policy.Templated = policyEntry.Templated
+	return policy, nil
+}
+
+// snapshotEntity reads the entity from the storage of the identity store,
+// which is the snapshot if the context has one.
+func (i *IdentityStore) snapshotEntity(ctx context.Context, entityID string) (*identity.Entity, error) {
+	bucket, err := i.entityPacker.GetBucket(ctx, i.entityPacker.BucketKey(entityID))
+	if err != nil || bucket == nil {
+		return nil, err
+	}
+	for _, item := range bucket.Items {
+		if item.ID == entityID {
+			return i.parseCachedEntity(item)
+		}
+	}
+	return nil, nil
+}
+
+// snapshotGroup reads the group from the storage of the identity store, which
+// is the snapshot if the context has one.
+func (i *IdentityStore) snapshotGroup(ctx context.Context, groupID string) (*identity.Group, error) {
+	bucket, err := i.groupPacker.GetBucket(ctx, i.groupPacker.BucketKey(groupID))
+	if err != nil || bucket == nil {
+		return nil, err
+	}
+	for _, item := range bucket.Items {
+		if item.ID == groupID {
+			return i.parseGroupFromBucketItem(item)
+		}
+	}
+	return nil, nil
+}
+
+// restoreSnapshotEntities upserts the entities of the diff. Restoring an
+// entity never merges it with another one, so the restore fails if its name
+// or one of its aliases now belongs to a different entity.
+func (i *IdentityStore) restoreSnapshotEntities(ctx context.Context, diff *snapshotDiff) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for _, item := range diff.Entities {
+		if item.Status == snapshotDiffUnchanged {
+			continue
+		}
+		entity := diff.entities[item.Name]
+
+		existing, err := i.MemDBEntityByName(ctx, entity.Name, false)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != entity.ID {
+			return fmt.Errorf("failed to restore entity %q: its name %q belongs to entity %q", entity.ID, entity.Name, existing.ID)
+		}
+		for _, alias := range entity.Aliases {
+			existing, err := i.MemDBAliasByFactors(alias.MountAccessor, alias.Name, false, false)
+			if err != nil {
+				return err
+			}
+			if existing != nil && existing.CanonicalID != entity.ID {
+				return fmt.Errorf("failed to restore entity %q: its alias %q belongs to entity %q", entity.ID, alias.ID, existing.CanonicalID)
+			}
+		}
+
+		// Local aliases aren't stored with the entity, so keep the ones of the
+		// live entity
+		previous, err := i.MemDBEntityByID(entity.ID, true)
+		if err != nil {
+			return err
+		}
+		if previous != nil {
+			for _, alias := range previous.Aliases {
+				if alias.Local {
+					entity.UpsertAlias(alias)
+				}
+			}
+		}
+
+		if err := i.upsertEntity(ctx, entity, previous, true); err != nil {
+			return fmt.Errorf("failed to restore entity %q: %w", entity.ID, err)
+		}
+	}
+	return nil
+}
+
+// restoreSnapshotGroups upserts the groups of the diff. The restore fails if
+// the name or the alias of a group now belongs to a different group.
+func (i *IdentityStore) restoreSnapshotGroups(ctx context.Context, diff *snapshotDiff) error {
+	i.groupLock.Lock()
+	defer i.groupLock.Unlock()
+
+	for _, item := range diff.Groups {
+		if item.Status == snapshotDiffUnchanged {
+			continue
+		}
+		group := diff.groups[item.Name]
+
+		existing, err := i.MemDBGroupByName(ctx, group.Name, false)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != group.ID {
+			return fmt.Errorf("failed to restore group %q: its name %q belongs to group %q", group.ID, group.Name, existing.ID)
+		}
+		if group.Alias != nil {
+			existing, err := i.MemDBAliasByFactors(group.Alias.MountAccessor, group.Alias.Name, false, true)
+			if err != nil {
+				return err
+			}
+			if existing != nil && existing.CanonicalID != group.ID {
+				return fmt.Errorf("failed to restore group %q: its alias %q belongs to group %q", group.ID, group.Alias.ID, existing.CanonicalID)
+			}
+		}
+
+		if err := i.UpsertGroup(ctx, group, true); err != nil {
+			return fmt.Errorf("failed to restore group %q: %w", group.ID, err)
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package vault
 import (
 	"context"
 	"crypto/hmac"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -34,6 +35,7 @@ import (
 	"github.com/hashicorp/vault/http/priority"
 	"github.com/hashicorp/vault/internalshared/configutil"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/errutil"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
@@ -325,6 +327,27 @@ func (c *Core) fetchACLTokenEntryAndEntity(ctx context.Context, req *logical.Req
 		}
 	}
 
+	// Certificate-bound tokens may only be used over a TLS connection
+	// presenting the client certificate they were issued to
+	if te.BoundCertificateThumbprint != "" {
+		if req.Connection == nil || req.Connection.ConnState == nil || len(req.Connection.ConnState.PeerCertificates) == 0 {
+			c.logger.Warn("token is bound to a client certificate but no client certificate was presented")
+			return nil, nil, nil, nil, logical.ErrPermissionDenied
+		}
+		thumbprint := certutil.GetCertificateThumbprint(req.Connection.ConnState.PeerCertificates[0])
+		if subtle.ConstantTimeCompare([]byte(thumbprint), []byte(te.BoundCertificateThumbprint)) != 1 {
+			return nil, nil, nil, nil, logical.ErrPermissionDenied
+		}
+	}
+
+	// DPoP-bound tokens require a proof signed by the bound key with every
+	// request. The proof itself is verified by the HTTP layer.
+	if te.BoundDPoPKeyThumbprint != "" {
+		if subtle.ConstantTimeCompare([]byte(req.DPoPKeyThumbprint), []byte(te.BoundDPoPKeyThumbprint)) != 1 {
+			return nil, nil, nil, nil, logical.ErrPermissionDenied
+		}
+	}
+
 	policyNames := make(map[string][]string)
 	// Add tokens policies
 	policyNames[te.NamespaceID] = append(policyNames[te.NamespaceID], te.Policies...)
@@ -408,6 +431,10 @@ func (c *Core) fetchACLTokenEntryAndEntity(ctx context.Context, req *logical.Req
 		c.logger.Error("failed to construct ACL", "error", err)
 		return nil, nil, nil, nil, ErrInternalError
 	}
+	if err := c.scopeTokenACL(tokenCtx, tokenNS, te, acl); err != nil {
+		c.logger.Error("failed to construct ACL", "error", err)
+		return nil, nil, nil, nil, ErrInternalError
+	}
 
 	if actorEntity != nil {
 		newAcl, err := c.performDelegationTokenChecks(tokenCtx, acl, actorEntity, actorEntityPolicyNames)
@@ -1949,6 +1976,12 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 		var entity *identity.Entity
 		auth = resp.Auth
 
+		// Bind the token to the key that signed the DPoP proof of the login
+		// request, if any, independently of the auth method used
+		if req.DPoPKeyThumbprint != "" {
+			auth.BoundDPoPKeyThumbprint = req.DPoPKeyThumbprint
+		}
+
 		mEntry := c.router.MatchingMountEntry(ctx, req.Path)
 
 		if auth.Alias != nil &&
@@ -2341,6 +2374,11 @@ func (c *Core) LoginCreateToken(ctx context.Context, ns *namespace.Namespace, re
 		}
 	}
 
+	// Batch tokens are not persisted, so they can't carry a key binding
+	if auth.TokenType == logical.TokenTypeBatch && (auth.BoundCertificateThumbprint != "" || auth.BoundDPoPKeyThumbprint != "") {
+		return false, logical.ErrorResponse("batch tokens cannot be bound to a client certificate or DPoP key"), logical.ErrInvalidRequest
+	}
+
 	var registerFunc RegisterAuthFunc
 	var funcGetErr error
 	// Batch tokens should not be forwarded to perf standby
@@ -2771,19 +2809,21 @@ func (c *Core) RegisterAuth(ctx context.Context, tokenTTL time.Duration, path st
 		return err
 	}
 	te := logical.TokenEntry{
-		Path:           path,
-		Meta:           auth.Metadata,
-		DisplayName:    auth.DisplayName,
-		CreationTime:   time.Now().Unix(),
-		TTL:            tokenTTL,
-		NumUses:        auth.NumUses,
-		EntityID:       auth.EntityID,
-		BoundCIDRs:     auth.BoundCIDRs,
-		Policies:       auth.TokenPolicies,
-		NamespaceID:    ns.ID,
-		ExplicitMaxTTL: auth.ExplicitMaxTTL,
-		Period:         auth.Period,
-		Type:           auth.TokenType,
+		Path:                       path,
+		Meta:                       auth.Metadata,
+		DisplayName:                auth.DisplayName,
+		CreationTime:               time.Now().Unix(),
+		TTL:                        tokenTTL,
+		NumUses:                    auth.NumUses,
+		EntityID:                   auth.EntityID,
+		BoundCIDRs:                 auth.BoundCIDRs,
+		Policies:                   auth.TokenPolicies,
+		BoundCertificateThumbprint: auth.BoundCertificateThumbprint,
+		BoundDPoPKeyThumbprint:     auth.BoundDPoPKeyThumbprint,
+		NamespaceID:                ns.ID,
+		ExplicitMaxTTL:             auth.ExplicitMaxTTL,
+		Period:                     auth.Period,
+		Type:                       auth.TokenType,
 	}
 
 	if te.TTL == 0 && (len(te.Policies) != 1 || te.Policies[0] != "root") {
@@ -7,7 +7,7 @@ package vault
 
 import (
 	"context"
-	"errors"
+	"net/http"
 	"sync"
 	"time"
 
@@ -86,6 +86,15 @@ func forwardCreateTokenRegisterAuth(ctx context.Context, c *Core, te *logical.To
 	return nil, nil
 }
 
+// lockSnapshotForRequest keeps the snapshot the request reads from loaded
+// until the returned function is called.
 func (c *Core) lockSnapshotForRequest(ctx context.Context, req *logical.Request, entry *MountEntry) (func(), error) {
-	return nil, errors.New("loaded snapshots not supported")
+	if !snapshotReadableMount(entry) {
+		return nil, logical.CodedError(http.StatusBadRequest, "the mount does not support reading from loaded snapshots")
+	}
+	unlock, err := c.raftSnapshotLoad.acquire(req.RequiresSnapshotID)
+	if err != nil {
+		return nil, logical.CodedError(http.StatusNotFound, err.Error())
+	}
+	return unlock, nil
 }
@@ -0,0 +1,211 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package snapshots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-secure-stdlib/awsutil"
+)
+
+// Target is used to store snapshot data, such as the snapshots taken on a
+// schedule
+type Target interface {
+	// Put stores the snapshot read from r under the given name, and returns
+	// where it was stored
+	Put(ctx context.Context, name string, r io.Reader) (string, error)
+
+	// Delete removes the snapshot stored under the given name
+	Delete(ctx context.Context, name string) error
+
+	Type() string
+}
+
+var (
+	_ Target = (*localTarget)(nil)
+	_ Target = (*s3Target)(nil)
+)
+
+type localTarget struct {
+	dir string
+}
+
+// NewLocalTarget creates a new Target that stores snapshots as files in the
+// given directory, which must exist
+func NewLocalTarget(dir string) (Target, error) {
+	if dir == "" {
+		return nil, errors.New("directory is required")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", dir)
+	}
+	return &localTarget{dir: dir}, nil
+}
+
+func (t *localTarget) Type() string {
+	return "local"
+}
+
+func (t *localTarget) Put(ctx context.Context, name string, r io.Reader) (string, error) {
+	dest := filepath.Join(t.dir, name)
+
+	// Write to a temporary file first so that partial snapshots are never
+	// left under the final name
+	f, err := os.CreateTemp(t.dir, ".vault-snapshot-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, &ctxAwareReader{ctx: ctx, Reader: r}); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(f.Name(), dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+func (t *localTarget) Delete(_ context.Context, name string) error {
+	err := os.Remove(filepath.Join(t.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// S3TargetConfig configures a Target storing snapshots in an S3-compatible
+// object storage
+type S3TargetConfig struct {
+	Bucket          string
+	PathPrefix      string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	DisableTLS      bool
+	ForcePathStyle  bool
+	Logger          hclog.Logger
+}
+
+type s3Target struct {
+	bucket   string
+	prefix   string
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+// NewS3Target creates a new Target that stores snapshots as objects in an
+// S3-compatible object storage. Without static credentials, the default AWS
+// credential chain is used.
+func NewS3Target(config *S3TargetConfig) (Target, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+	region := config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	credsConfig := &awsutil.CredentialsConfig{
+		AccessKey:    config.AccessKeyID,
+		SecretKey:    config.SecretAccessKey,
+		SessionToken: config.SessionToken,
+		Logger:       config.Logger,
+	}
+	creds, err := credsConfig.GenerateCredentialChain()
+	if err != nil {
+		return nil, err
+	}
+
+	awsConfig := &aws.Config{
+		Credentials: creds,
+		HTTPClient: &http.Client{
+			Transport: cleanhttp.DefaultPooledTransport(),
+		},
+		Region:           aws.String(region),
+		S3ForcePathStyle: aws.Bool(config.ForcePathStyle),
+		DisableSSL:       aws.Bool(config.DisableTLS),
+	}
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Target{
+		bucket:   config.Bucket,
+		prefix:   config.PathPrefix,
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+	}, nil
+}
+
+func (t *s3Target) Type() string {
+	return "aws-s3"
+}
+
+func (t *s3Target) Put(ctx context.Context, name string, r io.Reader) (string, error) {
+	out, err := t.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(path.Join(t.prefix, name)),
+		Body:   r,
+	})
+	if err != nil {
+		return "", err
+	}
+	return out.Location, nil
+}
+
+func (t *s3Target) Delete(ctx context.Context, name string) error {
+	_, err := t.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(path.Join(t.prefix, name)),
+	})
+	return err
+}
+
+type ctxAwareReader struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (c *ctxAwareReader) Read(p []byte) (n int, err error) {
+	if c.ctx.Err() != nil {
+		return 0, c.ctx.Err()
+	}
+	return c.Reader.Read(p)
+}
@@ -0,0 +1,45 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package snapshots
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalTarget(t *testing.T) {
+	dir := t.TempDir()
+	target, err := NewLocalTarget(dir)
+	require.NoError(t, err)
+	require.Equal(t, "local", target.Type())
+
+	location, err := target.Put(context.Background(), "a.snap", strings.NewReader("snapshot"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "a.snap"), location)
+
+	data, err := os.ReadFile(location)
+	require.NoError(t, err)
+	require.Equal(t, "snapshot", string(data))
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Snapshots are stored in directories
+	_, err = NewLocalTarget(location)
+	require.Error(t, err)
+
+	require.NoError(t, target.Delete(context.Background(), "a.snap"))
+	require.NoFileExists(t, location)
+	require.NoError(t, target.Delete(context.Background(), "a.snap"))
+
+	_, err = NewLocalTarget(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
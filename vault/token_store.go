@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -197,6 +198,10 @@ func (ts *TokenStore) paths() []*framework.Path {
 			Type:        framework.TypeStringSlice,
 			Description: "List of policies for the token",
 		},
+		"inline_policy": {
+			Type:        framework.TypeString,
+			Description: "ACL policy, optionally base64 encoded, that limits the token to the requests it also allows on top of its other policies",
+		},
 	}
 
 	fieldsForCreateWithRole := map[string]*framework.FieldSchema{
@@ -667,6 +672,7 @@ func (ts *TokenStore) paths() []*framework.Path {
 
 	tokenutil.AddTokenFieldsWithAllowList(rolesPath.Fields, []string{"token_bound_cidrs", "token_explicit_max_ttl", "token_period", "token_type", "token_no_default_policy", "token_num_uses"})
 	p = append(p, rolesPath)
+	p = append(p, ts.exchangePath(commonFieldsForCreate))
 
 	return p
 }
@@ -1204,6 +1210,18 @@ func (ts *TokenStore) create(ctx context.Context, entry *logical.TokenEntry) err
 		return nil
 
 	case logical.TokenTypeBatch:
+		// Batch tokens are not persisted and their proto encoding carries no
+		// key bindings, so refuse to silently drop them
+		if entry.BoundCertificateThumbprint != "" {
+			return errors.New("batch tokens cannot be bound to a client certificate")
+		}
+		if entry.BoundDPoPKeyThumbprint != "" {
+			return errors.New("batch tokens cannot be bound to a DPoP key")
+		}
+		if len(entry.ScopePolicies) > 0 {
+			return errors.New("batch tokens cannot be down-scoped")
+		}
+
 		// Ensure fields we don't support/care about are nilled, proto marshal,
 		// encrypt, skip persistence
 		entry.ID = ""
@@ -1615,6 +1633,52 @@ func (ts *TokenStore) lookupBatchToken(ctx context.Context, id string) (*logical
 	return te, nil
 }
 
+// lookupForRestore returns the entry of a token, read from storage without the
+// expiration checks of lookupInternal. Those load, and may revoke, the lease
+// of the token, so they can't be run while leases are being restored.
+func (ts *TokenStore) lookupForRestore(ctx context.Context, id string) (*logical.TokenEntry, error) {
+	if IsBatchToken(id) {
+		return ts.lookupBatchTokenInternal(ctx, id)
+	}
+
+	if IsSSCToken(id) {
+		internalID, err := ts.core.DecodeSSCToken(id)
+		if err == nil && internalID != "" {
+			id = internalID
+		}
+	}
+
+	ns := namespace.RootNamespace
+	if _, nsID := namespace.SplitIDFromString(id); nsID != "" {
+		tokenNS, err := NamespaceByID(ctx, nsID, ts.core)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up namespace from the token: %w", err)
+		}
+		if tokenNS != nil {
+			ns = tokenNS
+		}
+	}
+	ctx = namespace.ContextWithNamespace(ctx, ns)
+
+	saltedID, err := ts.SaltID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := ts.idView(ns).Get(ctx, saltedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entry: %w", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	entry := new(logical.TokenEntry)
+	if err := jsonutil.DecodeJSON(raw.Value, entry); err != nil {
+		return nil, fmt.Errorf("failed to decode entry: %w", err)
+	}
+	return entry, nil
+}
+
 // lookupInternal is used to find a token given its (possibly salted) ID. If
 // tainted is true, entries that are in some revocation state (currently,
 // indicated by num uses < 0), the entry will be returned anyways
@@ -2737,6 +2801,26 @@ func (ts *TokenStore) handleCreateCommon(ctx context.Context, req *logical.Reque
 		if strutil.StrListContains(policies, "root") {
 			return logical.ErrorResponse("root tokens may not be created from a parent namespace"), logical.ErrInvalidRequest
 		}
+
+		// Scope policies are parsed in the namespace of the token, so they
+		// can't carry over to another one
+		if len(parent.ScopePolicies) > 0 {
+			return logical.ErrorResponse("down-scoped tokens cannot create tokens in a child namespace"), logical.ErrInvalidRequest
+		}
+	}
+
+	// The token is limited to the scopes of its parent, and to its inline
+	// policy if one is given, so that it can never be allowed more than its
+	// parent whatever its other policies are
+	scopePolicies := slices.Clone(parent.ScopePolicies)
+	if inlinePolicy := d.Get("inline_policy").(string); inlinePolicy != "" {
+		if polBytes, err := base64.StdEncoding.DecodeString(inlinePolicy); err == nil {
+			inlinePolicy = string(polBytes)
+		}
+		if _, err := parseScopePolicy(ns, inlinePolicy, WithDenySlashInTemplatedPaths(ts.core.denySlashInTemplatedPolicyPaths)); err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("invalid inline policy: %s", err)), logical.ErrInvalidRequest
+		}
+		scopePolicies = append(scopePolicies, inlinePolicy)
 	}
 
 	tokenType := logical.TokenTypeService
@@ -2879,12 +2963,13 @@ func (ts *TokenStore) handleCreateCommon(ctx context.Context, req *logical.Reque
 		// have an official mount
 		Path: fmt.Sprintf("auth/token/%s", req.Path),
 
-		Meta:         metaMap,
-		DisplayName:  "token",
-		NumUses:      numUses,
-		CreationTime: time.Now().Unix(),
-		NamespaceID:  ns.ID,
-		Type:         tokenType,
+		Meta:          metaMap,
+		DisplayName:   "token",
+		NumUses:       numUses,
+		CreationTime:  time.Now().Unix(),
+		NamespaceID:   ns.ID,
+		Type:          tokenType,
+		ScopePolicies: scopePolicies,
 	}
 
 	// If the role is not nil, we add the role name as part of the token's
@@ -3079,6 +3164,16 @@ func (ts *TokenStore) handleCreateCommon(ctx context.Context, req *logical.Reque
 			// Batch tokens cannot be revoked so we should never have root batch tokens
 			return logical.ErrorResponse("batch tokens cannot be root tokens"), nil
 		}
+
+		if len(te.ScopePolicies) > 0 {
+			return logical.ErrorResponse("root tokens cannot be down-scoped"), logical.ErrInvalidRequest
+		}
+	}
+
+	// Batch tokens don't carry scope policies, so they would not be limited
+	// by them
+	if len(te.ScopePolicies) > 0 && te.Type == logical.TokenTypeBatch {
+		return logical.ErrorResponse("batch tokens cannot be down-scoped"), nil
 	}
 
 	//
@@ -3132,6 +3227,17 @@ func (ts *TokenStore) handleCreateCommon(ctx context.Context, req *logical.Reque
 		}
 	}
 
+	// Tokens created by certificate- or DPoP-bound tokens are bound to the
+	// same key, orphans included, so that creating a token can't be used to
+	// escape the binding. Batch tokens cannot carry the binding.
+	if parent.BoundCertificateThumbprint != "" || parent.BoundDPoPKeyThumbprint != "" {
+		if te.Type == logical.TokenTypeBatch {
+			return logical.ErrorResponse("certificate- or DPoP-bound tokens cannot create batch tokens"), logical.ErrInvalidRequest
+		}
+		te.BoundCertificateThumbprint = parent.BoundCertificateThumbprint
+		te.BoundDPoPKeyThumbprint = parent.BoundDPoPKeyThumbprint
+	}
+
 	var explicitMaxTTLToUse time.Duration
 	if explicitMaxTTL != "" {
 		dur, err := parseutil.ParseDurationSecond(explicitMaxTTL)
@@ -3511,6 +3617,18 @@ func (ts *TokenStore) handleLookup(ctx context.Context, req *logical.Request, da
 		resp.Data["bound_cidrs"] = out.BoundCIDRs
 	}
 
+	if out.BoundCertificateThumbprint != "" {
+		resp.Data["bound_certificate_thumbprint"] = out.BoundCertificateThumbprint
+	}
+
+	if out.BoundDPoPKeyThumbprint != "" {
+		resp.Data["bound_dpop_key_thumbprint"] = out.BoundDPoPKeyThumbprint
+	}
+
+	if len(out.ScopePolicies) > 0 {
+		resp.Data["scope_policies"] = out.ScopePolicies
+	}
+
 	tokenNS, err := NamespaceByID(ctx, out.NamespaceID, ts.core)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
@@ -0,0 +1,184 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// The grant and token types of RFC 8693 token exchange requests. Vault tokens
+// are exchanged for Vault tokens, so only access tokens are supported.
+const (
+	tokenExchangeGrantType   = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenExchangeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// tokenExchangeCreateFields are the fields of token creation that a token
+// exchange request can also set.
+var tokenExchangeCreateFields = []string{"ttl", "explicit_max_ttl", "num_uses", "display_name", "meta"}
+
+func (ts *TokenStore) exchangePath(createFields map[string]*framework.FieldSchema) *framework.Path {
+	fields := map[string]*framework.FieldSchema{
+		"grant_type": {
+			Type:        framework.TypeString,
+			Default:     tokenExchangeGrantType,
+			Description: fmt.Sprintf("Grant type of the request, which must be %q", tokenExchangeGrantType),
+		},
+		"subject_token": {
+			Type:        framework.TypeString,
+			Description: "Token to exchange for a down-scoped child token. If given, it must be the token of the request.",
+		},
+		"subject_token_type": {
+			Type:        framework.TypeString,
+			Default:     tokenExchangeAccessToken,
+			Description: fmt.Sprintf("Type of the subject token, which must be %q", tokenExchangeAccessToken),
+		},
+		"requested_token_type": {
+			Type:        framework.TypeString,
+			Default:     tokenExchangeAccessToken,
+			Description: fmt.Sprintf("Type of the issued token, which must be %q", tokenExchangeAccessToken),
+		},
+		"resource": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "Paths, which can contain globs and segment wildcards as in policies, that the issued token is limited to",
+		},
+		"scope": {
+			Type:        framework.TypeString,
+			Default:     ReadCapability,
+			Description: "Space-delimited capabilities the issued token is limited to on the resource paths",
+		},
+		"inline_policy": {
+			Type:        framework.TypeString,
+			Description: "ACL policy, optionally base64 encoded, that limits the issued token instead of resource and scope",
+		},
+	}
+	for _, field := range tokenExchangeCreateFields {
+		fields[field] = createFields[field]
+	}
+
+	return &framework.Path{
+		Pattern: "exchange$",
+
+		Fields: fields,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "token",
+			OperationVerb:   "exchange",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: ts.handleExchange(createFields),
+		},
+
+		HelpSynopsis:    strings.TrimSpace(tokenExchangeHelp),
+		HelpDescription: strings.TrimSpace(tokenExchangeDesc),
+	}
+}
+
+// handleExchange handles the auth/token/exchange path, which exchanges a token
+// for a child token down-scoped to the given paths and capabilities, in the
+// style of RFC 8693.
+func (ts *TokenStore) handleExchange(createFields map[string]*framework.FieldSchema) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if grantType := d.Get("grant_type").(string); grantType != tokenExchangeGrantType {
+			return logical.ErrorResponse(fmt.Sprintf("unsupported grant type %q", grantType)), logical.ErrInvalidRequest
+		}
+		for _, field := range []string{"subject_token_type", "requested_token_type"} {
+			if tokenType := d.Get(field).(string); tokenType != tokenExchangeAccessToken {
+				return logical.ErrorResponse(fmt.Sprintf("unsupported %s %q", field, tokenType)), logical.ErrInvalidRequest
+			}
+		}
+
+		inlinePolicy := d.Get("inline_policy").(string)
+		resources := d.Get("resource").([]string)
+		var scope string
+		switch {
+		case inlinePolicy != "" && len(resources) > 0:
+			return logical.ErrorResponse("only one of inline_policy and resource can be given"), logical.ErrInvalidRequest
+
+		case len(resources) > 0:
+			capabilities := strings.Fields(d.Get("scope").(string))
+			if len(capabilities) == 0 {
+				return logical.ErrorResponse("scope must contain at least one capability"), logical.ErrInvalidRequest
+			}
+			scope = strings.Join(capabilities, " ")
+			inlinePolicy = tokenExchangePolicy(resources, capabilities)
+
+		case inlinePolicy == "":
+			return logical.ErrorResponse("one of inline_policy and resource must be given"), logical.ErrInvalidRequest
+		}
+
+		// The request-time checks of the token, such as its CIDRs, bindings
+		// and entity, have only been run on the token of the request, so no
+		// other token can be exchanged
+		if subjectToken := d.Get("subject_token").(string); subjectToken != "" && subjectToken != req.ClientToken {
+			return logical.ErrorResponse("subject_token must be the token of the request"), logical.ErrInvalidRequest
+		}
+
+		createData := &framework.FieldData{
+			Raw: map[string]interface{}{
+				"inline_policy": inlinePolicy,
+			},
+			Schema: createFields,
+		}
+		for _, field := range tokenExchangeCreateFields {
+			if raw, ok := d.Raw[field]; ok {
+				createData.Raw[field] = raw
+			}
+		}
+
+		resp, err := ts.handleCreateCommon(ctx, req, createData, false, nil)
+		if err != nil || resp == nil || resp.IsError() || resp.Auth == nil {
+			return resp, err
+		}
+
+		// The issued token itself is returned in the auth block, as for
+		// every token created by the token store
+		resp.Data = map[string]interface{}{
+			"issued_token_type": tokenExchangeAccessToken,
+			"token_type":        "Bearer",
+			"expires_in":        int64(resp.Auth.TTL.Seconds()),
+		}
+		if scope != "" {
+			resp.Data["scope"] = scope
+		}
+		return resp, nil
+	}
+}
+
+// tokenExchangePolicy returns the ACL policy granting the capabilities on the
+// resource paths.
+func tokenExchangePolicy(resources, capabilities []string) string {
+	quoted := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		quoted = append(quoted, strconv.Quote(capability))
+	}
+
+	var policy strings.Builder
+	for _, resource := range resources {
+		fmt.Fprintf(&policy, "path %s {\n  capabilities = [%s]\n}\n", strconv.Quote(resource), strings.Join(quoted, ", "))
+	}
+	return policy.String()
+}
+
+const (
+	tokenExchangeHelp = `Exchange a token for a child token down-scoped to some paths.`
+	tokenExchangeDesc = `
+This endpoint exchanges the token of the request, which is also the subject
+token if one is given, for a child token in the style of RFC 8693 token
+exchange. The child token is limited to the capabilities given as scope on the
+resource paths, or to the given inline policy, on top of the policies it
+inherits from the subject token. It is also limited to the scopes of the
+subject token, so it can never be allowed more than the subject token, and
+neither can its own children.
+
+The issued token is returned in the auth block of the response.
+`
+)
@@ -0,0 +1,227 @@
+// Copyright IBM Corp. 2016, 2025
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func testTokenStoreScopeSetup(t *testing.T) (*Core, *TokenStore, string) {
+	t.Helper()
+
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(context.Background())
+
+	policy, err := ParseACLPolicy(namespace.RootNamespace, `
+path "secret/*" {
+	capabilities = ["create", "read", "update", "list"]
+}
+`)
+	require.NoError(t, err)
+	policy.Name = "dev"
+	require.NoError(t, c.policyStore.SetPolicy(ctx, policy))
+
+	testMakeServiceTokenViaBackend(t, c.tokenStore, root, "parent", "", []string{"dev"})
+	return c, c.tokenStore, root
+}
+
+// testTokenStoreScopeRequest makes a token creating request to the token store,
+// and registers the created token as request handling does.
+func testTokenStoreScopeRequest(t *testing.T, ts *TokenStore, clientToken, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+
+	ctx := namespace.RootContext(context.Background())
+	req := logical.TestRequest(t, logical.UpdateOperation, path)
+	req.ClientToken = clientToken
+	req.Data = data
+	resp, err := ts.HandleRequest(ctx, req)
+	if resp != nil && resp.IsError() {
+		return resp
+	}
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	te := &logical.TokenEntry{
+		Path:        resp.Auth.CreationPath,
+		NamespaceID: namespace.RootNamespaceID,
+	}
+	require.NoError(t, ts.expiration.RegisterAuth(ctx, te, resp.Auth, ""))
+	return resp
+}
+
+func TestTokenStore_CreateToken_InlinePolicy(t *testing.T) {
+	c, ts, root := testTokenStoreScopeSetup(t)
+	ctx := namespace.RootContext(context.Background())
+
+	create := func(clientToken string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		return testTokenStoreScopeRequest(t, ts, clientToken, "create", data)
+	}
+	capabilities := func(token, path string) []string {
+		t.Helper()
+		capabilities, err := c.Capabilities(ctx, token, path)
+		require.NoError(t, err)
+		return capabilities
+	}
+
+	// The child is limited to the intersection of its inline policy and the
+	// policies it inherits from its parent
+	resp := create("parent", map[string]interface{}{
+		"inline_policy": `
+path "secret/app/*" {
+	capabilities = ["read", "delete"]
+}
+path "sys/mounts" {
+	capabilities = ["read"]
+}
+`,
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	child := resp.Auth.ClientToken
+	require.Equal(t, []string{"read"}, capabilities(child, "secret/app/config"))
+	require.Equal(t, []string{DenyCapability}, capabilities(child, "secret/other"))
+	require.Equal(t, []string{DenyCapability}, capabilities(child, "sys/mounts"))
+
+	te, err := ts.Lookup(ctx, child)
+	require.NoError(t, err)
+	require.Equal(t, []string{"default", "dev"}, te.Policies)
+	require.Len(t, te.ScopePolicies, 1)
+
+	req := logical.TestRequest(t, logical.ReadOperation, "lookup-self")
+	req.ClientToken = child
+	resp, err = ts.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, te.ScopePolicies, resp.Data["scope_policies"])
+
+	// The child can be checked against the request it makes
+	allowed, err := c.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "sys/mounts",
+		ClientToken: child,
+	})
+	require.ErrorIs(t, err, logical.ErrPermissionDenied, allowed)
+
+	// Children of a down-scoped token inherit its scope, and narrow it further
+	resp = create(child, nil)
+	require.False(t, resp.IsError(), resp.Error())
+	grandchild := resp.Auth.ClientToken
+	require.Equal(t, []string{DenyCapability}, capabilities(grandchild, "secret/other"))
+	require.Equal(t, []string{"read"}, capabilities(grandchild, "secret/app/config"))
+
+	resp = create(child, map[string]interface{}{
+		"inline_policy": `path "secret/app/db" { capabilities = ["read", "update"] }`,
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	te, err = ts.Lookup(ctx, resp.Auth.ClientToken)
+	require.NoError(t, err)
+	require.Len(t, te.ScopePolicies, 2)
+	require.Equal(t, []string{"read"}, capabilities(resp.Auth.ClientToken, "secret/app/db"))
+	require.Equal(t, []string{DenyCapability}, capabilities(resp.Auth.ClientToken, "secret/app/config"))
+
+	// Even with sudo, the children of a down-scoped token stay within scope
+	resp = create(child, map[string]interface{}{"no_parent": true})
+	require.True(t, resp.IsError())
+
+	// Invalid inline policies and token types are rejected
+	for name, data := range map[string]map[string]interface{}{
+		"invalid":   {"inline_policy": `path "secret/app/*" { capabilities = ["bogus"] }`},
+		"templated": {"inline_policy": `path "secret/{{identity.entity.id}}/*" { capabilities = ["read"] }`},
+		"batch":     {"inline_policy": `path "secret/app/*" { capabilities = ["read"] }`, "type": "batch"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := create("parent", data)
+			require.NotNil(t, resp)
+			require.True(t, resp.IsError())
+		})
+	}
+
+	// Root tokens can't be down-scoped
+	resp = create(root, map[string]interface{}{
+		"inline_policy": `path "secret/app/*" { capabilities = ["read"] }`,
+	})
+	require.True(t, resp.IsError())
+	require.Contains(t, resp.Error().Error(), "root tokens cannot be down-scoped")
+
+	resp = create(root, map[string]interface{}{
+		"policies":      []string{"dev"},
+		"inline_policy": `path "secret/app/*" { capabilities = ["read"] }`,
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	require.Equal(t, []string{DenyCapability}, capabilities(resp.Auth.ClientToken, "secret/other"))
+}
+
+func TestTokenStore_Exchange(t *testing.T) {
+	c, ts, root := testTokenStoreScopeSetup(t)
+	ctx := namespace.RootContext(context.Background())
+
+	exchange := func(clientToken string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		return testTokenStoreScopeRequest(t, ts, clientToken, "exchange", data)
+	}
+
+	resp := exchange("parent", map[string]interface{}{
+		"grant_type": tokenExchangeGrantType,
+		"resource":   []string{"secret/jobs/42/*"},
+		"scope":      "read list",
+		"ttl":        "10m",
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	require.Equal(t, tokenExchangeAccessToken, resp.Data["issued_token_type"])
+	require.Equal(t, "read list", resp.Data["scope"])
+	require.Equal(t, int64((10 * time.Minute).Seconds()), resp.Data["expires_in"])
+
+	token := resp.Auth.ClientToken
+	capabilities, err := c.Capabilities(ctx, token, "secret/jobs/42/config")
+	require.NoError(t, err)
+	require.Equal(t, []string{"list", "read"}, capabilities)
+	capabilities, err = c.Capabilities(ctx, token, "secret/jobs/43/config")
+	require.NoError(t, err)
+	require.Equal(t, []string{DenyCapability}, capabilities)
+
+	te, err := ts.Lookup(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, "parent", te.Parent)
+
+	// The subject token is the parent of the issued token, and must be the
+	// token of the request
+	resp = exchange(root, map[string]interface{}{
+		"subject_token": "parent",
+		"resource":      "secret/a",
+	})
+	require.True(t, resp.IsError())
+	resp = exchange("parent", map[string]interface{}{
+		"subject_token": "parent",
+		"inline_policy": `path "secret/jobs/*" { capabilities = ["create", "sudo"] }`,
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	require.NotContains(t, resp.Data, "scope")
+	te, err = ts.Lookup(ctx, resp.Auth.ClientToken)
+	require.NoError(t, err)
+	require.Equal(t, "parent", te.Parent)
+	capabilities, err = c.Capabilities(ctx, resp.Auth.ClientToken, "secret/jobs/42/config")
+	require.NoError(t, err)
+	require.Equal(t, []string{"create"}, capabilities)
+
+	for name, data := range map[string]map[string]interface{}{
+		"grant_type":   {"grant_type": "client_credentials", "resource": "secret/a"},
+		"token_type":   {"requested_token_type": "urn:ietf:params:oauth:token-type:jwt", "resource": "secret/a"},
+		"no_scope":     {},
+		"both":         {"resource": "secret/a", "inline_policy": `path "secret/a" { capabilities = ["read"] }`},
+		"empty_scope":  {"resource": "secret/a", "scope": " "},
+		"bad_scope":    {"resource": "secret/a", "scope": "read bogus"},
+		"root_subject": {"subject_token": root, "resource": "secret/a"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := exchange("parent", data)
+			require.NotNil(t, resp)
+			require.True(t, resp.IsError())
+		})
+	}
+}
@@ -5,9 +5,16 @@ package vault
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"path"
 	"reflect"
 	"sort"
@@ -28,6 +35,7 @@ import (
 	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/helper/metricsutil"
 	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/helper/tokenutil"
@@ -6176,3 +6184,119 @@ func TestTokenStore_Collectors(t *testing.T) {
 	// Need to set up router for this to work, TODO
 	// ts.gaugeCollectorByMethod( ctx )
 }
+
+func TestTokenStore_CertificateBoundToken(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	newCert := func() *x509.Certificate {
+		t.Helper()
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "client"},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	bound, other := newCert(), newCert()
+	connection := func(cert *x509.Certificate) *logical.Connection {
+		if cert == nil {
+			return &logical.Connection{}
+		}
+		return &logical.Connection{ConnState: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}
+	}
+
+	// Issue a certificate-bound token from the root token
+	te := &logical.TokenEntry{
+		Path:                       "auth/token/create",
+		Policies:                   []string{"root"},
+		NamespaceID:                namespace.RootNamespaceID,
+		BoundCertificateThumbprint: certutil.GetCertificateThumbprint(bound),
+	}
+	if err := c.tokenStore.create(ctx, te); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, cert := range map[string]*x509.Certificate{"no certificate": nil, "other certificate": other} {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.HandleRequest(ctx, &logical.Request{
+				Operation:   logical.ReadOperation,
+				Path:        "auth/token/lookup-self",
+				ClientToken: te.ID,
+				Connection:  connection(cert),
+			})
+			if !errors.Is(err, logical.ErrPermissionDenied) {
+				t.Fatalf("expected permission denied, got %v", err)
+			}
+		})
+	}
+
+	resp, err := c.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "auth/token/lookup-self",
+		ClientToken: te.ID,
+		Connection:  connection(bound),
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	// Batch tokens can't carry the binding, so they can't be created
+	resp, err = c.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.UpdateOperation,
+		Path:        "auth/token/create",
+		ClientToken: te.ID,
+		Connection:  connection(bound),
+		Data: map[string]interface{}{
+			"type":     "batch",
+			"policies": "default",
+		},
+	})
+	if err == nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error creating a batch token, got %#v", resp)
+	}
+
+	// Orphans keep the binding
+	resp, err = c.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.UpdateOperation,
+		Path:        "auth/token/create-orphan",
+		ClientToken: te.ID,
+		Connection:  connection(bound),
+		Data: map[string]interface{}{
+			"policies": "default",
+		},
+	})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+	child, err := c.tokenStore.Lookup(ctx, resp.Auth.ClientToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.Parent != "" || child.BoundCertificateThumbprint != te.BoundCertificateThumbprint {
+		t.Fatalf("expected a bound orphan token, got %#v", child)
+	}
+
+	resp, err = c.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "auth/token/lookup-self",
+		ClientToken: child.ID,
+		Connection:  connection(other),
+	})
+	if !errors.Is(err, logical.ErrPermissionDenied) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+}